@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -130,12 +131,80 @@ type API struct {
 	tpool    modules.TransactionPool
 	wallet   modules.Wallet
 
-	router http.Handler
+	// router serves requests that carry the required user agent, and
+	// corsRouter serves requests from allowed cross-origin clients, which
+	// cannot set the User-Agent header.
+	router     http.Handler
+	corsRouter http.Handler
+
+	// corsOrigins and corsMethods control which cross-origin requests are
+	// permitted. By default, no origins are allowed.
+	corsOrigins []string
+	corsMethods []string
+	corsMu      sync.RWMutex
 }
 
+// defaultCORSMethods are the methods allowed for cross-origin requests if
+// SetCORS is called without any methods.
+var defaultCORSMethods = []string{"GET", "POST"}
+
 // api.ServeHTTP implements the http.Handler interface.
 func (api *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	api.router.ServeHTTP(w, r)
+	if !api.setCORSHeaders(w, r) {
+		api.router.ServeHTTP(w, r)
+		return
+	}
+	if r.Method == "OPTIONS" {
+		// Preflight requests are answered before reaching the router.
+		WriteSuccess(w)
+		return
+	}
+	// Browsers do not allow scripts to set the User-Agent header, so
+	// requests from allowed origins are exempt from the user agent check.
+	// The check exists to stop arbitrary websites from using the API through
+	// the user's browser, and the browser-supplied Origin header already
+	// identifies the website making the request.
+	api.corsRouter.ServeHTTP(w, r)
+}
+
+// SetCORS sets the origins and methods that browser-based clients are allowed
+// to use for cross-origin requests. An origin of "*" allows any origin. If
+// methods is empty, GET and POST are allowed. Calling SetCORS with no origins
+// disables cross-origin requests, which is the default.
+//
+// Requests from allowed origins do not need to carry the required user
+// agent, so any website allowed here can use the API through a visiting
+// user's browser, subject to the API password. siad refuses to allow
+// cross-origin requests unless the API password is set.
+func (api *API) SetCORS(origins []string, methods []string) {
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	api.corsMu.Lock()
+	defer api.corsMu.Unlock()
+	api.corsOrigins = append([]string(nil), origins...)
+	api.corsMethods = append([]string(nil), methods...)
+}
+
+// setCORSHeaders adds the CORS headers to w if the origin of r is allowed,
+// and reports whether it did so.
+func (api *API) setCORSHeaders(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	api.corsMu.RLock()
+	defer api.corsMu.RUnlock()
+	for _, allowed := range api.corsOrigins {
+		if allowed == origin || allowed == "*" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(api.corsMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Add("Vary", "Origin")
+			return true
+		}
+	}
+	return false
 }
 
 // New creates a new Sia API from the provided modules.  The API will require
@@ -161,9 +230,15 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 	// Consensus API Calls
 	if api.cs != nil {
 		router.GET("/consensus", api.consensusHandler)
+		router.GET("/consensus/blocks", api.consensusBlocksRangeHandler)
+		router.GET("/consensus/blocks/:height", api.consensusBlocksHandler)
 		router.POST("/consensus/validate/transactionset", api.consensusValidateTransactionsetHandler)
 	}
 
+	// Debug API Calls
+	router.GET("/debug/goroutines", RequirePassword(api.debugGoroutinesHandler, requiredPassword))
+	router.GET("/debug/locks", RequirePassword(api.debugLocksHandler, requiredPassword))
+
 	// Explorer API Calls
 	if api.explorer != nil {
 		router.GET("/explorer", api.explorerHandler)
@@ -208,6 +283,7 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		router.GET("/renter", api.renterHandlerGET)
 		router.POST("/renter", RequirePassword(api.renterHandlerPOST, requiredPassword))
 		router.GET("/renter/contracts", api.renterContractsHandler)
+		router.GET("/renter/contracts/health", api.renterContractsHealthHandler)
 		router.GET("/renter/downloads", api.renterDownloadsHandler)
 		router.GET("/renter/files", api.renterFilesHandler)
 		router.GET("/renter/prices", api.renterPricesHandler)
@@ -248,11 +324,19 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		router.GET("/wallet/address", RequirePassword(api.walletAddressHandler, requiredPassword))
 		router.GET("/wallet/addresses", api.walletAddressesHandler)
 		router.GET("/wallet/backup", RequirePassword(api.walletBackupHandler, requiredPassword))
+		router.GET("/wallet/contacts", api.walletContactsHandler)
+		router.POST("/wallet/contacts/add", RequirePassword(api.walletContactsAddHandler, requiredPassword))
+		router.POST("/wallet/contacts/remove", RequirePassword(api.walletContactsRemoveHandler, requiredPassword))
 		router.POST("/wallet/init", RequirePassword(api.walletInitHandler, requiredPassword))
 		router.POST("/wallet/init/seed", RequirePassword(api.walletInitSeedHandler, requiredPassword))
+		router.GET("/wallet/kdf", api.walletKDFHandlerGET)
+		router.POST("/wallet/kdf", RequirePassword(api.walletKDFHandlerPOST, requiredPassword))
+		router.GET("/wallet/largesendthreshold", api.walletLargeSendThresholdHandlerGET)
+		router.POST("/wallet/largesendthreshold", RequirePassword(api.walletLargeSendThresholdHandlerPOST, requiredPassword))
 		router.POST("/wallet/lock", RequirePassword(api.walletLockHandler, requiredPassword))
 		router.POST("/wallet/seed", RequirePassword(api.walletSeedHandler, requiredPassword))
 		router.GET("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
+		router.POST("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
 		router.POST("/wallet/siacoins", RequirePassword(api.walletSiacoinsHandler, requiredPassword))
 		router.POST("/wallet/siafunds", RequirePassword(api.walletSiafundsHandler, requiredPassword))
 		router.POST("/wallet/siagkey", RequirePassword(api.walletSiagkeyHandler, requiredPassword))
@@ -267,6 +351,7 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 
 	// Apply UserAgent middleware and return the API
 	api.router = RequireUserAgent(router, requiredUserAgent)
+	api.corsRouter = router
 	return api
 }
 
@@ -300,6 +385,23 @@ func WriteJSON(w http.ResponseWriter, obj interface{}) {
 	}
 }
 
+// checkETag sets the ETag header of the response to the quoted tag. If the
+// request's If-None-Match header contains the tag, a 304 Not Modified response
+// is written and true is returned, in which case the caller should not write
+// a response body.
+func checkETag(w http.ResponseWriter, req *http.Request, tag string) bool {
+	etag := `"` + tag + `"`
+	w.Header().Set("ETag", etag)
+	for _, match := range strings.Split(req.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
 // WriteSuccess writes the HTTP header with status 204 No Content to the
 // ResponseWriter. WriteSuccess should only be used to indicate that the
 // requested action succeeded AND there is no data to return.
@@ -2,13 +2,19 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/julienschmidt/httprouter"
 )
 
+// maxConsensusBlocksRange is the maximum number of blocks that can be
+// requested in a single call to /consensus/blocks.
+const maxConsensusBlocksRange = 100
+
 // ConsensusGET contains general information about the consensus set, with tags
 // to support idiomatic json encodings.
 type ConsensusGET struct {
@@ -19,6 +25,20 @@ type ConsensusGET struct {
 	Difficulty   types.Currency    `json:"difficulty"`
 }
 
+// ConsensusBlocksGET contains a block found in the consensus set, along with
+// its id and height.
+type ConsensusBlocksGET struct {
+	ID     types.BlockID     `json:"id"`
+	Height types.BlockHeight `json:"height"`
+	Block  types.Block       `json:"block"`
+}
+
+// ConsensusBlocksRangeGET contains a range of consecutive blocks found in the
+// consensus set.
+type ConsensusBlocksRangeGET struct {
+	Blocks []ConsensusBlocksGET `json:"blocks"`
+}
+
 // consensusHandler handles the API calls to /consensus.
 func (api *API) consensusHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	cbid := api.cs.CurrentBlock().ID()
@@ -32,6 +52,83 @@ func (api *API) consensusHandler(w http.ResponseWriter, req *http.Request, _ htt
 	})
 }
 
+// consensusBlocksHandler handles the API calls to /consensus/blocks/:height.
+// The ETag of the response is the id of the block, allowing clients to skip
+// downloading a block that they already have.
+func (api *API) consensusBlocksHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var height types.BlockHeight
+	_, err := fmt.Sscan(ps.ByName("height"), &height)
+	if err != nil {
+		WriteError(w, Error{"unable to parse height: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	block, exists := api.cs.BlockAtHeight(height)
+	if !exists {
+		WriteError(w, Error{"no block found at input height in call to /consensus/blocks"}, http.StatusBadRequest)
+		return
+	}
+	id := block.ID()
+	if checkETag(w, req, id.String()) {
+		return
+	}
+	WriteJSON(w, ConsensusBlocksGET{
+		ID:     id,
+		Height: height,
+		Block:  block,
+	})
+}
+
+// consensusBlocksRangeHandler handles the API calls to /consensus/blocks. It
+// returns the blocks from start to end, inclusive. If end is beyond the
+// current height, the range stops at the current block.
+func (api *API) consensusBlocksRangeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	startStr, endStr := req.FormValue("start"), req.FormValue("end")
+	if startStr == "" || endStr == "" {
+		WriteError(w, Error{"start and end must be provided to a /consensus/blocks call"}, http.StatusBadRequest)
+		return
+	}
+	start, err := strconv.ParseUint(startStr, 10, 64)
+	if err != nil {
+		WriteError(w, Error{"parsing integer value for parameter `start` failed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	end, err := strconv.ParseUint(endStr, 10, 64)
+	if err != nil {
+		WriteError(w, Error{"parsing integer value for parameter `end` failed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if end < start {
+		WriteError(w, Error{"end cannot be less than start"}, http.StatusBadRequest)
+		return
+	}
+	if end-start >= maxConsensusBlocksRange {
+		WriteError(w, Error{fmt.Sprintf("range of %v blocks exceeds the maximum of %v", end-start+1, maxConsensusBlocksRange)}, http.StatusBadRequest)
+		return
+	}
+	if height := uint64(api.cs.Height()); start > height {
+		WriteError(w, Error{"no block found at start height in call to /consensus/blocks"}, http.StatusBadRequest)
+		return
+	} else if end > height {
+		end = height
+	}
+
+	blocks := make([]ConsensusBlocksGET, 0, end-start+1)
+	for height := types.BlockHeight(start); height <= types.BlockHeight(end); height++ {
+		block, exists := api.cs.BlockAtHeight(height)
+		if !exists {
+			// The chain may have been reorganized to a lower height during
+			// the call.
+			break
+		}
+		blocks = append(blocks, ConsensusBlocksGET{
+			ID:     block.ID(),
+			Height: height,
+			Block:  block,
+		})
+	}
+	WriteJSON(w, ConsensusBlocksRangeGET{Blocks: blocks})
+}
+
 // consensusValidateTransactionsetHandler handles the API calls to
 // /consensus/validate/transactionset.
 func (api *API) consensusValidateTransactionsetHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
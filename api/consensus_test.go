@@ -2,7 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/types"
@@ -109,3 +113,134 @@ func TestConsensusValidateTransactionSet(t *testing.T) {
 		t.Fatal("expected validation error")
 	}
 }
+
+// TestIntegrationConsensusBlocksETag probes the GET call to
+// /consensus/blocks/:height, checking that conditional requests are honored.
+func TestIntegrationConsensusBlocksETag(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var cbg ConsensusBlocksGET
+	if err = st.getAPI("/consensus/blocks/1", &cbg); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := st.server.api.cs.BlockAtHeight(1)
+	if cbg.ID != block.ID() || cbg.Height != 1 || cbg.Block.ID() != block.ID() {
+		t.Fatal("wrong block returned in consensus blocks GET call")
+	}
+
+	// get performs a GET request for the block at height 1 with the supplied
+	// If-None-Match header.
+	get := func(ifNoneMatch string) (*http.Response, []byte) {
+		req, err := http.NewRequest("GET", "http://"+st.server.listener.Addr().String()+"/consensus/blocks/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("User-Agent", "Sia-Agent")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, body
+	}
+
+	resp, body := get("")
+	etag := resp.Header.Get("ETag")
+	if etag != `"`+block.ID().String()+`"` {
+		t.Fatal("wrong ETag:", etag)
+	}
+	if resp.StatusCode != http.StatusOK || len(body) == 0 {
+		t.Fatal("expected full response, got status", resp.StatusCode)
+	}
+
+	// A matching If-None-Match should return 304 with no body.
+	resp, body = get(etag)
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatal("expected 304, got", resp.StatusCode)
+	}
+	if len(body) != 0 {
+		t.Fatal("304 response should not have a body")
+	}
+
+	// A stale If-None-Match should return the full block.
+	stale := `"` + types.BlockID{}.String() + `"`
+	resp, body = get(stale)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("expected 200, got", resp.StatusCode)
+	}
+	var cbg2 ConsensusBlocksGET
+	if err := json.Unmarshal(body, &cbg2); err != nil {
+		t.Fatal(err)
+	}
+	if cbg2.ID != block.ID() {
+		t.Fatal("wrong block returned for stale ETag")
+	}
+}
+
+// TestIntegrationConsensusBlocksRange probes the GET call to /consensus/blocks,
+// checking that a range of blocks is returned and that oversized ranges are
+// rejected.
+func TestIntegrationConsensusBlocksRange(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var cbrg ConsensusBlocksRangeGET
+	if err = st.getAPI("/consensus/blocks?start=1&end=3", &cbrg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cbrg.Blocks) != 3 {
+		t.Fatal("expected 3 blocks, got", len(cbrg.Blocks))
+	}
+	for i, cbg := range cbrg.Blocks {
+		height := types.BlockHeight(i + 1)
+		block, _ := st.server.api.cs.BlockAtHeight(height)
+		if cbg.Height != height || cbg.ID != block.ID() || cbg.Block.ID() != block.ID() {
+			t.Fatal("wrong block returned at height", height)
+		}
+	}
+
+	// A range that extends beyond the current block stops at the current
+	// block.
+	height := st.server.api.cs.Height()
+	err = st.getAPI(fmt.Sprintf("/consensus/blocks?start=%v&end=%v", height-1, height+10), &cbrg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cbrg.Blocks) != 2 || cbrg.Blocks[1].ID != st.server.api.cs.CurrentBlock().ID() {
+		t.Fatal("range beyond the current block was not truncated")
+	}
+
+	// Oversized and invalid ranges are rejected.
+	err = st.getAPI(fmt.Sprintf("/consensus/blocks?start=0&end=%v", maxConsensusBlocksRange), &cbrg)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum") {
+		t.Fatal("expected oversized range to be rejected, got", err)
+	}
+	if err = st.getAPI("/consensus/blocks?start=3&end=1", &cbrg); err == nil {
+		t.Fatal("expected error for end before start")
+	}
+	if err = st.getAPI("/consensus/blocks?start=1", &cbrg); err == nil {
+		t.Fatal("expected error for missing end")
+	}
+}
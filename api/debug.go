@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"runtime/pprof"
+
+	"github.com/NebulousLabs/Sia/sync"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// DebugLocksGET contains the locks that are currently held by the modules.
+type DebugLocksGET struct {
+	Locks []sync.HeldLock `json:"locks"`
+}
+
+// debugGoroutinesHandler handles the API call that dumps the stacks of all
+// running goroutines.
+func (api *API) debugGoroutinesHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// debugLocksHandler handles the API call that lists the module locks that are
+// currently held, along with the call stacks that acquired them.
+func (api *API) debugLocksHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	locks := sync.HeldLocks()
+	if locks == nil {
+		locks = []sync.HeldLock{}
+	}
+	WriteJSON(w, DebugLocksGET{Locks: locks})
+}
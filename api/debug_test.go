@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/sync"
+)
+
+// TestDebugEndpoints checks that the debug endpoints require authentication,
+// that /debug/goroutines dumps the goroutine stacks, and that /debug/locks
+// reports a lock that is held while another goroutine waits on it.
+func TestDebugEndpoints(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createAuthenticatedServerTester(t.Name(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+	baseURL := "http://" + st.server.listener.Addr().String()
+
+	// Unauthenticated calls should fail.
+	for _, call := range []string{"/debug/goroutines", "/debug/locks"} {
+		resp, err := HttpGET(baseURL + call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("unauthenticated call to %v returned %v", call, resp.StatusCode)
+		}
+	}
+
+	// The goroutine dump should include the stack of this test.
+	resp, err := HttpGETAuthenticated(baseURL+"/debug/goroutines", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump) == 0 || !strings.Contains(string(dump), "goroutine") {
+		t.Fatal("goroutine dump is empty")
+	} else if !strings.Contains(string(dump), "TestDebugEndpoints") {
+		t.Fatal("goroutine dump does not contain the test goroutine")
+	}
+
+	// Hold a lock while another goroutine tries to acquire it.
+	mu := sync.New(time.Minute, 0)
+	id := mu.Lock()
+	acquired := make(chan struct{})
+	go func() {
+		mu.Unlock(mu.Lock())
+		close(acquired)
+	}()
+	defer func() {
+		mu.Unlock(id)
+		<-acquired
+	}()
+
+	resp, err = HttpGETAuthenticated(baseURL+"/debug/locks", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dlg DebugLocksGET
+	err = json.NewDecoder(resp.Body).Decode(&dlg)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, l := range dlg.Locks {
+		if len(l.Callers) > 0 && strings.Contains(l.Callers[0], "debug_test.go") && !l.Read {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("held lock was not reported:", dlg.Locks)
+	}
+}
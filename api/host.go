@@ -107,7 +107,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.BlockHeight
 		_, err := fmt.Sscan(req.FormValue("maxduration"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, err
 		}
 		settings.MaxDuration = x
 	}
@@ -119,6 +119,14 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.MaxReviseBatchSize = x
 	}
+	if req.FormValue("minduration") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("minduration"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MinDuration = x
+	}
 	if req.FormValue("netaddress") != "" {
 		var x modules.NetAddress
 		_, err := fmt.Sscan(req.FormValue("netaddress"), &x)
@@ -250,7 +258,12 @@ func (api *API) hostHandlerPOST(w http.ResponseWriter, req *http.Request, _ http
 		return
 	}
 
-	err = api.host.SetInternalSettings(settings)
+	// If this is a dry run, only check that the settings are consistent.
+	if req.FormValue("dryrun") == "true" {
+		err = api.host.ValidateSettings(settings)
+	} else {
+		err = api.host.SetInternalSettings(settings)
+	}
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
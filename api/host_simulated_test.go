@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/host"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestHostSimulatedStorage checks that a renter can upload to and download from
+// a host that uses simulated storage, and that /host/storage reports the
+// simulated storage that is in use.
+func TestHostSimulatedStorage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Give the host simulated storage instead of a storage folder.
+	capacity := modules.SectorSize * 64
+	newSimulatedHost := func(cs modules.ConsensusSet, tp modules.TransactionPool, w modules.Wallet, addr string, dir string) (*host.Host, error) {
+		return host.NewSimulated(cs, tp, w, addr, dir, capacity)
+	}
+	st, err := assembleServerTesterWithHost(crypto.GenerateTwofishKey(), build.TempDir("api", t.Name()), newSimulatedHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+	for i := types.BlockHeight(0); i <= types.MaturityDelay; i++ {
+		if _, err := st.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.announceHost(); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.acceptContracts(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set an allowance for the renter, allowing a contract to be formed.
+	allowanceValues := url.Values{}
+	allowanceValues.Set("funds", testFunds)
+	allowanceValues.Set("period", testPeriod)
+	if err = st.stdPostAPI("/renter", allowanceValues); err != nil {
+		t.Fatal(err)
+	}
+
+	// Upload a file and wait for it to become available.
+	path := filepath.Join(st.dir, "test.dat")
+	if err := createRandFile(path, 1024); err != nil {
+		t.Fatal(err)
+	}
+	uploadValues := url.Values{}
+	uploadValues.Set("source", path)
+	uploadValues.Set("datapieces", "1")
+	uploadValues.Set("paritypieces", "1")
+	if err := st.stdPostAPI("/renter/upload/test", uploadValues); err != nil {
+		t.Fatal(err)
+	}
+	err = retry(200, 100*time.Millisecond, func() error {
+		var rf RenterFiles
+		st.getAPI("/renter/files", &rf)
+		if len(rf.Files) != 1 || !rf.Files[0].Available {
+			return errors.New("file is not available")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The data should be accounted for in the simulated storage folder.
+	var sg StorageGET
+	if err := st.getAPI("/host/storage", &sg); err != nil {
+		t.Fatal(err)
+	}
+	if len(sg.Folders) != 1 || sg.Folders[0].Capacity != capacity {
+		t.Fatal("host is not reporting its simulated storage:", sg.Folders)
+	}
+	if used := sg.Folders[0].Capacity - sg.Folders[0].CapacityRemaining; used != modules.SectorSize {
+		t.Fatalf("expected used capacity to be the size of one sector (%v bytes), got %v bytes", modules.SectorSize, used)
+	}
+
+	// Download the file and compare it to the original.
+	resp, err := HttpGET("http://" + st.server.listener.Addr().String() + "/renter/download/test?httpresp=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	downloaded, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(downloaded, original) {
+		t.Fatal("downloaded file does not match the original")
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -1002,3 +1004,134 @@ func TestDeleteNonexistentSector(t *testing.T) {
 		t.Fatalf("expected error to be %v; got %v", crypto.ErrHashWrongLen, err)
 	}
 }
+
+// TestContractBandwidthReport checks that the host tracks the bytes uploaded
+// and downloaded under each contract, along with the contract revenue.
+func TestContractBandwidthReport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, path := setupTestDownload(t, int(modules.SectorSize), "test.dat", true)
+	defer func() {
+		st.server.panicClose()
+		os.Remove(path)
+	}()
+
+	// report sums the host's bandwidth report over the renter's contracts.
+	report := func() (uploaded, downloaded uint64, revenue types.Currency) {
+		for _, c := range st.renter.Contracts() {
+			ul, dl, rev := st.host.ContractBandwidthReport(c.ID)
+			uploaded += ul
+			downloaded += dl
+			revenue = revenue.Add(rev)
+		}
+		return
+	}
+
+	// The upload should be reflected in the report.
+	uploaded, downloaded, revenue := report()
+	if uploaded == 0 || uploaded%modules.SectorSize != 0 {
+		t.Fatal("expected a whole number of sectors to be uploaded, got", uploaded)
+	} else if downloaded != 0 {
+		t.Fatal("expected nothing to be downloaded, got", downloaded)
+	} else if revenue.IsZero() {
+		t.Fatal("expected nonzero revenue after upload")
+	}
+
+	// Download the file. The download should be reflected in the report, and
+	// should increase the revenue.
+	downpath := filepath.Join(st.dir, "testdown.dat")
+	err := st.stdGetAPI("/renter/download/test.dat?destination=" + downpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploaded2, downloaded2, revenue2 := report()
+	if uploaded2 != uploaded {
+		t.Fatalf("expected upload counter to stay at %v, got %v", uploaded, uploaded2)
+	} else if downloaded2 < modules.SectorSize {
+		t.Fatalf("expected at least %v bytes to be downloaded, got %v", modules.SectorSize, downloaded2)
+	} else if revenue2.Cmp(revenue) <= 0 {
+		t.Fatal("expected revenue to increase after download")
+	}
+
+	// Unknown contracts should report nothing.
+	if ul, dl, rev := st.host.ContractBandwidthReport(types.FileContractID{}); ul != 0 || dl != 0 || !rev.IsZero() {
+		t.Fatal("expected empty report for unknown contract")
+	}
+}
+
+// TestHostAccessLogDownload checks that downloading a file from the host
+// records the sectors read by the download RPC in the host's access log.
+func TestHostAccessLogDownload(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	st, _ := setupTestDownload(t, 1e4, "test.dat", true)
+	var log bytes.Buffer
+	st.host.SetAccessLog(&log)
+
+	downpath := filepath.Join(st.dir, "down.dat")
+	if err := st.stdGetAPI("/renter/download/test.dat?destination=" + downpath); err != nil {
+		st.server.panicClose()
+		t.Fatal(err)
+	}
+	// Closing the host writes any queued records, so the log can be read
+	// safely afterwards.
+	st.server.panicClose()
+
+	var reads int
+	dec := json.NewDecoder(&log)
+	for dec.More() {
+		var record struct {
+			RenterKey types.SiaPublicKey `json:"renterkey"`
+			Operation string             `json:"operation"`
+		}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		if record.Operation != "read" {
+			continue
+		}
+		if len(record.RenterKey.Key) == 0 {
+			t.Error("read record is missing the renter's key")
+		}
+		reads++
+	}
+	if reads == 0 {
+		t.Fatal("download did not record any sector reads")
+	}
+}
+
+// TestHostDurationBoundsInvalid checks that invalid duration bounds are
+// rejected by POST /host, and that the host's settings are left unchanged.
+func TestHostDurationBoundsInvalid(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var before HostGET
+	if err := st.getAPI("/host", &before); err != nil {
+		t.Fatal(err)
+	}
+	for _, param := range []string{"minduration", "maxduration"} {
+		if err := st.stdPostAPI("/host", url.Values{param: {"foo"}}); err == nil {
+			t.Errorf("expected invalid %v to be rejected", param)
+		}
+	}
+	var after HostGET
+	if err := st.getAPI("/host", &after); err != nil {
+		t.Fatal(err)
+	}
+	if after.InternalSettings.MinDuration != before.InternalSettings.MinDuration || after.InternalSettings.MaxDuration != before.InternalSettings.MaxDuration {
+		t.Fatal("duration bounds changed after invalid POST:", after.InternalSettings.MinDuration, after.InternalSettings.MaxDuration)
+	}
+}
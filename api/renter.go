@@ -72,6 +72,7 @@ type (
 		Settings         modules.RenterSettings `json:"settings"`
 		FinancialMetrics RenterFinancialMetrics `json:"financialmetrics"`
 		CurrentPeriod    types.BlockHeight      `json:"currentperiod"`
+		PublicKey        types.SiaPublicKey     `json:"publickey"`
 	}
 
 	// RenterFinancialMetrics contains metrics about how much the Renter has
@@ -192,6 +193,7 @@ func (api *API) renterHandlerGET(w http.ResponseWriter, req *http.Request, _ htt
 		Settings:         settings,
 		FinancialMetrics: fm,
 		CurrentPeriod:    periodStart,
+		PublicKey:        api.renter.PublicKey(),
 	})
 }
 
@@ -285,6 +287,12 @@ func (api *API) renterContractsHandler(w http.ResponseWriter, _ *http.Request, _
 	})
 }
 
+// renterContractsHealthHandler handles the API call to request a summary of
+// the health of the Renter's contracts.
+func (api *API) renterContractsHealthHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, api.renter.ContractHealth())
+}
+
 // renterDownloadsHandler handles the API call to request the download queue.
 func (api *API) renterDownloadsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	var downloads []DownloadInfo
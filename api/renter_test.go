@@ -647,6 +647,18 @@ func TestRenterHandlerContracts(t *testing.T) {
 		t.Fatalf("expected renter to have 1 contract; got %v", len(contracts.Contracts))
 	}
 
+	// The contract should be reported as healthy.
+	var health modules.RenterContractHealth
+	if err = st.getAPI("/renter/contracts/health", &health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Good != 1 || health.Renewing != 0 || health.Expiring != 0 || health.Failed != 0 {
+		t.Fatalf("wrong contract health: %+v", health)
+	}
+	if !health.RemainingFunds.Equals(contracts.Contracts[0].RenterFunds) {
+		t.Fatalf("expected remaining funds to be %v; got %v", contracts.Contracts[0].RenterFunds, health.RemainingFunds)
+	}
+
 	// Check the renter's contract spending.
 	var get RenterGET
 	if err = st.getAPI("/renter", &get); err != nil {
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -872,6 +873,101 @@ func TestRenterUploadDownload(t *testing.T) {
 	}
 }
 
+// TestRenterRotateFileKey checks that a file is still downloadable after its
+// key has been rotated, and that its metadata reflects a new key.
+func TestRenterRotateFileKey(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Announce the host and form a contract with it.
+	err = st.announceHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.acceptContracts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.setHostStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowanceValues := url.Values{}
+	allowanceValues.Set("funds", "10000000000000000000000000000") // 10k SC
+	allowanceValues.Set("period", "10")
+	err = st.stdPostAPI("/renter", allowanceValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upload a file.
+	path := filepath.Join(st.dir, "test.dat")
+	err = createRandFile(path, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadValues := url.Values{}
+	uploadValues.Set("source", path)
+	err = st.stdPostAPI("/renter/upload/test", uploadValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rf RenterFiles
+	for i := 0; i < 200 && (len(rf.Files) != 1 || !rf.Files[0].Available); i++ {
+		st.getAPI("/renter/files", &rf)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(rf.Files) != 1 || !rf.Files[0].Available {
+		t.Fatal("file did not become available:", rf.Files)
+	}
+
+	if err := st.renter.RotateFileKey("nonexistent"); err == nil {
+		t.Fatal("expected error when rotating the key of a nonexistent file")
+	}
+	before, err := st.renter.ShareFilesAscii([]string{"test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.renter.RotateFileKey("test"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := st.renter.ShareFilesAscii([]string{"test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Fatal("file metadata did not change after rotating its key")
+	}
+
+	// Only the rotated file should remain, and it should download correctly.
+	st.getAPI("/renter/files", &rf)
+	if len(rf.Files) != 1 || rf.Files[0].SiaPath != "test" || !rf.Files[0].Available {
+		t.Fatal("unexpected files after key rotation:", rf.Files)
+	}
+	downpath := filepath.Join(st.dir, "testdown.dat")
+	err = st.stdGetAPI("/renter/download/test?destination=" + downpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	download, err := ioutil.ReadFile(downpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(orig, download) {
+		t.Fatal("data mismatch when downloading a file after key rotation")
+	}
+}
+
 // TestRenterCancelAllowance tests that setting an empty allowance causes
 // uploads, downloads, and renewals to cease.
 func TestRenterCancelAllowance(t *testing.T) {
@@ -1417,6 +1513,151 @@ func TestHostAndRentReload(t *testing.T) {
 	}
 }
 
+// TestRenterExportImportContracts checks that contracts exported from one
+// renter can be imported into a fresh renter, which can then download the
+// files stored in those contracts.
+func TestRenterExportImportContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Announce the host and start accepting contracts.
+	err = st.announceHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.acceptContracts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.setHostStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Mine a block so that the wallet reclaims refund outputs
+	_, err = st.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set an allowance for the renter, allowing a contract to be formed.
+	allowanceValues := url.Values{}
+	allowanceValues.Set("funds", "10000000000000000000000000000") // 10k SC
+	allowanceValues.Set("period", "10")
+	err = st.stdPostAPI("/renter", allowanceValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upload a file.
+	path := filepath.Join(st.dir, "test.dat")
+	err = createRandFile(path, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadValues := url.Values{}
+	uploadValues.Set("source", path)
+	err = st.stdPostAPI("/renter/upload/test", uploadValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only one piece will be uploaded (10% at current redundancy).
+	var rf RenterFiles
+	for i := 0; i < 200 && (len(rf.Files) != 1 || rf.Files[0].UploadProgress < 10); i++ {
+		st.getAPI("/renter/files", &rf)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(rf.Files) != 1 || rf.Files[0].UploadProgress < 10 {
+		t.Fatal("the uploading is not succeeding for some reason:", rf.Files[0])
+	}
+
+	// Export the contracts, and share the file metadata.
+	var backup bytes.Buffer
+	err = st.renter.ExportContracts(&backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharePath := filepath.Join(st.dir, "test.sia")
+	err = st.renter.ShareFiles([]string{"test"}, sharePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a fresh renter that uses the same wallet, and load the file
+	// metadata into it. Without the contracts, the file cannot be downloaded.
+	r, err := renter.New(st.gateway, st.cs, st.wallet, st.tpool, filepath.Join(st.dir, "freshrenter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, err = r.LoadSharedFiles(sharePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the fresh renter's hostdb to scan the host, so that downloads
+	// are paid for at the host's advertised price.
+	err = retry(100, 100*time.Millisecond, func() error {
+		if len(r.ActiveHosts()) == 0 {
+			return errors.New("host has not been scanned")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	downpath := filepath.Join(st.dir, "testdown.dat")
+	err = r.Download(modules.RenterDownloadParameters{
+		Siapath:     "test",
+		Destination: downpath,
+	})
+	if err == nil {
+		t.Fatal("expected download to fail before importing contracts")
+	}
+
+	// Import the contracts; the fresh renter should now be able to download
+	// the file.
+	err = r.ImportContracts(bytes.NewReader(backup.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.AllContracts()) != len(st.renter.(*renter.Renter).AllContracts()) {
+		t.Fatalf("expected %v contracts after import, got %v", len(st.renter.(*renter.Renter).AllContracts()), len(r.AllContracts()))
+	}
+	err = retry(100, 100*time.Millisecond, func() error {
+		return r.Download(modules.RenterDownloadParameters{
+			Siapath:     "test",
+			Destination: downpath,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	download, err := ioutil.ReadFile(downpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(orig, download) {
+		t.Fatal("data mismatch when downloading a file")
+	}
+
+	// Importing the same contracts again should fail, as none of them are
+	// new.
+	err = r.ImportContracts(bytes.NewReader(backup.Bytes()))
+	if err == nil {
+		t.Fatal("expected an error when importing already known contracts")
+	}
+}
+
 // TestHostAndRenterRenewInterrupt
 func TestHostAndRenterRenewInterrupt(t *testing.T) {
 	t.Skip("active test following contractor overhaul")
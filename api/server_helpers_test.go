@@ -156,6 +156,12 @@ type serverTester struct {
 // assembleServerTester creates a bunch of modules and assembles them into a
 // server tester, without creating any directories or mining any blocks.
 func assembleServerTester(key crypto.TwofishKey, testdir string) (*serverTester, error) {
+	return assembleServerTesterWithHost(key, testdir, host.New)
+}
+
+// assembleServerTesterWithHost is like assembleServerTester, but creates the
+// host using newHost.
+func assembleServerTesterWithHost(key crypto.TwofishKey, testdir string, newHost func(modules.ConsensusSet, modules.TransactionPool, modules.Wallet, string, string) (*host.Host, error)) (*serverTester, error) {
 	// assembleServerTester should not get called during short tests, as it
 	// takes a long time to run.
 	if testing.Short() {
@@ -193,7 +199,7 @@ func assembleServerTester(key crypto.TwofishKey, testdir string) (*serverTester,
 	if err != nil {
 		return nil, err
 	}
-	h, err := host.New(cs, tp, w, "localhost:0", filepath.Join(testdir, modules.HostDir))
+	h, err := newHost(cs, tp, w, "localhost:0", filepath.Join(testdir, modules.HostDir))
 	if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -75,7 +76,7 @@ func TestAuthentication(t *testing.T) {
 	}
 	defer st.server.panicClose()
 
-	testGETURL := "http://" + st.server.listener.Addr().String() + "/wallet/seeds"
+	testGETURL := "http://" + st.server.listener.Addr().String() + "/wallet/address"
 	testPOSTURL := "http://" + st.server.listener.Addr().String() + "/host/announce"
 
 	// Test that unauthenticated API calls fail.
@@ -132,3 +133,72 @@ func TestAuthentication(t *testing.T) {
 		t.Fatal("authenticated API call failed with the correct password")
 	}
 }
+
+// TestCORS checks that CORS headers are only set for requests from allowed
+// origins.
+func TestCORS(t *testing.T) {
+	api := New("Sia-Agent", "", nil, nil, nil, nil, nil, nil, nil, nil)
+	const browserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0"
+	userAgent := "Sia-Agent"
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/debug/goroutines", nil)
+		req.Header.Set("User-Agent", userAgent)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// By default, no origins are allowed.
+	if rec := request("GET", "http://example.com"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("CORS headers should not be set by default")
+	}
+
+	api.SetCORS([]string{"http://example.com"}, nil)
+	rec := request("GET", "http://example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "http://example.com" {
+		t.Fatal("allowed origin did not receive CORS headers")
+	} else if rec.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Fatal("wrong allowed methods:", rec.Header().Get("Access-Control-Allow-Methods"))
+	}
+	if rec := request("GET", "http://evil.com"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("disallowed origin received CORS headers")
+	}
+	if rec := request("GET", ""); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("request without an origin received CORS headers")
+	}
+
+	// Preflight requests from allowed origins should succeed.
+	if rec := request("OPTIONS", "http://example.com"); rec.Code != http.StatusNoContent {
+		t.Fatal("preflight request failed:", rec.Code)
+	}
+	if rec := request("OPTIONS", "http://evil.com"); rec.Code == http.StatusNoContent {
+		t.Fatal("preflight request from disallowed origin succeeded")
+	}
+
+	// Browsers cannot set the user agent, so requests from allowed origins
+	// should succeed without it. Other browser requests should still be
+	// rejected.
+	userAgent = browserAgent
+	if rec := request("GET", "http://example.com"); rec.Code != http.StatusOK {
+		t.Fatal("browser request from allowed origin failed:", rec.Code, rec.Body.String())
+	}
+	if rec := request("GET", "http://evil.com"); rec.Code != http.StatusBadRequest {
+		t.Fatal("browser request from disallowed origin was not rejected:", rec.Code)
+	}
+	if rec := request("GET", ""); rec.Code != http.StatusBadRequest {
+		t.Fatal("browser request without an origin was not rejected:", rec.Code)
+	}
+	userAgent = "Sia-Agent"
+
+	// A wildcard should allow any origin.
+	api.SetCORS([]string{"*"}, []string{"GET"})
+	rec = request("GET", "http://evil.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "http://evil.com" {
+		t.Fatal("wildcard did not allow origin")
+	} else if rec.Header().Get("Access-Control-Allow-Methods") != "GET" {
+		t.Fatal("wrong allowed methods:", rec.Header().Get("Access-Control-Allow-Methods"))
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -42,6 +43,11 @@ type (
 		Addresses []types.UnlockHash `json:"addresses"`
 	}
 
+	// WalletContactsGET contains the entries of the wallet's address book.
+	WalletContactsGET struct {
+		Contacts []modules.WalletContact `json:"contacts"`
+	}
+
 	// WalletInitPOST contains the primary seed that gets generated during a
 	// POST call to /wallet/init.
 	WalletInitPOST struct {
@@ -60,6 +66,20 @@ type (
 		TransactionIDs []types.TransactionID `json:"transactionids"`
 	}
 
+	// WalletKDFGET contains the scrypt cost parameters used to derive the
+	// wallet's encryption key.
+	WalletKDFGET struct {
+		N uint64 `json:"n"`
+		R uint64 `json:"r"`
+		P uint64 `json:"p"`
+	}
+
+	// WalletLargeSendThresholdGET contains the amount above which sends
+	// require confirmation.
+	WalletLargeSendThresholdGET struct {
+		Threshold types.Currency `json:"threshold"`
+	}
+
 	// WalletSeedsGET contains the seeds used by the wallet.
 	WalletSeedsGET struct {
 		PrimarySeed        string   `json:"primaryseed"`
@@ -315,6 +335,90 @@ func (api *API) walletSiagkeyHandler(w http.ResponseWriter, req *http.Request, _
 	WriteError(w, Error{"error when calling /wallet/siagkey: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
+// walletKDFHandlerGET handles API calls to GET /wallet/kdf.
+func (api *API) walletKDFHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	params := api.wallet.KDFParams()
+	WriteJSON(w, WalletKDFGET{
+		N: params.N,
+		R: params.R,
+		P: params.P,
+	})
+}
+
+// walletKDFHandlerPOST handles API calls to POST /wallet/kdf.
+func (api *API) walletKDFHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params crypto.ScryptParams
+	for _, f := range []struct {
+		name string
+		val  *uint64
+	}{{"n", &params.N}, {"r", &params.R}, {"p", &params.P}} {
+		v, err := strconv.ParseUint(req.FormValue(f.name), 10, 64)
+		if err != nil {
+			WriteError(w, Error{"could not read '" + f.name + "' from POST call to /wallet/kdf"}, http.StatusBadRequest)
+			return
+		}
+		*f.val = v
+	}
+	if err := api.wallet.SetKDFParams(params); err != nil {
+		WriteError(w, Error{"error when calling /wallet/kdf: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletContactsHandler handles API calls to /wallet/contacts.
+func (api *API) walletContactsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, WalletContactsGET{
+		Contacts: api.wallet.Contacts(),
+	})
+}
+
+// walletContactsAddHandler handles API calls to /wallet/contacts/add.
+func (api *API) walletContactsAddHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	addr, err := scanAddress(req.FormValue("address"))
+	if err != nil {
+		WriteError(w, Error{"could not read 'address' from POST call to /wallet/contacts/add"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.AddContact(req.FormValue("name"), addr); err != nil {
+		WriteError(w, Error{"error when calling /wallet/contacts/add: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletContactsRemoveHandler handles API calls to /wallet/contacts/remove.
+func (api *API) walletContactsRemoveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.wallet.RemoveContact(req.FormValue("name")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/contacts/remove: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletLargeSendThresholdHandlerGET handles API calls to GET
+// /wallet/largesendthreshold.
+func (api *API) walletLargeSendThresholdHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, WalletLargeSendThresholdGET{
+		Threshold: api.wallet.LargeSendThreshold(),
+	})
+}
+
+// walletLargeSendThresholdHandlerPOST handles API calls to POST
+// /wallet/largesendthreshold.
+func (api *API) walletLargeSendThresholdHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	threshold, ok := scanAmount(req.FormValue("threshold"))
+	if !ok {
+		WriteError(w, Error{"could not read 'threshold' from POST call to /wallet/largesendthreshold"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SetLargeSendThreshold(threshold); err != nil {
+		WriteError(w, Error{"error when calling /wallet/largesendthreshold: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // walletLockHanlder handles API calls to /wallet/lock.
 func (api *API) walletLockHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	err := api.wallet.Lock()
@@ -325,8 +429,36 @@ func (api *API) walletLockHandler(w http.ResponseWriter, req *http.Request, _ ht
 	WriteSuccess(w)
 }
 
-// walletSeedsHandler handles API calls to /wallet/seeds.
+// walletSeedsHandler handles API calls to /wallet/seeds. The seeds are only
+// returned if the wallet password is supplied, so that they are not exposed
+// to anyone who merely holds the API password. The password is read from the
+// Sia-Wallet-Password header, or from the body of a POST request, so that it
+// does not end up in URLs and access logs.
 func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	password := req.Header.Get("Sia-Wallet-Password")
+	if password == "" {
+		password = req.PostFormValue("encryptionpassword")
+	}
+	if password == "" {
+		WriteError(w, Error{"error after call to /wallet/seeds: the wallet password must be provided in the Sia-Wallet-Password header"}, http.StatusBadRequest)
+		return
+	}
+	var authenticated bool
+	for _, key := range encryptionKeys(password) {
+		err := api.wallet.CheckKey(key)
+		if err == nil {
+			authenticated = true
+			break
+		} else if err != modules.ErrBadEncryptionKey {
+			WriteError(w, Error{"error after call to /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if !authenticated {
+		WriteError(w, Error{"error after call to /wallet/seeds: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+		return
+	}
+
 	dictionary := mnemonics.DictionaryID(req.FormValue("dictionary"))
 	if dictionary == "" {
 		dictionary = mnemonics.English
@@ -368,6 +500,16 @@ func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ h
 
 // walletSiacoinsHandler handles API calls to /wallet/siacoins.
 func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var confirmed bool
+	if c := req.FormValue("confirmed"); c != "" {
+		var err error
+		confirmed, err = strconv.ParseBool(c)
+		if err != nil {
+			WriteError(w, Error{"could not decode confirmed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
 	var txns []types.Transaction
 	if req.FormValue("outputs") != "" {
 		// multiple amounts + destinations
@@ -382,7 +524,11 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			WriteError(w, Error{"could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
-		txns, err = api.wallet.SendSiacoinsMulti(outputs)
+		if confirmed {
+			txns, err = api.wallet.SendSiacoinsMultiConfirmed(outputs)
+		} else {
+			txns, err = api.wallet.SendSiacoinsMulti(outputs)
+		}
 		if err != nil {
 			WriteError(w, Error{"error after call to /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
@@ -400,8 +546,20 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			return
 		}
 
-		txns, err = api.wallet.SendSiacoins(amount, dest)
-		if err != nil {
+		if confirmed {
+			txns, err = api.wallet.SendSiacoinsConfirmed(amount, dest)
+		} else {
+			txns, err = api.wallet.SendSiacoins(amount, dest)
+		}
+		if err != nil && len(txns) > 0 {
+			// Part of a split send was submitted before the error occurred.
+			var submitted []string
+			for _, txn := range txns {
+				submitted = append(submitted, txn.ID().String())
+			}
+			WriteError(w, Error{fmt.Sprintf("error after call to /wallet/siacoins: %v (submitted transactions: %v)", err, strings.Join(submitted, ", "))}, http.StatusInternalServerError)
+			return
+		} else if err != nil {
 			WriteError(w, Error{"error after call to /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
@@ -514,12 +672,47 @@ func (api *API) walletTransactionsHandler(w http.ResponseWriter, req *http.Reque
 	}
 	unconfirmedTxns := api.wallet.UnconfirmedTransactions()
 
+	// Filter the transactions by type, if requested.
+	switch txnType := req.FormValue("type"); txnType {
+	case "":
+	case "incoming", "outgoing":
+		outgoing := txnType == "outgoing"
+		confirmedTxns = filterTransactions(confirmedTxns, outgoing)
+		unconfirmedTxns = filterTransactions(unconfirmedTxns, outgoing)
+	default:
+		WriteError(w, Error{"type must be either 'incoming' or 'outgoing'"}, http.StatusBadRequest)
+		return
+	}
+
 	WriteJSON(w, WalletTransactionsGET{
 		ConfirmedTransactions:   confirmedTxns,
 		UnconfirmedTransactions: unconfirmedTxns,
 	})
 }
 
+// isOutgoing returns true if the transaction spends any of the wallet's
+// outputs. All other transactions related to the wallet are incoming.
+func isOutgoing(pt modules.ProcessedTransaction) bool {
+	for _, input := range pt.Inputs {
+		if input.WalletAddress {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTransactions returns the transactions in pts that are outgoing if
+// outgoing is true, or incoming otherwise.
+func filterTransactions(pts []modules.ProcessedTransaction, outgoing bool) []modules.ProcessedTransaction {
+	var filtered []modules.ProcessedTransaction
+	for _, pt := range pts {
+		if isOutgoing(pt) == outgoing {
+			filtered = append(filtered, pt)
+		}
+	}
+	return filtered
+}
+
 // walletTransactionsAddrHandler handles API calls to
 // /wallet/transactions/:addr.
 func (api *API) walletTransactionsAddrHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
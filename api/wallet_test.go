@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -526,6 +528,87 @@ func TestIntegrationWalletLoadSeedPOST(t *testing.T) {
 	}
 }
 
+// TestWalletTransactionsFilter checks that /wallet/transactions filters
+// transactions by height range and type.
+func TestWalletTransactionsFilter(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Send some siacoins out of the wallet so that there is an outgoing
+	// transaction in addition to the miner payouts.
+	_, err = st.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(3), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	height := st.cs.Height()
+
+	// Filter by height range.
+	var wtg WalletTransactionsGET
+	err = st.getAPI(fmt.Sprintf("/wallet/transactions?startheight=2&endheight=%v", height-2), &wtg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.ConfirmedTransactions) == 0 {
+		t.Fatal("expected transactions in height range")
+	}
+	for _, pt := range wtg.ConfirmedTransactions {
+		if pt.ConfirmationHeight < 2 || pt.ConfirmationHeight > height-2 {
+			t.Fatalf("transaction at height %v is outside of range [2, %v]", pt.ConfirmationHeight, height-2)
+		}
+	}
+
+	// Filter by type. The incoming and outgoing transactions should partition
+	// the full set.
+	var all, incoming, outgoing WalletTransactionsGET
+	query := fmt.Sprintf("/wallet/transactions?startheight=0&endheight=%v", height)
+	if err := st.getAPI(query, &all); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI(query+"&type=incoming", &incoming); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI(query+"&type=outgoing", &outgoing); err != nil {
+		t.Fatal(err)
+	}
+	if len(incoming.ConfirmedTransactions) == 0 || len(outgoing.ConfirmedTransactions) == 0 {
+		t.Fatal("expected both incoming and outgoing transactions")
+	}
+	if len(incoming.ConfirmedTransactions)+len(outgoing.ConfirmedTransactions) != len(all.ConfirmedTransactions) {
+		t.Fatal("incoming and outgoing transactions do not add up to all transactions")
+	}
+	for _, pt := range incoming.ConfirmedTransactions {
+		for _, input := range pt.Inputs {
+			if input.WalletAddress {
+				t.Fatal("incoming transaction spends a wallet output")
+			}
+		}
+	}
+	for _, pt := range outgoing.ConfirmedTransactions {
+		var spendsWalletOutput bool
+		for _, input := range pt.Inputs {
+			spendsWalletOutput = spendsWalletOutput || input.WalletAddress
+		}
+		if !spendsWalletOutput {
+			t.Fatal("outgoing transaction does not spend a wallet output")
+		}
+	}
+
+	// An unknown type should be rejected.
+	if err := st.getAPI(query+"&type=foo", &wtg); err == nil {
+		t.Fatal("expected error for unknown transaction type")
+	}
+}
+
 // TestWalletTransactionGETid queries the /wallet/transaction/$(id)
 // api call.
 func TestWalletTransactionGETid(t *testing.T) {
@@ -1190,6 +1273,140 @@ func TestWalletVerifyAddress(t *testing.T) {
 	}
 }
 
+// TestWalletLargeSendThreshold tests that the large send threshold can be set
+// via the API, and that /wallet/siacoins enforces it.
+func TestWalletLargeSendThreshold(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var lst WalletLargeSendThresholdGET
+	if err = st.getAPI("/wallet/largesendthreshold", &lst); err != nil {
+		t.Fatal(err)
+	}
+	if !lst.Threshold.IsZero() {
+		t.Fatal("expected threshold to be disabled by default, got", lst.Threshold)
+	}
+
+	threshold := types.SiacoinPrecision.Mul64(100)
+	if err = st.stdPostAPI("/wallet/largesendthreshold", url.Values{"threshold": {threshold.String()}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = st.getAPI("/wallet/largesendthreshold", &lst); err != nil {
+		t.Fatal(err)
+	}
+	if lst.Threshold.Cmp(threshold) != 0 {
+		t.Fatal("threshold was not set:", lst.Threshold)
+	}
+	if err = st.stdPostAPI("/wallet/largesendthreshold", url.Values{"threshold": {"foo"}}); err == nil {
+		t.Fatal("expected an invalid threshold to be rejected")
+	}
+
+	// A send above the threshold should be rejected unless it is confirmed.
+	sendValues := url.Values{
+		"amount":      {threshold.Add(types.NewCurrency64(1)).String()},
+		"destination": {st.coinAddress()},
+	}
+	if err = st.stdPostAPI("/wallet/siacoins", sendValues); err == nil || !strings.Contains(err.Error(), modules.ErrLargeSendNeedsConfirmation.Error()) {
+		t.Fatal("expected ErrLargeSendNeedsConfirmation, got", err)
+	}
+	sendValues.Set("confirmed", "true")
+	if err = st.stdPostAPI("/wallet/siacoins", sendValues); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestWalletContacts checks that contacts can be added, listed, and removed
+// through the API.
+func TestWalletContacts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var wcg WalletContactsGET
+	if err = st.getAPI("/wallet/contacts", &wcg); err != nil {
+		t.Fatal(err)
+	}
+	if len(wcg.Contacts) != 0 {
+		t.Fatal("expected no contacts, got", wcg.Contacts)
+	}
+
+	addr := st.coinAddress()
+	if err = st.stdPostAPI("/wallet/contacts/add", url.Values{"name": {"alice"}, "address": {addr}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = st.stdPostAPI("/wallet/contacts/add", url.Values{"name": {"alice"}, "address": {addr}}); err == nil {
+		t.Fatal("expected a duplicate contact to be rejected")
+	}
+	if err = st.stdPostAPI("/wallet/contacts/add", url.Values{"name": {"bob"}, "address": {"foo"}}); err == nil {
+		t.Fatal("expected an invalid address to be rejected")
+	}
+	if err = st.getAPI("/wallet/contacts", &wcg); err != nil {
+		t.Fatal(err)
+	}
+	if len(wcg.Contacts) != 1 || wcg.Contacts[0].Name != "alice" || wcg.Contacts[0].Address.String() != addr {
+		t.Fatal("contact was not added:", wcg.Contacts)
+	}
+
+	if err = st.stdPostAPI("/wallet/contacts/remove", url.Values{"name": {"alice"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = st.stdPostAPI("/wallet/contacts/remove", url.Values{"name": {"alice"}}); err == nil {
+		t.Fatal("expected removing an unknown contact to fail")
+	}
+	if err = st.getAPI("/wallet/contacts", &wcg); err != nil {
+		t.Fatal(err)
+	}
+	if len(wcg.Contacts) != 0 {
+		t.Fatal("contact was not removed:", wcg.Contacts)
+	}
+}
+
+// TestWalletKDF checks that the wallet's KDF parameters can be read and set
+// through the API.
+func TestWalletKDF(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	if err = st.stdPostAPI("/wallet/kdf", url.Values{"n": {"4096"}, "r": {"8"}, "p": {"2"}}); err != nil {
+		t.Fatal(err)
+	}
+	var kdf WalletKDFGET
+	if err = st.getAPI("/wallet/kdf", &kdf); err != nil {
+		t.Fatal(err)
+	}
+	if kdf.N != 4096 || kdf.R != 8 || kdf.P != 2 {
+		t.Fatal("KDF parameters were not set:", kdf)
+	}
+	if err = st.stdPostAPI("/wallet/kdf", url.Values{"n": {"4095"}, "r": {"8"}, "p": {"1"}}); err == nil {
+		t.Fatal("expected invalid KDF parameters to be rejected")
+	}
+	if err = st.stdPostAPI("/wallet/kdf", url.Values{"n": {"4096"}}); err == nil {
+		t.Fatal("expected missing KDF parameters to be rejected")
+	}
+}
+
 // TestWalletChangePassword verifies that the /wallet/changepassword endpoint
 // works correctly and changes a wallet password.
 func TestWalletChangePassword(t *testing.T) {
@@ -1420,3 +1637,85 @@ func TestWalletSiacoins(t *testing.T) {
 		}
 	}
 }
+
+// TestWalletSeedsPassword checks that /wallet/seeds only returns the seeds
+// when the wallet password is supplied.
+func TestWalletSeedsPassword(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	walletPassword := "testpass"
+	key := crypto.TwofishKey(crypto.HashObject(walletPassword))
+	st, err := assembleServerTester(key, build.TempDir("api", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// The seeds should be refused without the wallet password, or with the
+	// wrong one.
+	var wsg WalletSeedsGET
+	for _, password := range []string{"", "wrongpass"} {
+		if err := st.postAPI("/wallet/seeds", url.Values{"encryptionpassword": {password}}, &wsg); err == nil {
+			t.Fatalf("seeds were returned for password %q", password)
+		}
+		if wsg.PrimarySeed != "" {
+			t.Fatal("primary seed was exposed without the wallet password")
+		}
+	}
+
+	// The password must not be accepted in the query string.
+	if err := st.postAPI("/wallet/seeds?encryptionpassword="+walletPassword, url.Values{}, &wsg); err == nil {
+		t.Fatal("seeds were returned for a password in the query string")
+	}
+
+	// With the correct password, the primary seed should be returned.
+	err = st.postAPI("/wallet/seeds", url.Values{"encryptionpassword": {walletPassword}}, &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, _, err := st.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedStr, err := modules.SeedToString(seed, "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsg.PrimarySeed != seedStr {
+		t.Fatal("wrong primary seed returned")
+	}
+
+	// GET requests should be served when the password is in the header.
+	getSeeds := func(password string) (*http.Response, error) {
+		req, err := http.NewRequest("GET", "http://"+st.server.listener.Addr().String()+"/wallet/seeds", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", "Sia-Agent")
+		req.Header.Set("Sia-Wallet-Password", password)
+		return http.DefaultClient.Do(req)
+	}
+	resp, err := getSeeds("wrongpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatal("seeds were returned for a wrong password in the header:", resp.Status)
+	}
+	resp, err = getSeeds(walletPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	wsg = WalletSeedsGET{}
+	if err := json.NewDecoder(resp.Body).Decode(&wsg); err != nil {
+		t.Fatal(err)
+	}
+	if wsg.PrimarySeed != seedStr {
+		t.Fatal("wrong primary seed returned for GET request")
+	}
+}
@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// golang.org/x/crypto/blake2b selects an AVX2, AVX, or SSE4.1 implementation
+// at runtime on amd64, and falls back to a portable implementation elsewhere.
+// The functions below are a straightforward, unoptimized implementation of
+// blake2b-256 (RFC 7693), used to check that the accelerated path produces
+// the same hashes as the portable one, and to measure the speedup.
+
+var (
+	blake2bIV = [8]uint64{
+		0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+		0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+	}
+
+	blake2bSigma = [10][16]byte{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+		{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+		{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+		{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+		{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+		{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+		{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+		{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+		{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	}
+)
+
+// rotr64 rotates x right by n bits.
+func rotr64(x uint64, n uint) uint64 {
+	return x>>n | x<<(64-n)
+}
+
+// blake2bCompress applies the blake2b compression function to h.
+func blake2bCompress(h *[8]uint64, block []byte, t uint64, final bool) {
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+	var v [16]uint64
+	copy(v[:8], h[:])
+	copy(v[8:], blake2bIV[:])
+	v[12] ^= t
+	if final {
+		v[14] = ^v[14]
+	}
+	g := func(a, b, c, d int, x, y uint64) {
+		v[a] = v[a] + v[b] + x
+		v[d] = rotr64(v[d]^v[a], 32)
+		v[c] = v[c] + v[d]
+		v[b] = rotr64(v[b]^v[c], 24)
+		v[a] = v[a] + v[b] + y
+		v[d] = rotr64(v[d]^v[a], 16)
+		v[c] = v[c] + v[d]
+		v[b] = rotr64(v[b]^v[c], 63)
+	}
+	for r := 0; r < 12; r++ {
+		s := &blake2bSigma[r%10]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := range h {
+		h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// portableHashBytes computes the unkeyed blake2b-256 hash of data without
+// any platform-specific acceleration.
+func portableHashBytes(data []byte) (hash Hash) {
+	h := blake2bIV
+	h[0] ^= 0x01010000 | HashSize
+	var t uint64
+	for len(data) > 128 {
+		t += 128
+		blake2bCompress(&h, data[:128], t, false)
+		data = data[128:]
+	}
+	var last [128]byte
+	copy(last[:], data)
+	t += uint64(len(data))
+	blake2bCompress(&h, last[:], t, true)
+	for i := 0; i < HashSize/8; i++ {
+		binary.LittleEndian.PutUint64(hash[i*8:], h[i])
+	}
+	return
+}
+
+// TestHashBytesPortable checks that HashBytes, which uses an accelerated
+// implementation where available, agrees with a portable implementation of
+// blake2b for inputs of many sizes, including block boundaries.
+func TestHashBytesPortable(t *testing.T) {
+	for _, n := range []int{0, 1, 31, 32, 64, 127, 128, 129, 255, 256, 257, 1000, 4096, 1 << 16} {
+		data := fastrand.Bytes(n)
+		if HashBytes(data) != portableHashBytes(data) {
+			t.Errorf("hashes of %v bytes differ", n)
+		}
+	}
+	// The hash of the empty input is a known value.
+	if HashBytes(nil).String() != "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8" {
+		t.Error("wrong hash for empty input:", HashBytes(nil))
+	}
+}
+
+// BenchmarkHashBytes64 benchmarks HashBytes on a 64 byte input, the size of a
+// Merkle tree node.
+func BenchmarkHashBytes64(b *testing.B) {
+	benchmarkHashBytes(b, HashBytes, 64)
+}
+
+// BenchmarkHashBytes4MiB benchmarks HashBytes on a 4 MiB input, the size of a
+// sector.
+func BenchmarkHashBytes4MiB(b *testing.B) {
+	benchmarkHashBytes(b, HashBytes, 1<<22)
+}
+
+// BenchmarkHashBytesPortable64 benchmarks the portable implementation on a
+// 64 byte input.
+func BenchmarkHashBytesPortable64(b *testing.B) {
+	benchmarkHashBytes(b, portableHashBytes, 64)
+}
+
+// BenchmarkHashBytesPortable4MiB benchmarks the portable implementation on a
+// 4 MiB input.
+func BenchmarkHashBytesPortable4MiB(b *testing.B) {
+	benchmarkHashBytes(b, portableHashBytes, 1<<22)
+}
+
+func benchmarkHashBytes(b *testing.B, hashFn func([]byte) Hash, n int) {
+	data := fastrand.Bytes(n)
+	b.SetBytes(int64(n))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = hashFn(data)
+	}
+}
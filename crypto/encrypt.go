@@ -19,6 +19,10 @@ const (
 )
 
 var (
+	// ErrAuthFailed is returned when a ciphertext or its additional data has
+	// been modified.
+	ErrAuthFailed = errors.New("ciphertext failed authentication")
+
 	ErrInsufficientLen = errors.New("supplied ciphertext is not long enough to contain a nonce")
 )
 
@@ -44,6 +48,23 @@ func (key TwofishKey) NewCipher() cipher.Block {
 // EncryptBytes encrypts a []byte using the key. EncryptBytes uses GCM and
 // prepends the nonce (12 bytes) to the ciphertext.
 func (key TwofishKey) EncryptBytes(plaintext []byte) Ciphertext {
+	// No additional data is provided, as EncryptBytes is meant for file
+	// encryption.
+	return EncryptAEAD(key, plaintext, nil)
+}
+
+// DecryptBytes decrypts the ciphertext created by EncryptBytes. The nonce is
+// expected to be the first 12 bytes of the ciphertext.
+func (key TwofishKey) DecryptBytes(ct Ciphertext) ([]byte, error) {
+	return DecryptAEAD(key, ct, nil)
+}
+
+// EncryptAEAD encrypts and authenticates plaintext using the key, and
+// authenticates additionalData without encrypting it. The same additionalData
+// must be supplied to DecryptAEAD. The nonce (12 bytes) is prepended to the
+// ciphertext. With no additionalData, the ciphertext is identical in format
+// to the output of EncryptBytes.
+func EncryptAEAD(key TwofishKey, plaintext, additionalData []byte) (ciphertext []byte) {
 	// Create the cipher.
 	// NOTE: NewGCM only returns an error if twofishCipher.BlockSize != 16.
 	aead, _ := cipher.NewGCM(key.NewCipher())
@@ -51,25 +72,28 @@ func (key TwofishKey) EncryptBytes(plaintext []byte) Ciphertext {
 	// Create the nonce.
 	nonce := fastrand.Bytes(aead.NonceSize())
 
-	// Encrypt the data. No authenticated data is provided, as EncryptBytes is
-	// meant for file encryption.
-	return aead.Seal(nonce, nonce, plaintext, nil)
+	// Encrypt the data.
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
 }
 
-// DecryptBytes decrypts the ciphertext created by EncryptBytes. The nonce is
-// expected to be the first 12 bytes of the ciphertext.
-func (key TwofishKey) DecryptBytes(ct Ciphertext) ([]byte, error) {
+// DecryptAEAD decrypts a ciphertext created by EncryptAEAD. ErrAuthFailed is
+// returned if the ciphertext or additionalData has been modified.
+func DecryptAEAD(key TwofishKey, ciphertext, additionalData []byte) ([]byte, error) {
 	// Create the cipher.
 	// NOTE: NewGCM only returns an error if twofishCipher.BlockSize != 16.
 	aead, _ := cipher.NewGCM(key.NewCipher())
 
 	// Check for a nonce.
-	if len(ct) < aead.NonceSize() {
+	if len(ciphertext) < aead.NonceSize() {
 		return nil, ErrInsufficientLen
 	}
 
 	// Decrypt the data.
-	return aead.Open(nil, ct[:aead.NonceSize()], ct[aead.NonceSize():], nil)
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+	return plaintext, nil
 }
 
 // NewWriter returns a writer that encrypts or decrypts its input stream.
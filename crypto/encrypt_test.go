@@ -62,6 +62,55 @@ func TestTwofishEncryption(t *testing.T) {
 	}
 }
 
+// TestAEAD checks that EncryptAEAD and DecryptAEAD round-trip, and that
+// modifying any byte of the ciphertext or the additional data causes
+// decryption to fail.
+func TestAEAD(t *testing.T) {
+	key := GenerateTwofishKey()
+	plaintext := fastrand.Bytes(100)
+	ad := fastrand.Bytes(20)
+	ciphertext := EncryptAEAD(key, plaintext, ad)
+	decrypted, err := DecryptAEAD(key, ciphertext, ad)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(decrypted, plaintext) {
+		t.Fatal("decrypted plaintext does not match the original")
+	}
+
+	// Flip each bit of the ciphertext and the additional data in turn.
+	for i := range ciphertext {
+		ciphertext[i] ^= 1
+		if _, err := DecryptAEAD(key, ciphertext, ad); err != ErrAuthFailed {
+			t.Fatalf("expected ErrAuthFailed after modifying ciphertext byte %v, got %v", i, err)
+		}
+		ciphertext[i] ^= 1
+	}
+	for i := range ad {
+		ad[i] ^= 1
+		if _, err := DecryptAEAD(key, ciphertext, ad); err != ErrAuthFailed {
+			t.Fatalf("expected ErrAuthFailed after modifying additional data byte %v, got %v", i, err)
+		}
+		ad[i] ^= 1
+	}
+	if _, err := DecryptAEAD(key, ciphertext, nil); err != ErrAuthFailed {
+		t.Fatal("expected ErrAuthFailed when the additional data is omitted, got", err)
+	}
+	if _, err := DecryptAEAD(key, ciphertext[:10], ad); err != ErrInsufficientLen {
+		t.Fatal("expected ErrInsufficientLen, got", err)
+	}
+
+	// Without additional data, the ciphertext is compatible with
+	// EncryptBytes and DecryptBytes.
+	decrypted, err = key.DecryptBytes(EncryptAEAD(key, plaintext, nil))
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Fatal("DecryptBytes could not decrypt an EncryptAEAD ciphertext:", err)
+	}
+	decrypted, err = DecryptAEAD(key, key.EncryptBytes(plaintext), nil)
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Fatal("DecryptAEAD could not decrypt an EncryptBytes ciphertext:", err)
+	}
+}
+
 // TestReaderWriter probes the NewReader and NewWriter methods of the key type.
 func TestReaderWriter(t *testing.T) {
 	// Get a key for encryption.
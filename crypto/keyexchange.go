@@ -0,0 +1,35 @@
+package crypto
+
+import (
+	"github.com/NebulousLabs/fastrand"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// GenerateExchangeKeyPair creates an X25519 key pair that can be used to
+// derive a shared secret with a peer.
+func GenerateExchangeKeyPair() (priv, pub [32]byte) {
+	fastrand.Read(priv[:])
+	p, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		// X25519 only fails for low-order points, which the base point is
+		// not.
+		panic("crypto: X25519 failed with the base point: " + err.Error())
+	}
+	copy(pub[:], p)
+	return
+}
+
+// SharedSecret derives the X25519 shared secret between priv and peerPub. Both
+// peers derive the same secret from their own private key and the other
+// peer's public key. An error is returned if peerPub is a low-order point,
+// which would result in an all-zero secret. The secret is not uniformly
+// random, and should be hashed before being used as a symmetric key.
+func SharedSecret(priv, peerPub [32]byte) (secret [32]byte, err error) {
+	s, err := curve25519.X25519(priv[:], peerPub[:])
+	if err != nil {
+		return [32]byte{}, err
+	}
+	copy(secret[:], s)
+	return secret, nil
+}
@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"testing"
+)
+
+// TestSharedSecret checks that both sides of an exchange derive the same
+// secret, that a different peer key results in a different secret, and that
+// low-order peer keys are rejected.
+func TestSharedSecret(t *testing.T) {
+	privA, pubA := GenerateExchangeKeyPair()
+	privB, pubB := GenerateExchangeKeyPair()
+	if pubA == pubB {
+		t.Fatal("generated identical public keys")
+	}
+
+	secretA, err := SharedSecret(privA, pubB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretB, err := SharedSecret(privB, pubA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secretA != secretB {
+		t.Fatal("peers derived different shared secrets")
+	}
+	if secretA == [32]byte{} {
+		t.Fatal("shared secret is empty")
+	}
+
+	// Using a key from a third party should produce a different secret.
+	_, pubC := GenerateExchangeKeyPair()
+	if secretC, err := SharedSecret(privA, pubC); err != nil {
+		t.Fatal(err)
+	} else if secretC == secretA {
+		t.Fatal("mismatched peer key produced the same shared secret")
+	}
+
+	// The zero point has low order, and would produce an all-zero secret.
+	if _, err := SharedSecret(privA, [32]byte{}); err == nil {
+		t.Fatal("expected low-order peer key to be rejected")
+	}
+}
+
+// BenchmarkSharedSecret benchmarks the derivation of a shared secret.
+func BenchmarkSharedSecret(b *testing.B) {
+	priv, _ := GenerateExchangeKeyPair()
+	_, pub := GenerateExchangeKeyPair()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SharedSecret(priv, pub)
+	}
+}
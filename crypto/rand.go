@@ -0,0 +1,52 @@
+package crypto
+
+// rand.go contains helpers for unbiased random selection, backed by
+// crypto/rand.
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+)
+
+// RandUint64 returns a uniformly random uint64 read from crypto/rand. It
+// panics if the system's secure randomness source fails.
+func RandUint64() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("crypto: could not read from crypto/rand: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// RandIntn returns a uniformly random int in [0,n). Rejection sampling is used
+// so that the result is not biased towards smaller values. RandIntn panics if
+// n <= 0.
+func RandIntn(n int) int {
+	if n <= 0 {
+		panic("crypto: RandIntn called with non-positive n")
+	}
+	// Reject any value at or above the largest multiple of n that fits in a
+	// uint64. The remaining values map evenly onto [0,n).
+	limit := math.MaxUint64 - math.MaxUint64%uint64(n)
+	for {
+		r := RandUint64()
+		if r < limit {
+			return int(r % uint64(n))
+		}
+	}
+}
+
+// Perm returns a uniformly random permutation of the integers in [0,n).
+func Perm(n int) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = i
+	}
+	// Fisher-Yates shuffle.
+	for i := n - 1; i > 0; i-- {
+		j := RandIntn(i + 1)
+		m[i], m[j] = m[j], m[i]
+	}
+	return m
+}
@@ -0,0 +1,92 @@
+package crypto
+
+import (
+	"sort"
+	"testing"
+)
+
+// TestRandIntn checks that RandIntn stays within bounds and produces a
+// roughly uniform distribution.
+func TestRandIntn(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	const n = 10
+	const samples = 100000
+	var counts [n]int
+	for i := 0; i < samples; i++ {
+		r := RandIntn(n)
+		if r < 0 || r >= n {
+			t.Fatal("RandIntn returned a value out of range:", r)
+		}
+		counts[r]++
+	}
+	// Each bucket should be within 10% of the expected count. The standard
+	// deviation of each bucket is ~95, so a legitimate failure is extremely
+	// unlikely.
+	expected := samples / n
+	for i, c := range counts {
+		if c < expected*9/10 || c > expected*11/10 {
+			t.Errorf("bucket %v has %v samples, expected roughly %v", i, c, expected)
+		}
+	}
+
+	// RandIntn(1) must always return 0.
+	for i := 0; i < 100; i++ {
+		if RandIntn(1) != 0 {
+			t.Fatal("RandIntn(1) returned a non-zero value")
+		}
+	}
+}
+
+// TestRandIntnPanics checks that RandIntn panics on non-positive input.
+func TestRandIntnPanics(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RandIntn(%v) did not panic", n)
+				}
+			}()
+			RandIntn(n)
+		}()
+	}
+}
+
+// TestPerm checks that Perm returns a valid permutation and that the
+// permutations it returns are shuffled.
+func TestPerm(t *testing.T) {
+	if len(Perm(0)) != 0 {
+		t.Fatal("Perm(0) should return an empty slice")
+	}
+
+	const n = 50
+	p := Perm(n)
+	sorted := append([]int(nil), p...)
+	sort.Ints(sorted)
+	for i := range sorted {
+		if sorted[i] != i {
+			t.Fatal("Perm did not return a permutation:", p)
+		}
+	}
+
+	// Every value should appear in the first position at least once over
+	// many trials.
+	const trials = 10000
+	var firsts [5]int
+	for i := 0; i < trials; i++ {
+		firsts[Perm(len(firsts))[0]]++
+	}
+	for i, c := range firsts {
+		if c < trials/len(firsts)*9/10 || c > trials/len(firsts)*11/10 {
+			t.Errorf("value %v was first %v times, expected roughly %v", i, c, trials/len(firsts))
+		}
+	}
+}
+
+// BenchmarkRandIntn benchmarks the RandIntn function.
+func BenchmarkRandIntn(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		RandIntn(1000)
+	}
+}
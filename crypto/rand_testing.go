@@ -0,0 +1,17 @@
+// +build testing
+
+package crypto
+
+import (
+	"io"
+	"math/rand"
+)
+
+// NewDeterministicReader returns a reader that produces a reproducible stream
+// of pseudorandom bytes derived from seed. Two readers created with the same
+// seed produce identical streams. The stream is NOT cryptographically secure,
+// and the reader is not safe for concurrent use; it is only available in
+// testing builds, for making tests reproducible.
+func NewDeterministicReader(seed int64) io.Reader {
+	return rand.New(rand.NewSource(seed))
+}
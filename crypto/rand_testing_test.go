@@ -0,0 +1,33 @@
+// +build testing
+
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// TestDeterministicReader checks that readers created with the same seed
+// produce identical streams, and that readers with different seeds do not.
+func TestDeterministicReader(t *testing.T) {
+	read := func(r io.Reader, n int) []byte {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	r1, r2 := NewDeterministicReader(42), NewDeterministicReader(42)
+	// Read in differently-sized chunks to check that the stream does not
+	// depend on how it is consumed.
+	b1 := append(read(r1, 100), read(r1, 900)...)
+	b2 := read(r2, 1000)
+	if !bytes.Equal(b1, b2) {
+		t.Fatal("readers with the same seed produced different streams")
+	}
+	if bytes.Equal(b1, read(NewDeterministicReader(43), 1000)) {
+		t.Fatal("readers with different seeds produced the same stream")
+	}
+}
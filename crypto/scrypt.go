@@ -0,0 +1,102 @@
+package crypto
+
+// scrypt.go contains functions for deriving encryption keys from passphrases
+// using scrypt.
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/fastrand"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// ScryptSaltSize is the size of the salt used when deriving a key with
+	// scrypt.
+	ScryptSaltSize = 32
+
+	// scryptKeyLen is the length of the keys produced by DeriveKeyScrypt. It
+	// matches the size of a TwofishKey.
+	scryptKeyLen = EntropySize
+)
+
+var (
+	// DefaultScryptParams are the cost parameters used when no others are
+	// specified. They take roughly 100ms and 32 MiB of memory to evaluate on
+	// a typical desktop.
+	DefaultScryptParams = ScryptParams{N: 1 << 15, R: 8, P: 1}
+
+	// MinScryptParams are the weakest cost parameters that are considered
+	// safe for protecting secrets such as wallet seeds.
+	MinScryptParams = ScryptParams{N: 1 << 14, R: 8, P: 1}
+
+	// MaxScryptParams are the most expensive cost parameters that will be
+	// accepted when deriving a key. They take roughly 256 MiB of memory to
+	// evaluate. Parameters read from untrusted data must be checked against
+	// them, as arbitrary parameters can exhaust the machine's memory.
+	MaxScryptParams = ScryptParams{N: 1 << 18, R: 8, P: 4}
+
+	// ErrExpensiveScryptParams is returned when scrypt cost parameters
+	// exceed MaxScryptParams.
+	ErrExpensiveScryptParams = errors.New("scrypt parameters are too expensive")
+
+	// ErrInvalidScryptParams is returned when scrypt cost parameters are
+	// out of range. N must be a power of two greater than 1, R and P must
+	// be nonzero, and R*P must be less than 2^30.
+	ErrInvalidScryptParams = errors.New("invalid scrypt parameters")
+)
+
+// ScryptParams are the parameters used to derive a key with scrypt. N is the
+// CPU/memory cost, R is the block size, and P is the parallelization factor.
+// The params, including the salt, must be stored alongside any data encrypted
+// with the derived key, so that the key can be derived again later.
+type ScryptParams struct {
+	N    uint64
+	R    uint64
+	P    uint64
+	Salt [ScryptSaltSize]byte
+}
+
+// NewScryptParams returns ScryptParams with the supplied cost parameters and
+// a random salt.
+func NewScryptParams(n, r, p uint64) ScryptParams {
+	params := ScryptParams{N: n, R: r, P: p}
+	fastrand.Read(params.Salt[:])
+	return params
+}
+
+// Validate returns ErrInvalidScryptParams if the cost parameters are out of
+// range.
+func (params ScryptParams) Validate() error {
+	if params.N <= 1 || params.N&(params.N-1) != 0 || params.N >= 1<<31 {
+		return ErrInvalidScryptParams
+	} else if params.R == 0 || params.P == 0 || params.R >= 1<<30 || params.P >= 1<<30 || params.R*params.P >= 1<<30 {
+		return ErrInvalidScryptParams
+	}
+	return nil
+}
+
+// Weak reports whether the cost parameters are weaker than MinScryptParams.
+// Keys derived with weak parameters are easier to brute-force, so callers
+// should warn the user before using them.
+func (params ScryptParams) Weak() bool {
+	return params.N < MinScryptParams.N || params.R < MinScryptParams.R
+}
+
+// Expensive reports whether any of the cost parameters exceed those of
+// MaxScryptParams.
+func (params ScryptParams) Expensive() bool {
+	return params.N > MaxScryptParams.N || params.R > MaxScryptParams.R || params.P > MaxScryptParams.P
+}
+
+// DeriveKeyScrypt derives a 32-byte key from passphrase using scrypt with the
+// supplied parameters. Parameters that exceed MaxScryptParams are rejected.
+func DeriveKeyScrypt(passphrase []byte, params ScryptParams) ([]byte, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	} else if params.Expensive() {
+		return nil, ErrExpensiveScryptParams
+	}
+	return scrypt.Key(passphrase, params.Salt[:], int(params.N), int(params.R), int(params.P), scryptKeyLen)
+}
@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestDeriveKeyScrypt checks that DeriveKeyScrypt is deterministic, and that
+// its output depends on the passphrase, cost parameters, and salt.
+func TestDeriveKeyScrypt(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	params := NewScryptParams(1<<10, 8, 1)
+	key, err := DeriveKeyScrypt(passphrase, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(key) != scryptKeyLen {
+		t.Fatal("wrong key length:", len(key))
+	}
+	key2, err := DeriveKeyScrypt(passphrase, params)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(key, key2) {
+		t.Fatal("DeriveKeyScrypt is not deterministic")
+	}
+
+	// Changing any input should change the key.
+	differentParams := []ScryptParams{params, params, params, params}
+	differentParams[0].N = 1 << 11
+	differentParams[1].R = 4
+	differentParams[2].P = 2
+	differentParams[3].Salt[0]++
+	for _, p := range differentParams {
+		key2, err := DeriveKeyScrypt(passphrase, p)
+		if err != nil {
+			t.Fatal(err)
+		} else if bytes.Equal(key, key2) {
+			t.Fatal("different params produced the same key:", p)
+		}
+	}
+	if key2, _ := DeriveKeyScrypt([]byte("wrong"), params); bytes.Equal(key, key2) {
+		t.Fatal("different passphrases produced the same key")
+	}
+}
+
+// TestScryptParamsValidate checks that invalid cost parameters are rejected.
+func TestScryptParamsValidate(t *testing.T) {
+	for _, p := range []ScryptParams{
+		{N: 0, R: 8, P: 1},
+		{N: 1, R: 8, P: 1},
+		{N: 1000, R: 8, P: 1},
+		{N: 1 << 31, R: 8, P: 1},
+		{N: 1 << 14, R: 0, P: 1},
+		{N: 1 << 14, R: 8, P: 0},
+		{N: 1 << 14, R: 1 << 15, P: 1 << 15},
+	} {
+		if err := p.Validate(); err != ErrInvalidScryptParams {
+			t.Error("expected ErrInvalidScryptParams for", p.N, p.R, p.P)
+		}
+		if _, err := DeriveKeyScrypt(nil, p); err != ErrInvalidScryptParams {
+			t.Error("DeriveKeyScrypt accepted invalid params", p.N, p.R, p.P)
+		}
+	}
+	if err := DefaultScryptParams.Validate(); err != nil {
+		t.Fatal("DefaultScryptParams are invalid:", err)
+	}
+}
+
+// TestScryptParamsWeak checks that parameters weaker than MinScryptParams are
+// reported as weak.
+func TestScryptParamsWeak(t *testing.T) {
+	if DefaultScryptParams.Weak() || MinScryptParams.Weak() {
+		t.Fatal("default and minimum params should not be weak")
+	}
+	if !(ScryptParams{N: 1 << 10, R: 8, P: 1}).Weak() {
+		t.Fatal("low N should be weak")
+	}
+	if !(ScryptParams{N: 1 << 20, R: 1, P: 1}).Weak() {
+		t.Fatal("low R should be weak")
+	}
+}
+
+// TestScryptParamsExpensive checks that parameters exceeding MaxScryptParams
+// are reported as expensive and rejected by DeriveKeyScrypt.
+func TestScryptParamsExpensive(t *testing.T) {
+	if DefaultScryptParams.Expensive() || MaxScryptParams.Expensive() {
+		t.Fatal("default and maximum params should not be expensive")
+	}
+	for _, p := range []ScryptParams{
+		{N: MaxScryptParams.N * 2, R: 8, P: 1},
+		{N: 1 << 10, R: MaxScryptParams.R + 1, P: 1},
+		{N: 1 << 10, R: 8, P: MaxScryptParams.P + 1},
+	} {
+		if !p.Expensive() {
+			t.Error("params should be expensive:", p.N, p.R, p.P)
+		}
+		if _, err := DeriveKeyScrypt([]byte("foo"), p); err != ErrExpensiveScryptParams {
+			t.Error("expected ErrExpensiveScryptParams, got", err)
+		}
+	}
+}
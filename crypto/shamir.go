@@ -0,0 +1,126 @@
+package crypto
+
+// shamir.go implements Shamir's secret sharing over GF(2^8). Each byte of the
+// secret is shared independently, using a random polynomial whose constant
+// term is the secret byte. A share consists of the x-coordinate at which the
+// polynomials were evaluated, followed by the evaluations.
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+var (
+	// ErrInvalidShareParams is returned by SplitSecret if the number of
+	// shares or the threshold is out of range.
+	ErrInvalidShareParams = errors.New("threshold must be between 1 and the number of shares, which must be at most 255")
+
+	// ErrInvalidShares is returned by CombineShares if the shares are
+	// malformed, have different lengths, or share an x-coordinate.
+	ErrInvalidShares = errors.New("shares are malformed or inconsistent")
+)
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(2^8), using the
+// AES polynomial x^8 + x^4 + x^3 + x + 1 and the generator 3. gfExp is
+// doubled in length so that the sum of two logarithms can be used as an index
+// without reduction.
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = x, x
+		log[x] = byte(i)
+		// Multiply x by the generator, 3 = x + 1.
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return
+}()
+
+// gfMul multiplies a and b in GF(2^8).
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides a by b in GF(2^8). b must be nonzero.
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// SplitSecret splits secret into the requested number of shares, such that
+// any threshold of them can be combined to recover the secret, while fewer
+// than threshold shares reveal nothing about it. Each share is one byte longer
+// than the secret. At most 255 shares can be created.
+func SplitSecret(secret []byte, shares, threshold int) ([][]byte, error) {
+	if threshold < 1 || threshold > shares || shares > 255 {
+		return nil, ErrInvalidShareParams
+	}
+
+	// Choose a random polynomial of degree threshold-1 for each byte of the
+	// secret, with the secret byte as the constant term.
+	coeffs := make([][]byte, len(secret))
+	for i, b := range secret {
+		coeffs[i] = fastrand.Bytes(threshold)
+		coeffs[i][0] = b
+	}
+
+	// Evaluate the polynomials at x = 1, 2, ..., shares using Horner's
+	// method.
+	out := make([][]byte, shares)
+	for s := range out {
+		x := byte(s + 1)
+		share := make([]byte, len(secret)+1)
+		share[0] = x
+		for i, c := range coeffs {
+			var y byte
+			for j := len(c) - 1; j >= 0; j-- {
+				y = gfMul(y, x) ^ c[j]
+			}
+			share[i+1] = y
+		}
+		out[s] = share
+	}
+	return out, nil
+}
+
+// CombineShares recovers a secret from shares produced by SplitSecret. At
+// least the threshold number of shares must be supplied; if fewer are
+// supplied, the result is unrelated to the secret, and no error is returned.
+func CombineShares(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 || len(shares[0]) < 1 {
+		return nil, ErrInvalidShares
+	}
+	seen := make(map[byte]bool)
+	for _, share := range shares {
+		if len(share) != len(shares[0]) || share[0] == 0 || seen[share[0]] {
+			return nil, ErrInvalidShares
+		}
+		seen[share[0]] = true
+	}
+
+	// Use Lagrange interpolation to evaluate the polynomials at x = 0. In
+	// GF(2^8), subtraction is the same as addition (xor).
+	secret := make([]byte, len(shares[0])-1)
+	for i, si := range shares {
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = gfMul(basis, gfDiv(sj[0], sj[0]^si[0]))
+			}
+		}
+		for k := range secret {
+			secret[k] ^= gfMul(si[k+1], basis)
+		}
+	}
+	return secret, nil
+}
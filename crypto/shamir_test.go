@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestGFArithmetic checks that division in GF(2^8) is the inverse of
+// multiplication.
+func TestGFArithmetic(t *testing.T) {
+	for a := 0; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			if gfDiv(gfMul(byte(a), byte(b)), byte(b)) != byte(a) {
+				t.Fatalf("(%v * %v) / %v != %v", a, b, b, a)
+			}
+		}
+	}
+}
+
+// TestShamirSubsets checks that every subset of threshold shares reconstructs
+// the secret, and that fewer than threshold shares do not.
+func TestShamirSubsets(t *testing.T) {
+	secret := fastrand.Bytes(EntropySize)
+	const n, k = 5, 3
+	shares, err := SplitSecret(secret, n, k)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(shares) != n {
+		t.Fatal("wrong number of shares:", len(shares))
+	}
+
+	// Try every subset of the shares.
+	for mask := 1; mask < 1<<n; mask++ {
+		var subset [][]byte
+		for i := 0; i < n; i++ {
+			if mask&(1<<uint(i)) != 0 {
+				subset = append(subset, shares[i])
+			}
+		}
+		recovered, err := CombineShares(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(subset) >= k && !bytes.Equal(recovered, secret) {
+			t.Fatalf("subset %b did not recover the secret", mask)
+		} else if len(subset) < k && bytes.Equal(recovered, secret) {
+			t.Fatalf("subset %b of fewer than %v shares recovered the secret", mask, k)
+		}
+	}
+}
+
+// TestShamirHiding checks that fewer than threshold shares are consistent with
+// every possible secret, so that they reveal nothing about it.
+func TestShamirHiding(t *testing.T) {
+	// Split a one-byte secret into 3 shares with a threshold of 3. For any
+	// pair of shares, and any candidate secret, there is a third point that
+	// makes the pair combine to the candidate; i.e. the pair alone is
+	// consistent with every secret.
+	shares, err := SplitSecret([]byte{42}, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair := shares[:2]
+	for candidate := 0; candidate < 256; candidate++ {
+		var found bool
+		for y := 0; y < 256 && !found; y++ {
+			third := []byte{shares[2][0], byte(y)}
+			recovered, err := CombineShares([][]byte{pair[0], pair[1], third})
+			if err != nil {
+				t.Fatal(err)
+			}
+			found = recovered[0] == byte(candidate)
+		}
+		if !found {
+			t.Fatalf("shares are inconsistent with secret %v", candidate)
+		}
+	}
+}
+
+// TestShamirInvalid checks that invalid parameters and shares are rejected.
+func TestShamirInvalid(t *testing.T) {
+	for _, p := range [][2]int{{0, 0}, {3, 0}, {3, 4}, {256, 2}} {
+		if _, err := SplitSecret([]byte("secret"), p[0], p[1]); err != ErrInvalidShareParams {
+			t.Errorf("expected ErrInvalidShareParams for %v shares with threshold %v, got %v", p[0], p[1], err)
+		}
+	}
+	shares, err := SplitSecret([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][][]byte{
+		nil,
+		{shares[0], shares[0]},
+		{shares[0], shares[1][:3]},
+		{{0, 1, 2, 3, 4, 5, 6}, shares[1]},
+	} {
+		if _, err := CombineShares(bad); err != ErrInvalidShares {
+			t.Error("expected ErrInvalidShares, got", err)
+		}
+	}
+
+	// A threshold of 1 makes each share a copy of the secret.
+	shares, err = SplitSecret([]byte("secret"), 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := CombineShares(shares[1:]); string(s) != "secret" {
+		t.Fatal("single share did not recover the secret")
+	}
+}
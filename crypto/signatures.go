@@ -24,6 +24,11 @@ const (
 
 	// SignatureSize defines the size of signatures in bytes.
 	SignatureSize = ed25519.SignatureSize
+
+	// RecoverableSignatureSize defines the size of recoverable signatures in
+	// bytes. A recoverable signature is PublicKeySize (32) bytes larger than
+	// a regular signature.
+	RecoverableSignatureSize = SignatureSize + PublicKeySize
 )
 
 var (
@@ -42,6 +47,12 @@ type (
 	// Signature proves that data was signed by the owner of a particular
 	// public key's corresponding secret key.
 	Signature [SignatureSize]byte
+
+	// RecoverableSignature is a signature from which the signer's public key
+	// can be recovered. ed25519 does not support key recovery, so the public
+	// key is simply appended to the signature; recovery succeeds only if the
+	// signature is valid for that key.
+	RecoverableSignature [RecoverableSignatureSize]byte
 )
 
 // PublicKey returns the public key that corresponds to a secret key.
@@ -54,10 +65,21 @@ func (sk SecretKey) PublicKey() (pk PublicKey) {
 // messages.
 func GenerateKeyPair() (sk SecretKey, pk PublicKey) {
 	// no error possible when using fastrand.Reader
-	epk, esk, _ := ed25519.GenerateKey(fastrand.Reader)
+	sk, pk, _ = GenerateKeyPairFromReader(fastrand.Reader)
+	return
+}
+
+// GenerateKeyPairFromReader creates a public-secret keypair using entropy
+// read from r. Tests can pass a reader created by NewDeterministicReader to
+// generate reproducible keys.
+func GenerateKeyPairFromReader(r io.Reader) (sk SecretKey, pk PublicKey, err error) {
+	epk, esk, err := ed25519.GenerateKey(r)
+	if err != nil {
+		return SecretKey{}, PublicKey{}, err
+	}
 	copy(sk[:], esk)
 	copy(pk[:], epk)
-	return
+	return sk, pk, nil
 }
 
 // GenerateKeyPairDeterministic generates keys deterministically using the input
@@ -98,6 +120,29 @@ func SignHash(data Hash, sk SecretKey) (sig Signature) {
 	return
 }
 
+// SignRecoverable signs a message using a secret key, producing a signature
+// from which the corresponding public key can be recovered.
+func SignRecoverable(data Hash, sk SecretKey) (rsig RecoverableSignature) {
+	sig := SignHash(data, sk)
+	pk := sk.PublicKey()
+	copy(rsig[:SignatureSize], sig[:])
+	copy(rsig[SignatureSize:], pk[:])
+	return
+}
+
+// RecoverPublicKey returns the public key that produced a recoverable
+// signature for the input data. ErrInvalidSignature is returned if the
+// signature is not valid for the data.
+func RecoverPublicKey(data Hash, rsig RecoverableSignature) (pk PublicKey, err error) {
+	var sig Signature
+	copy(sig[:], rsig[:SignatureSize])
+	copy(pk[:], rsig[SignatureSize:])
+	if err := VerifyHash(data, pk, sig); err != nil {
+		return PublicKey{}, err
+	}
+	return pk, nil
+}
+
 // VerifyHash uses a public key and input data to verify a signature.
 func VerifyHash(data Hash, pk PublicKey, sig Signature) error {
 	verifies := ed25519.Verify(pk[:], data[:], sig[:])
@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"bytes"
+	"io"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/encoding"
@@ -138,6 +139,31 @@ func TestIntegrationSigKeyGeneration(t *testing.T) {
 	}
 }
 
+// TestGenerateKeyPairFromReader checks that keys generated from identical
+// entropy are identical.
+func TestGenerateKeyPairFromReader(t *testing.T) {
+	entropy := func(b byte) io.Reader { return bytes.NewReader(bytes.Repeat([]byte{b}, EntropySize)) }
+	sk1, pk1, err := GenerateKeyPairFromReader(entropy(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk2, pk2, err := GenerateKeyPairFromReader(entropy(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sk1 != sk2 || pk1 != pk2 {
+		t.Fatal("keys generated from the same seed differ")
+	}
+	if _, pk3, _ := GenerateKeyPairFromReader(entropy(2)); pk3 == pk1 {
+		t.Fatal("keys generated from different seeds are identical")
+	}
+
+	// A reader that runs out of entropy should cause an error.
+	if _, _, err := GenerateKeyPairFromReader(bytes.NewReader(make([]byte, 10))); err == nil {
+		t.Fatal("expected error when reader has insufficient entropy")
+	}
+}
+
 // TestReadWriteSignedObject tests the ReadSignObject and WriteSignedObject
 // functions, which are inverses of each other.
 func TestReadWriteSignedObject(t *testing.T) {
@@ -193,3 +219,35 @@ func TestUnitPublicKey(t *testing.T) {
 		}
 	}
 }
+
+// TestRecoverPublicKey checks that the public key can be recovered from a
+// recoverable signature, and that recovery fails if the message or key is
+// tampered with.
+func TestRecoverPublicKey(t *testing.T) {
+	sk, pk := GenerateKeyPair()
+	data := HashBytes(fastrand.Bytes(32))
+	rsig := SignRecoverable(data, sk)
+
+	recovered, err := RecoverPublicKey(data, rsig)
+	if err != nil {
+		t.Fatal(err)
+	} else if recovered != pk {
+		t.Fatal("recovered wrong public key")
+	}
+
+	// A tampered message should not yield the signer's key.
+	tampered := data
+	tampered[0]++
+	if recovered, err := RecoverPublicKey(tampered, rsig); err != ErrInvalidSignature {
+		t.Fatal("expected ErrInvalidSignature, got", err)
+	} else if recovered == pk {
+		t.Fatal("recovered signer's key from tampered message")
+	}
+
+	// Substituting another key should also fail.
+	_, pk2 := GenerateKeyPair()
+	copy(rsig[SignatureSize:], pk2[:])
+	if _, err := RecoverPublicKey(data, rsig); err != ErrInvalidSignature {
+		t.Fatal("expected ErrInvalidSignature, got", err)
+	}
+}
@@ -0,0 +1,172 @@
+package crypto
+
+// vrf.go implements the ECVRF-EDWARDS25519-SHA512-TAI verifiable random
+// function specified in RFC 9381, using the ed25519 keys used by the rest of
+// the package. For a given key and input there is exactly one output that
+// will pass verification, so the key holder cannot grind its outputs, and
+// anyone holding the public key can check that an output is correct.
+//
+// The curve arithmetic is provided by filippo.io/edwards25519, which performs
+// all operations involving the secret key in constant time.
+
+import (
+	"bytes"
+	"crypto/sha512"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	// VRFProofSize is the size of a VRF proof in bytes.
+	VRFProofSize = 80
+
+	// VRFOutputSize is the size of a VRF output in bytes.
+	VRFOutputSize = sha512.Size
+
+	// vrfSuite is the suite_string of ECVRF-EDWARDS25519-SHA512-TAI.
+	vrfSuite = 0x03
+
+	// vrfChallengeSize is the size of the challenge within a proof.
+	vrfChallengeSize = 16
+)
+
+// VRFOutput is the pseudorandom output of the VRF.
+type VRFOutput [VRFOutputSize]byte
+
+// VRFProve computes the VRF output for input using sk, along with a proof that
+// can be checked with VRFVerify. The output is deterministic for a given key
+// and input.
+func VRFProve(sk SecretKey, input []byte) (output VRFOutput, proof []byte) {
+	// Expand the secret key as ed25519 does: the first half of the hash of
+	// the seed is the secret scalar, and the second half seeds the nonce.
+	h := sha512.Sum512(sk[:32])
+	x, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		panic(err) // the input is always 32 bytes
+	}
+	pkBytes := sk[32:]
+
+	hPoint := vrfEncodeToCurve(pkBytes, input)
+	hBytes := hPoint.Bytes()
+	gamma := new(edwards25519.Point).ScalarMult(x, hPoint)
+
+	kHash := sha512.Sum512(append(h[32:], hBytes...))
+	k, err := edwards25519.NewScalar().SetUniformBytes(kHash[:])
+	if err != nil {
+		panic(err) // the input is always 64 bytes
+	}
+	kB := new(edwards25519.Point).ScalarBaseMult(k)
+	kH := new(edwards25519.Point).ScalarMult(k, hPoint)
+	c := vrfChallenge(pkBytes, hBytes, gamma.Bytes(), kB.Bytes(), kH.Bytes())
+	s := edwards25519.NewScalar().MultiplyAdd(vrfChallengeScalar(c), x, k)
+
+	proof = make([]byte, 0, VRFProofSize)
+	proof = append(proof, gamma.Bytes()...)
+	proof = append(proof, c...)
+	proof = append(proof, s.Bytes()...)
+	return vrfProofToOutput(gamma), proof
+}
+
+// VRFVerify reports whether output is the VRF output for input under pk, as
+// proven by proof.
+func VRFVerify(pk PublicKey, input []byte, output VRFOutput, proof []byte) bool {
+	if len(proof) != VRFProofSize {
+		return false
+	}
+	y, ok := vrfDecodePoint(pk[:])
+	if !ok || vrfIsIdentity(new(edwards25519.Point).MultByCofactor(y)) {
+		return false
+	}
+	gamma, ok := vrfDecodePoint(proof[:32])
+	if !ok {
+		return false
+	}
+	c := proof[32 : 32+vrfChallengeSize]
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(proof[32+vrfChallengeSize:])
+	if err != nil {
+		return false
+	}
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	hPoint := vrfEncodeToCurve(pk[:], input)
+	negC := edwards25519.NewScalar().Negate(vrfChallengeScalar(c))
+	u := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(negC, y, s)
+	v := new(edwards25519.Point).Add(
+		new(edwards25519.Point).ScalarMult(s, hPoint),
+		new(edwards25519.Point).ScalarMult(negC, gamma),
+	)
+	expected := vrfChallenge(pk[:], hPoint.Bytes(), proof[:32], u.Bytes(), v.Bytes())
+	if !bytes.Equal(expected, c) {
+		return false
+	}
+	return vrfProofToOutput(gamma) == output
+}
+
+// vrfEncodeToCurve hashes input to a point in the prime subgroup using the
+// try-and-increment method.
+func vrfEncodeToCurve(pk, input []byte) *edwards25519.Point {
+	for ctr := 0; ctr < 256; ctr++ {
+		buf := []byte{vrfSuite, 0x01}
+		buf = append(buf, pk...)
+		buf = append(buf, input...)
+		buf = append(buf, byte(ctr), 0x00)
+		h := sha512.Sum512(buf)
+		p, ok := vrfDecodePoint(h[:32])
+		if !ok {
+			continue
+		}
+		p.MultByCofactor(p)
+		if !vrfIsIdentity(p) {
+			return p
+		}
+	}
+	// Each attempt succeeds with probability 1/2, so this is unreachable in
+	// practice.
+	panic("failed to hash VRF input to curve")
+}
+
+// vrfChallenge computes the challenge for the given encoded points.
+func vrfChallenge(points ...[]byte) []byte {
+	buf := []byte{vrfSuite, 0x02}
+	for _, p := range points {
+		buf = append(buf, p...)
+	}
+	buf = append(buf, 0x00)
+	h := sha512.Sum512(buf)
+	return h[:vrfChallengeSize]
+}
+
+// vrfChallengeScalar interprets a challenge as a scalar. Challenges are much
+// shorter than the group order, so they never need to be reduced.
+func vrfChallengeScalar(c []byte) *edwards25519.Scalar {
+	var buf [32]byte
+	copy(buf[:], c)
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(buf[:])
+	if err != nil {
+		panic(err) // 16-byte values are always canonical
+	}
+	return s
+}
+
+// vrfProofToOutput computes the VRF output from the gamma point of a proof.
+func vrfProofToOutput(gamma *edwards25519.Point) VRFOutput {
+	buf := []byte{vrfSuite, 0x03}
+	buf = append(buf, new(edwards25519.Point).MultByCofactor(gamma).Bytes()...)
+	buf = append(buf, 0x00)
+	return VRFOutput(sha512.Sum512(buf))
+}
+
+// vrfDecodePoint decodes a point as specified in RFC 8032, returning false if
+// b is not the canonical encoding of a point on the curve.
+func vrfDecodePoint(b []byte) (*edwards25519.Point, bool) {
+	p, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil || !bytes.Equal(p.Bytes(), b) {
+		return nil, false
+	}
+	return p, true
+}
+
+// vrfIsIdentity reports whether p is the identity point.
+func vrfIsIdentity(p *edwards25519.Point) bool {
+	return p.Equal(edwards25519.NewIdentityPoint()) == 1
+}
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestVRFVectors checks VRFProve and VRFVerify against the
+// ECVRF-EDWARDS25519-SHA512-TAI test vectors in RFC 9381.
+func TestVRFVectors(t *testing.T) {
+	vectors := []struct {
+		sk, pk, alpha, pi, beta string
+	}{
+		{
+			sk:    "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			pk:    "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			alpha: "",
+			pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+			beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+		},
+	}
+	for i, v := range vectors {
+		var sk SecretKey
+		var pk PublicKey
+		var beta VRFOutput
+		seed, _ := hex.DecodeString(v.sk)
+		pkBytes, _ := hex.DecodeString(v.pk)
+		alpha, _ := hex.DecodeString(v.alpha)
+		pi, _ := hex.DecodeString(v.pi)
+		betaBytes, _ := hex.DecodeString(v.beta)
+		copy(sk[:], seed)
+		copy(sk[32:], pkBytes)
+		copy(pk[:], pkBytes)
+		copy(beta[:], betaBytes)
+
+		output, proof := VRFProve(sk, alpha)
+		if !bytes.Equal(proof, pi) {
+			t.Errorf("vector %v: wrong proof %x", i, proof)
+		}
+		if output != beta {
+			t.Errorf("vector %v: wrong output %x", i, output)
+		}
+		if !VRFVerify(pk, alpha, beta, pi) {
+			t.Errorf("vector %v: valid proof was rejected", i)
+		}
+	}
+}
+
+// TestVRF checks that VRF outputs are deterministic and verifiable, and that
+// forged outputs and proofs are rejected.
+func TestVRF(t *testing.T) {
+	sk, pk := GenerateKeyPair()
+	input := fastrand.Bytes(64)
+
+	// The output should be the same every time.
+	output, proof := VRFProve(sk, input)
+	output2, proof2 := VRFProve(sk, input)
+	if output != output2 || !bytes.Equal(proof, proof2) {
+		t.Fatal("VRF output is not deterministic")
+	}
+	if !VRFVerify(pk, input, output, proof) {
+		t.Fatal("valid VRF proof was rejected")
+	}
+
+	// A different input should produce a different output.
+	otherOutput, _ := VRFProve(sk, append(input, 0))
+	if otherOutput == output {
+		t.Error("different inputs produced the same output")
+	}
+	if VRFVerify(pk, append(input, 0), output, proof) {
+		t.Error("proof verified for the wrong input")
+	}
+
+	// A forged output should be rejected, even with a valid proof.
+	var forged VRFOutput
+	fastrand.Read(forged[:])
+	if VRFVerify(pk, input, forged, proof) {
+		t.Error("forged output was accepted")
+	}
+
+	// An output and proof created with a different key should be rejected.
+	sk2, pk2 := GenerateKeyPair()
+	output2, proof2 = VRFProve(sk2, input)
+	if VRFVerify(pk, input, output2, proof2) {
+		t.Error("proof from the wrong key was accepted")
+	}
+	if !VRFVerify(pk2, input, output2, proof2) {
+		t.Error("valid VRF proof was rejected")
+	}
+
+	// Tampering with any part of the proof should cause it to be rejected.
+	for _, i := range []int{0, 32, VRFProofSize - 1} {
+		badProof := append([]byte(nil), proof...)
+		badProof[i] ^= 1
+		if VRFVerify(pk, input, output, badProof) {
+			t.Errorf("proof with byte %v tampered was accepted", i)
+		}
+	}
+	if VRFVerify(pk, input, output, proof[:VRFProofSize-1]) {
+		t.Error("truncated proof was accepted")
+	}
+}
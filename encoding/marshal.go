@@ -15,10 +15,18 @@ import (
 const (
 	maxDecodeLen = 12e6 // 12 MB
 	maxSliceLen  = 5e6  // 5 MB
+
+	// DefaultMaxDepth is the maximum nesting depth of decoded objects if a
+	// Decoder's MaxDepth is zero.
+	DefaultMaxDepth = 64
 )
 
 var (
 	errBadPointer = errors.New("cannot decode into invalid pointer")
+
+	// ErrMaxDepth is returned by Decode if the object being decoded is
+	// nested more deeply than the Decoder's MaxDepth.
+	ErrMaxDepth = errors.New("encoded object exceeds maximum nesting depth")
 )
 
 type (
@@ -34,7 +42,8 @@ type (
 
 	// An Encoder writes objects to an output stream.
 	Encoder struct {
-		w io.Writer
+		w      io.Writer
+		varint bool
 	}
 )
 
@@ -67,7 +76,7 @@ func (e *Encoder) write(p []byte) error {
 // the package docstring.
 func (e *Encoder) encode(val reflect.Value) error {
 	// check for MarshalSia interface first
-	if val.CanInterface() {
+	if val.CanInterface() && (!e.varint || !reflectable(val.Type())) {
 		if m, ok := val.Interface().(SiaMarshaler); ok {
 			return m.MarshalSia(e.w)
 		}
@@ -89,14 +98,17 @@ func (e *Encoder) encode(val reflect.Value) error {
 			return e.write([]byte{0})
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return e.write(EncInt64(val.Int()))
+		return e.writeInt(val.Int())
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return WriteUint64(e.w, val.Uint())
+		return e.writeUint(val.Uint())
 	case reflect.String:
-		return WritePrefix(e.w, []byte(val.String()))
+		if err := e.writeUint(uint64(val.Len())); err != nil {
+			return err
+		}
+		return e.write([]byte(val.String()))
 	case reflect.Slice:
 		// slices are variable length, so prepend the length and then fallthrough to array logic
-		if err := WriteInt(e.w, val.Len()); err != nil {
+		if err := e.writeUint(uint64(val.Len())); err != nil {
 			return err
 		}
 		if val.Len() == 0 {
@@ -138,7 +150,7 @@ func (e *Encoder) encode(val reflect.Value) error {
 
 // NewEncoder returns a new encoder that writes to w.
 func NewEncoder(w io.Writer) *Encoder {
-	return &Encoder{w}
+	return &Encoder{w: w}
 }
 
 // Marshal returns the encoding of v. For encoding details, see the package
@@ -175,8 +187,16 @@ func WriteFile(filename string, v interface{}) error {
 
 // A Decoder reads and decodes values from an input stream.
 type Decoder struct {
-	r io.Reader
-	n int
+	// MaxDepth is the maximum depth of nested pointers, slices, arrays, and
+	// structs that the Decoder will decode, guarding against stack exhaustion
+	// when decoding recursive types. If MaxDepth is zero, DefaultMaxDepth is
+	// used.
+	MaxDepth int
+
+	r      io.Reader
+	n      int
+	depth  int
+	varint bool
 }
 
 // Read implements the io.Reader interface. It also keeps track of the total
@@ -204,13 +224,16 @@ func (d *Decoder) Decode(v interface{}) (err error) {
 	// catch decoding panics and convert them to errors
 	// note that this allows us to skip boundary checks during decoding
 	defer func() {
-		if r := recover(); r != nil {
+		if r := recover(); r == ErrMaxDepth {
+			err = ErrMaxDepth
+		} else if r != nil {
 			err = fmt.Errorf("could not decode type %s: %v", pval.Elem().Type().String(), r)
 		}
 	}()
 
-	// reset the read count
+	// reset the read count and nesting depth
 	d.n = 0
+	d.depth = 0
 
 	d.decode(pval.Elem())
 	return
@@ -238,6 +261,13 @@ func (d *Decoder) readN(n int) []byte {
 
 // readPrefix reads a length-prefixed byte slice and panics if the read fails.
 func (d *Decoder) readPrefix() []byte {
+	if d.varint {
+		n := d.readUint()
+		if n > maxSliceLen {
+			panic(fmt.Sprintf("length %v exceeds maxLen of %v", n, uint64(maxSliceLen)))
+		}
+		return d.readN(int(n))
+	}
 	b, err := ReadPrefix(d, maxSliceLen)
 	if err != nil {
 		panic(err)
@@ -245,12 +275,23 @@ func (d *Decoder) readPrefix() []byte {
 	return b
 }
 
+// enter increments the nesting depth and panics if it exceeds the maximum.
+func (d *Decoder) enter() {
+	maxDepth := d.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if d.depth++; d.depth > maxDepth {
+		panic(ErrMaxDepth)
+	}
+}
+
 // decode reads the next encoded value from its input stream and stores it in
 // val. The decoding rules are the inverse of those specified in the package
 // docstring.
 func (d *Decoder) decode(val reflect.Value) {
 	// check for UnmarshalSia interface first
-	if val.CanAddr() && val.Addr().CanInterface() {
+	if val.CanAddr() && val.Addr().CanInterface() && (!d.varint || !reflectable(val.Type())) {
 		if u, ok := val.Addr().Interface().(SiaUnmarshaler); ok {
 			err := u.UnmarshalSia(d)
 			if err != nil {
@@ -272,7 +313,9 @@ func (d *Decoder) decode(val reflect.Value) {
 		if val.IsNil() {
 			val.Set(reflect.New(val.Type().Elem()))
 		}
+		d.enter()
 		d.decode(val.Elem())
+		d.depth--
 	case reflect.Bool:
 		b := d.readN(1)
 		if b[0] > 1 {
@@ -280,15 +323,15 @@ func (d *Decoder) decode(val reflect.Value) {
 		}
 		val.SetBool(b[0] == 1)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		val.SetInt(DecInt64(d.readN(8)))
+		val.SetInt(d.readInt())
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		val.SetUint(DecUint64(d.readN(8)))
+		val.SetUint(d.readUint())
 	case reflect.String:
 		val.SetString(string(d.readPrefix()))
 	case reflect.Slice:
 		// slices are variable length, but otherwise the same as arrays.
 		// just have to allocate them first, then we can fallthrough to the array logic.
-		sliceLen := DecUint64(d.readN(8))
+		sliceLen := d.readUint()
 		// sanity-check the sliceLen, otherwise you can crash a peer by making
 		// them allocate a massive slice
 		if sliceLen > 1<<31-1 || sliceLen*uint64(val.Type().Elem().Size()) > maxSliceLen {
@@ -310,14 +353,18 @@ func (d *Decoder) decode(val reflect.Value) {
 			return
 		}
 		// arrays are unmarshalled by sequentially unmarshalling their elements
+		d.enter()
 		for i := 0; i < val.Len(); i++ {
 			d.decode(val.Index(i))
 		}
+		d.depth--
 		return
 	case reflect.Struct:
+		d.enter()
 		for i := 0; i < val.NumField(); i++ {
 			d.decode(val.Field(i))
 		}
+		d.depth--
 		return
 	default:
 		panic("unknown type")
@@ -326,7 +373,7 @@ func (d *Decoder) decode(val reflect.Value) {
 
 // NewDecoder returns a new decoder that reads from r.
 func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{r, 0}
+	return &Decoder{r: r}
 }
 
 // Unmarshal decodes the encoded value b and stores it in v, which must be a
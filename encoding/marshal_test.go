@@ -181,6 +181,47 @@ func TestDecode(t *testing.T) {
 
 }
 
+// nestedSlice is a recursive type that can be nested arbitrarily deeply.
+type nestedSlice []nestedSlice
+
+// TestDecodeMaxDepth checks that the decoder rejects objects that are nested
+// more deeply than its MaxDepth.
+func TestDecodeMaxDepth(t *testing.T) {
+	var deep nestedSlice
+	for i := 0; i < DefaultMaxDepth*2; i++ {
+		deep = nestedSlice{deep}
+	}
+	b := Marshal(deep)
+	if err := Unmarshal(b, new(nestedSlice)); err != ErrMaxDepth {
+		t.Fatal("expected ErrMaxDepth, got", err)
+	}
+
+	// A higher limit should allow the object to be decoded.
+	dec := NewDecoder(bytes.NewReader(b))
+	dec.MaxDepth = DefaultMaxDepth * 2
+	var decoded nestedSlice
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(decoded, deep) {
+		t.Fatal("nested object was not decoded correctly")
+	}
+
+	// The limit applies to statically nested types as well.
+	dec = NewDecoder(bytes.NewReader(Marshal([][][]uint64{{{1}}})))
+	dec.MaxDepth = 2
+	if err := dec.Decode(new([][][]uint64)); err != ErrMaxDepth {
+		t.Fatal("expected ErrMaxDepth, got", err)
+	}
+
+	// Normal objects should be unaffected.
+	for i, v := range testStructs {
+		dec := NewDecoder(bytes.NewReader(testEncodings[i]))
+		if err := dec.Decode(reflect.New(reflect.Indirect(reflect.ValueOf(v)).Type()).Interface()); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
 // TestMarshalUnmarshal tests the Marshal and Unmarshal functions, which are
 // inverses of each other.
 func TestMarshalUnmarshal(t *testing.T) {
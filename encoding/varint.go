@@ -0,0 +1,128 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+)
+
+// The v2 object format is identical to the standard format, except that
+// integers, string lengths, and slice lengths are encoded as varints instead
+// of 8-byte little-endian integers. Unsigned integers use the LEB128 encoding
+// of encoding/binary, and signed integers are zig-zag encoded first. Varints
+// must be minimally encoded, so that every object has exactly one encoding.
+//
+// The v2 format is opt-in, since it is not wire-compatible with the standard
+// format. It is produced by an Encoder created with NewVarintEncoder, or by
+// MarshalV2.
+//
+// Many types implement SiaMarshaler only to speed up the standard encoding,
+// which they hardcode. When encoding in the v2 format, custom marshalers are
+// therefore only used for types that cannot be encoded using reflection,
+// i.e. structs with unexported fields such as types.Currency.
+
+// reflectable reports whether values of type t can be encoded and decoded
+// using reflection alone, without a custom (un)marshaler.
+func reflectable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// writeUint writes u as an 8-byte integer, or as a varint if the encoder is
+// in varint mode.
+func (e *Encoder) writeUint(u uint64) error {
+	if !e.varint {
+		return e.write(EncUint64(u))
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	return e.write(buf[:binary.PutUvarint(buf, u)])
+}
+
+// writeInt writes i as an 8-byte integer, or as a zig-zag encoded varint if
+// the encoder is in varint mode.
+func (e *Encoder) writeInt(i int64) error {
+	if !e.varint {
+		return e.write(EncInt64(i))
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	return e.write(buf[:binary.PutVarint(buf, i)])
+}
+
+// ReadByte implements the io.ByteReader interface.
+func (d *Decoder) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(d, b[:])
+	return b[0], err
+}
+
+// readUint reads an 8-byte integer, or a varint if the decoder is in varint
+// mode, and panics if the read fails.
+func (d *Decoder) readUint() uint64 {
+	if !d.varint {
+		return DecUint64(d.readN(8))
+	}
+	start := d.n
+	u, err := binary.ReadUvarint(d)
+	if err != nil {
+		panic(err)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	if d.n-start != binary.PutUvarint(buf, u) {
+		panic("varint is not minimally encoded")
+	}
+	return u
+}
+
+// readInt reads an 8-byte integer, or a zig-zag encoded varint if the decoder
+// is in varint mode, and panics if the read fails.
+func (d *Decoder) readInt() int64 {
+	if !d.varint {
+		return DecInt64(d.readN(8))
+	}
+	start := d.n
+	i, err := binary.ReadVarint(d)
+	if err != nil {
+		panic(err)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	if d.n-start != binary.PutVarint(buf, i) {
+		panic("varint is not minimally encoded")
+	}
+	return i
+}
+
+// NewVarintEncoder returns a new encoder that writes objects to w in the v2
+// format.
+func NewVarintEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, varint: true}
+}
+
+// NewVarintDecoder returns a new decoder that reads objects in the v2 format
+// from r.
+func NewVarintDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, varint: true}
+}
+
+// MarshalV2 returns the v2 encoding of v.
+func MarshalV2(v interface{}) []byte {
+	b := new(bytes.Buffer)
+	NewVarintEncoder(b).Encode(v) // no error possible when using a bytes.Buffer
+	return b.Bytes()
+}
+
+// UnmarshalV2 decodes the v2-encoded value b and stores it in v, which must
+// be a pointer.
+func UnmarshalV2(b []byte, v interface{}) error {
+	return NewVarintDecoder(bytes.NewReader(b)).Decode(v)
+}
@@ -0,0 +1,110 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestVarintUint64 checks that unsigned varints round-trip across the full
+// uint64 range, including every power-of-two boundary.
+func TestVarintUint64(t *testing.T) {
+	vals := []uint64{0, math.MaxUint64}
+	for i := uint(0); i < 64; i++ {
+		vals = append(vals, 1<<i-1, 1<<i, 1<<i+1)
+	}
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, binary.LittleEndian.Uint64(fastrand.Bytes(8)))
+	}
+	for _, u := range vals {
+		b := MarshalV2(u)
+		if len(b) > 10 {
+			t.Fatalf("encoding of %v is %v bytes", u, len(b))
+		}
+		var dec uint64
+		if err := UnmarshalV2(b, &dec); err != nil {
+			t.Fatal(err)
+		} else if dec != u {
+			t.Fatalf("expected %v, got %v", u, dec)
+		}
+	}
+	// Small values should fit in a single byte.
+	if b := MarshalV2(uint64(127)); len(b) != 1 {
+		t.Error("127 should be encoded as a single byte, got", b)
+	}
+}
+
+// TestVarintInt64 checks that signed varints round-trip across the full int64
+// range.
+func TestVarintInt64(t *testing.T) {
+	vals := []int64{0, math.MinInt64, math.MaxInt64}
+	for i := uint(0); i < 63; i++ {
+		vals = append(vals, 1<<i-1, 1<<i, -1<<i, -1<<i+1)
+	}
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, int64(binary.LittleEndian.Uint64(fastrand.Bytes(8))))
+	}
+	for _, i := range vals {
+		var dec int64
+		if err := UnmarshalV2(MarshalV2(i), &dec); err != nil {
+			t.Fatal(err)
+		} else if dec != i {
+			t.Fatalf("expected %v, got %v", i, dec)
+		}
+	}
+	if b := MarshalV2(int64(-1)); len(b) != 1 {
+		t.Error("-1 should be encoded as a single byte, got", b)
+	}
+}
+
+// TestVarintNonMinimal checks that varints with redundant bytes are rejected,
+// so that each object has a single encoding.
+func TestVarintNonMinimal(t *testing.T) {
+	var u uint64
+	if err := UnmarshalV2([]byte{0x80, 0x00}, &u); err == nil {
+		t.Error("expected non-minimal varint to be rejected")
+	}
+	var i int64
+	if err := UnmarshalV2([]byte{0x81, 0x00}, &i); err == nil {
+		t.Error("expected non-minimal varint to be rejected")
+	}
+	// An overlong varint should be rejected rather than overflowing.
+	overlong := bytes.Repeat([]byte{0xFF}, 11)
+	if err := UnmarshalV2(overlong, &u); err == nil {
+		t.Error("expected overlong varint to be rejected")
+	}
+}
+
+// TestMarshalUnmarshalV2 checks that the test types round-trip in the v2
+// format, and that the v2 format is smaller than the standard format.
+func TestMarshalUnmarshalV2(t *testing.T) {
+	var emptyStructs = []interface{}{&test0{}, &test1{}, &test2{}, &test3{}, &test4{}, &test5{}, &test6{}}
+	for i := range testStructs {
+		b := MarshalV2(testStructs[i])
+		if len(b) > len(Marshal(testStructs[i])) {
+			t.Errorf("v2 encoding of testStructs[%d] is larger than the standard encoding", i)
+		}
+		err := UnmarshalV2(b, emptyStructs[i])
+		if err != nil {
+			t.Error(err)
+		}
+		exp := reflect.ValueOf(testStructs[i])
+		if exp.Kind() != reflect.Ptr {
+			exp = reflect.New(exp.Type())
+			exp.Elem().Set(reflect.ValueOf(testStructs[i]))
+		}
+		if !reflect.DeepEqual(exp.Interface(), emptyStructs[i]) {
+			t.Errorf("testStructs[%d] did not round-trip: expected %v, got %v", i, exp.Interface(), emptyStructs[i])
+		}
+	}
+
+	// Objects in the v2 format should not decode in the standard format.
+	var s string
+	if err := Unmarshal(MarshalV2("foo"), &s); err == nil {
+		t.Error("v2 encoding decoded as a standard encoding")
+	}
+}
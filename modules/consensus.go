@@ -2,6 +2,7 @@ package modules
 
 import (
 	"errors"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/types"
@@ -111,6 +112,14 @@ type (
 		// consensus set in the recent change.
 		SiafundPoolDiffs []SiafundPoolDiff
 
+		// MaturedSiacoinOutputs contains the delayed siacoin outputs, such as
+		// miner payouts, that matured in the recent change. Outputs that
+		// matured in an applied block have the direction 'DiffApply', and
+		// outputs that matured in a reverted block, and are therefore no
+		// longer mature, have the direction 'DiffRevert'. The same outputs
+		// also appear in SiacoinOutputDiffs and DelayedSiacoinOutputDiffs.
+		MaturedSiacoinOutputs []SiacoinOutputDiff
+
 		// ChildTarget defines the target of any block that would be the child
 		// of the block most recently appended to the consensus set.
 		ChildTarget types.Target
@@ -175,6 +184,40 @@ type (
 		Adjusted  types.Currency
 	}
 
+	// A ChainTip is the last block of a chain known to the consensus set. Work
+	// is the cumulative difficulty of the chain ending at the tip; the
+	// consensus set follows the chain with the most work, subject to
+	// SurpassThreshold.
+	ChainTip struct {
+		ID     types.BlockID     `json:"id"`
+		Height types.BlockHeight `json:"height"`
+		Work   types.Currency    `json:"work"`
+	}
+
+	// A DifficultyPoint describes the difficulty of a block in the current
+	// path. Target is the target that the block had to meet, and Hashrate is
+	// the estimated network hashrate in hashes per second, computed from the
+	// difficulty of the target and the time since the block's parent.
+	DifficultyPoint struct {
+		Height    types.BlockHeight `json:"height"`
+		Timestamp types.Timestamp   `json:"timestamp"`
+		Target    types.Target      `json:"target"`
+		Hashrate  types.Currency    `json:"hashrate"`
+	}
+
+	// ValidationTimings break down the time spent validating a block.
+	// ProofOfWork is the time spent checking the block header, including its
+	// proof of work. Signatures is the time spent checking the standalone
+	// validity of the block's transactions, which is dominated by signature
+	// verification. Updates is the time spent validating the transactions
+	// against the consensus set and applying the resulting diffs to the
+	// unspent outputs.
+	ValidationTimings struct {
+		ProofOfWork time.Duration `json:"proofofwork"`
+		Signatures  time.Duration `json:"signatures"`
+		Updates     time.Duration `json:"updates"`
+	}
+
 	// A ConsensusSet accepts blocks and builds an understanding of network
 	// consensus.
 	ConsensusSet interface {
@@ -190,6 +233,23 @@ type (
 		// bool to indicate whether that block exists.
 		BlockAtHeight(types.BlockHeight) (types.Block, bool)
 
+		// BlockSubsidy returns the number of siacoins created by the block at
+		// the given height, excluding miner fees. The subsidy is paid out as
+		// a delayed siacoin output, and is reported to subscribers through
+		// the DelayedSiacoinOutputDiffs of the consensus change.
+		BlockSubsidy(types.BlockHeight) types.Currency
+
+		// CirculatingSupply returns the number of spendable siacoins in
+		// existence after the block at the given height in the current path:
+		// the initial allocation plus all block subsidies, minus any coins
+		// burned by sending them to the void address.
+		CirculatingSupply(types.BlockHeight) types.Currency
+
+		// ChangeForBlock returns the consensus change produced by applying
+		// the block with the given id on its own. The block must have been
+		// applied at some point, but need not be in the current path.
+		ChangeForBlock(types.BlockID) (ConsensusChange, error)
+
 		// ChildTarget returns the target required to extend the current heaviest
 		// fork. This function is typically used by miners looking to extend the
 		// heaviest fork.
@@ -205,17 +265,41 @@ type (
 		// described by the ConsensusChangeX variables in this package.
 		ConsensusSetSubscribe(ConsensusSetSubscriber, ConsensusChangeID) error
 
+		// CompetingChains returns the tips of every chain known to the
+		// consensus set, including the current chain, sorted from most to
+		// least work.
+		CompetingChains() []ChainTip
+
+		// Confirmations returns the number of blocks in the current path
+		// that confirm the transaction with the given id, counting the block
+		// that contains it. False is returned if the transaction is not in
+		// the current path.
+		Confirmations(types.TransactionID) (int, bool)
+
 		// CurrentBlock returns the latest block in the heaviest known
 		// blockchain.
 		CurrentBlock() types.Block
 
+		// CurrentTip returns the tip of the chain that the consensus set is
+		// currently following.
+		CurrentTip() ChainTip
+
 		// Flush will cause the consensus set to finish all in-progress
 		// routines.
 		Flush() error
 
+		// DifficultyHistory returns the target and estimated hashrate of
+		// each block in the current path between fromHeight and toHeight,
+		// inclusive.
+		DifficultyHistory(fromHeight, toHeight types.BlockHeight) []DifficultyPoint
+
 		// Height returns the current height of consensus.
 		Height() types.BlockHeight
 
+		// IsFinal returns true if the transaction with the given id is in the
+		// current path and has at least the given number of confirmations.
+		IsFinal(id types.TransactionID, minConfirmations int) bool
+
 		// Synced returns true if the consensus set is synced with the network.
 		Synced() bool
 
@@ -229,6 +313,17 @@ type (
 		// risk of mining invalid blocks.
 		MinimumValidChildTimestamp(types.BlockID) (types.Timestamp, bool)
 
+		// MedianTimePast returns the median timestamp of the window of blocks
+		// ending at the given height in the current path. The child of the
+		// block at that height must have a timestamp no earlier than the
+		// median.
+		MedianTimePast(types.BlockHeight) types.Timestamp
+
+		// SetValidationProfiler sets a function that is called with the
+		// validation timings of each block that the consensus set validates.
+		// A nil function disables profiling.
+		SetValidationProfiler(func(height types.BlockHeight, timings ValidationTimings))
+
 		// StorageProofSegment returns the segment to be used in the storage proof for
 		// a given file contract.
 		StorageProofSegment(types.FileContractID) (uint64, error)
@@ -243,6 +338,10 @@ type (
 		// allowing for garbage collection and rescanning. If the subscriber is
 		// not found in the subscriber database, no action is taken.
 		Unsubscribe(ConsensusSetSubscriber)
+
+		// ValidateTransaction checks whether a transaction would be accepted
+		// by the current consensus set without applying it.
+		ValidateTransaction(types.Transaction) error
 	}
 )
 
@@ -1,7 +1,6 @@
 package consensus
 
 import (
-	"bytes"
 	"errors"
 	"time"
 
@@ -65,8 +64,7 @@ func (cs *ConsensusSet) validateHeaderAndBlock(tx dbTx, b types.Block) error {
 
 // checkHeaderTarget returns true if the header's ID meets the given target.
 func checkHeaderTarget(h types.BlockHeader, target types.Target) bool {
-	blockHash := h.ID()
-	return bytes.Compare(target[:], blockHash[:]) >= 0
+	return h.ValidateProofOfWork(target)
 }
 
 // validateHeader does some early, low computation verification on the header
@@ -190,6 +188,12 @@ func (cs *ConsensusSet) addBlockToTree(b types.Block) (ce changeEntry, err error
 	if err != nil {
 		return changeEntry{}, err
 	}
+	// The block was added to the block map, so it replaces its parent as a
+	// chain tip.
+	if cs.chainTips != nil {
+		delete(cs.chainTips, b.ParentID)
+		cs.chainTips[b.ID()] = struct{}{}
+	}
 	if nonExtending {
 		return changeEntry{}, modules.ErrNonExtendingBlock
 	}
@@ -211,8 +215,10 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	// Grab a lock on the consensus set. Lock is demoted later in the function,
 	// failure to unlock before returning an error will cause a deadlock.
 	cs.mu.Lock()
+	cs.profiledBlocks = cs.profiledBlocks[:0]
 
 	// Start verification inside of a bolt View tx.
+	var headerTime time.Duration
 	err := cs.db.View(func(tx *bolt.Tx) error {
 		// Do not accept a block if the database is inconsistent.
 		if inconsistencyDetected(tx) {
@@ -222,7 +228,9 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 		// Do some relatively inexpensive checks to validate the header and block.
 		// Validation generally occurs in the order of least expensive validation
 		// first.
+		start := time.Now()
 		err := cs.validateHeaderAndBlock(boltTxWrapper{tx}, b)
+		headerTime = time.Since(start)
 		if err != nil {
 			// If the block is in the near future, but too far to be acceptable, then
 			// save the block and add it to the consensus set after it is no longer
@@ -255,6 +263,10 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 		return nil
 	})
 	if err != nil {
+		// Hold on to orphans in case their parents arrive shortly.
+		if err == errOrphan {
+			cs.addOrphan(b)
+		}
 		cs.mu.Unlock()
 		return err
 	}
@@ -265,7 +277,14 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	// the longest fork.
 	changeEntry, err := cs.addBlockToTree(b)
 	if err != nil {
+		// A non-extending block is still added to the block tree, so its
+		// orphaned children may now extend the longest fork.
+		var children []types.Block
+		if err == modules.ErrNonExtendingBlock {
+			children = cs.takeOrphanChildren(b.ID())
+		}
 		cs.mu.Unlock()
+		cs.managedPromoteOrphans(children)
 		return err
 	}
 	// If appliedBlocks is 0, revertedBlocks will also be 0.
@@ -277,7 +296,19 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	if len(changeEntry.AppliedBlocks) > 0 {
 		cs.readlockUpdateSubscribers(changeEntry)
 	}
+	children := cs.takeOrphanChildren(b.ID())
+	profiler, profiled := cs.validationProfiler, cs.takeProfiledBlocks(headerTime)
 	cs.mu.Unlock()
+
+	// Report the validation timings of the applied blocks.
+	if profiler != nil {
+		for _, pb := range profiled {
+			profiler(pb.height, pb.timings)
+		}
+	}
+
+	// Add any orphans that were waiting for this block.
+	cs.managedPromoteOrphans(children)
 	return nil
 }
 
@@ -3,6 +3,7 @@ package consensus
 import (
 	"bytes"
 	"errors"
+	"math/big"
 	"testing"
 	"time"
 
@@ -623,6 +624,90 @@ func TestOrphanHandling(t *testing.T) {
 	}
 }
 
+// TestOrphanPromotion checks that an orphan block is held by the consensus set
+// and added once its parent arrives.
+func TestOrphanPromotion(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Create a parent block and a child of the parent, without submitting
+	// either. The child is solved against a harder target than its parent so
+	// that it meets the parent's child target.
+	height := cst.cs.Height()
+	parent, target, err := cst.miner.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent = solveTestBlock(parent, target)
+	child := solveTestBlock(types.Block{
+		ParentID:     parent.ID(),
+		Timestamp:    types.CurrentTimestamp(),
+		MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(height + 2), UnlockHash: parent.MinerPayouts[0].UnlockHash}},
+	}, target.MulDifficulty(big.NewRat(16, 1)))
+
+	// Submit the child first. It should be held as an orphan.
+	if err := cst.cs.AcceptBlock(child); err != errOrphan {
+		t.Fatalf("expected %v, got %v", errOrphan, err)
+	}
+	if n := cst.cs.OrphanCount(); n != 1 {
+		t.Fatal("expected 1 orphan, got", n)
+	}
+
+	// Submitting the parent should add both blocks.
+	if err := cst.cs.AcceptBlock(parent); err != nil {
+		t.Fatal(err)
+	}
+	if cst.cs.Height() != height+2 || cst.cs.CurrentBlock().ID() != child.ID() {
+		t.Fatal("orphan was not promoted after its parent arrived")
+	}
+	if n := cst.cs.OrphanCount(); n != 0 {
+		t.Fatal("expected 0 orphans, got", n)
+	}
+}
+
+// TestOrphanTarget checks that orphan blocks are only held if they meet the
+// minimum orphan target.
+func TestOrphanTarget(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Find an orphan that does not meet the minimum orphan target.
+	target := cst.cs.minOrphanTarget()
+	orphan := types.Block{ParentID: types.BlockID{1}}
+	for orphan.Header().ValidateProofOfWork(target) {
+		orphan.Nonce[0]++
+	}
+	if err := cst.cs.AcceptBlock(orphan); err != errOrphan {
+		t.Fatalf("expected %v, got %v", errOrphan, err)
+	}
+	if n := cst.cs.OrphanCount(); n != 0 {
+		t.Fatal("orphan without enough work was held:", n)
+	}
+
+	// An orphan that meets the target should be held.
+	orphan = solveTestBlock(orphan, target)
+	if err := cst.cs.AcceptBlock(orphan); err != errOrphan {
+		t.Fatalf("expected %v, got %v", errOrphan, err)
+	}
+	if n := cst.cs.OrphanCount(); n != 1 {
+		t.Fatal("expected 1 orphan, got", n)
+	}
+}
+
 // TestMissedTarget submits a block that does not meet the required target.
 func TestMissedTarget(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,97 @@
+package consensus
+
+import (
+	"bytes"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// updateTransactionIndex adds the transactions of pb to the transaction index
+// when pb is applied to the current path, and removes them when pb is
+// reverted. Transactions without inputs can appear in more than one block; the
+// index refers to the earliest one.
+func updateTransactionIndex(tx *bolt.Tx, pb *processedBlock, dir modules.DiffDirection) {
+	bucket := tx.Bucket(TransactionIndex)
+	bid := pb.Block.ID()
+	for _, txn := range pb.Block.Transactions {
+		txid := txn.ID()
+		indexed := bucket.Get(txid[:])
+		var err error
+		if dir == modules.DiffApply && indexed == nil {
+			err = bucket.Put(txid[:], bid[:])
+		} else if dir == modules.DiffRevert && bytes.Equal(indexed, bid[:]) {
+			err = bucket.Delete(txid[:])
+		}
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+	}
+}
+
+// initTransactionIndex creates the TransactionIndex bucket and fills it in for
+// every block in the current path.
+func initTransactionIndex(tx *bolt.Tx) error {
+	_, err := tx.CreateBucket(TransactionIndex)
+	if err != nil {
+		return err
+	}
+	for height := types.BlockHeight(0); height <= blockHeight(tx); height++ {
+		id, err := getPath(tx, height)
+		if err != nil {
+			return err
+		}
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return err
+		}
+		updateTransactionIndex(tx, pb, modules.DiffApply)
+	}
+	return nil
+}
+
+// transactionConfirmations returns the number of confirmations of the
+// transaction with the given id.
+func transactionConfirmations(tx *bolt.Tx, id types.TransactionID) (int, bool) {
+	bidBytes := tx.Bucket(TransactionIndex).Get(id[:])
+	if bidBytes == nil {
+		return 0, false
+	}
+	var bid types.BlockID
+	copy(bid[:], bidBytes)
+	pb, err := getBlockMap(tx, bid)
+	if err != nil {
+		return 0, false
+	}
+	return int(blockHeight(tx)-pb.Height) + 1, true
+}
+
+// Confirmations returns the number of blocks in the current path that confirm
+// the transaction with the given id, including the block that contains it. A
+// transaction in the current block has one confirmation. False is returned if
+// the transaction is not in the current path, for example because the block
+// containing it was reverted.
+func (cs *ConsensusSet) Confirmations(id types.TransactionID) (confirmations int, exists bool) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return 0, false
+	}
+	defer cs.tg.Done()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		confirmations, exists = transactionConfirmations(tx, id)
+		return nil
+	})
+	return confirmations, exists
+}
+
+// IsFinal returns true if the transaction with the given id is in the current
+// path and has at least minConfirmations confirmations.
+func (cs *ConsensusSet) IsFinal(id types.TransactionID, minConfirmations int) bool {
+	confirmations, exists := cs.Confirmations(id)
+	return exists && confirmations >= minConfirmations
+}
@@ -0,0 +1,84 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestConfirmations checks that the number of confirmations of a transaction
+// increases as blocks are added, and that the transaction is no longer
+// confirmed after a reorg removes it.
+func TestConfirmations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Genesis transactions are confirmed by every block.
+	genesisTxn := types.GenesisBlock.Transactions[0].ID()
+	if n, ok := cst.cs.Confirmations(genesisTxn); !ok || n != int(cst.cs.Height())+1 {
+		t.Fatal("wrong number of confirmations for genesis transaction:", n, ok)
+	}
+
+	txns, err := cst.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := txns[len(txns)-1].ID()
+	if _, ok := cst.cs.Confirmations(id); ok {
+		t.Fatal("unconfirmed transaction should not have confirmations")
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := cst.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+		if n, ok := cst.cs.Confirmations(id); !ok || n != i {
+			t.Fatalf("expected %v confirmations, got %v (%v)", i, n, ok)
+		}
+	}
+	if !cst.cs.IsFinal(id, 3) {
+		t.Fatal("transaction with 3 confirmations should be final with a minimum of 3")
+	}
+	if cst.cs.IsFinal(id, 4) {
+		t.Fatal("transaction with 3 confirmations should not be final with a minimum of 4")
+	}
+
+	// Reorg onto a longer chain that does not contain the transaction.
+	alt, err := createConsensusSetTester(t.Name() + "alt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer alt.Close()
+	for alt.cs.Height() <= cst.cs.Height() {
+		if _, err := alt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := types.BlockHeight(1); i <= alt.cs.Height(); i++ {
+		b, ok := alt.cs.BlockAtHeight(i)
+		if !ok {
+			t.Fatal("missing block at height", i)
+		}
+		// err is not checked - the blocks before the fork overtakes the
+		// current chain are not extending.
+		_ = cst.cs.AcceptBlock(b)
+	}
+	if cst.cs.CurrentBlock().ID() != alt.cs.CurrentBlock().ID() {
+		t.Fatal("reorg did not happen")
+	}
+	if n, ok := cst.cs.Confirmations(id); ok {
+		t.Fatal("reorged transaction should not have confirmations, got", n)
+	}
+	if cst.cs.IsFinal(id, 1) {
+		t.Fatal("reorged transaction should not be final")
+	}
+	if n, ok := cst.cs.Confirmations(genesisTxn); !ok || n != int(cst.cs.Height())+1 {
+		t.Fatal("wrong number of confirmations for genesis transaction after reorg:", n, ok)
+	}
+}
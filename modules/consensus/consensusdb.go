@@ -41,6 +41,12 @@ var (
 	// blocks in the current path.
 	BlockPath = []byte("BlockPath")
 
+	// CirculatingSupply is a database bucket containing a mapping from the
+	// height of a block to the circulating supply of siacoins after the block
+	// was applied. Like BlockPath, it only includes blocks in the current
+	// path.
+	CirculatingSupply = []byte("CirculatingSupply")
+
 	// Consistency is a database bucket with a flag indicating whether
 	// inconsistencies within the database have been detected.
 	Consistency = []byte("Consistency")
@@ -60,6 +66,11 @@ var (
 	// SiafundPool is a database bucket storing the current value of the
 	// siafund pool.
 	SiafundPool = []byte("SiafundPool")
+
+	// TransactionIndex is a database bucket containing a mapping from the id
+	// of each transaction in the current path to the id of the block that
+	// contains it.
+	TransactionIndex = []byte("TransactionIndex")
 )
 
 // createConsensusObjects initialzes the consensus portions of the database.
@@ -69,11 +80,13 @@ func (cs *ConsensusSet) createConsensusDB(tx *bolt.Tx) error {
 		BlockHeight,
 		BlockMap,
 		BlockPath,
+		CirculatingSupply,
 		Consistency,
 		SiacoinOutputs,
 		FileContracts,
 		SiafundOutputs,
 		SiafundPool,
+		TransactionIndex,
 	}
 	for _, bucket := range buckets {
 		_, err := tx.CreateBucket(bucket)
@@ -93,9 +106,12 @@ func (cs *ConsensusSet) createConsensusDB(tx *bolt.Tx) error {
 	// Set the siafund pool to 0.
 	setSiafundPool(tx, types.NewCurrency64(0))
 
-	// Update the siafund output diffs map for the genesis block on disk. This
-	// needs to happen between the database being opened/initilized and the
-	// consensus set hash being calculated
+	// Update the siacoin and siafund output diffs map for the genesis block on
+	// disk. This needs to happen between the database being
+	// opened/initilized and the consensus set hash being calculated
+	for _, scod := range cs.blockRoot.SiacoinOutputDiffs {
+		commitSiacoinOutputDiff(tx, scod, modules.DiffApply)
+	}
 	for _, sfod := range cs.blockRoot.SiafundOutputDiffs {
 		commitSiafundOutputDiff(tx, sfod, modules.DiffApply)
 	}
@@ -111,6 +127,8 @@ func (cs *ConsensusSet) createConsensusDB(tx *bolt.Tx) error {
 	// Add the genesis block to the block structures - checksum must be taken
 	// after pushing the genesis block into the path.
 	pushPath(tx, cs.blockRoot.Block.ID())
+	updateCirculatingSupply(tx, &cs.blockRoot, modules.DiffApply)
+	updateTransactionIndex(tx, &cs.blockRoot, modules.DiffApply)
 	if build.DEBUG {
 		cs.blockRoot.ConsensusChecksum = consensusChecksum(tx)
 	}
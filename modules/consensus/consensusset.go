@@ -7,7 +7,9 @@ package consensus
 // commitDiff functions will be sufficient.
 
 import (
+	"bytes"
 	"errors"
+	"sort"
 
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
@@ -20,7 +22,8 @@ import (
 )
 
 var (
-	errNilGateway = errors.New("cannot have a nil gateway as input")
+	errGenesisHasParent = errors.New("genesis block cannot have a parent")
+	errNilGateway       = errors.New("cannot have a nil gateway as input")
 )
 
 // marshaler marshals objects into byte slices and unmarshals byte
@@ -69,6 +72,16 @@ type ConsensusSet struct {
 	// the genesis block, meaning the PoW is not very expensive.
 	dosBlocks map[types.BlockID]struct{}
 
+	// orphans are blocks whose parents are not yet known. They are held
+	// briefly, and added to the consensus set if their parents arrive. The
+	// pool is bounded by maxOrphans.
+	orphans map[types.BlockID]orphanBlock
+
+	// chainTips is the set of known blocks that no other known block builds
+	// on. It is computed from the block map the first time it is needed, and
+	// then kept up to date as blocks are added to the block tree.
+	chainTips map[types.BlockID]struct{}
+
 	// checkingConsistency is a bool indicating whether or not a consistency
 	// check is in progress. The consistency check logic call itself, resulting
 	// in infinite loops. This bool prevents that while still allowing for full
@@ -81,6 +94,12 @@ type ConsensusSet struct {
 	// whether the consensus set is synced with the network.
 	synced bool
 
+	// validationProfiler, if set, is called with the validation timings of
+	// each block that is validated. profiledBlocks holds the timings of the
+	// blocks validated by the current call to managedAcceptBlock.
+	validationProfiler func(types.BlockHeight, modules.ValidationTimings)
+	profiledBlocks     []profiledBlock
+
 	// Interfaces to abstract the dependencies of the ConsensusSet.
 	marshaler       marshaler
 	blockRuleHelper blockRuleHelper
@@ -98,17 +117,31 @@ type ConsensusSet struct {
 // there is an existing block database present in the persist directory, it
 // will be loaded.
 func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*ConsensusSet, error) {
+	return NewCustomConsensusSet(types.GenesisBlock, gateway, bootstrap, persistDir)
+}
+
+// NewCustomConsensusSet returns a new ConsensusSet that uses the provided
+// genesis block instead of types.GenesisBlock. This allows private test
+// networks to define their own initial siacoin and siafund allocations, which
+// are taken from the outputs of the genesis block's transactions. A database
+// created with one genesis block cannot be loaded with another. The genesis
+// block must not have a parent ID, as other modules recognize the genesis
+// block by its empty parent ID.
+func NewCustomConsensusSet(genesis types.Block, gateway modules.Gateway, bootstrap bool, persistDir string) (*ConsensusSet, error) {
 	// Check for nil dependencies.
 	if gateway == nil {
 		return nil, errNilGateway
 	}
+	if !genesis.IsGenesis() {
+		return nil, errGenesisHasParent
+	}
 
 	// Create the ConsensusSet object.
 	cs := &ConsensusSet{
 		gateway: gateway,
 
 		blockRoot: processedBlock{
-			Block:       types.GenesisBlock,
+			Block:       genesis,
 			ChildTarget: types.RootTarget,
 			Depth:       types.RootDepth,
 
@@ -116,6 +149,7 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*Consensus
 		},
 
 		dosBlocks: make(map[types.BlockID]struct{}),
+		orphans:   make(map[types.BlockID]orphanBlock),
 
 		marshaler:       stdMarshaler{},
 		blockRuleHelper: stdBlockRuleHelper{},
@@ -124,15 +158,24 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*Consensus
 		persistDir: persistDir,
 	}
 
-	// Create the diffs for the genesis siafund outputs.
-	for i, siafundOutput := range types.GenesisBlock.Transactions[0].SiafundOutputs {
-		sfid := types.GenesisBlock.Transactions[0].SiafundOutputID(uint64(i))
-		sfod := modules.SiafundOutputDiff{
-			Direction:     modules.DiffApply,
-			ID:            sfid,
-			SiafundOutput: siafundOutput,
+	// Create the diffs for the genesis siacoin and siafund outputs.
+	for _, txn := range genesis.Transactions {
+		for i, siacoinOutput := range txn.SiacoinOutputs {
+			scod := modules.SiacoinOutputDiff{
+				Direction:     modules.DiffApply,
+				ID:            txn.SiacoinOutputID(uint64(i)),
+				SiacoinOutput: siacoinOutput,
+			}
+			cs.blockRoot.SiacoinOutputDiffs = append(cs.blockRoot.SiacoinOutputDiffs, scod)
+		}
+		for i, siafundOutput := range txn.SiafundOutputs {
+			sfod := modules.SiafundOutputDiff{
+				Direction:     modules.DiffApply,
+				ID:            txn.SiafundOutputID(uint64(i)),
+				SiafundOutput: siafundOutput,
+			}
+			cs.blockRoot.SiafundOutputDiffs = append(cs.blockRoot.SiafundOutputDiffs, sfod)
 		}
-		cs.blockRoot.SiafundOutputDiffs = append(cs.blockRoot.SiafundOutputDiffs, sfod)
 	}
 
 	// Initialize the consensus persistence structures.
@@ -202,6 +245,12 @@ func (cs *ConsensusSet) BlockAtHeight(height types.BlockHeight) (block types.Blo
 	return block, exists
 }
 
+// BlockSubsidy returns the coinbase subsidy for a block at the given height.
+// The subsidy does not include any miner fees.
+func (cs *ConsensusSet) BlockSubsidy(height types.BlockHeight) types.Currency {
+	return types.CalculateCoinbase(height)
+}
+
 // ChildTarget returns the target for the child of a block.
 func (cs *ConsensusSet) ChildTarget(id types.BlockID) (target types.Target, exists bool) {
 	// A call to a closed database can cause undefined behavior.
@@ -342,6 +391,130 @@ func (cs *ConsensusSet) MinimumValidChildTimestamp(id types.BlockID) (timestamp
 	return timestamp, exists
 }
 
+// MedianTimePast returns the median timestamp of the MedianTimestampWindow
+// blocks in the current path ending at the block at the given height. This is
+// the value that consensus uses to validate timestamps: the child of that block
+// must have a timestamp no earlier than the median. Zero is returned if there
+// is no block at the given height.
+func (cs *ConsensusSet) MedianTimePast(height types.BlockHeight) (timestamp types.Timestamp) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return 0
+	}
+	defer cs.tg.Done()
+
+	// Error is not checked because it does not matter.
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		id, err := getPath(tx, height)
+		if err != nil {
+			return err
+		}
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return err
+		}
+		timestamp = cs.blockRuleHelper.minimumValidChildTimestamp(tx.Bucket(BlockMap), pb)
+		return nil
+	})
+	return timestamp
+}
+
+// chainTip returns the ChainTip for a processed block.
+func chainTip(id types.BlockID, height types.BlockHeight, depth types.Target) modules.ChainTip {
+	return modules.ChainTip{
+		ID:     id,
+		Height: height,
+		Work:   depth.Difficulty(),
+	}
+}
+
+// scanChainTips returns the IDs of the blocks in the block map that no other
+// block builds on. Only the leading fields of each processed block are
+// decoded; the diffs are not needed.
+func scanChainTips(tx *bolt.Tx) (map[types.BlockID]struct{}, error) {
+	var ids []types.BlockID
+	hasChild := make(map[types.BlockID]struct{})
+	err := tx.Bucket(BlockMap).ForEach(func(_, v []byte) error {
+		var b types.Block
+		err := encoding.NewDecoder(bytes.NewReader(v)).Decode(&b)
+		if err != nil {
+			return err
+		}
+		hasChild[b.ParentID] = struct{}{}
+		ids = append(ids, b.ID())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[types.BlockID]struct{})
+	for _, id := range ids {
+		if _, ok := hasChild[id]; !ok {
+			tips[id] = struct{}{}
+		}
+	}
+	return tips, nil
+}
+
+// CompetingChains returns the tips of every chain known to the consensus set,
+// sorted from most to least work. The current tip is included. A block is a
+// tip if no other known block builds on it, so stale blocks from old forks
+// appear as well.
+func (cs *ConsensusSet) CompetingChains() []modules.ChainTip {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cs.tg.Done()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var tips []modules.ChainTip
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		// The block map is only scanned on the first call. Afterwards, the
+		// set of tips is updated by addBlockToTree.
+		if cs.chainTips == nil {
+			chainTips, err := scanChainTips(tx)
+			if err != nil {
+				return err
+			}
+			cs.chainTips = chainTips
+		}
+		for id := range cs.chainTips {
+			pb, err := getBlockMap(tx, id)
+			if err != nil {
+				return err
+			}
+			tips = append(tips, chainTip(id, pb.Height, pb.Depth))
+		}
+		return nil
+	})
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Work.Cmp(tips[j].Work) > 0
+	})
+	return tips
+}
+
+// CurrentTip returns the tip of the chain that the consensus set is currently
+// following.
+func (cs *ConsensusSet) CurrentTip() (tip modules.ChainTip) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return modules.ChainTip{}
+	}
+	defer cs.tg.Done()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		pb := currentProcessedBlock(tx)
+		tip = chainTip(pb.Block.ID(), pb.Height, pb.Depth)
+		return nil
+	})
+	return tip
+}
+
 // StorageProofSegment returns the segment to be used in the storage proof for
 // a given file contract.
 func (cs *ConsensusSet) StorageProofSegment(fcid types.FileContractID) (index uint64, err error) {
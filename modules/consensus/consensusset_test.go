@@ -1,7 +1,10 @@
 package consensus
 
 import (
+	"bytes"
+	"encoding/binary"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -12,6 +15,7 @@ import (
 	"github.com/NebulousLabs/Sia/modules/transactionpool"
 	"github.com/NebulousLabs/Sia/modules/wallet"
 	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/bolt"
 	"github.com/NebulousLabs/fastrand"
 )
 
@@ -199,3 +203,328 @@ func TestDatabaseClosing(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestBlockSubsidy checks that the subsidy reported by the consensus set
+// follows the coinbase schedule, including the point at which the coinbase
+// stops decreasing.
+func TestBlockSubsidy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := blankConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	deflationBlocks := types.BlockHeight(types.InitialCoinbase - types.MinimumCoinbase)
+	tests := []struct {
+		height types.BlockHeight
+		coins  uint64
+	}{
+		{0, types.InitialCoinbase},
+		{1, types.InitialCoinbase - 1},
+		{deflationBlocks - 1, types.MinimumCoinbase + 1},
+		{deflationBlocks, types.MinimumCoinbase},
+		{deflationBlocks + 1, types.MinimumCoinbase},
+		{types.BlockHeight(types.InitialCoinbase) + 1, types.MinimumCoinbase},
+		{1e9, types.MinimumCoinbase},
+	}
+	for _, test := range tests {
+		expected := types.NewCurrency64(test.coins).Mul(types.SiacoinPrecision)
+		if subsidy := cst.cs.BlockSubsidy(test.height); subsidy.Cmp(expected) != 0 {
+			t.Errorf("wrong subsidy at height %v: expected %v, got %v", test.height, expected, subsidy)
+		}
+	}
+
+	// The subsidy of the current block should match the delayed output
+	// created for the miner payout, less the miner fees.
+	b, _ := cst.miner.FindBlock()
+	if err := cst.cs.AcceptBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	if subsidy := cst.cs.BlockSubsidy(cst.cs.Height()); subsidy.Cmp(b.CalculateSubsidy(cst.cs.Height())) != 0 {
+		t.Error("block subsidy does not match the subsidy of the mined block")
+	}
+}
+
+// TestMedianTimePast checks that MedianTimePast matches the median of a known
+// sequence of block timestamps.
+func TestMedianTimePast(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// The median at the current height should match the minimum timestamp
+	// that consensus enforces for the next block.
+	minTimestamp, _ := cst.cs.MinimumValidChildTimestamp(cst.cs.CurrentBlock().ID())
+	if mtp := cst.cs.MedianTimePast(cst.cs.Height()); mtp != minTimestamp {
+		t.Fatalf("expected %v, got %v", minTimestamp, mtp)
+	}
+
+	// Mine a sequence of blocks whose timestamps are out of order, while
+	// remaining valid.
+	startHeight := cst.cs.Height() + 1
+	var timestamps []types.Timestamp
+	for i := 0; i < 2*int(types.MedianTimestampWindow); i++ {
+		block, target, err := cst.miner.BlockForWork()
+		if err != nil {
+			t.Fatal(err)
+		}
+		minTimestamp, _ := cst.cs.MinimumValidChildTimestamp(block.ParentID)
+		block.Timestamp = minTimestamp + types.Timestamp(i*7%5)
+		solvedBlock, _ := cst.miner.SolveBlock(block, target)
+		err = cst.cs.AcceptBlock(solvedBlock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timestamps = append(timestamps, block.Timestamp)
+	}
+
+	// Check every height whose window is covered by the known timestamps.
+	window := int(types.MedianTimestampWindow)
+	for i := window - 1; i < len(timestamps); i++ {
+		sorted := append(types.TimestampSlice(nil), timestamps[i-window+1:i+1]...)
+		sort.Sort(sorted)
+		expected := sorted[len(sorted)/2]
+		height := startHeight + types.BlockHeight(i)
+		if mtp := cst.cs.MedianTimePast(height); mtp != expected {
+			t.Errorf("wrong median at height %v: expected %v, got %v", height, expected, mtp)
+		}
+	}
+
+	// The genesis block fills the window with its own timestamp.
+	if mtp := cst.cs.MedianTimePast(0); mtp != types.GenesisTimestamp {
+		t.Errorf("expected the genesis timestamp at height 0, got %v", mtp)
+	}
+	if mtp := cst.cs.MedianTimePast(cst.cs.Height() + 1); mtp != 0 {
+		t.Errorf("expected zero for a height beyond the current block, got %v", mtp)
+	}
+}
+
+// TestCompetingChains feeds two competing chains to a consensus set and
+// checks that both tips are reported with the correct work, and that the
+// heavier one is current.
+func TestCompetingChains(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst1, err := blankConsensusSetTester(t.Name() + "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst1.Close()
+	cst2, err := blankConsensusSetTester(t.Name() + "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst2.Close()
+
+	// Only the genesis block is known.
+	genesis := cst1.cs.CurrentTip()
+	if tips := cst1.cs.CompetingChains(); len(tips) != 1 || tips[0].ID != genesis.ID || tips[0].Work.Cmp(genesis.Work) != 0 {
+		t.Fatal("expected only the genesis tip, got", tips)
+	}
+
+	// Mine two blocks on the first chain and three on the second.
+	var chain1, chain2 []types.Block
+	for i := 0; i < 2; i++ {
+		b, err := cst1.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain1 = append(chain1, b)
+	}
+	for i := 0; i < 3; i++ {
+		b, err := cst2.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain2 = append(chain2, b)
+	}
+
+	// checkTip checks that tip matches the block in cst1's block map.
+	checkTip := func(tip modules.ChainTip, id types.BlockID) {
+		pb, err := cst1.cs.dbGetBlockMap(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tip.ID != id || tip.Height != pb.Height || tip.Work.Cmp(pb.Depth.Difficulty()) != 0 {
+			t.Errorf("tip %v does not match block %v at height %v", tip, id, pb.Height)
+		}
+	}
+
+	// Feed the first two blocks of the second chain to the first consensus
+	// set. The chains are the same length, so the first remains current.
+	for _, b := range chain2[:2] {
+		err = cst1.cs.AcceptBlock(b)
+		if err != nil && err != modules.ErrNonExtendingBlock {
+			t.Fatal(err)
+		}
+	}
+	if tip := cst1.cs.CurrentTip(); tip.ID != chain1[1].ID() {
+		t.Fatal("current tip changed before the second chain was heavier")
+	}
+	tips := cst1.cs.CompetingChains()
+	if len(tips) != 2 {
+		t.Fatal("expected 2 tips, got", len(tips))
+	}
+	ids := map[types.BlockID]modules.ChainTip{tips[0].ID: tips[0], tips[1].ID: tips[1]}
+	for _, id := range []types.BlockID{chain1[1].ID(), chain2[1].ID()} {
+		tip, ok := ids[id]
+		if !ok {
+			t.Fatal("missing tip", id)
+		}
+		checkTip(tip, id)
+	}
+
+	// Feed the last block of the second chain. It is now heavier, and should
+	// become current.
+	err = cst1.cs.AcceptBlock(chain2[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := cst1.cs.CurrentTip()
+	checkTip(current, chain2[2].ID())
+	tips = cst1.cs.CompetingChains()
+	if len(tips) != 2 {
+		t.Fatal("expected 2 tips, got", len(tips))
+	}
+	if tips[0].ID != current.ID {
+		t.Error("heaviest tip is not current")
+	}
+	checkTip(tips[1], chain1[1].ID())
+	if tips[0].Work.Cmp(tips[1].Work) <= 0 {
+		t.Error("current tip does not have the most work")
+	}
+}
+
+// solveTestBlock finds a nonce for b that satisfies target.
+func solveTestBlock(b types.Block, target types.Target) types.Block {
+	for nonce := uint64(0); ; nonce++ {
+		binary.LittleEndian.PutUint64(b.Nonce[:], nonce)
+		id := b.ID()
+		if bytes.Compare(target[:], id[:]) >= 0 {
+			return b
+		}
+	}
+}
+
+// TestCustomGenesis checks that a consensus set created with a custom genesis
+// block includes the genesis outputs, accepts blocks building on the custom
+// genesis, and rejects blocks building on the standard genesis.
+func TestCustomGenesis(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testdir := build.TempDir(modules.ConsensusDir, t.Name())
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	addr := randAddress()
+	genesis := types.Block{
+		Timestamp: types.CurrentTimestamp() - 1e4,
+		Transactions: []types.Transaction{{
+			SiacoinOutputs: []types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(1e6), UnlockHash: addr}},
+			SiafundOutputs: []types.SiafundOutput{{Value: types.NewCurrency64(500), UnlockHash: addr}},
+		}},
+	}
+	cs, err := NewCustomConsensusSet(genesis, g, false, filepath.Join(testdir, modules.ConsensusDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	if cs.CurrentBlock().ID() != genesis.ID() {
+		t.Fatal("consensus set does not start at the custom genesis block")
+	}
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		sco, err := getSiacoinOutput(tx, genesis.Transactions[0].SiacoinOutputID(0))
+		if err != nil || sco.UnlockHash != addr {
+			t.Error("genesis siacoin output was not created:", err)
+		}
+		sfo, err := getSiafundOutput(tx, genesis.Transactions[0].SiafundOutputID(0))
+		if err != nil || sfo.UnlockHash != addr {
+			t.Error("genesis siafund output was not created:", err)
+		}
+		return nil
+	})
+
+	// A block building on the custom genesis should be accepted.
+	target, _ := cs.ChildTarget(genesis.ID())
+	b := solveTestBlock(types.Block{
+		ParentID:     genesis.ID(),
+		Timestamp:    types.CurrentTimestamp(),
+		MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(1), UnlockHash: addr}},
+	}, target)
+	if err := cs.AcceptBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	if cs.Height() != 1 || cs.CurrentBlock().ID() != b.ID() {
+		t.Fatal("block building on custom genesis was not added to the current path")
+	}
+	expected := types.SiacoinPrecision.Mul64(1e6).Add(types.CalculateCoinbase(1))
+	if supply := cs.CirculatingSupply(1); !supply.Equals(expected) {
+		t.Fatalf("expected circulating supply %v, got %v", expected, supply)
+	}
+
+	// Other modules should track the height of the custom chain; a miner
+	// that miscounted would create blocks with the wrong subsidy.
+	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.Close()
+	w, err := wallet.New(cs, tp, filepath.Join(testdir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	key := crypto.GenerateTwofishKey()
+	if _, err := w.Encrypt(key); err != nil {
+		t.Fatal(err)
+	} else if err := w.Unlock(key); err != nil {
+		t.Fatal(err)
+	}
+	m, err := miner.New(cs, tp, w, filepath.Join(testdir, modules.MinerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, err := m.AddBlock(); err != nil {
+		t.Fatal("miner could not extend the custom chain:", err)
+	}
+
+	// A block building on the standard genesis should be rejected.
+	b = solveTestBlock(types.Block{
+		ParentID:     types.GenesisID,
+		Timestamp:    types.CurrentTimestamp(),
+		MinerPayouts: []types.SiacoinOutput{{Value: types.CalculateCoinbase(1), UnlockHash: addr}},
+	}, target)
+	if err := cs.AcceptBlock(b); err != errOrphan {
+		t.Fatal("expected errOrphan, got", err)
+	}
+
+	// The consensus set should be consistent with the custom allocation.
+	_ = cs.db.Update(func(tx *bolt.Tx) error {
+		cs.checkConsistency(tx)
+		return nil
+	})
+
+	// A genesis block must not have a parent.
+	if _, err := NewCustomConsensusSet(b, g, false, filepath.Join(testdir, "other")); err != errGenesisHasParent {
+		t.Fatal("expected errGenesisHasParent, got", err)
+	}
+}
@@ -76,9 +76,22 @@ func consensusChecksum(tx *bolt.Tx) crypto.Hash {
 	return tree.Root()
 }
 
+// genesisAllocation returns the number of siacoins and siafunds created by the
+// outputs of the genesis block.
+func (cs *ConsensusSet) genesisAllocation() (siacoins, siafunds types.Currency) {
+	for _, scod := range cs.blockRoot.SiacoinOutputDiffs {
+		siacoins = siacoins.Add(scod.SiacoinOutput.Value)
+	}
+	for _, sfod := range cs.blockRoot.SiafundOutputDiffs {
+		siafunds = siafunds.Add(sfod.SiafundOutput.Value)
+	}
+	return siacoins, siafunds
+}
+
 // checkSiacoinCount checks that the number of siacoins countable within the
-// consensus set equal the expected number of siacoins for the block height.
-func checkSiacoinCount(tx *bolt.Tx) {
+// consensus set equal the expected number of siacoins for the block height,
+// plus the siacoins allocated in the genesis block.
+func checkSiacoinCount(tx *bolt.Tx, genesisSiacoins types.Currency) {
 	// Iterate through all the buckets looking for the delayed siacoin output
 	// buckets, and check that they are for the correct heights.
 	var dscoSiacoins types.Currency
@@ -159,7 +172,7 @@ func checkSiacoinCount(tx *bolt.Tx) {
 		manageErr(tx, err)
 	}
 
-	expectedSiacoins := types.CalculateNumSiacoins(blockHeight(tx))
+	expectedSiacoins := types.CalculateNumSiacoins(blockHeight(tx)).Add(genesisSiacoins)
 	totalSiacoins := dscoSiacoins.Add(scoSiacoins).Add(fcSiacoins).Add(claimSiacoins)
 	if !totalSiacoins.Equals(expectedSiacoins) {
 		diagnostics := fmt.Sprintf("Wrong number of siacoins\nDsco: %v\nSco: %v\nFc: %v\nClaim: %v\n", dscoSiacoins, scoSiacoins, fcSiacoins, claimSiacoins)
@@ -173,8 +186,8 @@ func checkSiacoinCount(tx *bolt.Tx) {
 }
 
 // checkSiafundCount checks that the number of siafunds countable within the
-// consensus set equal the expected number of siafunds for the block height.
-func checkSiafundCount(tx *bolt.Tx) {
+// consensus set equal the number of siafunds allocated in the genesis block.
+func checkSiafundCount(tx *bolt.Tx, genesisSiafunds types.Currency) {
 	var total types.Currency
 	err := tx.Bucket(SiafundOutputs).ForEach(func(_, siafundOutputBytes []byte) error {
 		var sfo types.SiafundOutput
@@ -188,7 +201,7 @@ func checkSiafundCount(tx *bolt.Tx) {
 	if err != nil {
 		manageErr(tx, err)
 	}
-	if !total.Equals(types.SiafundCount) {
+	if !total.Equals(genesisSiafunds) {
 		manageErr(tx, errors.New("wrong number if siafunds in the consensus set"))
 	}
 }
@@ -319,8 +332,9 @@ func (cs *ConsensusSet) checkConsistency(tx *bolt.Tx) {
 
 	cs.checkingConsistency = true
 	checkDSCOs(tx)
-	checkSiacoinCount(tx)
-	checkSiafundCount(tx)
+	genesisSiacoins, genesisSiafunds := cs.genesisAllocation()
+	checkSiacoinCount(tx, genesisSiacoins)
+	checkSiafundCount(tx, genesisSiafunds)
 	if build.DEBUG {
 		cs.checkRevertApply(tx)
 	}
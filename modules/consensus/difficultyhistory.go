@@ -0,0 +1,72 @@
+package consensus
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// difficultyPoint returns the DifficultyPoint of the block at the given height
+// in the current path. The genesis block has no parent, so its interval is
+// assumed to be types.BlockFrequency.
+func difficultyPoint(tx *bolt.Tx, height types.BlockHeight) (modules.DifficultyPoint, error) {
+	id, err := getPath(tx, height)
+	if err != nil {
+		return modules.DifficultyPoint{}, err
+	}
+	pb, err := getBlockMap(tx, id)
+	if err != nil {
+		return modules.DifficultyPoint{}, err
+	}
+	target := types.RootTarget
+	interval := types.Timestamp(types.BlockFrequency)
+	if height > 0 {
+		parent, err := getBlockMap(tx, pb.Block.ParentID)
+		if err != nil {
+			return modules.DifficultyPoint{}, err
+		}
+		target = parent.ChildTarget
+		interval = 1
+		if pb.Block.Timestamp > parent.Block.Timestamp {
+			interval = pb.Block.Timestamp - parent.Block.Timestamp
+		}
+	}
+	return modules.DifficultyPoint{
+		Height:    height,
+		Timestamp: pb.Block.Timestamp,
+		Target:    target,
+		Hashrate:  target.Difficulty().Div64(uint64(interval)),
+	}, nil
+}
+
+// DifficultyHistory returns the target and estimated hashrate of each block in
+// the current path between fromHeight and toHeight, inclusive. toHeight is
+// capped at the current height. Since block timestamps are only accurate to
+// the second, and blocks can be found in quick succession, the hashrate of an
+// individual block is a noisy estimate; the interval between two blocks is
+// treated as at least one second.
+func (cs *ConsensusSet) DifficultyHistory(fromHeight, toHeight types.BlockHeight) []modules.DifficultyPoint {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cs.tg.Done()
+
+	var points []modules.DifficultyPoint
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		if height := blockHeight(tx); toHeight > height {
+			toHeight = height
+		}
+		for height := fromHeight; height <= toHeight; height++ {
+			point, err := difficultyPoint(tx, height)
+			if err != nil {
+				return err
+			}
+			points = append(points, point)
+		}
+		return nil
+	})
+	return points
+}
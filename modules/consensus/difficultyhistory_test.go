@@ -0,0 +1,59 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestDifficultyHistory checks that DifficultyHistory covers the requested
+// range, and that the reported targets and hashrates match the blocks.
+func TestDifficultyHistory(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	height := cst.cs.Height()
+	points := cst.cs.DifficultyHistory(0, height+10)
+	if len(points) != int(height)+1 {
+		t.Fatalf("expected %v points, got %v", height+1, len(points))
+	}
+	for i, p := range points {
+		if p.Height != types.BlockHeight(i) {
+			t.Fatalf("point %v has height %v", i, p.Height)
+		}
+		if i == 0 {
+			if !p.Hashrate.Equals(types.RootTarget.Difficulty().Div64(uint64(types.BlockFrequency))) {
+				t.Fatal("genesis point has wrong hashrate")
+			}
+			continue
+		}
+
+		// The target should be the target that the block had to meet, and
+		// the hashrate should follow from the target and block interval.
+		parent, _ := cst.cs.BlockAtHeight(p.Height - 1)
+		target, _ := cst.cs.ChildTarget(parent.ID())
+		if p.Target != target {
+			t.Fatalf("point %v has wrong target", i)
+		}
+		interval := types.Timestamp(1)
+		if p.Timestamp > parent.Timestamp {
+			interval = p.Timestamp - parent.Timestamp
+		}
+		if !p.Hashrate.Equals(target.Difficulty().Div64(uint64(interval))) {
+			t.Fatalf("point %v has wrong hashrate", i)
+		}
+	}
+
+	// A subrange should match the full history.
+	sub := cst.cs.DifficultyHistory(2, 4)
+	if len(sub) != 3 || sub[0].Height != 2 || !sub[2].Hashrate.Equals(points[4].Hashrate) {
+		t.Fatal("subrange does not match full history:", sub)
+	}
+}
@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"errors"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -194,14 +195,17 @@ func commitDiffSet(tx *bolt.Tx, pb *processedBlock, dir modules.DiffDirection) {
 	commitNodeDiffs(tx, pb, dir)
 	deleteObsoleteDelayedOutputMaps(tx, pb, dir)
 	updateCurrentPath(tx, pb, dir)
+	updateCirculatingSupply(tx, pb, dir)
+	updateTransactionIndex(tx, pb, dir)
 }
 
 // generateAndApplyDiff will verify the block and then integrate it into the
 // consensus state. These two actions must happen at the same time because
 // transactions are allowed to depend on each other. We can't be sure that a
 // transaction is valid unless we have applied all of the previous transactions
-// in the block, which means we need to apply while we verify.
-func generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) error {
+// in the block, which means we need to apply while we verify. The time spent
+// on each phase is added to timings.
+func generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock, timings *modules.ValidationTimings) error {
 	// Sanity check - the block being applied should have the current block as
 	// a parent.
 	if build.DEBUG && pb.Block.ParentID != currentBlockID(tx) {
@@ -217,17 +221,29 @@ func generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) error {
 	// validated all at once because some transactions may not be valid until
 	// previous transactions have been applied.
 	for _, txn := range pb.Block.Transactions {
-		err := validTransaction(tx, txn)
+		start := time.Now()
+		err := txn.StandaloneValid(blockHeight(tx))
+		timings.Signatures += time.Since(start)
+		if err != nil {
+			return err
+		}
+		start = time.Now()
+		err = validTransactionState(tx, txn)
+		if err == nil {
+			applyTransaction(tx, pb, txn)
+		}
+		timings.Updates += time.Since(start)
 		if err != nil {
 			return err
 		}
-		applyTransaction(tx, pb, txn)
 	}
 
 	// After all of the transactions have been applied, 'maintenance' is
 	// applied on the block. This includes adding any outputs that have reached
 	// maturity, applying any contracts with missed storage proofs, and adding
 	// the miner payouts to the list of delayed outputs.
+	start := time.Now()
+	defer func() { timings.Updates += time.Since(start) }()
 	applyMaintenance(tx, pb)
 
 	// DiffsGenerated are only set to true after the block has been fully
@@ -243,6 +259,8 @@ func generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) error {
 	bid := pb.Block.ID()
 	blockMap := tx.Bucket(BlockMap)
 	updateCurrentPath(tx, pb, modules.DiffApply)
+	updateCirculatingSupply(tx, pb, modules.DiffApply)
+	updateTransactionIndex(tx, pb, modules.DiffApply)
 
 	// Sanity check preparation - set the consensus hash at this height so that
 	// during reverting a check can be performed to assure consistency when
@@ -81,12 +81,17 @@ func (cs *ConsensusSet) applyUntilBlock(tx *bolt.Tx, pb *processedBlock) (applie
 		if block.DiffsGenerated {
 			commitDiffSet(tx, block, modules.DiffApply)
 		} else {
-			err := generateAndApplyDiff(tx, block)
+			var timings modules.ValidationTimings
+			err := generateAndApplyDiff(tx, block, &timings)
 			if err != nil {
 				// Mark the block as invalid.
 				cs.dosBlocks[block.Block.ID()] = struct{}{}
 				return nil, err
 			}
+			cs.profiledBlocks = append(cs.profiledBlocks, profiledBlock{
+				height:  block.Height,
+				timings: timings,
+			})
 		}
 		appliedBlocks = append(appliedBlocks, block)
 
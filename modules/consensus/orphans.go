@@ -0,0 +1,113 @@
+package consensus
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	// maxOrphans is the maximum number of orphan blocks held by the consensus
+	// set. When the pool is full, the oldest orphan is evicted.
+	maxOrphans = build.Select(build.Var{
+		Standard: 100,
+		Dev:      50,
+		Testing:  10,
+	}).(int)
+
+	// orphanExpiry is the amount of time that an orphan block is held while
+	// waiting for its parent to arrive.
+	orphanExpiry = build.Select(build.Var{
+		Standard: 10 * time.Minute,
+		Dev:      2 * time.Minute,
+		Testing:  10 * time.Second,
+	}).(time.Duration)
+)
+
+// An orphanBlock is a block whose parent is not yet known to the consensus
+// set.
+type orphanBlock struct {
+	block types.Block
+	added time.Time
+}
+
+// minOrphanTarget returns the easiest target that an orphan block must meet.
+// The true target of an orphan is not known, since its parent is not known.
+// However, the target only changes every TargetWindow/2 blocks, and by at most
+// MaxAdjustmentUp, so a block that builds on the current tip cannot have a
+// target easier than the current child target adjusted upwards once. Orphans
+// on older forks may fail this check; they are fetched again when the
+// consensus set synchronizes with its peers.
+func (cs *ConsensusSet) minOrphanTarget() types.Target {
+	var target types.Target
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		target = currentProcessedBlock(tx).ChildTarget
+		return nil
+	})
+	return types.RatToTarget(new(big.Rat).Mul(target.Rat(), types.MaxAdjustmentUp))
+}
+
+// addOrphan adds b to the orphan pool, evicting expired orphans and, if the
+// pool is full, the oldest orphan. Orphans that do not meet minOrphanTarget
+// are dropped, so that filling the pool requires real work. It must be called
+// with a write lock.
+func (cs *ConsensusSet) addOrphan(b types.Block) {
+	if !checkHeaderTarget(b.Header(), cs.minOrphanTarget()) {
+		return
+	}
+	var oldest types.BlockID
+	var oldestTime time.Time
+	for id, ob := range cs.orphans {
+		if time.Since(ob.added) > orphanExpiry {
+			delete(cs.orphans, id)
+		} else if oldestTime.IsZero() || ob.added.Before(oldestTime) {
+			oldest, oldestTime = id, ob.added
+		}
+	}
+	if len(cs.orphans) >= maxOrphans {
+		delete(cs.orphans, oldest)
+	}
+	cs.orphans[b.ID()] = orphanBlock{
+		block: b,
+		added: time.Now(),
+	}
+}
+
+// takeOrphanChildren removes the orphans whose parent is id from the orphan
+// pool and returns them. It must be called with a write lock.
+func (cs *ConsensusSet) takeOrphanChildren(id types.BlockID) []types.Block {
+	var children []types.Block
+	for oid, ob := range cs.orphans {
+		if ob.block.ParentID == id {
+			children = append(children, ob.block)
+			delete(cs.orphans, oid)
+		}
+	}
+	return children
+}
+
+// managedPromoteOrphans adds orphans whose parent has just been added to the
+// block tree to the consensus set. Promoted blocks are not relayed.
+func (cs *ConsensusSet) managedPromoteOrphans(children []types.Block) {
+	for _, b := range children {
+		err := cs.managedAcceptBlock(b)
+		if err != nil && err != modules.ErrNonExtendingBlock {
+			cs.log.Debugln("WARN: failed to promote orphan block:", err)
+			continue
+		}
+		cs.log.Debugln("Promoted orphan block", b.ID())
+	}
+}
+
+// OrphanCount returns the number of orphan blocks held by the consensus set
+// while it waits for their parents to arrive.
+func (cs *ConsensusSet) OrphanCount() int {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return len(cs.orphans)
+}
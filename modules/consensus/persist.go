@@ -50,6 +50,18 @@ func (cs *ConsensusSet) loadDB() error {
 		if genesisID != cs.blockRoot.Block.ID() {
 			return errors.New("Blockchain has wrong genesis block, exiting.")
 		}
+
+		// Databases created before the circulating supply was tracked need
+		// to have it computed from the current path.
+		if tx.Bucket(CirculatingSupply) == nil {
+			if err := initCirculatingSupply(tx); err != nil {
+				return err
+			}
+		}
+		// Likewise for the transaction index.
+		if tx.Bucket(TransactionIndex) == nil {
+			return initTransactionIndex(tx)
+		}
 		return nil
 	})
 }
@@ -0,0 +1,42 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// A profiledBlock holds the validation timings of a block that was validated
+// and applied to the consensus set.
+type profiledBlock struct {
+	height  types.BlockHeight
+	timings modules.ValidationTimings
+}
+
+// takeProfiledBlocks returns the blocks profiled by the current call to
+// managedAcceptBlock and clears them. headerTime is attributed to the last
+// block, which is the block that was accepted; the headers of any other
+// blocks applied during a reorg were checked when those blocks were first
+// received. nil is returned if no profiler is set.
+func (cs *ConsensusSet) takeProfiledBlocks(headerTime time.Duration) []profiledBlock {
+	if cs.validationProfiler == nil || len(cs.profiledBlocks) == 0 {
+		cs.profiledBlocks = cs.profiledBlocks[:0]
+		return nil
+	}
+	profiled := append([]profiledBlock(nil), cs.profiledBlocks...)
+	profiled[len(profiled)-1].timings.ProofOfWork = headerTime
+	cs.profiledBlocks = cs.profiledBlocks[:0]
+	return profiled
+}
+
+// SetValidationProfiler sets a function that is called after each block is
+// validated and applied, with the block's height and the time spent on each
+// phase of its validation. The function is called without the consensus set
+// lock held, but it blocks the acceptance of further blocks, so it should
+// return quickly. A nil function disables profiling.
+func (cs *ConsensusSet) SetValidationProfiler(fn func(height types.BlockHeight, timings modules.ValidationTimings)) {
+	cs.mu.Lock()
+	cs.validationProfiler = fn
+	cs.mu.Unlock()
+}
@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestValidationProfiler checks that the validation profiler is called for
+// each processed block with non-zero phase timings.
+func TestValidationProfiler(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	var heights []types.BlockHeight
+	var timings []modules.ValidationTimings
+	cst.cs.SetValidationProfiler(func(height types.BlockHeight, vt modules.ValidationTimings) {
+		heights = append(heights, height)
+		timings = append(timings, vt)
+	})
+
+	// Mine a block containing a signed transaction.
+	_, err = cst.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cst.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if len(timings) != 1 || heights[0] != cst.cs.Height() {
+		t.Fatal("profiler was not called for the processed block:", heights)
+	}
+	vt := timings[0]
+	if vt.ProofOfWork == 0 || vt.Signatures == 0 || vt.Updates == 0 {
+		t.Fatal("expected non-zero phase timings, got", vt)
+	}
+
+	// Blocks without transactions still have header and update timings.
+	if _, err := cst.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if len(timings) != 2 || heights[1] != cst.cs.Height() {
+		t.Fatal("profiler was not called for the processed block:", heights)
+	}
+	if timings[1].ProofOfWork == 0 || timings[1].Updates == 0 {
+		t.Fatal("expected non-zero phase timings, got", timings[1])
+	}
+
+	// Rejected blocks should not be profiled, and removing the profiler
+	// should stop further calls.
+	if err := cst.cs.AcceptBlock(cst.cs.CurrentBlock()); err != modules.ErrBlockKnown {
+		t.Fatal("expected modules.ErrBlockKnown, got", err)
+	}
+	cst.cs.SetValidationProfiler(nil)
+	if _, err := cst.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if len(timings) != 2 {
+		t.Fatal("profiler was called after being removed")
+	}
+}
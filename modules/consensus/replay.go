@@ -0,0 +1,54 @@
+package consensus
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	// errBlockNotApplied is returned by ChangeForBlock if the block is known
+	// but has never been applied, meaning its diffs were never generated.
+	errBlockNotApplied = errors.New("block has never been applied to the consensus set")
+
+	// errUnknownBlock is returned by ChangeForBlock if the block is not in the
+	// block map.
+	errUnknownBlock = errors.New("block is not known to the consensus set")
+)
+
+// ChangeForBlock returns the consensus change produced by applying the block
+// with the given id, as if it were the only block in the change. The block
+// does not need to be in the current path, but it must have been applied at
+// some point so that its diffs are known.
+//
+// The returned change is synthetic: its ID is derived from a change entry
+// that does not appear in the change log, so it cannot be used to subscribe.
+// Synced only reports whether the block is the current block of a synced
+// consensus set, and says nothing about the state in which the block was
+// applied. TryTransactionSet is nil, since the change does not describe the
+// current state of the consensus set.
+func (cs *ConsensusSet) ChangeForBlock(id types.BlockID) (cc modules.ConsensusChange, err error) {
+	// A call to a closed database can cause undefined behavior.
+	if err := cs.tg.Add(); err != nil {
+		return modules.ConsensusChange{}, err
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return errUnknownBlock
+		} else if !pb.DiffsGenerated {
+			return errBlockNotApplied
+		}
+		cc, err = cs.computeConsensusChange(tx, changeEntry{AppliedBlocks: []types.BlockID{id}})
+		return err
+	})
+	cc.TryTransactionSet = nil
+	return cc, err
+}
@@ -0,0 +1,102 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestChangeForBlock checks that the change returned by ChangeForBlock
+// contains the output diffs of the transactions in the block.
+func TestChangeForBlock(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	if _, err := cst.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := cst.cs.ChangeForBlock(b.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.AppliedBlocks) != 1 || cc.AppliedBlocks[0].ID() != b.ID() || len(cc.RevertedBlocks) != 0 {
+		t.Fatal("change should apply exactly the requested block")
+	}
+	if cc.TryTransactionSet != nil {
+		t.Fatal("change should not carry a TryTransactionSet function")
+	}
+
+	// Every siacoin output created by the block's transactions should be
+	// added, and every siacoin input should remove its parent output. An
+	// output can be both created and spent within the block, so the diffs are
+	// keyed by both the ID and the direction.
+	type diffKey struct {
+		id  types.SiacoinOutputID
+		dir modules.DiffDirection
+	}
+	diffs := make(map[diffKey]struct{})
+	for _, scod := range cc.SiacoinOutputDiffs {
+		diffs[diffKey{scod.ID, scod.Direction}] = struct{}{}
+	}
+	var expected int
+	for _, txn := range b.Transactions {
+		for _, sci := range txn.SiacoinInputs {
+			if _, ok := diffs[diffKey{sci.ParentID, modules.DiffRevert}]; !ok {
+				t.Fatal("missing diff for spent output", sci.ParentID)
+			}
+			expected++
+		}
+		for i := range txn.SiacoinOutputs {
+			id := txn.SiacoinOutputID(uint64(i))
+			if _, ok := diffs[diffKey{id, modules.DiffApply}]; !ok {
+				t.Fatal("missing diff for created output", id)
+			}
+			expected++
+		}
+	}
+	// Delayed outputs that mature in the block are also added.
+	for _, mscod := range cc.MaturedSiacoinOutputs {
+		if _, ok := diffs[diffKey{mscod.ID, modules.DiffApply}]; !ok {
+			t.Fatal("missing diff for matured output", mscod.ID)
+		}
+		expected++
+	}
+	if expected == 0 {
+		t.Fatal("block contains no siacoin inputs or outputs")
+	} else if len(cc.SiacoinOutputDiffs) != expected {
+		t.Fatalf("expected %v siacoin output diffs, got %v", expected, len(cc.SiacoinOutputDiffs))
+	}
+
+	// The diffs should match those sent to subscribers when the block was
+	// applied.
+	ms := newMockSubscriber()
+	if err := cst.cs.ConsensusSetSubscribe(&ms, modules.ConsensusChangeBeginning); err != nil {
+		t.Fatal(err)
+	}
+	last := ms.updates[len(ms.updates)-1]
+	if len(last.SiacoinOutputDiffs) != len(cc.SiacoinOutputDiffs) || len(last.DelayedSiacoinOutputDiffs) != len(cc.DelayedSiacoinOutputDiffs) {
+		t.Fatal("change does not match the change sent to subscribers")
+	}
+	for i := range last.SiacoinOutputDiffs {
+		if last.SiacoinOutputDiffs[i].ID != cc.SiacoinOutputDiffs[i].ID {
+			t.Fatal("change does not match the change sent to subscribers")
+		}
+	}
+
+	// Unknown blocks should be rejected.
+	if _, err := cst.cs.ChangeForBlock(types.BlockID{}); err != errUnknownBlock {
+		t.Fatal("expected errUnknownBlock, got", err)
+	}
+}
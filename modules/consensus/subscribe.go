@@ -7,6 +7,13 @@ import (
 	"github.com/NebulousLabs/bolt"
 )
 
+// isMaturation returns true if the delayed siacoin output diff records the
+// maturation of an output in the processed block, as opposed to the creation
+// of a new delayed output.
+func isMaturation(pb *processedBlock, dscod modules.DelayedSiacoinOutputDiff) bool {
+	return dscod.Direction == modules.DiffRevert && dscod.MaturityHeight == pb.Height
+}
+
 // computeConsensusChange computes the consensus change from the change entry
 // at index 'i' in the change log. If i is out of bounds, an error is returned.
 func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (modules.ConsensusChange, error) {
@@ -40,6 +47,13 @@ func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (mod
 		}
 		for i := len(revertedBlock.DelayedSiacoinOutputDiffs) - 1; i >= 0; i-- {
 			dscod := revertedBlock.DelayedSiacoinOutputDiffs[i]
+			if isMaturation(revertedBlock, dscod) {
+				cc.MaturedSiacoinOutputs = append(cc.MaturedSiacoinOutputs, modules.SiacoinOutputDiff{
+					Direction:     modules.DiffRevert,
+					ID:            dscod.ID,
+					SiacoinOutput: dscod.SiacoinOutput,
+				})
+			}
 			dscod.Direction = !dscod.Direction
 			cc.DelayedSiacoinOutputDiffs = append(cc.DelayedSiacoinOutputDiffs, dscod)
 		}
@@ -67,6 +81,13 @@ func (cs *ConsensusSet) computeConsensusChange(tx *bolt.Tx, ce changeEntry) (mod
 			cc.SiafundOutputDiffs = append(cc.SiafundOutputDiffs, sfod)
 		}
 		for _, dscod := range appliedBlock.DelayedSiacoinOutputDiffs {
+			if isMaturation(appliedBlock, dscod) {
+				cc.MaturedSiacoinOutputs = append(cc.MaturedSiacoinOutputs, modules.SiacoinOutputDiff{
+					Direction:     modules.DiffApply,
+					ID:            dscod.ID,
+					SiacoinOutput: dscod.SiacoinOutput,
+				})
+			}
 			cc.DelayedSiacoinOutputDiffs = append(cc.DelayedSiacoinOutputDiffs, dscod)
 		}
 		for _, sfpd := range appliedBlock.SiafundPoolDiffs {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // mockSubscriber receives and holds changes to the consensus set, remembering
@@ -148,3 +149,59 @@ func TestUnsubscribe(t *testing.T) {
 		t.Error("mock subscriber was not correctly unsubscribed")
 	}
 }
+
+// TestMaturedSiacoinOutputs checks that a miner payout appears in the
+// MaturedSiacoinOutputs of the consensus change that applies the block at its
+// maturity height, and in no other change.
+func TestMaturedSiacoinOutputs(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	ms := newMockSubscriber()
+	err = cst.cs.ConsensusSetSubscribe(&ms, modules.ConsensusChangeRecent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.cs.Unsubscribe(&ms)
+
+	// Mine a block and then mine until its miner payout matures.
+	b, err := cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payoutID := b.MinerPayoutID(0)
+	maturityHeight := cst.cs.Height() + types.MaturityDelay
+	for cst.cs.Height() < maturityHeight {
+		if _, err := cst.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	maturityBlock, _ := cst.cs.BlockAtHeight(maturityHeight)
+
+	var matured int
+	for _, cc := range ms.updates {
+		for _, scod := range cc.MaturedSiacoinOutputs {
+			if scod.ID != payoutID {
+				continue
+			}
+			matured++
+			if len(cc.AppliedBlocks) != 1 || cc.AppliedBlocks[0].ID() != maturityBlock.ID() {
+				t.Fatal("payout matured in the wrong block")
+			} else if scod.Direction != modules.DiffApply {
+				t.Fatal("matured payout has the wrong direction")
+			} else if scod.SiacoinOutput.Value.Cmp(b.MinerPayouts[0].Value) != 0 {
+				t.Fatal("matured payout has the wrong value")
+			}
+		}
+	}
+	if matured != 1 {
+		t.Fatalf("expected payout to mature once, matured %v times", matured)
+	}
+}
@@ -0,0 +1,133 @@
+package consensus
+
+import (
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// burnedCoins returns the value of the siacoin outputs created by pb that are
+// sent to the void address, which cannot be spent. This includes the void
+// outputs of missed storage proofs and any miner payouts sent to the void.
+func burnedCoins(pb *processedBlock) (burned types.Currency) {
+	for _, txn := range pb.Block.Transactions {
+		for _, sco := range txn.SiacoinOutputs {
+			if sco.UnlockHash == (types.UnlockHash{}) {
+				burned = burned.Add(sco.Value)
+			}
+		}
+	}
+	// Transactions only create delayed outputs through storage proofs, so
+	// there is no overlap with the outputs counted above. Outputs that mature
+	// in this block are reverted from the delayed set and are not counted
+	// again.
+	for _, dscod := range pb.DelayedSiacoinOutputDiffs {
+		if dscod.Direction == modules.DiffApply && dscod.SiacoinOutput.UnlockHash == (types.UnlockHash{}) {
+			burned = burned.Add(dscod.SiacoinOutput.Value)
+		}
+	}
+	return burned
+}
+
+// getCirculatingSupply returns the circulating supply after the block at the
+// given height in the current path was applied.
+func getCirculatingSupply(tx *bolt.Tx, height types.BlockHeight) (supply types.Currency, err error) {
+	supplyBytes := tx.Bucket(CirculatingSupply).Get(encoding.Marshal(height))
+	if supplyBytes == nil {
+		return types.Currency{}, errNilItem
+	}
+	err = encoding.Unmarshal(supplyBytes, &supply)
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	return supply, nil
+}
+
+// updateCirculatingSupply updates the circulating supply after pb is applied
+// to or reverted from the current path. The supply after a block is the
+// supply after its parent, plus the block subsidy, minus any burned coins.
+func updateCirculatingSupply(tx *bolt.Tx, pb *processedBlock, dir modules.DiffDirection) {
+	bucket := tx.Bucket(CirculatingSupply)
+	heightBytes := encoding.Marshal(pb.Height)
+	if dir == modules.DiffRevert {
+		err := bucket.Delete(heightBytes)
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	var supply types.Currency
+	burned := burnedCoins(pb)
+	if pb.Height == 0 {
+		// The genesis block contains the initial siacoin allocation, if any.
+		// Its miner payout is added to the delayed outputs directly, rather
+		// than through a diff, and is sent to the void.
+		for _, txn := range pb.Block.Transactions {
+			for _, sco := range txn.SiacoinOutputs {
+				supply = supply.Add(sco.Value)
+			}
+		}
+		burned = burned.Add(types.CalculateCoinbase(0))
+	} else {
+		parentSupply, err := getCirculatingSupply(tx, pb.Height-1)
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+		supply = parentSupply
+	}
+	supply = supply.Add(types.CalculateCoinbase(pb.Height))
+	if build.DEBUG && supply.Cmp(burned) < 0 {
+		panic("more coins burned than are in circulation")
+	}
+	err := bucket.Put(heightBytes, encoding.Marshal(supply.Sub(burned)))
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+}
+
+// initCirculatingSupply creates the CirculatingSupply bucket and fills it in
+// for every block in the current path.
+func initCirculatingSupply(tx *bolt.Tx) error {
+	_, err := tx.CreateBucket(CirculatingSupply)
+	if err != nil {
+		return err
+	}
+	for height := types.BlockHeight(0); height <= blockHeight(tx); height++ {
+		id, err := getPath(tx, height)
+		if err != nil {
+			return err
+		}
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return err
+		}
+		updateCirculatingSupply(tx, pb, modules.DiffApply)
+	}
+	return nil
+}
+
+// CirculatingSupply returns the total number of spendable siacoins in
+// existence after the block at the given height in the current path. This is
+// the initial allocation plus the subsidies of every block up to and including
+// the height, minus any coins that have been burned by sending them to the
+// void address. Coins held in file contracts or the siafund pool are still
+// counted. Zero is returned if there is no block at the given height.
+func (cs *ConsensusSet) CirculatingSupply(height types.BlockHeight) (supply types.Currency) {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return types.Currency{}
+	}
+	defer cs.tg.Done()
+
+	// Error is not checked because it does not matter.
+	_ = cs.db.View(func(tx *bolt.Tx) (err error) {
+		supply, err = getCirculatingSupply(tx, height)
+		return err
+	})
+	return supply
+}
@@ -0,0 +1,55 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestCirculatingSupply checks that the circulating supply at genesis matches
+// the initial siacoin allocation, and that each block increases the supply by
+// its subsidy.
+func TestCirculatingSupply(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	var allocation types.Currency
+	for _, txn := range types.GenesisBlock.Transactions {
+		for _, sco := range txn.SiacoinOutputs {
+			allocation = allocation.Add(sco.Value)
+		}
+	}
+	if supply := cst.cs.CirculatingSupply(0); supply.Cmp(allocation) != 0 {
+		t.Fatalf("supply at genesis is %v, expected %v", supply, allocation)
+	}
+
+	// The tester mines all of its blocks to the wallet, so no coins are
+	// burned.
+	for height := types.BlockHeight(1); height <= cst.cs.Height(); height++ {
+		expected := cst.cs.CirculatingSupply(height - 1).Add(types.CalculateCoinbase(height))
+		if supply := cst.cs.CirculatingSupply(height); supply.Cmp(expected) != 0 {
+			t.Fatalf("supply at height %v is %v, expected %v", height, supply, expected)
+		}
+	}
+
+	// Mining another block should extend the supply, and heights beyond the
+	// current block have no supply.
+	prev := cst.cs.CirculatingSupply(cst.cs.Height())
+	if _, err := cst.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	height := cst.cs.Height()
+	if supply := cst.cs.CirculatingSupply(height); supply.Cmp(prev.Add(types.CalculateCoinbase(height))) != 0 {
+		t.Fatal("mined block did not increase the supply by its subsidy")
+	}
+	if supply := cst.cs.CirculatingSupply(height + 1); !supply.IsZero() {
+		t.Fatal("expected zero supply for a height beyond the current block, got", supply)
+	}
+}
@@ -291,10 +291,14 @@ func validTransaction(tx *bolt.Tx, t types.Transaction) error {
 	if err != nil {
 		return err
 	}
+	return validTransactionState(tx, t)
+}
 
-	// Check that each portion of the transaction is legal given the current
-	// consensus set.
-	err = validSiacoins(tx, t)
+// validTransactionState checks that each portion of the transaction is legal
+// given the current consensus set. It does not check the standalone validity
+// of the transaction.
+func validTransactionState(tx *bolt.Tx, t types.Transaction) error {
+	err := validSiacoins(tx, t)
 	if err != nil {
 		return err
 	}
@@ -384,3 +388,21 @@ func (cs *ConsensusSet) LockedTryTransactionSet(fn func(func(txns []types.Transa
 	defer cs.mu.RUnlock()
 	return fn(cs.tryTransactionSet)
 }
+
+// ValidateTransaction checks whether the transaction would be accepted by the
+// consensus set at its current height, without applying it. All standalone
+// checks (signatures, covered fields, etc.) are run, and the inputs and
+// outputs are checked against the current set of unspent outputs. Unconfirmed
+// parents are not considered.
+func (cs *ConsensusSet) ValidateTransaction(t types.Transaction) error {
+	err := cs.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.db.View(func(tx *bolt.Tx) error {
+		return validTransaction(tx, t)
+	})
+}
@@ -42,6 +42,102 @@ func TestTryValidTransactionSet(t *testing.T) {
 	}
 }
 
+// TestValidateTransaction checks that ValidateTransaction accepts a valid
+// transaction, rejects invalid ones with the specific error, and never
+// modifies the consensus set.
+func TestValidateTransaction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Create a confirmed output that can be spent without signatures.
+	value := types.SiacoinPrecision.Mul64(100)
+	txns, err := cst.wallet.SendSiacoins(value, types.UnlockConditions{}.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	initialHash := cst.cs.dbConsensusChecksum()
+
+	valid := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID: txns[len(txns)-1].SiacoinOutputID(0),
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      value.Sub(types.SiacoinPrecision),
+			UnlockHash: types.UnlockHash{},
+		}},
+		MinerFees: []types.Currency{types.SiacoinPrecision},
+	}
+	err = cst.cs.ValidateTransaction(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unbalanced sums.
+	unbalanced := valid
+	unbalanced.SiacoinOutputs = []types.SiacoinOutput{{
+		Value:      value,
+		UnlockHash: types.UnlockHash{},
+	}}
+	err = cst.cs.ValidateTransaction(unbalanced)
+	if err != errSiacoinInputOutputMismatch {
+		t.Fatal("expected errSiacoinInputOutputMismatch, got", err)
+	}
+
+	// Bad signature. Use the wallet to create a signed transaction spending
+	// confirmed outputs, then corrupt the signature.
+	builder := cst.wallet.StartTransaction()
+	err = builder.FundSiacoins(types.SiacoinPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedSet, err := builder.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signedSet[0]
+	err = cst.cs.ValidateTransaction(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.TransactionSignatures[0].Signature = append([]byte(nil), signed.TransactionSignatures[0].Signature...)
+	signed.TransactionSignatures[0].Signature[0]++
+	err = cst.cs.ValidateTransaction(signed)
+	if err != crypto.ErrInvalidSignature {
+		t.Fatal("expected crypto.ErrInvalidSignature, got", err)
+	}
+	builder.Drop()
+
+	// Validating must not have changed the consensus set.
+	if cst.cs.dbConsensusChecksum() != initialHash {
+		t.Fatal("ValidateTransaction modified the consensus set")
+	}
+
+	// Double-spend: confirm the valid transaction, then validate it again.
+	err = cst.tpool.AcceptTransactionSet([]types.Transaction{valid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cst.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cst.cs.ValidateTransaction(valid)
+	if err != errMissingSiacoinOutput {
+		t.Fatal("expected errMissingSiacoinOutput, got", err)
+	}
+}
+
 // TestTryInvalidTransactionSet submits an invalid transaction set to the
 // TryTransaction method.
 func TestTryInvalidTransactionSet(t *testing.T) {
@@ -529,6 +625,78 @@ func TestValidStorageProofs(t *testing.T) {
 	}
 }
 
+// TestBuildStorageProof checks that proofs built by types.BuildStorageProof
+// are accepted by consensus, including proofs of a partial final segment.
+func TestBuildStorageProof(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// COMPATv0.4.0
+	//
+	// Mine 10 blocks so that the post-hardfork rules are in effect.
+	for i := 0; i < 10; i++ {
+		block, _ := cst.miner.FindBlock()
+		err = cst.cs.AcceptBlock(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Use a file whose final segment is not full, and add contracts until
+	// the final segment is challenged.
+	file := fastrand.Bytes(1000)
+	fc := types.FileContract{
+		FileSize:       uint64(len(file)),
+		FileMerkleRoot: crypto.MerkleRoot(file),
+		Payout:         types.NewCurrency64(1),
+		WindowStart:    2,
+		WindowEnd:      1200,
+	}
+	lastSegment := crypto.CalculateLeaves(fc.FileSize) - 1
+	var fcid types.FileContractID
+	for {
+		fcid[0]++
+		cst.cs.dbAddFileContract(fcid, fc)
+		proofIndex, err := cst.cs.dbStorageProofSegment(fcid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp, err := types.BuildStorageProof(fcid, file, proofIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cst.cs.dbValidStorageProofs(types.Transaction{StorageProofs: []types.StorageProof{sp}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A proof of any other segment should be rejected.
+		sp, err = types.BuildStorageProof(fcid, file, (proofIndex+1)%(lastSegment+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cst.cs.dbValidStorageProofs(types.Transaction{StorageProofs: []types.StorageProof{sp}})
+		if err != errInvalidStorageProof {
+			t.Fatal("expected errInvalidStorageProof, got", err)
+		}
+
+		if proofIndex == lastSegment {
+			break
+		}
+	}
+
+	if _, err := types.BuildStorageProof(fcid, file, lastSegment+1); err != types.ErrSegmentIndexOutOfRange {
+		t.Fatal("expected ErrSegmentIndexOutOfRange, got", err)
+	}
+}
+
 // HARDFORK 21,000
 //
 // TestPreForkValidStorageProofs checks that storage proofs which are invalid
@@ -128,8 +128,8 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			tbid := types.TransactionID(bid)
 
 			// special handling for genesis block
-			if bid == types.GenesisID {
-				dbAddGenesisBlock(tx)
+			if block.IsGenesis() {
+				dbAddGenesisBlock(tx, block)
 				continue
 			}
 
@@ -525,16 +525,27 @@ func dbCalculateBlockFacts(tx *bolt.Tx, cs modules.ConsensusSet, block types.Blo
 }
 
 // Special handling for the genesis block. No other functions are called on it.
-func dbAddGenesisBlock(tx *bolt.Tx) {
-	id := types.GenesisID
+func dbAddGenesisBlock(tx *bolt.Tx, genesis types.Block) {
+	id := genesis.ID()
 	dbAddBlockID(tx, id, 0)
-	txid := types.GenesisBlock.Transactions[0].ID()
-	dbAddTransactionID(tx, txid, 0)
-	for i, sfo := range types.GenesisSiafundAllocation {
-		sfoid := types.GenesisBlock.Transactions[0].SiafundOutputID(uint64(i))
-		dbAddSiafundOutputID(tx, sfoid, txid)
-		dbAddUnlockHash(tx, sfo.UnlockHash, txid)
-		dbAddSiafundOutput(tx, sfoid, sfo)
+	var siacoinOutputCount, siafundOutputCount uint64
+	for _, txn := range genesis.Transactions {
+		txid := txn.ID()
+		dbAddTransactionID(tx, txid, 0)
+		for i, sco := range txn.SiacoinOutputs {
+			scoid := txn.SiacoinOutputID(uint64(i))
+			dbAddSiacoinOutputID(tx, scoid, txid)
+			dbAddUnlockHash(tx, sco.UnlockHash, txid)
+			dbAddSiacoinOutput(tx, scoid, sco)
+		}
+		for i, sfo := range txn.SiafundOutputs {
+			sfoid := txn.SiafundOutputID(uint64(i))
+			dbAddSiafundOutputID(tx, sfoid, txid)
+			dbAddUnlockHash(tx, sfo.UnlockHash, txid)
+			dbAddSiafundOutput(tx, sfoid, sfo)
+		}
+		siacoinOutputCount += uint64(len(txn.SiacoinOutputs))
+		siafundOutputCount += uint64(len(txn.SiafundOutputs))
 	}
 	dbAddBlockFacts(tx, blockFacts{
 		BlockFacts: modules.BlockFacts{
@@ -543,9 +554,10 @@ func dbAddGenesisBlock(tx *bolt.Tx) {
 			Difficulty:         types.RootTarget.Difficulty(),
 			Target:             types.RootTarget,
 			TotalCoins:         types.CalculateCoinbase(0),
-			TransactionCount:   1,
-			SiafundOutputCount: uint64(len(types.GenesisSiafundAllocation)),
+			TransactionCount:   uint64(len(genesis.Transactions)),
+			SiacoinOutputCount: siacoinOutputCount,
+			SiafundOutputCount: siafundOutputCount,
 		},
-		Timestamp: types.GenesisBlock.Timestamp,
+		Timestamp: genesis.Timestamp,
 	})
 }
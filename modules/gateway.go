@@ -2,6 +2,7 @@ package modules
 
 import (
 	"net"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 )
@@ -12,6 +13,14 @@ const (
 	GatewayDir = "gateway"
 )
 
+// Gateway feature flags. During the connection handshake, peers exchange a
+// bitfield of the optional features they support, and only features supported
+// by both peers are enabled for the connection.
+const (
+	// FeatureCompression indicates support for compressed connections.
+	FeatureCompression uint64 = 1 << iota
+)
+
 var (
 	// BootstrapPeers is a list of peers that can be used to find other peers -
 	// when a client first connects to the network, the only options for
@@ -106,6 +115,41 @@ type (
 		// Peers returns the addresses that the Gateway is currently connected to.
 		Peers() []Peer
 
+		// PeerFeatures returns the feature flags negotiated with a peer, i.e.
+		// the features supported by both the Gateway and the peer. It returns
+		// 0 if the Gateway is not connected to the peer.
+		PeerFeatures(NetAddress) uint64
+
+		// SetMaxInboundPeers sets the maximum number of inbound peers that
+		// the Gateway will accept. A value of 0 removes the limit.
+		SetMaxInboundPeers(int)
+
+		// SetMaxOutboundPeers sets the maximum number of outbound peers that
+		// the Gateway will form. A value of 0 restores the default limit.
+		SetMaxOutboundPeers(int)
+
+		// SetDialTimeout sets how long the Gateway waits for an outgoing
+		// connection to be established. A value of 0 restores the default.
+		SetDialTimeout(time.Duration)
+
+		// SetKeepAlive sets the TCP keepalive period of new peer
+		// connections. A value of 0 restores the default, and a negative
+		// value disables keepalives.
+		SetKeepAlive(time.Duration)
+
+		// SetRPCCompression sets whether the Gateway offers to compress new
+		// peer connections. Compression is only used if both peers offer it.
+		SetRPCCompression(bool)
+
+		// SetUPnP sets whether the Gateway uses UPnP to forward its port on
+		// the router. Disabling UPnP removes any existing port mapping.
+		SetUPnP(bool)
+
+		// SetDNSSeeds sets the DNS seeds used to discover peers. Each seed is
+		// a hostname and port; the Gateway resolves the hostname and attempts
+		// to connect to every address it resolves to.
+		SetDNSSeeds([]string) error
+
 		// RegisterRPC registers a function to handle incoming connections that
 		// supply the given RPC ID.
 		RegisterRPC(string, RPCFunc)
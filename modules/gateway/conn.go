@@ -1,7 +1,11 @@
 package gateway
 
 import (
+	"compress/flate"
+	"context"
+	"io"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
@@ -19,15 +23,93 @@ func (pc peerConn) RPCAddr() modules.NetAddress {
 	return pc.dialbackAddr
 }
 
+// compressedConn wraps a net.Conn, compressing everything written to the
+// connection and decompressing everything read from it. Each write is flushed
+// immediately so that RPC messages are never held back by the compressor.
+type compressedConn struct {
+	net.Conn
+	r io.ReadCloser
+
+	mu sync.Mutex // Protects w.
+	w  *flate.Writer
+}
+
+// newCompressedConn returns a compressedConn wrapping conn.
+func newCompressedConn(conn net.Conn) *compressedConn {
+	// NewWriter only returns an error if the compression level is invalid.
+	w, err := flate.NewWriter(conn, compressionLevel)
+	if err != nil {
+		panic(err)
+	}
+	return &compressedConn{
+		Conn: conn,
+		r:    flate.NewReader(conn),
+		w:    w,
+	}
+}
+
+// Read implements the io.Reader interface.
+func (cc *compressedConn) Read(b []byte) (int, error) {
+	return cc.r.Read(b)
+}
+
+// Write implements the io.Writer interface.
+func (cc *compressedConn) Write(b []byte) (int, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	n, err := cc.w.Write(b)
+	if err != nil {
+		return n, err
+	}
+	return n, cc.w.Flush()
+}
+
+// Close closes the underlying connection.
+func (cc *compressedConn) Close() error {
+	cc.r.Close()
+	return cc.Conn.Close()
+}
+
+// setKeepAlive applies the gateway's keepalive setting to an accepted
+// connection. Connections that are not TCP connections are unaffected.
+func (g *Gateway) setKeepAlive(conn net.Conn) {
+	g.mu.RLock()
+	keepAlive := g.keepAlive
+	g.mu.RUnlock()
+	tc, ok := conn.(*net.TCPConn)
+	if !ok || keepAlive == 0 {
+		return
+	}
+	if keepAlive < 0 {
+		tc.SetKeepAlive(false)
+		return
+	}
+	tc.SetKeepAlive(true)
+	tc.SetKeepAlivePeriod(keepAlive)
+}
+
+// dialTCP dials addr over TCP using d, giving up when ctx is done.
+func dialTCP(ctx context.Context, d *net.Dialer, addr string) (net.Conn, error) {
+	return d.DialContext(ctx, "tcp", addr)
+}
+
 // dial will dial the input address and return a connection. dial appropriately
 // handles things like clean shutdown, fast shutdown, and chooses the correct
 // communication protocol.
 func (g *Gateway) dial(addr modules.NetAddress) (net.Conn, error) {
+	g.mu.RLock()
+	timeout, keepAlive, dial := g.dialTimeout, g.keepAlive, g.dialTCP
+	g.mu.RUnlock()
+	if timeout <= 0 {
+		timeout = dialTimeout
+	}
 	dialer := &net.Dialer{
-		Cancel:  g.threads.StopChan(),
-		Timeout: dialTimeout,
+		Cancel:    g.threads.StopChan(),
+		KeepAlive: keepAlive,
 	}
-	conn, err := dialer.Dial("tcp", string(addr))
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := dial(ctx, dialer, string(addr))
 	if err != nil {
 		return nil, err
 	}
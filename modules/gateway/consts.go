@@ -1,12 +1,19 @@
 package gateway
 
 import (
+	"compress/flate"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
 )
 
 const (
+	// compressionLevel is the flate compression level used for compressed
+	// peer connections. Relayed blocks and transactions are latency
+	// sensitive, so speed is preferred over compression ratio.
+	compressionLevel = flate.BestSpeed
+
 	// handshakeUpgradeVersion is the version where the gateway handshake RPC
 	// was altered to include adiitional information transfer.
 	handshakeUpgradeVersion = "1.0.0"
@@ -20,6 +27,11 @@ const (
 	// connect to itself, this number can be reduced.
 	maxLocalOutboundPeers = 3
 
+	// maxSeenObjects is the number of broadcast objects that are remembered
+	// for each peer. Once the limit is reached, the oldest objects are
+	// forgotten.
+	maxSeenObjects = 1000
+
 	// minAcceptableVersion is the version below which the gateway will refuse to
 	// connect to peers and reject connection attempts.
 	//
@@ -27,11 +39,34 @@ const (
 	// pre-hardfork.
 	minAcceptableVersion = "0.4.0"
 
+	// supportedFeatures is the set of optional features that the gateway
+	// supports.
+	supportedFeatures = modules.FeatureCompression
+
 	// saveFrequency defines how often the gateway saves its persistence.
 	saveFrequency = time.Minute * 2
 )
 
 var (
+	// compressionUpgradeVersion is the version at which peers begin
+	// negotiating compression during the connection handshake. Older peers
+	// are never sent the compression handshake and their connections are not
+	// compressed. In testing, all peers run the current build.
+	compressionUpgradeVersion = build.Select(build.Var{
+		Standard: "1.3.0",
+		Dev:      "1.3.0",
+		Testing:  "1.0.0",
+	}).(string)
+
+	// featuresUpgradeVersion is the version at which peers begin exchanging
+	// feature flags during the connection handshake. Older peers are assumed
+	// to support no optional features.
+	featuresUpgradeVersion = build.Select(build.Var{
+		Standard: "1.3.0",
+		Dev:      "1.3.0",
+		Testing:  "1.0.0",
+	}).(string)
+
 	// fastNodePurgeDelay defines the amount of time that is waited between each
 	// iteration of the purge loop when the gateway has enough nodes to be
 	// needing to purge quickly.
@@ -97,6 +132,15 @@ var (
 		Dev:      int(40),
 		Testing:  int(20),
 	}).(int)
+
+	// seenObjectExpiry defines how long the gateway remembers that a peer has
+	// sent or been sent a broadcast object. After this time, the object may
+	// be broadcast to the peer again.
+	seenObjectExpiry = build.Select(build.Var{
+		Standard: 10 * time.Minute,
+		Dev:      1 * time.Minute,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
 )
 
 var (
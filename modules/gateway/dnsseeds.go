@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"net"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// SetDNSSeeds sets the DNS seeds that the gateway uses to discover peers. Each
+// seed is a hostname and port, e.g. "seed.example.com:9981". The hostname is
+// resolved to its A and AAAA records, and the gateway adds each resulting
+// address to its node list and attempts to connect to it. Resolution happens
+// in the background; SetDNSSeeds only returns an error if a seed is
+// malformed.
+func (g *Gateway) SetDNSSeeds(seeds []string) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	for _, seed := range seeds {
+		if _, _, err := net.SplitHostPort(seed); err != nil {
+			return err
+		}
+	}
+	g.mu.Lock()
+	g.dnsSeeds = append([]string(nil), seeds...)
+	g.mu.Unlock()
+
+	go g.threadedResolveDNSSeeds()
+	return nil
+}
+
+// threadedResolveDNSSeeds resolves the gateway's DNS seeds and attempts to
+// connect to each of the resulting addresses.
+func (g *Gateway) threadedResolveDNSSeeds() {
+	if err := g.threads.Add(); err != nil {
+		return
+	}
+	defer g.threads.Done()
+
+	g.mu.RLock()
+	seeds := g.dnsSeeds
+	lookupHost := g.lookupHost
+	g.mu.RUnlock()
+
+	for _, seed := range seeds {
+		host, port, err := net.SplitHostPort(seed)
+		if err != nil {
+			continue
+		}
+		ips, err := lookupHost(host)
+		if err != nil {
+			g.log.Printf("WARN: failed to resolve DNS seed '%v': %v", seed, err)
+			continue
+		}
+		for _, ip := range ips {
+			addr := modules.NetAddress(net.JoinHostPort(ip, port))
+			g.mu.Lock()
+			err := g.addNode(addr)
+			g.mu.Unlock()
+			if err != nil && err != errNodeExists {
+				g.log.Printf("WARN: failed to add node '%v' from DNS seed '%v': %v", addr, seed, err)
+				continue
+			}
+			go func() {
+				if err := g.threads.Add(); err != nil {
+					return
+				}
+				defer g.threads.Done()
+				g.managedPeerManagerConnect(addr)
+			}()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestDNSSeeds checks that the gateway connects to every address that its DNS
+// seeds resolve to.
+func TestDNSSeeds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	// Create three peers listening on the same port of different loopback
+	// addresses, so that a single seed can resolve to all of them.
+	peer1, err := New("127.0.0.1:0", false, build.TempDir("gateway", t.Name()+"1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer1.Close()
+	port := peer1.port
+	for i := 2; i <= 3; i++ {
+		addr := net.JoinHostPort("127.0.0."+strconv.Itoa(i), port)
+		p, err := New(addr, false, build.TempDir("gateway", t.Name()+strconv.Itoa(i)))
+		if err != nil {
+			t.Skip("loopback address not available:", err)
+		}
+		defer p.Close()
+	}
+
+	// Use a mock resolver that returns the addresses of the peers.
+	g.mu.Lock()
+	g.lookupHost = func(host string) ([]string, error) {
+		if host != "seed.example.com" {
+			return nil, errors.New("no such host")
+		}
+		return []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}, nil
+	}
+	g.mu.Unlock()
+
+	// Malformed seeds should be rejected.
+	if err := g.SetDNSSeeds([]string{"seed.example.com"}); err == nil {
+		t.Fatal("expected error for seed without port")
+	}
+	err = g.SetDNSSeeds([]string{"unknown.example.com:" + port, "seed.example.com:" + port})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The gateway should connect to each of the peers.
+	for i := 1; i <= 3; i++ {
+		addr := modules.NetAddress(net.JoinHostPort("127.0.0."+strconv.Itoa(i), port))
+		for j := 0; ; j++ {
+			g.mu.RLock()
+			_, connected := g.peers[addr]
+			g.mu.RUnlock()
+			if connected {
+				break
+			} else if j == 50 {
+				t.Fatal("gateway did not connect to", addr)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
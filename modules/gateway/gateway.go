@@ -135,6 +135,7 @@ import (
 // (probably 2).
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -183,6 +184,42 @@ type Gateway struct {
 	peers  map[modules.NetAddress]*peer
 	peerTG siasync.ThreadGroup
 
+	// maxInboundPeers and maxOutboundPeers limit the number of inbound and
+	// outbound peers that the gateway will keep. A value of 0 means that no
+	// explicit limit has been set, and the default thresholds are used.
+	maxInboundPeers  int
+	maxOutboundPeers int
+
+	// dialTimeout and keepAlive override the default dial timeout and TCP
+	// keepalive period of peer connections. A value of 0 means that the
+	// default is used; a negative keepAlive disables keepalives. dialTCP
+	// opens outgoing connections, and is replaced with a mock during testing.
+	dialTimeout time.Duration
+	keepAlive   time.Duration
+	dialTCP     func(context.Context, *net.Dialer, string) (net.Conn, error)
+
+	// disableCompression prevents the gateway from offering compression when
+	// negotiating new peer connections.
+	disableCompression bool
+
+	// features is the bitfield of optional features that the gateway
+	// advertises to new peers.
+	features uint64
+
+	// disableUPnP prevents the gateway from asking the router to forward its
+	// port. forwardedPort is the port that has been forwarded, or the empty
+	// string if no mapping has been added. discoverUPnP locates the router,
+	// and is replaced with a mock during testing.
+	disableUPnP   bool
+	forwardedPort string
+	discoverUPnP  func() (upnpDevice, error)
+
+	// dnsSeeds are hostnames, with ports, that resolve to the addresses of
+	// potential peers. lookupHost resolves the hostnames, and is replaced
+	// with a mock during testing.
+	dnsSeeds   []string
+	lookupHost func(string) ([]string, error)
+
 	// Utilities.
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -209,6 +246,51 @@ func (g *Gateway) Address() modules.NetAddress {
 	return g.myAddr
 }
 
+// SetMaxInboundPeers sets the maximum number of inbound peers that the gateway
+// will accept. Once the limit is reached, new inbound connections are rejected
+// until an existing inbound peer disconnects. A value of 0 or less removes the
+// limit.
+func (g *Gateway) SetMaxInboundPeers(n int) {
+	g.mu.Lock()
+	g.maxInboundPeers = n
+	g.mu.Unlock()
+}
+
+// SetMaxOutboundPeers sets the maximum number of outbound peers that the
+// gateway will form. The gateway will also try to maintain this many outbound
+// peers. A value of 0 or less restores the default behavior.
+func (g *Gateway) SetMaxOutboundPeers(n int) {
+	g.mu.Lock()
+	g.maxOutboundPeers = n
+	g.mu.Unlock()
+}
+
+// SetDialTimeout sets how long the gateway waits for an outgoing connection to
+// be established before giving up. A value of 0 or less restores the default.
+func (g *Gateway) SetDialTimeout(d time.Duration) {
+	g.mu.Lock()
+	g.dialTimeout = d
+	g.mu.Unlock()
+}
+
+// SetKeepAlive sets the TCP keepalive period of new peer connections, both
+// inbound and outbound. A value of 0 restores the default, and a negative
+// value disables keepalives. Existing connections are not affected.
+func (g *Gateway) SetKeepAlive(d time.Duration) {
+	g.mu.Lock()
+	g.keepAlive = d
+	g.mu.Unlock()
+}
+
+// SetRPCCompression sets whether the gateway offers to compress new peer
+// connections. A connection is only compressed if both peers support and
+// offer compression. Existing connections are not affected.
+func (g *Gateway) SetRPCCompression(enabled bool) {
+	g.mu.Lock()
+	g.disableCompression = !enabled
+	g.mu.Unlock()
+}
+
 // Close saves the state of the Gateway and stops its listener process.
 func (g *Gateway) Close() error {
 	if err := g.threads.Stop(); err != nil {
@@ -221,6 +303,14 @@ func (g *Gateway) Close() error {
 
 // New returns an initialized Gateway.
 func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
+	return NewCustomGateway(addr, bootstrap, true, persistDir)
+}
+
+// NewCustomGateway returns an initialized Gateway. If useUPnP is false, the
+// gateway will not ask the router to forward its port until UPnP is enabled
+// with SetUPnP. Some networks disallow UPnP, and probing them at startup is
+// best avoided.
+func NewCustomGateway(addr string, bootstrap, useUPnP bool, persistDir string) (*Gateway, error) {
 	// Create the directory if it doesn't exist.
 	err := os.MkdirAll(persistDir, 0700)
 	if err != nil {
@@ -234,6 +324,13 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 		peers: make(map[modules.NetAddress]*peer),
 		nodes: make(map[modules.NetAddress]struct{}),
 
+		features: supportedFeatures,
+
+		disableUPnP:  !useUPnP,
+		discoverUPnP: discoverUPnPDevice,
+		lookupHost:   net.LookupHost,
+		dialTCP:      dialTCP,
+
 		persistDir: persistDir,
 	}
 
@@ -344,8 +441,10 @@ func New(addr string, bootstrap bool, persistDir string) (*Gateway, error) {
 	})
 	go g.permanentNodePurger(nodePurgerClosedChan)
 
-	// Spawn threads to take care of port forwarding and hostname discovery.
-	go g.threadedForwardPort(g.port)
+	// Spawn threads to take care of port forwarding and hostname discovery,
+	// and remove the port mapping at shutdown.
+	g.threads.AfterStop(g.managedClearPort)
+	go g.threadedForwardPort()
 	go g.threadedLearnHostname()
 
 	return g, nil
@@ -1,12 +1,14 @@
 package gateway
 
 import (
+	"container/list"
 	"errors"
 	"fmt"
 	"net"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/fastrand"
@@ -14,6 +16,8 @@ import (
 )
 
 var (
+	errMaxInboundPeers  = errors.New("maximum number of inbound peers reached")
+	errMaxOutboundPeers = errors.New("maximum number of outbound peers reached")
 	errPeerExists       = errors.New("already connected to this peer")
 	errPeerRejectedConn = errors.New("peer rejected connection")
 )
@@ -36,7 +40,16 @@ func (s invalidVersionError) Error() string {
 
 type peer struct {
 	modules.Peer
-	sess muxado.Session
+	compressed bool
+	features   uint64
+	sess       muxado.Session
+
+	// seenObjects maps the hashes of broadcast objects that the peer has sent
+	// or been sent to their entries in seenOrder, which holds the objects
+	// ordered from least to most recently seen. Both are protected by the
+	// gateway's lock.
+	seenObjects map[crypto.Hash]*list.Element
+	seenOrder   *list.List
 }
 
 func (p *peer) open() (modules.PeerConn, error) {
@@ -62,6 +75,36 @@ func (g *Gateway) addPeer(p *peer) {
 	go g.threadedListenPeer(p)
 }
 
+// inboundLimitReached returns true if the gateway has an inbound peer limit
+// and has reached it.
+func (g *Gateway) inboundLimitReached() bool {
+	if g.maxInboundPeers <= 0 {
+		return false
+	}
+	var inbound int
+	for _, p := range g.peers {
+		if p.Inbound {
+			inbound++
+		}
+	}
+	return inbound >= g.maxInboundPeers
+}
+
+// outboundLimitReached returns true if the gateway has an outbound peer limit
+// and has reached it.
+func (g *Gateway) outboundLimitReached() bool {
+	if g.maxOutboundPeers <= 0 {
+		return false
+	}
+	var outbound int
+	for _, p := range g.peers {
+		if !p.Inbound {
+			outbound++
+		}
+	}
+	return outbound >= g.maxOutboundPeers
+}
+
 // randomOutboundPeer returns a random outbound peer.
 func (g *Gateway) randomOutboundPeer() (modules.NetAddress, error) {
 	// Get the list of outbound peers.
@@ -115,10 +158,23 @@ func (g *Gateway) threadedAcceptConn(conn net.Conn) {
 	}
 	defer g.threads.Done()
 	conn.SetDeadline(time.Now().Add(connStdDeadline))
+	g.setKeepAlive(conn)
 
 	addr := modules.NetAddress(conn.RemoteAddr().String())
 	g.log.Debugf("INFO: %v wants to connect", addr)
 
+	// Reject the connection outright if there is no room for another inbound
+	// peer.
+	g.mu.RLock()
+	full := g.inboundLimitReached()
+	g.mu.RUnlock()
+	if full {
+		g.log.Debugf("INFO: %v wanted to connect, but %v", addr, errMaxInboundPeers)
+		rejectConnVersionHandshake(conn)
+		conn.Close()
+		return
+	}
+
 	remoteVersion, err := acceptConnVersionHandshake(conn, build.Version)
 	if err != nil {
 		g.log.Debugf("INFO: %v wanted to connect but version handshake failed: %v", addr, err)
@@ -154,7 +210,7 @@ func (g *Gateway) managedAcceptConnOldPeer(conn net.Conn, remoteVersion string)
 
 	// Old peers are unable to give us a dialback port, so we can't verify
 	// whether or not they are local peers.
-	g.acceptPeer(&peer{
+	err := g.acceptPeer(&peer{
 		Peer: modules.Peer{
 			Inbound:    true,
 			Local:      false,
@@ -163,6 +219,9 @@ func (g *Gateway) managedAcceptConnOldPeer(conn net.Conn, remoteVersion string)
 		},
 		sess: muxado.Server(conn),
 	})
+	if err != nil {
+		return err
+	}
 	g.addNode(addr)
 	return nil
 }
@@ -177,6 +236,14 @@ func (g *Gateway) managedAcceptConnNewPeer(conn net.Conn, remoteVersion string)
 	if err != nil {
 		return err
 	}
+	features, err := g.managedNegotiateFeatures(conn, remoteVersion, acceptFeaturesHandshake)
+	if err != nil {
+		return err
+	}
+	sessConn, compressed, err := g.managedNegotiateCompression(conn, remoteVersion, acceptCompressionHandshake)
+	if err != nil {
+		return err
+	}
 
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -186,7 +253,7 @@ func (g *Gateway) managedAcceptConnNewPeer(conn net.Conn, remoteVersion string)
 		return fmt.Errorf("already connected to a peer on that address: %v", remoteAddr)
 	}
 	// Accept the peer.
-	g.acceptPeer(&peer{
+	err = g.acceptPeer(&peer{
 		Peer: modules.Peer{
 			Inbound: true,
 			// NOTE: local may be true even if the supplied remoteAddr is not
@@ -195,8 +262,13 @@ func (g *Gateway) managedAcceptConnNewPeer(conn net.Conn, remoteVersion string)
 			NetAddress: remoteAddr,
 			Version:    remoteVersion,
 		},
-		sess: muxado.Server(conn),
+		compressed: compressed,
+		features:   features,
+		sess:       muxado.Server(sessConn),
 	})
+	if err != nil {
+		return err
+	}
 
 	// Attempt to ping the supplied address. If successful, we will add
 	// remoteAddr to our node list after accepting the peer. We do this in a
@@ -214,12 +286,18 @@ func (g *Gateway) managedAcceptConnNewPeer(conn net.Conn, remoteVersion string)
 }
 
 // acceptPeer makes room for the peer if necessary by kicking out existing
-// peers, then adds the peer to the peer list.
-func (g *Gateway) acceptPeer(p *peer) {
+// peers, then adds the peer to the peer list. errMaxInboundPeers is returned if
+// the inbound peer limit has been reached; another peer may have taken the
+// last inbound slot while the peer was completing its handshake.
+func (g *Gateway) acceptPeer(p *peer) error {
+	if p.Inbound && g.inboundLimitReached() {
+		return errMaxInboundPeers
+	}
+
 	// If we are not fully connected, add the peer without kicking any out.
 	if len(g.peers) < fullyConnectedThreshold {
 		g.addPeer(p)
-		return
+		return nil
 	}
 
 	// Select a peer to kick. Outbound peers and local peers are not
@@ -241,7 +319,7 @@ func (g *Gateway) acceptPeer(p *peer) {
 	if len(addrs) == 0 {
 		// There is nobody suitable to kick, therefore do not kick anyone.
 		g.addPeer(p)
-		return
+		return nil
 	}
 
 	// Of the remaining options, select one at random.
@@ -251,6 +329,7 @@ func (g *Gateway) acceptPeer(p *peer) {
 	delete(g.peers, kick)
 	g.log.Printf("INFO: disconnected from %v to make room for %v\n", kick, p.NetAddress)
 	g.addPeer(p)
+	return nil
 }
 
 // acceptConnPortHandshake performs the port handshake and should be called on
@@ -325,6 +404,20 @@ func connectVersionHandshake(conn net.Conn, version string) (remoteVersion strin
 	return remoteVersion, nil
 }
 
+// rejectConnVersionHandshake reads the version of the peer requesting a
+// connection and responds with a rejection. It should be called on the side
+// accepting a connection request when the connection is not wanted.
+func rejectConnVersionHandshake(conn net.Conn) error {
+	var remoteVersion string
+	if err := encoding.ReadObject(conn, &remoteVersion, build.MaxEncodedVersionLength); err != nil {
+		return fmt.Errorf("failed to read remote version: %v", err)
+	}
+	if err := encoding.WriteObject(conn, "reject"); err != nil {
+		return fmt.Errorf("failed to write reject: %v", err)
+	}
+	return nil
+}
+
 // acceptConnVersionHandshake performs the version handshake and should be
 // called on the side accepting a connection request. The remote version is
 // only returned if err == nil.
@@ -347,11 +440,106 @@ func acceptConnVersionHandshake(conn net.Conn, version string) (remoteVersion st
 	return remoteVersion, nil
 }
 
+// connectCompressionHandshake performs the compression handshake and should
+// be called on the side making the connection request. Each side shares
+// whether it would like the connection to be compressed, and the connection
+// is only compressed if both sides do.
+func connectCompressionHandshake(conn net.Conn, compress bool) (bool, error) {
+	if err := encoding.WriteObject(conn, compress); err != nil {
+		return false, fmt.Errorf("failed to write compression preference: %v", err)
+	}
+	var remoteCompress bool
+	if err := encoding.ReadObject(conn, &remoteCompress, 1); err != nil {
+		return false, fmt.Errorf("failed to read remote compression preference: %v", err)
+	}
+	return compress && remoteCompress, nil
+}
+
+// acceptCompressionHandshake performs the compression handshake and should be
+// called on the side accepting a connection request.
+func acceptCompressionHandshake(conn net.Conn, compress bool) (bool, error) {
+	var remoteCompress bool
+	if err := encoding.ReadObject(conn, &remoteCompress, 1); err != nil {
+		return false, fmt.Errorf("failed to read remote compression preference: %v", err)
+	}
+	if err := encoding.WriteObject(conn, compress); err != nil {
+		return false, fmt.Errorf("failed to write compression preference: %v", err)
+	}
+	return compress && remoteCompress, nil
+}
+
+// managedNegotiateCompression performs the compression handshake if both
+// peers are new enough to support it. It returns the connection that the
+// peer's session should use, and whether that connection is compressed.
+func (g *Gateway) managedNegotiateCompression(conn net.Conn, remoteVersion string, handshake func(net.Conn, bool) (bool, error)) (net.Conn, bool, error) {
+	if build.VersionCmp(build.Version, compressionUpgradeVersion) < 0 || build.VersionCmp(remoteVersion, compressionUpgradeVersion) < 0 {
+		return conn, false, nil
+	}
+	g.mu.RLock()
+	offer := !g.disableCompression
+	g.mu.RUnlock()
+	compressed, err := handshake(conn, offer)
+	if err != nil {
+		return nil, false, err
+	}
+	if compressed {
+		return newCompressedConn(conn), true, nil
+	}
+	return conn, false, nil
+}
+
+// connectFeaturesHandshake performs the feature flag handshake and should be
+// called on the side making the connection request. Each side shares the
+// features it supports, and the features supported by both are returned.
+func connectFeaturesHandshake(conn net.Conn, features uint64) (uint64, error) {
+	if err := encoding.WriteObject(conn, features); err != nil {
+		return 0, fmt.Errorf("failed to write feature flags: %v", err)
+	}
+	var remoteFeatures uint64
+	if err := encoding.ReadObject(conn, &remoteFeatures, 8); err != nil {
+		return 0, fmt.Errorf("failed to read remote feature flags: %v", err)
+	}
+	return features & remoteFeatures, nil
+}
+
+// acceptFeaturesHandshake performs the feature flag handshake and should be
+// called on the side accepting a connection request.
+func acceptFeaturesHandshake(conn net.Conn, features uint64) (uint64, error) {
+	var remoteFeatures uint64
+	if err := encoding.ReadObject(conn, &remoteFeatures, 8); err != nil {
+		return 0, fmt.Errorf("failed to read remote feature flags: %v", err)
+	}
+	if err := encoding.WriteObject(conn, features); err != nil {
+		return 0, fmt.Errorf("failed to write feature flags: %v", err)
+	}
+	return features & remoteFeatures, nil
+}
+
+// managedNegotiateFeatures performs the feature flag handshake if both peers
+// are new enough to support it, and returns the features supported by both.
+// Peers that are too old are assumed to support no optional features.
+// Compression is not advertised while it is disabled.
+func (g *Gateway) managedNegotiateFeatures(conn net.Conn, remoteVersion string, handshake func(net.Conn, uint64) (uint64, error)) (uint64, error) {
+	if build.VersionCmp(build.Version, featuresUpgradeVersion) < 0 || build.VersionCmp(remoteVersion, featuresUpgradeVersion) < 0 {
+		return 0, nil
+	}
+	g.mu.RLock()
+	features := g.features
+	if g.disableCompression {
+		features &^= modules.FeatureCompression
+	}
+	g.mu.RUnlock()
+	return handshake(conn, features)
+}
+
 // managedConnectOldPeer connects to peers < v1.0.0. The peer is added as a
 // node and a peer. The peer is only added if a nil error is returned.
 func (g *Gateway) managedConnectOldPeer(conn net.Conn, remoteVersion string, remoteAddr modules.NetAddress) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	if g.outboundLimitReached() {
+		return errMaxOutboundPeers
+	}
 	g.addPeer(&peer{
 		Peer: modules.Peer{
 			Inbound:    false,
@@ -386,9 +574,20 @@ func (g *Gateway) managedConnectNewPeer(conn net.Conn, remoteVersion string, rem
 	if err != nil {
 		return err
 	}
+	features, err := g.managedNegotiateFeatures(conn, remoteVersion, connectFeaturesHandshake)
+	if err != nil {
+		return err
+	}
+	sessConn, compressed, err := g.managedNegotiateCompression(conn, remoteVersion, connectCompressionHandshake)
+	if err != nil {
+		return err
+	}
 
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	if g.outboundLimitReached() {
+		return errMaxOutboundPeers
+	}
 	g.addPeer(&peer{
 		Peer: modules.Peer{
 			Inbound:    false,
@@ -396,7 +595,9 @@ func (g *Gateway) managedConnectNewPeer(conn net.Conn, remoteVersion string, rem
 			NetAddress: remoteAddr,
 			Version:    remoteVersion,
 		},
-		sess: muxado.Client(conn),
+		compressed: compressed,
+		features:   features,
+		sess:       muxado.Client(sessConn),
 	})
 	// Add the peer to the node list. We can ignore the error: addNode
 	// validates the address and checks for duplicates, but we don't care
@@ -429,10 +630,14 @@ func (g *Gateway) managedConnect(addr modules.NetAddress) error {
 	}
 	g.mu.RLock()
 	_, exists := g.peers[addr]
+	full := g.outboundLimitReached()
 	g.mu.RUnlock()
 	if exists {
 		return errPeerExists
 	}
+	if full {
+		return errMaxOutboundPeers
+	}
 
 	// Dial the peer and perform peer initialization.
 	conn, err := g.dial(addr)
@@ -526,3 +731,16 @@ func (g *Gateway) Peers() []modules.Peer {
 	}
 	return peers
 }
+
+// PeerFeatures returns the feature flags negotiated with the peer at addr,
+// i.e. the optional features supported by both the gateway and the peer. It
+// returns 0 if the gateway is not connected to the peer.
+func (g *Gateway) PeerFeatures(addr modules.NetAddress) uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	p, ok := g.peers[addr]
+	if !ok {
+		return 0
+	}
+	return p.features
+}
@@ -1,6 +1,8 @@
 package gateway
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"strconv"
@@ -8,7 +10,9 @@ import (
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
 	"github.com/NebulousLabs/muxado"
 )
@@ -127,6 +131,76 @@ func TestAcceptPeer(t *testing.T) {
 	}
 }
 
+// TestAcceptPeerInboundLimit checks that acceptPeer rejects inbound peers once
+// the inbound limit is reached, and still kicks a peer to make room when the
+// gateway is fully connected but below the limit.
+func TestAcceptPeerInboundLimit(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := 0; i < fullyConnectedThreshold; i++ {
+		g.addPeer(&peer{
+			Peer: modules.Peer{
+				NetAddress: modules.NetAddress(fmt.Sprintf("1.2.3.%d", i)),
+				Inbound:    false,
+			},
+			sess: muxado.Client(new(dummyConn)),
+		})
+	}
+	for i := 0; i < 2; i++ {
+		g.addPeer(&peer{
+			Peer: modules.Peer{
+				NetAddress: modules.NetAddress(fmt.Sprintf("9.9.9.%d", i)),
+				Inbound:    true,
+			},
+			sess: muxado.Client(new(dummyConn)),
+		})
+	}
+	g.maxInboundPeers = 3
+
+	// The gateway is fully connected, so accepting an inbound peer should
+	// kick one of the existing inbound peers, even though the limit has not
+	// been reached.
+	err := g.acceptPeer(&peer{
+		Peer: modules.Peer{
+			NetAddress: "8.8.8.8",
+			Inbound:    true,
+		},
+		sess: muxado.Client(new(dummyConn)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, exists0 := g.peers["9.9.9.0"]
+	_, exists1 := g.peers["9.9.9.1"]
+	if exists0 && exists1 {
+		t.Fatal("acceptPeer did not kick a peer")
+	} else if _, exists := g.peers["8.8.8.8"]; !exists {
+		t.Fatal("acceptPeer did not add the peer")
+	}
+
+	// Once the limit is reached, inbound peers should be rejected.
+	g.maxInboundPeers = 2
+	err = g.acceptPeer(&peer{
+		Peer: modules.Peer{
+			NetAddress: "8.8.4.4",
+			Inbound:    true,
+		},
+		sess: muxado.Client(new(dummyConn)),
+	})
+	if err != errMaxInboundPeers {
+		t.Fatal("expected errMaxInboundPeers, got", err)
+	} else if _, exists := g.peers["8.8.4.4"]; exists {
+		t.Fatal("acceptPeer added a peer beyond the inbound limit")
+	}
+}
+
 // TestRandomInbountPeer checks that randomOutboundPeer returns the correct
 // peer.
 func TestRandomOutboundPeer(t *testing.T) {
@@ -242,6 +316,12 @@ func TestListen(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	if _, err := connectFeaturesHandshake(conn, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := connectCompressionHandshake(conn, false); err != nil {
+		t.Fatal(err)
+	}
 
 	// g should add the peer
 	var ok bool
@@ -590,6 +670,20 @@ func TestConnectRejectsVersions(t *testing.T) {
 			if remoteVersion != build.Version {
 				panic("remoteVersion != build.Version")
 			}
+			// Peers that are new enough exchange their ports, feature flags
+			// and compression preferences after the version handshake.
+			if acceptableVersion(tt.version) == nil && build.VersionCmp(tt.version, handshakeUpgradeVersion) >= 0 {
+				var port string
+				if err := encoding.ReadObject(conn, &port, 13); err != nil {
+					panic(err)
+				}
+				if _, err := acceptFeaturesHandshake(conn, 0); err != nil {
+					panic(err)
+				}
+				if _, err := acceptCompressionHandshake(conn, false); err != nil {
+					panic(err)
+				}
+			}
 		}()
 		err = g.Connect(modules.NetAddress(listener.Addr().String()))
 		switch {
@@ -770,6 +864,295 @@ func TestDisconnect(t *testing.T) {
 	}
 }
 
+// TestMaxInboundPeers checks that the gateway rejects inbound connections once
+// the inbound peer limit has been reached, and accepts them again after a slot
+// has been freed.
+func TestMaxInboundPeers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+	g3 := newNamedTestingGateway(t, "3")
+	defer g3.Close()
+
+	g1.SetMaxInboundPeers(1)
+	if err := g2.Connect(g1.Address()); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the accept interval so that the next connection is not
+	// delayed by the listener.
+	time.Sleep(acceptInterval)
+	if err := g3.Connect(g1.Address()); err != errPeerRejectedConn {
+		t.Fatal("expected connection to be rejected, got", err)
+	}
+	if len(g1.Peers()) != 1 {
+		t.Fatal("gateway should have exactly one peer, has", len(g1.Peers()))
+	}
+
+	// Free up the inbound slot. g1 will notice that g2 has disconnected and
+	// drop the peer.
+	if err := g2.Disconnect(g1.Address()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50 && len(g1.Peers()) != 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if len(g1.Peers()) != 0 {
+		t.Fatal("gateway did not drop the disconnected peer")
+	}
+	time.Sleep(acceptInterval)
+	if err := g3.Connect(g1.Address()); err != nil {
+		t.Fatal("connection should succeed after a slot was freed:", err)
+	}
+}
+
+// TestMaxOutboundPeers checks that the gateway refuses to form outbound
+// connections past the outbound peer limit.
+func TestMaxOutboundPeers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+	g3 := newNamedTestingGateway(t, "3")
+	defer g3.Close()
+
+	g1.SetMaxOutboundPeers(1)
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if err := g1.Connect(g3.Address()); err != errMaxOutboundPeers {
+		t.Fatal("expected errMaxOutboundPeers, got", err)
+	}
+
+	// Removing the limit should allow the connection.
+	g1.SetMaxOutboundPeers(0)
+	if err := g1.Connect(g3.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// exchangeLargeBlock has g1 send a large block to g2 over an RPC and checks
+// that it arrives intact.
+func exchangeLargeBlock(t *testing.T, g1, g2 *Gateway) {
+	// Use arbitrary data that compresses well, followed by random data that
+	// does not.
+	block := types.Block{
+		Transactions: []types.Transaction{{
+			ArbitraryData: [][]byte{
+				bytes.Repeat([]byte("sia"), 1<<20),
+				fastrand.Bytes(1 << 18),
+			},
+		}},
+	}
+	received := make(chan types.Block, 1)
+	g2.RegisterRPC("LargeBlock", func(conn modules.PeerConn) error {
+		var b types.Block
+		err := encoding.ReadObject(conn, &b, 8<<20)
+		if err != nil {
+			return err
+		}
+		received <- b
+		return encoding.WriteObject(conn, b.ID())
+	})
+	var id types.BlockID
+	err := g1.RPC(g2.Address(), "LargeBlock", func(conn modules.PeerConn) error {
+		err := encoding.WriteObject(conn, block)
+		if err != nil {
+			return err
+		}
+		return encoding.ReadObject(conn, &id, 32)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != block.ID() {
+		t.Fatal("peer acknowledged the wrong block")
+	}
+	b := <-received
+	if !bytes.Equal(encoding.Marshal(b), encoding.Marshal(block)) {
+		t.Fatal("block was corrupted in transit")
+	}
+}
+
+// TestCompressedConnection checks that two gateways that both offer
+// compression form a compressed connection and can exchange a large block
+// over it.
+func TestCompressedConnection(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	err := g1.Connect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1.mu.RLock()
+	compressed := g1.peers[g2.Address()].compressed
+	g1.mu.RUnlock()
+	if !compressed {
+		t.Fatal("connection between compression-capable gateways is not compressed")
+	}
+	exchangeLargeBlock(t, g1, g2)
+}
+
+// TestCompressionFallback checks that a connection is left uncompressed when
+// only one gateway offers compression, and that it still works.
+func TestCompressionFallback(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	g2.SetRPCCompression(false)
+	err := g1.Connect(g2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1.mu.RLock()
+	compressed := g1.peers[g2.Address()].compressed
+	g1.mu.RUnlock()
+	if compressed {
+		t.Fatal("connection was compressed even though the peer did not offer compression")
+	}
+	exchangeLargeBlock(t, g1, g2)
+}
+
+// TestNegotiateCompressionOldPeer checks that the compression handshake is
+// skipped for peers that are too old to support it.
+func TestNegotiateCompressionOldPeer(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	// If the handshake were attempted, it would block on the pipe.
+	conn, compressed, err := g.managedNegotiateCompression(c1, "0.9.0", connectCompressionHandshake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compressed || conn != c1 {
+		t.Fatal("compression was negotiated with an old peer")
+	}
+}
+
+// TestNegotiateFeatures checks that two gateways negotiate the intersection
+// of their feature flags, and that a peer lacking a feature can still connect.
+func TestNegotiateFeatures(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+	g3 := newNamedTestingGateway(t, "3")
+	defer g3.Close()
+
+	const featureA, featureB, featureC = 1 << 60, 1 << 61, 1 << 62
+	g1.mu.Lock()
+	g1.features = featureA | featureB
+	g1.mu.Unlock()
+	g2.mu.Lock()
+	g2.features = featureB | featureC
+	g2.mu.Unlock()
+	g3.mu.Lock()
+	g3.features = 0
+	g3.mu.Unlock()
+
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if f := g1.PeerFeatures(g2.Address()); f != featureB {
+		t.Fatalf("expected features %b, got %b", uint64(featureB), f)
+	}
+	if f := g2.PeerFeatures(g1.Address()); f != featureB {
+		t.Fatalf("expected features %b, got %b", uint64(featureB), f)
+	}
+
+	// A peer that supports none of the features should still be connected.
+	if err := g1.Connect(g3.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if f := g1.PeerFeatures(g3.Address()); f != 0 {
+		t.Fatalf("expected no features, got %b", f)
+	}
+	exchangeLargeBlock(t, g1, g3)
+
+	// Unknown peers have no features.
+	if f := g1.PeerFeatures("foo.com:123"); f != 0 {
+		t.Fatalf("expected no features for unknown peer, got %b", f)
+	}
+}
+
+// TestNegotiateFeaturesCompressionDisabled checks that a gateway with
+// compression disabled does not advertise the compression feature.
+func TestNegotiateFeaturesCompressionDisabled(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	g2.SetRPCCompression(false)
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if f := g1.PeerFeatures(g2.Address()); f&modules.FeatureCompression != 0 {
+		t.Fatal("compression was negotiated with a peer that disabled it")
+	}
+	if f := g2.PeerFeatures(g1.Address()); f&modules.FeatureCompression != 0 {
+		t.Fatal("compression was negotiated by a gateway that disabled it")
+	}
+	exchangeLargeBlock(t, g1, g2)
+}
+
+// TestNegotiateFeaturesOldPeer checks that the feature handshake is skipped
+// for peers that are too old to support it.
+func TestNegotiateFeaturesOldPeer(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	// If the handshake were attempted, it would block on the pipe.
+	features, err := g.managedNegotiateFeatures(c1, "0.9.0", connectFeaturesHandshake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if features != 0 {
+		t.Fatal("features were negotiated with an old peer")
+	}
+}
+
 // TestPeerManager checks that the peer manager is properly spacing out peer
 // connection requests.
 func TestPeerManager(t *testing.T) {
@@ -924,3 +1307,45 @@ func TestOverloadedBootstrap(t *testing.T) {
 		}
 	}
 }
+
+// TestSetDialTimeout checks that dialing an unresponsive address fails within
+// the configured dial timeout, and that the keepalive setting is applied to
+// accepted connections without error.
+func TestSetDialTimeout(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	// Simulate an unresponsive address with a dial that only returns once it
+	// is abandoned.
+	g.mu.Lock()
+	g.dialTCP = func(ctx context.Context, _ *net.Dialer, _ string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	g.mu.Unlock()
+	const timeout = 100 * time.Millisecond
+	g.SetDialTimeout(timeout)
+	start := time.Now()
+	if _, err := g.dial("foo.com:9981"); err != context.DeadlineExceeded {
+		t.Fatal("expected dial to time out, got", err)
+	}
+	if elapsed := time.Since(start); elapsed < timeout || elapsed > timeout*3 {
+		t.Fatalf("dial took %v, expected %v", elapsed, timeout)
+	}
+	g.mu.Lock()
+	g.dialTCP = dialTCP
+	g.mu.Unlock()
+
+	// Connecting to a peer should still work with a custom keepalive.
+	g.SetKeepAlive(time.Second)
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+	g2.SetKeepAlive(-1)
+	if err := g.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -60,6 +60,17 @@ func (g *Gateway) numOutboundPeers() (numOutboundPeers int) {
 	return numOutboundPeers
 }
 
+// managedWellConnectedThreshold returns the number of outbound peers at which
+// the gateway will stop forming new outbound connections.
+func (g *Gateway) managedWellConnectedThreshold() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.maxOutboundPeers > 0 {
+		return g.maxOutboundPeers
+	}
+	return wellConnectedThreshold
+}
+
 // permanentPeerManager tries to keep the Gateway well-connected. As long as
 // the Gateway is not well-connected, it tries to connect to random nodes.
 func (g *Gateway) permanentPeerManager(closedChan chan struct{}) {
@@ -77,7 +88,7 @@ func (g *Gateway) permanentPeerManager(closedChan chan struct{}) {
 		// If the gateway is well connected, sleep for a while and then try
 		// again.
 		numOutboundPeers := g.numOutboundPeers()
-		if numOutboundPeers >= wellConnectedThreshold {
+		if numOutboundPeers >= g.managedWellConnectedThreshold() {
 			g.log.Debugln("INFO: [PPM] Gateway has enough peers, sleeping.")
 			if !g.managedSleep(wellConnectedDelay) {
 				return
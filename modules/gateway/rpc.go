@@ -211,7 +211,11 @@ func (g *Gateway) threadedHandleConn(conn modules.PeerConn) {
 	}
 	g.log.Debugf("INFO: incoming conn %v requested RPC \"%v\"", conn.RPCAddr(), id)
 
-	// call fn
+	// call fn. Broadcast objects sent by the peer are recorded so that they
+	// are not relayed back to the peer.
+	if _, ok := broadcastRPCs[id]; ok {
+		conn = g.newRecordingConn(conn, id)
+	}
 	err = fn(conn)
 	// don't log benign errors
 	if err == modules.ErrDuplicateTransactionSet || err == modules.ErrBlockKnown {
@@ -226,6 +230,9 @@ func (g *Gateway) threadedHandleConn(conn modules.PeerConn) {
 // parallel. Broadcasts are restricted to "one-way" RPCs, which simply write an
 // object and disconnect. This is why Broadcast takes an interface{} instead of
 // an RPCFunc.
+//
+// An object is not broadcast to peers that recently sent it to the gateway,
+// or that the gateway recently broadcast it to.
 func (g *Gateway) Broadcast(name string, obj interface{}, peers []modules.Peer) {
 	if g.threads.Add() != nil {
 		return
@@ -240,8 +247,14 @@ func (g *Gateway) Broadcast(name string, obj interface{}, peers []modules.Peer)
 		return encoding.WritePrefix(conn, enc)
 	}
 
+	// Skip peers that have already seen the object. A peer is only marked as
+	// having seen the object once it has been sent successfully.
+	h := broadcastHash(name, enc)
 	var wg sync.WaitGroup
 	for _, p := range peers {
+		if g.managedHasSeen(p.NetAddress, h) {
+			continue
+		}
 		wg.Add(1)
 		go func(addr modules.NetAddress) {
 			defer wg.Done()
@@ -254,11 +267,13 @@ func (g *Gateway) Broadcast(name string, obj interface{}, peers []modules.Peer)
 				case <-g.threads.StopChan():
 					return
 				}
-				err := g.managedRPC(addr, name, fn)
+				err = g.managedRPC(addr, name, fn)
 				if err != nil {
 					g.log.Debugf("WARN: broadcasting RPC %q to peer %q failed twice: %v", name, addr, err)
+					return
 				}
 			}
+			g.managedMarkSeen(addr, h)
 		}(p.NetAddress)
 	}
 	wg.Wait()
@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"container/list"
+	"hash"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// broadcastRPCs are the one-way RPCs that other modules call with Broadcast.
+// Only connections serving these RPCs are wrapped in a recordingConn.
+var broadcastRPCs = map[rpcID]struct{}{
+	handlerName("RelayHeader"):         {},
+	handlerName("RelayTransactionSet"): {},
+}
+
+// seenObject is an entry in a peer's list of seen broadcast objects.
+type seenObject struct {
+	h    crypto.Hash
+	seen time.Time
+}
+
+// recordingConn is a modules.PeerConn that identifies the first
+// length-prefixed object read from it, which for a broadcast RPC is the
+// broadcast object. Once the object has been read, the peer is marked as
+// having seen it, so that the object is not relayed back to the peer.
+type recordingConn struct {
+	modules.PeerConn
+	g *Gateway
+
+	h      hash.Hash
+	prefix [8]byte
+	read   uint64
+	done   bool
+}
+
+// newRecordingConn returns a recordingConn wrapping conn, which is serving the
+// RPC with the given id.
+func (g *Gateway) newRecordingConn(conn modules.PeerConn, id rpcID) *recordingConn {
+	h := crypto.NewHash()
+	h.Write(id[:])
+	return &recordingConn{PeerConn: conn, g: g, h: h}
+}
+
+// Read implements the io.Reader interface.
+func (rc *recordingConn) Read(b []byte) (int, error) {
+	n, err := rc.PeerConn.Read(b)
+	if !rc.done {
+		rc.record(b[:n])
+	}
+	return n, err
+}
+
+// record hashes data read from the conn, up to the end of the first object.
+// Once the whole object has been read, the peer is marked as having seen it.
+func (rc *recordingConn) record(data []byte) {
+	if rc.read < 8 {
+		n := copy(rc.prefix[rc.read:], data)
+		rc.h.Write(data[:n])
+		rc.read += uint64(n)
+		data = data[n:]
+		if rc.read < 8 {
+			return
+		}
+	}
+	end := 8 + encoding.DecUint64(rc.prefix[:])
+	if remaining := end - rc.read; uint64(len(data)) > remaining {
+		data = data[:remaining]
+	}
+	rc.h.Write(data)
+	rc.read += uint64(len(data))
+	if rc.read == end {
+		rc.done = true
+		var sum crypto.Hash
+		copy(sum[:], rc.h.Sum(nil))
+		rc.g.managedMarkSeen(rc.RPCAddr(), sum)
+	}
+}
+
+// broadcastHash returns the hash identifying the broadcast of the encoded
+// object enc with the RPC name. It matches the hash computed by a
+// recordingConn that reads the broadcast.
+func broadcastHash(name string, enc []byte) crypto.Hash {
+	id := handlerName(name)
+	h := crypto.NewHash()
+	h.Write(id[:])
+	h.Write(encoding.EncUint64(uint64(len(enc))))
+	h.Write(enc)
+	var sum crypto.Hash
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// hasSeen reports whether the peer has sent or been sent the object with
+// hash h within the last seenObjectExpiry. The gateway's lock must be held.
+func (p *peer) hasSeen(h crypto.Hash, now time.Time) bool {
+	e, ok := p.seenObjects[h]
+	return ok && now.Sub(e.Value.(seenObject).seen) < seenObjectExpiry
+}
+
+// markSeen records that the peer has sent or been sent the object with hash
+// h. Expired objects are forgotten, and then the least recently seen objects
+// if the peer has seen more than maxSeenObjects. The gateway's lock must be
+// held.
+func (p *peer) markSeen(h crypto.Hash, now time.Time) {
+	if p.seenObjects == nil {
+		p.seenObjects = make(map[crypto.Hash]*list.Element)
+		p.seenOrder = list.New()
+	}
+	if e, ok := p.seenObjects[h]; ok {
+		e.Value = seenObject{h: h, seen: now}
+		p.seenOrder.MoveToBack(e)
+	} else {
+		p.seenObjects[h] = p.seenOrder.PushBack(seenObject{h: h, seen: now})
+	}
+
+	// The list is ordered from least to most recently seen, so expired and
+	// excess objects are at the front.
+	for e := p.seenOrder.Front(); e != nil; e = p.seenOrder.Front() {
+		so := e.Value.(seenObject)
+		if len(p.seenObjects) <= maxSeenObjects && now.Sub(so.seen) < seenObjectExpiry {
+			break
+		}
+		p.seenOrder.Remove(e)
+		delete(p.seenObjects, so.h)
+	}
+}
+
+// managedHasSeen reports whether the peer at addr has recently sent or been
+// sent the object with hash h. Peers that are not connected have not seen any
+// objects.
+func (g *Gateway) managedHasSeen(addr modules.NetAddress, h crypto.Hash) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	p, ok := g.peers[addr]
+	return ok && p.hasSeen(h, time.Now())
+}
+
+// managedMarkSeen records that the peer at addr has sent or been sent the
+// object with hash h. Nothing is recorded for peers that are not connected.
+func (g *Gateway) managedMarkSeen(addr modules.NetAddress, h crypto.Hash) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if p, ok := g.peers[addr]; ok {
+		p.markSeen(h, time.Now())
+	}
+}
@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestBroadcastNotEchoed checks that an object received from a peer is not
+// relayed back to that peer, and that duplicate broadcasts are not sent.
+func TestBroadcastNotEchoed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g1 := newNamedTestingGateway(t, "1")
+	defer g1.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+	g3 := newNamedTestingGateway(t, "3")
+	defer g3.Close()
+
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal("failed to connect:", err)
+	}
+	if err := g1.Connect(g3.Address()); err != nil {
+		t.Fatal("failed to connect:", err)
+	}
+
+	// g1 relays everything it receives to all of its peers. Only the
+	// broadcast RPCs are recorded, so the test uses one of their names.
+	g1.RegisterRPC("RelayTransactionSet", func(conn modules.PeerConn) error {
+		var payload string
+		if err := encoding.ReadObject(conn, &payload, 100); err != nil {
+			return err
+		}
+		g1.Broadcast("RelayTransactionSet", payload, g1.Peers())
+		return nil
+	})
+	g2Chan := make(chan string, 10)
+	g2.RegisterRPC("RelayTransactionSet", func(conn modules.PeerConn) error {
+		var payload string
+		encoding.ReadObject(conn, &payload, 100)
+		g2Chan <- payload
+		return nil
+	})
+	g3Chan := make(chan string, 10)
+	g3.RegisterRPC("RelayTransactionSet", func(conn modules.PeerConn) error {
+		var payload string
+		encoding.ReadObject(conn, &payload, 100)
+		g3Chan <- payload
+		return nil
+	})
+
+	// An object broadcast by g2 should be relayed to g3, but not back to g2.
+	g2.Broadcast("RelayTransactionSet", "foo", g2.Peers())
+	select {
+	case payload := <-g3Chan:
+		if payload != "foo" {
+			t.Fatal("wrong payload relayed:", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("object was not relayed to g3")
+	}
+	select {
+	case <-g2Chan:
+		t.Fatal("object was relayed back to its sender")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Broadcasting the same object again should not reach any peer.
+	g1.Broadcast("RelayTransactionSet", "foo", g1.Peers())
+	select {
+	case <-g2Chan:
+		t.Fatal("duplicate object was broadcast to g2")
+	case <-g3Chan:
+		t.Fatal("duplicate object was broadcast to g3")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// A new object should reach both peers.
+	g1.Broadcast("RelayTransactionSet", "bar", g1.Peers())
+	for _, c := range []chan string{g2Chan, g3Chan} {
+		select {
+		case payload := <-c:
+			if payload != "bar" {
+				t.Fatal("wrong payload broadcast:", payload)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("new object was not broadcast")
+		}
+	}
+}
+
+// TestMarkSeen checks that a peer remembers a limited number of objects, and
+// forgets them once they expire.
+func TestMarkSeen(t *testing.T) {
+	var p peer
+	now := time.Now()
+	h := crypto.HashObject("foo")
+	if p.hasSeen(h, now) {
+		t.Fatal("new object should not have been seen")
+	}
+	p.markSeen(h, now)
+	if !p.hasSeen(h, now) {
+		t.Fatal("object should have been seen")
+	} else if p.hasSeen(h, now.Add(seenObjectExpiry)) {
+		t.Fatal("object should have expired")
+	}
+
+	// Seeing an object again should make it the most recently seen.
+	p.markSeen(crypto.HashObject(0), now)
+	p.markSeen(h, now.Add(1))
+
+	// Filling the cache should evict the least recently seen object.
+	for i := 1; i < maxSeenObjects; i++ {
+		p.markSeen(crypto.HashObject(i), now.Add(time.Duration(i+1)))
+	}
+	if len(p.seenObjects) != maxSeenObjects || p.seenOrder.Len() != maxSeenObjects {
+		t.Fatal("wrong number of seen objects:", len(p.seenObjects), p.seenOrder.Len())
+	} else if p.hasSeen(crypto.HashObject(0), now) {
+		t.Fatal("least recently seen object was not evicted")
+	} else if !p.hasSeen(h, now) {
+		t.Fatal("recently seen object was evicted")
+	}
+
+	// Expired objects should be forgotten.
+	p.markSeen(crypto.HashObject("bar"), now.Add(seenObjectExpiry+2))
+	if len(p.seenObjects) != maxSeenObjects-1 || p.seenOrder.Len() != maxSeenObjects-1 {
+		t.Fatal("expired objects were not forgotten:", len(p.seenObjects), p.seenOrder.Len())
+	}
+}
@@ -83,8 +83,32 @@ func (g *Gateway) threadedLearnHostname() {
 	g.log.Println("INFO: our address is", addr)
 }
 
+// errUPnPTesting is returned when UPnP discovery is attempted during testing.
+var errUPnPTesting = errors.New("UPnP is disabled during testing")
+
+// upnpDevice is a router that can forward ports on behalf of the gateway. It is
+// satisfied by *upnp.IGD, and exists so that tests can substitute a mock
+// router.
+type upnpDevice interface {
+	Forward(port uint16, desc string) error
+	Clear(port uint16) error
+}
+
+// discoverUPnPDevice returns the first UPnP-enabled router found on the local
+// network.
+func discoverUPnPDevice() (upnpDevice, error) {
+	if build.Release == "testing" {
+		return nil, errUPnPTesting
+	}
+	d, err := upnp.Discover()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
 // threadedForwardPort adds a port mapping to the router.
-func (g *Gateway) threadedForwardPort(port string) {
+func (g *Gateway) threadedForwardPort() {
 	if err := g.threads.Add(); err != nil {
 		return
 	}
@@ -93,8 +117,25 @@ func (g *Gateway) threadedForwardPort(port string) {
 	if build.Release == "testing" {
 		return
 	}
+	g.mu.RLock()
+	port := g.port
+	g.mu.RUnlock()
+	g.managedForwardPort(port)
+}
 
-	d, err := upnp.Discover()
+// managedForwardPort asks the router to forward port to the gateway. The
+// mapping is removed by managedClearPort, which is called when UPnP is
+// disabled and at shutdown.
+func (g *Gateway) managedForwardPort(port string) {
+	g.mu.RLock()
+	disabled := g.disableUPnP || g.forwardedPort != ""
+	discover := g.discoverUPnP
+	g.mu.RUnlock()
+	if disabled {
+		return
+	}
+
+	d, err := discover()
 	if err != nil {
 		g.log.Printf("WARN: could not automatically forward port %s: no UPnP-enabled devices found: %v", port, err)
 		return
@@ -107,22 +148,27 @@ func (g *Gateway) threadedForwardPort(port string) {
 		return
 	}
 
+	g.mu.Lock()
+	g.forwardedPort = port
+	g.mu.Unlock()
 	g.log.Println("INFO: successfully forwarded port", port)
-
-	// Establish port-clearing at shutdown.
-	g.threads.AfterStop(func() {
-		g.managedClearPort(port)
-	})
 }
 
-// managedClearPort removes a port mapping from the router.
-func (g *Gateway) managedClearPort(port string) {
-	if build.Release == "testing" {
+// managedClearPort removes the gateway's port mapping from the router, if one
+// was added.
+func (g *Gateway) managedClearPort() {
+	g.mu.Lock()
+	port := g.forwardedPort
+	g.forwardedPort = ""
+	discover := g.discoverUPnP
+	g.mu.Unlock()
+	if port == "" {
 		return
 	}
 
-	d, err := upnp.Discover()
+	d, err := discover()
 	if err != nil {
+		g.log.Printf("WARN: could not automatically unforward port %s: no UPnP-enabled devices found: %v", port, err)
 		return
 	}
 
@@ -135,3 +181,25 @@ func (g *Gateway) managedClearPort(port string) {
 
 	g.log.Println("INFO: successfully unforwarded port", port)
 }
+
+// SetUPnP sets whether the gateway uses UPnP to forward its port on the
+// router. Enabling UPnP immediately requests a port mapping; disabling it
+// removes any mapping that the gateway has already added. Some networks
+// disallow UPnP, in which case it should be disabled.
+func (g *Gateway) SetUPnP(enabled bool) {
+	if err := g.threads.Add(); err != nil {
+		return
+	}
+	defer g.threads.Done()
+
+	g.mu.Lock()
+	g.disableUPnP = !enabled
+	port := g.port
+	g.mu.Unlock()
+
+	if enabled {
+		g.managedForwardPort(port)
+	} else {
+		g.managedClearPort()
+	}
+}
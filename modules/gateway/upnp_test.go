@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// mockUPnPDevice is a upnpDevice that records the ports it has been asked to
+// forward and clear.
+type mockUPnPDevice struct {
+	forwarded []uint16
+	cleared   []uint16
+	mu        sync.Mutex
+}
+
+func (d *mockUPnPDevice) Forward(port uint16, desc string) error {
+	d.mu.Lock()
+	d.forwarded = append(d.forwarded, port)
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *mockUPnPDevice) Clear(port uint16) error {
+	d.mu.Lock()
+	d.cleared = append(d.cleared, port)
+	d.mu.Unlock()
+	return nil
+}
+
+// calls returns the number of Forward and Clear calls made on the device.
+func (d *mockUPnPDevice) calls() (forwards, clears int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.forwarded), len(d.cleared)
+}
+
+// TestSetUPnP checks that the gateway maps its port when UPnP is enabled and
+// unmaps it when UPnP is disabled or the gateway shuts down.
+func TestSetUPnP(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+
+	d := new(mockUPnPDevice)
+	g.mu.Lock()
+	g.discoverUPnP = func() (upnpDevice, error) { return d, nil }
+	port, _ := strconv.Atoi(g.port)
+	g.mu.Unlock()
+
+	// Enabling UPnP should forward the gateway's port.
+	g.SetUPnP(true)
+	if f, c := d.calls(); f != 1 || c != 0 {
+		t.Fatalf("expected 1 forward and 0 clears, got %v and %v", f, c)
+	} else if d.forwarded[0] != uint16(port) {
+		t.Fatalf("forwarded port %v, expected %v", d.forwarded[0], port)
+	}
+	// Enabling UPnP again should not add a second mapping.
+	g.SetUPnP(true)
+	if f, c := d.calls(); f != 1 || c != 0 {
+		t.Fatalf("expected 1 forward and 0 clears, got %v and %v", f, c)
+	}
+
+	// Disabling UPnP should clear the mapping.
+	g.SetUPnP(false)
+	if f, c := d.calls(); f != 1 || c != 1 {
+		t.Fatalf("expected 1 forward and 1 clear, got %v and %v", f, c)
+	} else if d.cleared[0] != uint16(port) {
+		t.Fatalf("cleared port %v, expected %v", d.cleared[0], port)
+	}
+	// Disabling UPnP again should not clear anything, since there is no
+	// mapping.
+	g.SetUPnP(false)
+	if f, c := d.calls(); f != 1 || c != 1 {
+		t.Fatalf("expected 1 forward and 1 clear, got %v and %v", f, c)
+	}
+
+	// Re-enable UPnP and close the gateway. The mapping should be cleared
+	// during shutdown.
+	g.SetUPnP(true)
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if f, c := d.calls(); f != 2 || c != 2 {
+		t.Fatalf("expected 2 forwards and 2 clears, got %v and %v", f, c)
+	}
+}
+
+// TestUPnPDisabled checks that a gateway created with UPnP disabled does not
+// forward its port.
+func TestUPnPDisabled(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g, err := NewCustomGateway("localhost:0", false, false, build.TempDir("gateway", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := new(mockUPnPDevice)
+	g.mu.Lock()
+	g.discoverUPnP = func() (upnpDevice, error) { return d, nil }
+	port := g.port
+	g.mu.Unlock()
+
+	g.managedForwardPort(port)
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if f, c := d.calls(); f != 0 || c != 0 {
+		t.Fatalf("expected no forwards or clears, got %v and %v", f, c)
+	}
+}
@@ -1,6 +1,10 @@
 package modules
 
 import (
+	"io"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -83,6 +87,7 @@ type (
 		MaxDownloadBatchSize uint64            `json:"maxdownloadbatchsize"`
 		MaxDuration          types.BlockHeight `json:"maxduration"`
 		MaxReviseBatchSize   uint64            `json:"maxrevisebatchsize"`
+		MinDuration          types.BlockHeight `json:"minduration"`
 		NetAddress           NetAddress        `json:"netaddress"`
 		WindowSize           types.BlockHeight `json:"windowsize"`
 
@@ -102,6 +107,7 @@ type (
 		DownloadCalls     uint64 `json:"downloadcalls"`
 		ErrorCalls        uint64 `json:"errorcalls"`
 		FormContractCalls uint64 `json:"formcontractcalls"`
+		ProveSectorCalls  uint64 `json:"provesectorcalls"`
 		RenewCalls        uint64 `json:"renewcalls"`
 		ReviseCalls       uint64 `json:"revisecalls"`
 		SettingsCalls     uint64 `json:"settingscalls"`
@@ -139,6 +145,11 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// ClockSkew returns the estimated difference between the host's
+		// clock and network time, derived from recent block timestamps. A
+		// positive value means the host's clock is ahead.
+		ClockSkew() time.Duration
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -157,13 +168,79 @@ type (
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// SetAccessLog sets the writer that the host uses to record every
+		// sector read, write, and removal made on behalf of a renter. Passing
+		// nil disables the access log.
+		SetAccessLog(io.Writer)
+
+		// SetMaxRevisionsPerMinute limits the number of revisions that
+		// each contract may make per minute. A value of 0 removes the
+		// limit.
+		SetMaxRevisionsPerMinute(n int) error
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetPriceOracle causes the host to periodically replace its
+		// settings with those returned by the oracle. A nil oracle stops
+		// any previously set oracle.
+		SetPriceOracle(oracle func() (HostInternalSettings, error), interval time.Duration) error
+
+		// ValidateSettings checks that the provided settings are internally
+		// consistent, without applying them.
+		ValidateSettings(HostInternalSettings) error
+
+		// RenterAllowlist returns the public keys of the renters that may
+		// form contracts with the host. An empty list means that any renter
+		// may form contracts.
+		RenterAllowlist() []types.SiaPublicKey
+
+		// BandwidthPrices returns the prices that the host charges for
+		// uploading and downloading data.
+		BandwidthPrices() (uploadPrice, downloadPrice types.Currency)
+
+		// SetBandwidthPrices sets the prices that the host charges for
+		// uploading and downloading data, re-announcing the host if it has
+		// already announced.
+		SetBandwidthPrices(uploadPrice, downloadPrice types.Currency) error
+
+		// SetCollateralFunction sets a function that computes the maximum
+		// collateral that the host will add to a contract, given the renter's
+		// funds and the contract duration. The result is clamped to the
+		// MaxCollateral setting, and is reported to renters whose contracts
+		// exceed it.
+		SetCollateralFunction(func(contractValue types.Currency, duration types.BlockHeight) types.Currency)
+
+		// SetContractDurationBounds sets the minimum and maximum duration of
+		// contracts that the host will form.
+		SetContractDurationBounds(min, max types.BlockHeight) error
+
+		// SetRenterAllowlist restricts contract formation to the renters
+		// with the provided public keys. An empty list allows any renter.
+		// Keys are matched against each contract's renter key.
+		SetRenterAllowlist([]types.SiaPublicKey) error
+
+		// SetMinRenterCompletionRate sets the fraction of its previous
+		// contracts that a renter must have completed in order to form a
+		// new contract with the host. Renters are identified by the key
+		// they sign contracts with.
+		SetMinRenterCompletionRate(ratio float64) error
+
 		// StorageObligations returns the set of storage obligations held by
 		// the host.
 		StorageObligations() []StorageObligation
 
+		// IterateSectors calls fn on the Merkle root and data of each sector
+		// stored by the host. Sectors shared by multiple storage obligations
+		// are visited once. Sectors that the host has lost are passed to fn
+		// with nil data. Iteration stops if fn returns an error.
+		IterateSectors(fn func(root crypto.Hash, data []byte) error) error
+
+		// ContractBandwidthReport returns the number of bytes uploaded and
+		// downloaded under a contract, along with the revenue the host stands
+		// to earn from it.
+		ContractBandwidthReport(types.FileContractID) (uploaded, downloaded uint64, revenue types.Currency)
+
 		// ConnectabilityStatus returns the connectability status of the host, that
 		// is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
@@ -0,0 +1,108 @@
+package host
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// accesslog.go implements the sector access log. When enabled, the host emits
+// a record for every sector that is read, written, or removed on behalf of a
+// renter, which hosts can use to audit the data they are serving. Records are
+// queued and written by a background thread so that a slow writer never
+// blocks the RPCs that touch sectors.
+
+const (
+	// sectorAccessRead, sectorAccessWrite, and sectorAccessRemove are the
+	// operations that can appear in the sector access log.
+	sectorAccessRead   = "read"
+	sectorAccessWrite  = "write"
+	sectorAccessRemove = "remove"
+)
+
+// sectorAccessRecord is a single entry in the sector access log. Records are
+// written to the log as newline-delimited JSON.
+type sectorAccessRecord struct {
+	Timestamp  time.Time          `json:"timestamp"`
+	RenterKey  types.SiaPublicKey `json:"renterkey"`
+	SectorRoot crypto.Hash        `json:"sectorroot"`
+	Operation  string             `json:"operation"`
+}
+
+// logSectorAccess queues a record of a sector access. If the access log is
+// disabled the call is a no-op. If the queue is full the record is dropped
+// rather than blocking the caller.
+func (h *Host) logSectorAccess(renterKey types.SiaPublicKey, root crypto.Hash, op string) {
+	h.accessLogMu.Lock()
+	enabled := h.accessLog != nil
+	h.accessLogMu.Unlock()
+	if !enabled {
+		return
+	}
+
+	select {
+	case h.accessLogQueue <- sectorAccessRecord{
+		Timestamp:  time.Now(),
+		RenterKey:  renterKey,
+		SectorRoot: root,
+		Operation:  op,
+	}:
+	default:
+		h.log.Println("WARN: sector access log queue is full, dropping record for sector", root)
+	}
+}
+
+// threadedWriteAccessLog writes queued sector access records to the access
+// log until the host is shut down. Records that are still queued at shutdown
+// are written by flushAccessLog.
+func (h *Host) threadedWriteAccessLog(closeChan chan struct{}) {
+	defer close(closeChan)
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case record := <-h.accessLogQueue:
+			h.writeAccessRecord(record)
+		}
+	}
+}
+
+// flushAccessLog writes all of the queued sector access records to the
+// access log. It is called during shutdown, after threadedWriteAccessLog has
+// exited and every RPC has finished, so that no records are lost.
+func (h *Host) flushAccessLog() {
+	for {
+		select {
+		case record := <-h.accessLogQueue:
+			h.writeAccessRecord(record)
+		default:
+			return
+		}
+	}
+}
+
+// writeAccessRecord writes a sector access record to the access log, if it
+// is enabled.
+func (h *Host) writeAccessRecord(record sectorAccessRecord) {
+	h.accessLogMu.Lock()
+	w := h.accessLog
+	h.accessLogMu.Unlock()
+	if w == nil {
+		return
+	}
+	err := json.NewEncoder(w).Encode(record)
+	if err != nil {
+		h.log.Println("WARN: could not write to the sector access log:", err)
+	}
+}
+
+// SetAccessLog sets the writer that sector access records are written to.
+// Passing nil disables the access log.
+func (h *Host) SetAccessLog(w io.Writer) {
+	h.accessLogMu.Lock()
+	h.accessLog = w
+	h.accessLogMu.Unlock()
+}
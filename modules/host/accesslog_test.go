@@ -0,0 +1,191 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.Write(p)
+}
+
+// records decodes the sector access records that have been written to the
+// buffer.
+func (lb *lockedBuffer) records() ([]sectorAccessRecord, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	var records []sectorAccessRecord
+	scanner := bufio.NewScanner(bytes.NewReader(lb.buf.Bytes()))
+	for scanner.Scan() {
+		var record sectorAccessRecord
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// TestSectorAccessLog checks that the sector access log records a sequence
+// of sector writes and removals in order. Reads are recorded by the download
+// RPC, and are tested in the api package.
+func TestSectorAccessLog(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	var log lockedBuffer
+	ht.host.SetAccessLog(&log)
+
+	// Create a storage obligation with a revision that identifies the
+	// renter.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	defer ht.host.managedUnlockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renterKey := types.Ed25519PublicKey(crypto.PublicKey{1, 2, 3})
+	validPayouts, missedPayouts := so.payouts()
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID: so.id(),
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys: []types.SiaPublicKey{renterKey, ht.host.publicKey},
+			},
+			NewRevisionNumber:     1,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+		}},
+	}}
+
+	// Write two sectors, then remove the first sector.
+	root1, data1 := randSector()
+	root2, data2 := randSector()
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{root1}, [][]byte{data1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{root2}, [][]byte{data2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.modifyStorageObligation(so, []crypto.Hash{root1}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		root crypto.Hash
+		op   string
+	}{
+		{root1, sectorAccessWrite},
+		{root2, sectorAccessWrite},
+		{root1, sectorAccessRemove},
+	}
+
+	// The records are written asynchronously; wait for all of them to
+	// arrive.
+	var records []sectorAccessRecord
+	for i := 0; i < 50; i++ {
+		records, err = log.records()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) >= len(expected) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %v records, got %v", len(expected), len(records))
+	}
+	for i, record := range records {
+		if record.SectorRoot != expected[i].root || record.Operation != expected[i].op {
+			t.Errorf("record %v: expected %v of %v, got %v of %v", i, expected[i].op, expected[i].root, record.Operation, record.SectorRoot)
+		}
+		if record.RenterKey.String() != renterKey.String() {
+			t.Errorf("record %v: wrong renter key: %v", i, record.RenterKey)
+		}
+		if i > 0 && record.Timestamp.Before(records[i-1].Timestamp) {
+			t.Errorf("record %v is out of order", i)
+		}
+	}
+
+	// After disabling the access log, no further records should be written.
+	ht.host.SetAccessLog(nil)
+	ht.host.logSectorAccess(so.renterKey(), root2, sectorAccessRead)
+	time.Sleep(100 * time.Millisecond)
+	records, err = log.records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %v records after disabling the log, got %v", len(expected), len(records))
+	}
+}
+
+// TestSectorAccessLogFlush checks that records which are still queued when
+// the host shuts down are written to the access log.
+func TestSectorAccessLogFlush(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log lockedBuffer
+	ht.host.SetAccessLog(&log)
+	renterKey := types.Ed25519PublicKey(crypto.PublicKey{1, 2, 3})
+	const n = 100
+	for i := 0; i < n; i++ {
+		ht.host.logSectorAccess(renterKey, crypto.Hash{byte(i)}, sectorAccessRead)
+	}
+	if err := ht.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := log.records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != n {
+		t.Fatalf("expected %v records after shutdown, got %v", n, len(records))
+	}
+	for i, record := range records {
+		if record.SectorRoot != (crypto.Hash{byte(i)}) {
+			t.Fatalf("record %v is out of order", i)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestSetBandwidthPrices checks that SetBandwidthPrices updates the prices in
+// the host's external settings, rejects zero prices, and re-announces the
+// host.
+func TestSetBandwidthPrices(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Announce the host so that price changes trigger a re-announcement.
+	if err := ht.host.Announce(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change only the upload price.
+	up, down := ht.host.BandwidthPrices()
+	newUp := up.Mul64(2)
+	if err := ht.host.SetBandwidthPrices(newUp, down); err != nil {
+		t.Fatal(err)
+	}
+	es := ht.host.ExternalSettings()
+	if !es.UploadBandwidthPrice.Equals(newUp) || !es.DownloadBandwidthPrice.Equals(down) {
+		t.Fatal("upload price was not updated correctly:", es.UploadBandwidthPrice, es.DownloadBandwidthPrice)
+	}
+	if len(ht.tpool.TransactionList()) == 0 {
+		t.Fatal("host did not re-announce after changing prices")
+	}
+
+	// Change only the download price.
+	newDown := down.Mul64(3)
+	if err := ht.host.SetBandwidthPrices(newUp, newDown); err != nil {
+		t.Fatal(err)
+	}
+	es = ht.host.ExternalSettings()
+	if !es.UploadBandwidthPrice.Equals(newUp) || !es.DownloadBandwidthPrice.Equals(newDown) {
+		t.Fatal("download price was not updated correctly:", es.UploadBandwidthPrice, es.DownloadBandwidthPrice)
+	}
+	if up, down := ht.host.BandwidthPrices(); !up.Equals(newUp) || !down.Equals(newDown) {
+		t.Fatal("BandwidthPrices returned wrong prices:", up, down)
+	}
+
+	// Zero prices should be rejected, leaving the prices unchanged.
+	if err := ht.host.SetBandwidthPrices(types.ZeroCurrency, newDown); err != errZeroBandwidthPrice {
+		t.Fatal("expected errZeroBandwidthPrice, got", err)
+	}
+	if err := ht.host.SetBandwidthPrices(newUp, types.ZeroCurrency); err != errZeroBandwidthPrice {
+		t.Fatal("expected errZeroBandwidthPrice, got", err)
+	}
+	if up, down := ht.host.BandwidthPrices(); !up.Equals(newUp) || !down.Equals(newDown) {
+		t.Fatal("rejected prices were applied:", up, down)
+	}
+}
@@ -0,0 +1,56 @@
+package host
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// recordClockSample records the difference between the host's clock and the
+// timestamp of a newly received block, logging a warning if the estimated
+// skew has become large. The host uses the consensus height rather than its
+// clock to decide when to submit revisions and storage proofs, but a skewed
+// clock still indicates a misconfigured machine.
+func (h *Host) recordClockSample(blockTime types.Timestamp, now time.Time) {
+	h.clockSamples = append(h.clockSamples, now.Sub(time.Unix(int64(blockTime), 0)))
+	if len(h.clockSamples) > clockSkewSamples {
+		h.clockSamples = h.clockSamples[1:]
+	}
+	if len(h.clockSamples) < clockSkewSamples {
+		return
+	}
+
+	skew := h.clockSkew()
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > clockSkewWarningThreshold && !h.clockSkewWarned {
+		h.log.Printf("WARN: host clock differs from network time by about %v; check the system clock\n", h.clockSkew())
+		h.clockSkewWarned = true
+	} else if skew <= clockSkewWarningThreshold {
+		h.clockSkewWarned = false
+	}
+}
+
+// clockSkew returns the median of the recorded clock samples, or zero if no
+// samples have been recorded.
+func (h *Host) clockSkew() time.Duration {
+	if len(h.clockSamples) == 0 {
+		return 0
+	}
+	samples := append([]time.Duration(nil), h.clockSamples...)
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples[len(samples)/2]
+}
+
+// ClockSkew returns the estimated difference between the host's clock and
+// network time, as measured by the timestamps of recently received blocks. A
+// positive value means the host's clock is ahead. The estimate includes the
+// time taken for blocks to propagate, and is zero until the host has received
+// a block while synced.
+func (h *Host) ClockSkew() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.clockSkew()
+}
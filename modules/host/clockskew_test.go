@@ -0,0 +1,130 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestProofWindowHeight checks that the decision to submit a storage proof is
+// driven by the block height of the proof window alone.
+func TestProofWindowHeight(t *testing.T) {
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				WindowStart: 100,
+				WindowEnd:   120,
+			}},
+		}},
+	}
+	tests := []struct {
+		height types.BlockHeight
+		open   bool
+		closed bool
+	}{
+		{0, false, false},
+		{100, false, false},
+		{100 + resubmissionTimeout - 1, false, false},
+		{100 + resubmissionTimeout, true, false},
+		{120, true, false},
+		{121, true, true},
+	}
+	for _, test := range tests {
+		if so.proofWindowOpen(test.height) != test.open {
+			t.Errorf("proofWindowOpen(%v): expected %v", test.height, test.open)
+		}
+		if so.proofWindowClosed(test.height) != test.closed {
+			t.Errorf("proofWindowClosed(%v): expected %v", test.height, test.closed)
+		}
+	}
+
+	// Confirmed proofs do not need to be submitted again.
+	so.ProofConfirmed = true
+	if so.proofWindowOpen(110) {
+		t.Error("proof window should not be open for a confirmed proof")
+	}
+
+	// A revision moves the proof window.
+	so.ProofConfirmed = false
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			NewWindowStart: 200,
+			NewWindowEnd:   220,
+		}},
+	}}
+	if so.proofWindowOpen(110) || !so.proofWindowOpen(200+resubmissionTimeout) {
+		t.Error("proof window should follow the revised window start")
+	}
+	if so.proofWindowClosed(121) || !so.proofWindowClosed(221) {
+		t.Error("proof window should follow the revised window end")
+	}
+}
+
+// TestClockSkew checks that the host estimates its clock skew from block
+// timestamps, and that a large skew is flagged.
+func TestClockSkew(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Clear any samples recorded while the tester was being created.
+	ht.host.mu.Lock()
+	ht.host.clockSamples = nil
+	ht.host.mu.Unlock()
+	if skew := ht.host.ClockSkew(); skew != 0 {
+		t.Fatal("expected no skew without samples, got", skew)
+	}
+
+	// Simulate a host whose clock is two hours ahead of the network. One
+	// outlier should not affect the estimate.
+	now := time.Now()
+	ht.host.mu.Lock()
+	for i := 0; i < clockSkewSamples; i++ {
+		blockTime := types.Timestamp(now.Add(-2 * time.Hour).Unix())
+		if i == 0 {
+			blockTime = types.Timestamp(now.Unix())
+		}
+		ht.host.recordClockSample(blockTime, now)
+	}
+	warned := ht.host.clockSkewWarned
+	ht.host.mu.Unlock()
+	if skew := ht.host.ClockSkew(); skew != 2*time.Hour {
+		t.Fatal("expected skew of 2h, got", skew)
+	} else if !warned {
+		t.Fatal("large skew should have been flagged")
+	}
+
+	// Once the clock is corrected, the estimate should recover.
+	ht.host.mu.Lock()
+	for i := 0; i < clockSkewSamples; i++ {
+		ht.host.recordClockSample(types.Timestamp(now.Unix()), now)
+	}
+	warned = ht.host.clockSkewWarned
+	ht.host.mu.Unlock()
+	if skew := ht.host.ClockSkew(); skew != 0 {
+		t.Fatal("expected no skew, got", skew)
+	} else if warned {
+		t.Fatal("warning should be reset once the clock is corrected")
+	}
+
+	// Blocks received while synced should be sampled.
+	ht.host.mu.Lock()
+	ht.host.clockSamples = nil
+	ht.host.mu.Unlock()
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host.mu.RLock()
+	n := len(ht.host.clockSamples)
+	ht.host.mu.RUnlock()
+	if n != 1 {
+		t.Fatal("expected new block to be sampled, got", n, "samples")
+	}
+}
@@ -0,0 +1,34 @@
+package host
+
+import (
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// SetCollateralFunction sets a function that computes the maximum collateral
+// that the host is willing to add to a contract. contractValue is the amount
+// of money that the renter puts into the contract, and duration is the number
+// of blocks until the contract's proof window begins. The function's output
+// is still clamped to the MaxCollateral setting. Passing nil restores the
+// default behavior of accepting any collateral up to MaxCollateral. When a
+// contract is rejected for exceeding the limit, the limit is reported to the
+// renter so that it can retry with less collateral. The function is not
+// persisted.
+func (h *Host) SetCollateralFunction(fn func(contractValue types.Currency, duration types.BlockHeight) types.Currency) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.collateralFunc = fn
+}
+
+// collateralLimit returns the maximum collateral that the host will add to fc,
+// given the host's collateral function, its MaxCollateral setting, and the
+// current block height. fc.WindowStart must be greater than blockHeight.
+func collateralLimit(fn func(types.Currency, types.BlockHeight) types.Currency, maxCollateral types.Currency, fc types.FileContract, blockHeight types.BlockHeight) types.Currency {
+	if fn == nil {
+		return maxCollateral
+	}
+	limit := fn(fc.ValidProofOutputs[0].Value, fc.WindowStart-blockHeight)
+	if limit.Cmp(maxCollateral) > 0 {
+		return maxCollateral
+	}
+	return limit
+}
@@ -0,0 +1,72 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestCollateralFunction checks that the host's collateral function limits
+// the collateral of new contracts, and that its output is clamped to
+// MaxCollateral.
+func TestCollateralFunction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	_, renterPK := crypto.GenerateKeyPair()
+	settings := ht.host.InternalSettings()
+	// verifyCollateral checks whether the host accepts a contract in which
+	// the renter pays renterFunds and the host adds collateral.
+	verifyCollateral := func(renterFunds, collateral types.Currency) error {
+		txnSet := ht.newTestContractSet(renterPK)
+		fc := &txnSet[0].FileContracts[0]
+		fc.ValidProofOutputs[0].Value = renterFunds
+		fc.MissedProofOutputs[0].Value = renterFunds
+		fc.ValidProofOutputs[1].Value = settings.MinContractPrice.Add(collateral)
+		fc.MissedProofOutputs[1].Value = fc.ValidProofOutputs[1].Value
+		return ht.host.managedVerifyNewContract(txnSet, renterPK)
+	}
+
+	// Allow collateral of up to half of the renter's funds.
+	var gotDuration types.BlockHeight
+	ht.host.SetCollateralFunction(func(contractValue types.Currency, duration types.BlockHeight) types.Currency {
+		gotDuration = duration
+		return contractValue.Div64(2)
+	})
+	funds := types.SiacoinPrecision.Mul64(100)
+	if err := verifyCollateral(funds, funds.Div64(2)); err != nil {
+		t.Fatal(err)
+	}
+	err = verifyCollateral(funds, funds.Div64(2).Add(types.NewCurrency64(1)))
+	if limit, ok := modules.CollateralLimit(err); !ok || !limit.Equals(funds.Div64(2)) {
+		t.Fatal("expected the collateral limit to be reported, got", err)
+	}
+	if gotDuration != revisionSubmissionBuffer+1 {
+		t.Fatal("collateral function was passed the wrong duration:", gotDuration)
+	}
+
+	// The function's output should be clamped to MaxCollateral.
+	funds = settings.MaxCollateral.Mul64(4)
+	if err := verifyCollateral(funds, settings.MaxCollateral); err != nil {
+		t.Fatal(err)
+	}
+	err = verifyCollateral(funds, settings.MaxCollateral.Add(types.NewCurrency64(1)))
+	if limit, ok := modules.CollateralLimit(err); !ok || !limit.Equals(settings.MaxCollateral) {
+		t.Fatal("expected the collateral limit to be reported, got", err)
+	}
+
+	// Removing the function restores the default limit.
+	ht.host.SetCollateralFunction(nil)
+	if err := verifyCollateral(types.SiacoinPrecision, settings.MaxCollateral); err != nil {
+		t.Fatal(err)
+	}
+}
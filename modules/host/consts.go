@@ -9,6 +9,21 @@ import (
 )
 
 const (
+	// accessLogQueueSize is the number of sector access records that can be
+	// waiting to be written to the access log. Records are dropped once the
+	// queue is full so that a slow log writer cannot stall the host.
+	accessLogQueueSize = 4096
+
+	// clockSkewSamples is the number of recent blocks whose timestamps are
+	// used to estimate the difference between the host's clock and network
+	// time.
+	clockSkewSamples = 11
+
+	// clockSkewWarningThreshold is the estimated clock skew above which the
+	// host will log a warning. Block timestamps are only loosely tied to
+	// network time, so the threshold is generous.
+	clockSkewWarningThreshold = 15 * time.Minute
+
 	// defaultMaxDuration defines the maximum number of blocks into the future
 	// that the host will accept for the duration of an incoming file contract
 	// obligation. 6 months is chosen because hosts are expected to be
@@ -26,6 +41,11 @@ const (
 	// connection.
 	iteratedConnectionTime = 1200 * time.Second
 
+	// renterHistoryMinContracts is the number of resolved contracts that a
+	// renter must have with the host before its completion rate is used to
+	// decide whether to accept new contracts from it.
+	renterHistoryMinContracts = 3
+
 	// resubmissionTimeout defines the number of blocks that a host will wait
 	// before attempting to resubmit a transaction to the blockchain.
 	// Typically, this transaction will contain either a file contract, a file
@@ -213,6 +233,17 @@ var (
 		Testing:  types.BlockHeight(4),
 	}).(types.BlockHeight)
 
+	// proveSectorsPerMinute is the number of sector proofs that may be
+	// requested for a single contract per minute. Each proof requires the
+	// host to read a full sector from disk, but is only paid for at the price
+	// of the much smaller proof, so an unlimited number of proofs would let a
+	// renter tie up the host's disk cheaply.
+	proveSectorsPerMinute = build.Select(build.Var{
+		Dev:      30,
+		Standard: 10,
+		Testing:  3,
+	}).(int)
+
 	// rpcRatelimit prevents someone from spamming the host with connections,
 	// causing it to spin up enough goroutines to crash.
 	rpcRatelimit = build.Select(build.Var{
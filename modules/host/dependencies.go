@@ -6,6 +6,8 @@ import (
 	"net"
 	"os"
 
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/host/contractmanager"
 	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/fastrand"
 )
@@ -50,6 +52,10 @@ type (
 		// write critical statements.
 		newLogger(string) (*persist.Logger, error)
 
+		// newStorageManager creates the storage manager that the host uses to
+		// store sectors.
+		newStorageManager(string) (modules.StorageManager, error)
+
 		// openDatabase creates a database that the host can use to interact
 		// with large volumes of persistent data.
 		openDatabase(persist.Metadata, string) (*persist.BoltDatabase, error)
@@ -105,6 +111,12 @@ func (productionDependencies) newLogger(s string) (*persist.Logger, error) {
 	return persist.NewFileLogger(s)
 }
 
+// newStorageManager creates the storage manager that the host uses to store
+// sectors.
+func (productionDependencies) newStorageManager(s string) (modules.StorageManager, error) {
+	return contractmanager.New(s)
+}
+
 // openDatabase creates a database that the host can use to interact with large
 // volumes of persistent data.
 func (productionDependencies) openDatabase(m persist.Metadata, s string) (*persist.BoltDatabase, error) {
@@ -0,0 +1,83 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestContractDurationBounds checks that the host only forms contracts whose
+// duration is within its duration bounds, and that the bounds are persisted.
+func TestContractDurationBounds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	_, renterPK := crypto.GenerateKeyPair()
+	// verifyDuration checks whether the host accepts a contract whose window
+	// starts 'duration' blocks after the current height.
+	verifyDuration := func(duration types.BlockHeight) error {
+		ht.host.mu.RLock()
+		blockHeight := ht.host.blockHeight
+		windowSize := ht.host.settings.WindowSize
+		ht.host.mu.RUnlock()
+		txnSet := ht.newTestContractSet(renterPK)
+		fc := &txnSet[0].FileContracts[0]
+		fc.WindowStart = blockHeight + duration
+		fc.WindowEnd = fc.WindowStart + windowSize
+		return ht.host.managedVerifyNewContract(txnSet, renterPK)
+	}
+
+	min, max := revisionSubmissionBuffer+10, revisionSubmissionBuffer+100
+	if err := ht.host.SetContractDurationBounds(min, max); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		duration types.BlockHeight
+		err      error
+	}{
+		{min - 1, errShortDuration},
+		{min, nil},
+		{(min + max) / 2, nil},
+		{max, nil},
+		{max + 1, errLongDuration},
+	}
+	for _, test := range tests {
+		if err := verifyDuration(test.duration); err != test.err {
+			t.Errorf("duration %v: expected %v, got %v", test.duration, test.err, err)
+		}
+	}
+
+	// Invalid bounds should be rejected.
+	if err := ht.host.SetContractDurationBounds(max, min); err != errInvalidDurationBounds {
+		t.Fatal("expected errInvalidDurationBounds, got", err)
+	}
+	if err := ht.host.SetContractDurationBounds(0, 0); err != errInvalidDurationBounds {
+		t.Fatal("expected errInvalidDurationBounds, got", err)
+	}
+
+	// The bounds should persist across restarts.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings := ht.host.InternalSettings(); settings.MinDuration != min || settings.MaxDuration != max {
+		t.Fatalf("bounds were not persisted: got [%v, %v]", settings.MinDuration, settings.MaxDuration)
+	}
+	if err := verifyDuration(min - 1); err != errShortDuration {
+		t.Fatal("expected errShortDuration after restart, got", err)
+	}
+}
@@ -66,14 +66,15 @@ package host
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
-	"github.com/NebulousLabs/Sia/modules/host/contractmanager"
 	"github.com/NebulousLabs/Sia/persist"
 	siasync "github.com/NebulousLabs/Sia/sync"
 	"github.com/NebulousLabs/Sia/types"
@@ -105,6 +106,14 @@ var (
 	// having been closed.
 	errHostClosed = errors.New("call is disabled because the host is closed")
 
+	// errInvalidDurationBounds is returned when the minimum contract duration
+	// exceeds the maximum, or when the maximum is zero.
+	errInvalidDurationBounds = errors.New("minimum contract duration must not exceed the maximum, and the maximum must be nonzero")
+
+	// errZeroBandwidthPrice is returned by SetBandwidthPrices if either price
+	// is zero.
+	errZeroBandwidthPrice = errors.New("bandwidth prices must be nonzero")
+
 	// Nil dependency errors.
 	errNilCS     = errors.New("host cannot use a nil state")
 	errNilTpool  = errors.New("host cannot use a nil transaction pool")
@@ -119,6 +128,7 @@ type Host struct {
 	atomicDownloadCalls       uint64
 	atomicErroredCalls        uint64
 	atomicFormContractCalls   uint64
+	atomicProveSectorCalls    uint64
 	atomicRenewCalls          uint64
 	atomicReviseCalls         uint64
 	atomicRecentRevisionCalls uint64
@@ -154,18 +164,48 @@ type Host struct {
 
 	// Host transient fields - these fields are either determined at startup or
 	// otherwise are not critical to always be correct.
-	autoAddress          modules.NetAddress // Determined using automatic tooling in network.go
-	financialMetrics     modules.HostFinancialMetrics
-	settings             modules.HostInternalSettings
-	revisionNumber       uint64
-	workingStatus        modules.HostWorkingStatus
-	connectabilityStatus modules.HostConnectabilityStatus
+	autoAddress             modules.NetAddress // Determined using automatic tooling in network.go
+	collateralFunc          func(types.Currency, types.BlockHeight) types.Currency
+	financialMetrics        modules.HostFinancialMetrics
+	maxRevisionsPerMinute   int
+	minRenterCompletionRate float64
+	priceOracleStop         chan struct{} // closed to stop the current price oracle
+	settings                modules.HostInternalSettings
+	renterAllowlist         map[string]types.SiaPublicKey
+	renterHistory           map[string]renterHistory
+	revisionNumber          uint64
+	workingStatus           modules.HostWorkingStatus
+	connectabilityStatus    modules.HostConnectabilityStatus
+
+	// clockSamples holds the differences between the host's clock and the
+	// timestamps of the most recent blocks, oldest first. clockSkewWarned is
+	// set once a warning about the skew has been logged.
+	clockSamples    []time.Duration
+	clockSkewWarned bool
+
+	// recentRevisions holds the times of each contract's revisions in the
+	// last minute, for enforcing maxRevisionsPerMinute. Contracts without
+	// recent revisions are removed from the map once a minute;
+	// recentRevisionsPruned is the time at which this was last done.
+	recentRevisions       map[types.FileContractID][]time.Time
+	recentRevisionsPruned time.Time
+
+	// recentProofs holds the times of the sector proof requests made for
+	// each contract in the last minute, for enforcing proveSectorsPerMinute.
+	recentProofs map[types.FileContractID][]time.Time
 
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
 	lockedStorageObligations map[types.FileContractID]*siasync.TryMutex
 
+	// The sector access log. Records are queued on accessLogQueue and written
+	// to accessLog by threadedWriteAccessLog. accessLog is nil when the
+	// access log is disabled.
+	accessLog      io.Writer
+	accessLogMu    sync.Mutex
+	accessLogQueue chan sectorAccessRecord
+
 	// Utilities.
 	db         *persist.BoltDatabase
 	listener   net.Listener
@@ -224,6 +264,7 @@ func newHost(dependencies dependencies, cs modules.ConsensusSet, tpool modules.T
 		dependencies: dependencies,
 
 		lockedStorageObligations: make(map[types.FileContractID]*siasync.TryMutex),
+		accessLogQueue:           make(chan sectorAccessRecord, accessLogQueueSize),
 
 		persistDir: persistDir,
 	}
@@ -259,7 +300,7 @@ func newHost(dependencies dependencies, cs modules.ConsensusSet, tpool modules.T
 
 	// Add the storage manager to the host, and set up the stop call that will
 	// close the storage manager.
-	h.StorageManager, err = contractmanager.New(filepath.Join(persistDir, "contractmanager"))
+	h.StorageManager, err = dependencies.newStorageManager(filepath.Join(persistDir, "contractmanager"))
 	if err != nil {
 		h.log.Println("Could not open the storage manager:", err)
 		return nil, err
@@ -290,6 +331,16 @@ func newHost(dependencies dependencies, cs modules.ConsensusSet, tpool modules.T
 		h.log.Println("Could not initialize host networking:", err)
 		return nil, err
 	}
+
+	// Start the thread that writes the sector access log.
+	threadedWriteAccessLogClosedChan := make(chan struct{})
+	go h.threadedWriteAccessLog(threadedWriteAccessLogClosedChan)
+	h.tg.OnStop(func() {
+		<-threadedWriteAccessLogClosedChan
+	})
+	h.tg.AfterStop(func() {
+		h.flushAccessLog()
+	})
 	return h, nil
 }
 
@@ -398,6 +449,69 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 	return nil
 }
 
+// SetContractDurationBounds sets the minimum and maximum number of blocks
+// between the formation of a contract and the start of its proof window.
+// Contracts whose duration falls outside of the bounds are rejected at
+// formation.
+func (h *Host) SetContractDurationBounds(min, max types.BlockHeight) error {
+	if max == 0 || min > max {
+		return errInvalidDurationBounds
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.settings.MinDuration = min
+	h.settings.MaxDuration = max
+	h.revisionNumber++
+	return h.saveSync()
+}
+
+// SetBandwidthPrices sets the prices that the host charges renters for
+// uploading and downloading data. If the host has already announced itself,
+// it re-announces so that renters rescan it and learn the new prices.
+func (h *Host) SetBandwidthPrices(uploadPrice, downloadPrice types.Currency) error {
+	if uploadPrice.IsZero() || downloadPrice.IsZero() {
+		return errZeroBandwidthPrice
+	}
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	h.settings.MinUploadBandwidthPrice = uploadPrice
+	h.settings.MinDownloadBandwidthPrice = downloadPrice
+	h.revisionNumber++
+	announced := h.announced
+	err = h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return errors.New("bandwidth prices updated, but failed saving to disk: " + err.Error())
+	}
+
+	if announced {
+		err = h.Announce()
+		if err != nil {
+			return errors.New("bandwidth prices updated, but failed to re-announce: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// BandwidthPrices returns the prices that the host currently charges renters
+// for uploading and downloading data.
+func (h *Host) BandwidthPrices() (uploadPrice, downloadPrice types.Currency) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.settings.MinUploadBandwidthPrice, h.settings.MinDownloadBandwidthPrice
+}
+
 // InternalSettings returns the settings of a host.
 func (h *Host) InternalSettings() modules.HostInternalSettings {
 	h.mu.RLock()
@@ -125,6 +125,14 @@ var (
 	// formation.
 	errMismatchedHostPayouts = ErrorCommunication("rejected because host valid and missed payouts are not the same value")
 
+	// errRenterNotAllowed is returned if the host has a renter allowlist and
+	// the renter forming or renewing a contract is not on it.
+	errRenterNotAllowed = ErrorCommunication("rejected because the host only accepts contracts from allowlisted renters")
+
+	// errShortDuration is returned if the renter proposes a file contract
+	// with an expiration that is closer than the host's minimum duration.
+	errShortDuration = ErrorCommunication("renter proposed a file contract with a too-short duration")
+
 	// errSmallWindow is returned if the renter suggests a storage proof window
 	// that is too small.
 	errSmallWindow = ErrorCommunication("rejected for small window size")
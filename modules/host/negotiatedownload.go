@@ -65,10 +65,10 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 	// for the renter.
 	existingRevision := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0]
 	var payload [][]byte
+	var totalSize uint64
 	err = func() error {
 		// Check that the length of each file is in-bounds, and that the total
 		// size being requested is acceptable.
-		var totalSize uint64
 		for _, request := range requests {
 			if request.Length > modules.SectorSize || request.Offset+request.Length > modules.SectorSize {
 				return extendErr("download iteration request failed: ", errRequestOutOfBounds)
@@ -94,6 +94,7 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 				return extendErr("failed to load sector: ", ErrorInternal(err.Error()))
 			}
 			payload = append(payload, sectorData[request.Offset:request.Offset+request.Length])
+			h.logSectorAccess(so.renterKey(), request.MerkleRoot, sectorAccessRead)
 		}
 		return nil
 	}()
@@ -118,6 +119,7 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 	// Update the storage obligation.
 	paymentTransfer := existingRevision.NewValidProofOutputs[0].Value.Sub(paymentRevision.NewValidProofOutputs[0].Value)
 	so.PotentialDownloadRevenue = so.PotentialDownloadRevenue.Add(paymentTransfer)
+	so.BytesDownloaded += totalSize
 	so.RevisionTransactionSet = []types.Transaction{{
 		FileContractRevisions: []types.FileContractRevision{paymentRevision},
 		TransactionSignatures: []types.TransactionSignature{renterSignature, txn.TransactionSignatures[1]},
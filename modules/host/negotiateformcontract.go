@@ -14,13 +14,16 @@ var (
 	// errCollateralBudgetExceeded is returned if the host does not have enough
 	// room in the collateral budget to accept a particular file contract.
 	errCollateralBudgetExceeded = ErrorInternal("host has reached its collateral budget and cannot accept the file contract")
-
-	// errMaxCollateralReached is returned if a file contract is provided which
-	// would require the host to supply more collateral than the host allows
-	// per file contract.
-	errMaxCollateralReached = ErrorInternal("file contract proposal expects the host to pay more than the maximum allowed collateral")
 )
 
+// errMaxCollateralReached returns the error for a file contract that would
+// require the host to supply more collateral than limit, the most that the
+// host allows for that file contract. The limit is reported to the renter, so
+// that it can propose the contract again with less collateral.
+func errMaxCollateralReached(limit types.Currency) error {
+	return ErrorInternal(modules.NewCollateralLimitError(limit).Error())
+}
+
 // contractCollateral returns the amount of collateral that the host is
 // expected to add to the file contract based on the payout of the file
 // contract and based on the host settings.
@@ -210,13 +213,25 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 
 	h.mu.RLock()
 	blockHeight := h.blockHeight
+	collateralFunc := h.collateralFunc
 	lockedStorageCollateral := h.financialMetrics.LockedStorageCollateral
 	publicKey := h.publicKey
+	renterAllowed := h.renterAllowed(types.Ed25519PublicKey(renterPK))
+	renterReliable := h.renterReliable(types.Ed25519PublicKey(renterPK))
 	settings := h.settings
 	unlockHash := h.unlockHash
 	h.mu.RUnlock()
 	fc := txnSet[len(txnSet)-1].FileContracts[0]
 
+	// If the host has a renter allowlist, the renter must be on it.
+	if !renterAllowed {
+		return errRenterNotAllowed
+	}
+	// The renter must not have abandoned too many previous contracts.
+	if !renterReliable {
+		return errRenterUnreliable
+	}
+
 	// A new file contract should have a file size of zero.
 	if fc.FileSize != 0 {
 		return errBadFileSize
@@ -240,6 +255,11 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 	if fc.WindowStart > blockHeight+settings.MaxDuration {
 		return errLongDuration
 	}
+	// WindowStart must be at least settings.MinDuration blocks into the
+	// future.
+	if fc.WindowStart < blockHeight+settings.MinDuration {
+		return errShortDuration
+	}
 
 	// ValidProofOutputs shoud have 2 outputs (renter + host) and missed
 	// outputs should have 3 (renter + host + void)
@@ -265,10 +285,10 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 		return errLowHostValidOutput
 	}
 	// Check that the collateral does not exceed the maximum amount of
-	// collateral allowed.
+	// collateral allowed for this contract.
 	expectedCollateral := contractCollateral(settings, fc)
-	if expectedCollateral.Cmp(settings.MaxCollateral) > 0 {
-		return errMaxCollateralReached
+	if limit := collateralLimit(collateralFunc, settings.MaxCollateral, fc, blockHeight); expectedCollateral.Cmp(limit) > 0 {
+		return errMaxCollateralReached(limit)
 	}
 	// Check that the host has enough room in the collateral budget to add this
 	// collateral.
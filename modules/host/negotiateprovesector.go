@@ -0,0 +1,224 @@
+package host
+
+import (
+	"net"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	// errProveSectorRateLimited is returned if a renter requests more sector
+	// proofs for a contract than the host allows.
+	errProveSectorRateLimited = ErrorCommunication("rejected for exceeding the sector proof rate limit of the host")
+
+	// errSectorNotInContract is returned when a renter requests a proof for a
+	// sector that is not part of the contract used to open the RPC.
+	errSectorNotInContract = ErrorCommunication("requested sector is not part of the contract")
+
+	// errSegmentOutOfBounds is returned when a renter requests a proof for a
+	// segment that is not within the sector.
+	errSegmentOutOfBounds = ErrorCommunication("requested segment index is outside of the sector")
+)
+
+// managedProveSectorAllowed reports whether a sector proof may be requested
+// for the contract with the given id at time now, and if so, records the
+// request. Requests are allowed if fewer than proveSectorsPerMinute requests
+// were made for the contract in the minute before now.
+func (h *Host) managedProveSectorAllowed(id types.FileContractID, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.recentProofs == nil {
+		h.recentProofs = make(map[types.FileContractID][]time.Time)
+	}
+
+	// Forget the requests that are more than a minute old. Contracts without
+	// any recent requests are removed entirely so that the map does not grow
+	// with every contract that has ever requested a proof.
+	for fcid, times := range h.recentProofs {
+		for len(times) > 0 && now.Sub(times[0]) >= time.Minute {
+			times = times[1:]
+		}
+		if len(times) == 0 {
+			delete(h.recentProofs, fcid)
+		} else {
+			h.recentProofs[fcid] = times
+		}
+	}
+	times := h.recentProofs[id]
+	if len(times) >= proveSectorsPerMinute {
+		return false
+	}
+	h.recentProofs[id] = append(times, now)
+	return true
+}
+
+// managedRPCProveSector is an rpc that proves to the renter that the host is
+// storing a sector. Instead of the full sector, the host sends a single
+// segment along with the Merkle proof that the segment is part of the sector.
+// The renter pays for the proof with a file contract revision, at the host's
+// download bandwidth price.
+//
+// The renter must first prove ownership of a contract through
+// RPCRecentRevision, and may only request proofs for the sectors of that
+// contract.
+func (h *Host) managedRPCProveSector(conn net.Conn) error {
+	// Perform the file contract revision exchange, which authenticates the
+	// renter and provides the storage obligation holding the sectors that the
+	// renter may request proofs for.
+	_, so, err := h.managedRPCRecentRevision(conn)
+	if err != nil {
+		return extendErr("failed RPCRecentRevision during RPCProveSector: ", err)
+	}
+	// The storage obligation is returned with a lock on it. The lock is
+	// released while the sector is read from disk, so track whether it needs
+	// to be released on return.
+	locked := true
+	defer func() {
+		if locked {
+			h.managedUnlockStorageObligation(so.id())
+		}
+	}()
+
+	// Set the negotiation deadline.
+	conn.SetDeadline(time.Now().Add(modules.NegotiateProveSectorTime))
+
+	// Read the request, followed by the file contract revision that pays for
+	// it.
+	var req modules.ProveSectorRequest
+	err = encoding.ReadObject(conn, &req, modules.NegotiateMaxProveSectorRequestSize)
+	if err != nil {
+		return extendErr("failed to read prove sector request: ", ErrorConnection(err.Error()))
+	}
+	var paymentRevision types.FileContractRevision
+	err = encoding.ReadObject(conn, &paymentRevision, modules.NegotiateMaxFileContractRevisionSize)
+	if err != nil {
+		return extendErr("failed to read payment revision: ", ErrorConnection(err.Error()))
+	}
+
+	// Verify the request and the payment, then build the proof. The storage
+	// obligation is unlocked while the sector is read, so the payment is
+	// verified again against the latest revision once the lock is
+	// reacquired.
+	h.mu.RLock()
+	blockHeight := h.blockHeight
+	secretKey := h.secretKey
+	expectedTransfer := h.settings.MinDownloadBandwidthPrice.Mul64(modules.ProveSectorResponseSize())
+	h.mu.RUnlock()
+	var base []byte
+	var hashSet []crypto.Hash
+	var existingRevision types.FileContractRevision
+	err = func() error {
+		if req.SegmentIndex >= modules.SectorSize/crypto.SegmentSize {
+			return errSegmentOutOfBounds
+		}
+		inContract := false
+		for _, root := range so.SectorRoots {
+			if root == req.MerkleRoot {
+				inContract = true
+				break
+			}
+		}
+		if !inContract {
+			return errSectorNotInContract
+		}
+		existingRevision = so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0]
+		err := verifyPaymentRevision(existingRevision, paymentRevision, blockHeight, expectedTransfer)
+		if err != nil {
+			return extendErr("payment verification failed: ", err)
+		}
+		if !h.managedProveSectorAllowed(so.id(), time.Now()) {
+			return errProveSectorRateLimited
+		}
+
+		h.managedUnlockStorageObligation(so.id())
+		locked = false
+		sectorData, err := h.ReadSector(req.MerkleRoot)
+		if err != nil {
+			return extendErr("failed to load sector: ", ErrorInternal(err.Error()))
+		}
+		base, hashSet = crypto.MerkleProof(sectorData, req.SegmentIndex)
+		err = h.managedTryLockStorageObligation(so.id())
+		if err != nil {
+			return extendErr("failed to relock storage obligation: ", ErrorInternal(err.Error()))
+		}
+		locked = true
+
+		// Reload the storage obligation, which may have been revised while it
+		// was unlocked.
+		h.mu.RLock()
+		err = h.db.View(func(tx *bolt.Tx) error {
+			so, err = getStorageObligation(tx, so.id())
+			return err
+		})
+		h.mu.RUnlock()
+		if err != nil {
+			return extendErr("failed to reload storage obligation: ", ErrorInternal(err.Error()))
+		}
+		existingRevision = so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0]
+		err = verifyPaymentRevision(existingRevision, paymentRevision, blockHeight, expectedTransfer)
+		if err != nil {
+			return extendErr("payment verification failed: ", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		modules.WriteNegotiationRejection(conn, err) // Error not reported to preserve type in extendErr
+		return extendErr("prove sector request rejected: ", err)
+	}
+	// The proof was built and the revision is acceptable, write acceptance.
+	err = modules.WriteNegotiationAcceptance(conn)
+	if err != nil {
+		return extendErr("failed to write acceptance for prove sector request: ", ErrorConnection(err.Error()))
+	}
+
+	// The renter will send a transaction signature for the file contract
+	// revision.
+	var renterSignature types.TransactionSignature
+	err = encoding.ReadObject(conn, &renterSignature, modules.NegotiateMaxTransactionSignatureSize)
+	if err != nil {
+		return extendErr("failed to read renter signature: ", ErrorConnection(err.Error()))
+	}
+	txn, err := createRevisionSignature(paymentRevision, renterSignature, secretKey, blockHeight)
+	if err != nil {
+		modules.WriteNegotiationRejection(conn, err)
+		return extendErr("failed to create revision signature: ", err)
+	}
+
+	// Update the storage obligation.
+	paymentTransfer := existingRevision.NewValidProofOutputs[0].Value.Sub(paymentRevision.NewValidProofOutputs[0].Value)
+	so.PotentialDownloadRevenue = so.PotentialDownloadRevenue.Add(paymentTransfer)
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{paymentRevision},
+		TransactionSignatures: []types.TransactionSignature{renterSignature, txn.TransactionSignatures[1]},
+	}}
+	err = h.modifyStorageObligation(so, nil, nil, nil)
+	if err != nil {
+		return extendErr("failed to modify storage obligation: ", ErrorInternal(modules.WriteNegotiationRejection(conn, err).Error()))
+	}
+
+	// Write acceptance, followed by the host signature, the segment, and the
+	// hash set.
+	err = modules.WriteNegotiationAcceptance(conn)
+	if err != nil {
+		return extendErr("failed to write acceptance following obligation modification: ", ErrorConnection(err.Error()))
+	}
+	err = encoding.WriteObject(conn, txn.TransactionSignatures[1])
+	if err != nil {
+		return extendErr("failed to write signature: ", ErrorConnection(err.Error()))
+	}
+	err = encoding.WriteObject(conn, base)
+	if err != nil {
+		return extendErr("failed to write segment: ", ErrorConnection(err.Error()))
+	}
+	err = encoding.WriteObject(conn, hashSet)
+	if err != nil {
+		return extendErr("failed to write hash set: ", ErrorConnection(err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package host
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// newProveSectorObligation adds a storage obligation to the host that belongs
+// to the renter with public key renterPK and holds a single random sector.
+func (ht *hostTester) newProveSectorObligation(renterPK crypto.PublicKey) (storageObligation, crypto.Hash, error) {
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		return storageObligation{}, crypto.Hash{}, err
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	defer ht.host.managedUnlockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		return storageObligation{}, crypto.Hash{}, err
+	}
+
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	// Revisions that pay the host need a void output to move the renter's
+	// missed payout into.
+	validPayouts, missedPayouts := so.payouts()
+	missedPayouts = append(missedPayouts, types.SiacoinOutput{Value: types.ZeroCurrency})
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID: so.id(),
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(renterPK), ht.host.publicKey},
+				SignaturesRequired: 2,
+			},
+			NewRevisionNumber:     1,
+			NewFileSize:           uint64(len(sectorData)),
+			NewFileMerkleRoot:     sectorRoot,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+		}},
+	}}
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	if err != nil {
+		return storageObligation{}, crypto.Hash{}, err
+	}
+	return so, sectorRoot, nil
+}
+
+// requestSectorProof calls RPCProveSector on the host, authenticating with
+// the contract fcid and paying the host the given amount, and returns the
+// segment and hash set sent by the host.
+func (ht *hostTester) requestSectorProof(fcid types.FileContractID, renterSK crypto.SecretKey, root crypto.Hash, segmentIndex uint64, payment types.Currency) ([]byte, []crypto.Hash, error) {
+	renterConn, err := net.Dial("tcp", ht.host.listener.Addr().String())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer renterConn.Close()
+
+	// Perform the recent revision exchange.
+	err = encoding.WriteObject(renterConn, modules.RPCProveSector)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = encoding.WriteObject(renterConn, fcid)
+	if err != nil {
+		return nil, nil, err
+	}
+	var challenge crypto.Hash
+	err = encoding.ReadObject(renterConn, &challenge, uint64(len(challenge)))
+	if err != nil {
+		return nil, nil, err
+	}
+	err = encoding.WriteObject(renterConn, crypto.SignHash(challenge, renterSK))
+	if err != nil {
+		return nil, nil, err
+	}
+	err = modules.ReadNegotiationAcceptance(renterConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	var revision types.FileContractRevision
+	err = encoding.ReadObject(renterConn, &revision, modules.NegotiateMaxFileContractRevisionSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sigs []types.TransactionSignature
+	err = encoding.ReadObject(renterConn, &sigs, 2*modules.NegotiateMaxTransactionSignatureSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Request the proof, and send a revision that pays for it.
+	err = encoding.WriteObject(renterConn, modules.ProveSectorRequest{
+		MerkleRoot:   root,
+		SegmentIndex: segmentIndex,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	rev := revision
+	rev.NewRevisionNumber++
+	rev.NewValidProofOutputs = []types.SiacoinOutput{
+		{Value: revision.NewValidProofOutputs[0].Value.Sub(payment), UnlockHash: revision.NewValidProofOutputs[0].UnlockHash},
+		{Value: revision.NewValidProofOutputs[1].Value.Add(payment), UnlockHash: revision.NewValidProofOutputs[1].UnlockHash},
+	}
+	rev.NewMissedProofOutputs = []types.SiacoinOutput{
+		{Value: revision.NewMissedProofOutputs[0].Value.Sub(payment), UnlockHash: revision.NewMissedProofOutputs[0].UnlockHash},
+		revision.NewMissedProofOutputs[1],
+		{Value: revision.NewMissedProofOutputs[2].Value.Add(payment), UnlockHash: revision.NewMissedProofOutputs[2].UnlockHash},
+	}
+	err = encoding.WriteObject(renterConn, rev)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = modules.ReadNegotiationAcceptance(renterConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	txn := types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{rev},
+		TransactionSignatures: []types.TransactionSignature{{
+			ParentID:       crypto.Hash(fcid),
+			PublicKeyIndex: 0,
+			CoveredFields:  types.CoveredFields{FileContractRevisions: []uint64{0}},
+		}},
+	}
+	sig := crypto.SignHash(txn.SigHash(0), renterSK)
+	txn.TransactionSignatures[0].Signature = sig[:]
+	err = encoding.WriteObject(renterConn, txn.TransactionSignatures[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	err = modules.ReadNegotiationAcceptance(renterConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	var hostSig types.TransactionSignature
+	err = encoding.ReadObject(renterConn, &hostSig, modules.NegotiateMaxTransactionSignatureSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	var base []byte
+	var hashSet []crypto.Hash
+	err = encoding.ReadObject(renterConn, &base, crypto.SegmentSize+8)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = encoding.ReadObject(renterConn, &hashSet, 1e3)
+	if err != nil {
+		return nil, nil, err
+	}
+	return base, hashSet, nil
+}
+
+// TestRPCProveSector checks that the host returns a valid proof for a sector
+// in the renter's contract, and an error for other sectors and for renters
+// that cannot prove ownership of the contract.
+func TestRPCProveSector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	renterSK, renterPK := crypto.GenerateKeyPair()
+	so, root, err := ht.newProveSectorObligation(renterPK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	price := ht.host.InternalSettings().MinDownloadBandwidthPrice.Mul64(modules.ProveSectorResponseSize())
+	if price.IsZero() {
+		t.Fatal("test requires a nonzero download price")
+	}
+
+	// Request proofs for the first, last, and a random segment of the sector.
+	numSegments := modules.SectorSize / crypto.SegmentSize
+	for _, segmentIndex := range []uint64{0, numSegments - 1, uint64(fastrand.Intn(int(numSegments)))} {
+		base, hashSet, err := ht.requestSectorProof(so.id(), renterSK, root, segmentIndex, price)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !crypto.VerifySegment(base, hashSet, numSegments, segmentIndex, root) {
+			t.Error("host returned an invalid proof for segment", segmentIndex)
+		}
+	}
+
+	// The host should have been paid for each proof.
+	var updated storageObligation
+	ht.host.mu.RLock()
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		updated, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	ht.host.mu.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.PotentialDownloadRevenue.Equals(price.Mul64(3)) {
+		t.Errorf("expected download revenue of %v, got %v", price.Mul64(3), updated.PotentialDownloadRevenue)
+	}
+
+	// Wait for the rate limit to allow more proofs for the contract.
+	ht.host.mu.Lock()
+	ht.host.recentProofs = nil
+	ht.host.mu.Unlock()
+
+	// A request that does not pay enough should be rejected.
+	_, _, err = ht.requestSectorProof(so.id(), renterSK, root, 0, price.Sub(types.NewCurrency64(1)))
+	if err == nil {
+		t.Error("expected an error when underpaying for a proof")
+	}
+
+	// A proof for a segment outside of the sector should be rejected.
+	_, _, err = ht.requestSectorProof(so.id(), renterSK, root, numSegments, price)
+	if err == nil {
+		t.Error("expected an error when requesting an out-of-bounds segment")
+	}
+
+	// A proof for a sector that is not in the contract should be rejected,
+	// even if the host is storing the sector.
+	otherRoot, otherData := randSector()
+	err = ht.host.AddSector(otherRoot, otherData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = ht.requestSectorProof(so.id(), renterSK, otherRoot, 0, price)
+	if err == nil {
+		t.Error("expected an error when requesting a proof for a sector outside of the contract")
+	}
+
+	// A renter without the contract's key should be rejected.
+	otherSK, _ := crypto.GenerateKeyPair()
+	_, _, err = ht.requestSectorProof(so.id(), otherSK, root, 0, price)
+	if err == nil {
+		t.Error("expected an error when requesting a proof without the contract's key")
+	}
+}
+
+// TestProveSectorRateLimit checks that a contract requesting more sector
+// proofs than the host allows is throttled, while other contracts are not.
+func TestProveSectorRateLimit(t *testing.T) {
+	h := new(Host)
+	spammer := types.FileContractID{1}
+	other := types.FileContractID{2}
+	start := time.Now()
+
+	for i := 0; i < proveSectorsPerMinute; i++ {
+		if !h.managedProveSectorAllowed(spammer, start) {
+			t.Fatal("request under the limit was throttled")
+		}
+	}
+	if h.managedProveSectorAllowed(spammer, start) {
+		t.Fatal("request over the limit was allowed")
+	}
+	if !h.managedProveSectorAllowed(other, start) {
+		t.Fatal("request for another contract was throttled")
+	}
+
+	// Once the requests are a minute old, they are forgotten.
+	if !h.managedProveSectorAllowed(spammer, start.Add(time.Minute)) {
+		t.Fatal("request was throttled after the window passed")
+	}
+	if len(h.recentProofs) != 1 {
+		t.Fatal("stale contracts were not pruned:", len(h.recentProofs))
+	}
+}
@@ -223,14 +223,21 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 
 	h.mu.RLock()
 	blockHeight := h.blockHeight
+	collateralFunc := h.collateralFunc
 	externalSettings := h.externalSettings()
 	internalSettings := h.settings
 	lockedStorageCollateral := h.financialMetrics.LockedStorageCollateral
 	publicKey := h.publicKey
+	renterAllowed := h.renterAllowed(types.Ed25519PublicKey(renterPK))
 	unlockHash := h.unlockHash
 	h.mu.RUnlock()
 	fc := txnSet[len(txnSet)-1].FileContracts[0]
 
+	// If the host has a renter allowlist, the renter must be on it.
+	if !renterAllowed {
+		return errRenterNotAllowed
+	}
+
 	// The file size and merkle root must match the file size and merkle root
 	// from the previous file contract.
 	if fc.FileSize != so.fileSize() {
@@ -262,10 +269,16 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 	}
 
 	// Check that the collateral does not exceed the maximum amount of
-	// collateral allowed.
+	// collateral allowed for this contract.
+	// The renter caps only the collateral for new storage, so the base
+	// collateral for the existing data is excluded from the reported limit.
 	expectedCollateral := renewContractCollateral(so, externalSettings, fc)
-	if expectedCollateral.Cmp(externalSettings.MaxCollateral) > 0 {
-		return errMaxCollateralReached
+	if limit := collateralLimit(collateralFunc, externalSettings.MaxCollateral, fc, blockHeight); expectedCollateral.Cmp(limit) > 0 {
+		baseCollateral := renewBaseCollateral(so, externalSettings, fc)
+		if limit.Cmp(baseCollateral) > 0 {
+			return errMaxCollateralReached(limit.Sub(baseCollateral))
+		}
+		return errMaxCollateralReached(types.ZeroCurrency)
 	}
 	// Check that the host has enough room in the collateral budget to add this
 	// collateral.
@@ -62,10 +62,14 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	var bandwidthRevenue types.Currency // Upload bandwidth.
 	var storageRevenue types.Currency
 	var newCollateral types.Currency
+	var bytesUploaded uint64
 	var sectorsRemoved []crypto.Hash
 	var sectorsGained []crypto.Hash
 	var gainedSectorData [][]byte
 	err = func() error {
+		if !h.managedRevisionAllowed(so.id(), time.Now()) {
+			return errRevisionRateLimited
+		}
 		for _, modification := range modifications {
 			// Check that the index points to an existing sector root. If the type
 			// is ActionInsert, we permit inserting at the end.
@@ -94,6 +98,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				}
 
 				// Update finances.
+				bytesUploaded += modules.SectorSize
 				blocksRemaining := so.proofDeadline() - blockHeight
 				blockBytesCurrency := types.NewCurrency64(uint64(blocksRemaining)).Mul64(modules.SectorSize)
 				bandwidthRevenue = bandwidthRevenue.Add(settings.MinUploadBandwidthPrice.Mul64(modules.SectorSize))
@@ -122,6 +127,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				copy(sector[modification.Offset:], modification.Data)
 
 				// Update finances.
+				bytesUploaded += uint64(len(modification.Data))
 				bandwidthRevenue = bandwidthRevenue.Add(settings.MinUploadBandwidthPrice.Mul64(uint64(len(modification.Data))))
 
 				// Update the sectors removed and gained to indicate that the old
@@ -164,6 +170,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	so.PotentialStorageRevenue = so.PotentialStorageRevenue.Add(storageRevenue)
 	so.RiskedCollateral = so.RiskedCollateral.Add(newCollateral)
 	so.PotentialUploadRevenue = so.PotentialUploadRevenue.Add(bandwidthRevenue)
+	so.BytesUploaded += bytesUploaded
 	so.RevisionTransactionSet = []types.Transaction{txn}
 	h.mu.Lock()
 	err = h.modifyStorageObligation(*so, sectorsRemoved, sectorsGained, gainedSectorData)
@@ -290,6 +290,9 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 	case modules.RPCReviseContract:
 		atomic.AddUint64(&h.atomicReviseCalls, 1)
 		err = extendErr("incoming RPCReviseContract failed: ", h.managedRPCReviseContract(conn))
+	case modules.RPCProveSector:
+		atomic.AddUint64(&h.atomicProveSectorCalls, 1)
+		err = extendErr("incoming RPCProveSector failed: ", h.managedRPCProveSector(conn))
 	case modules.RPCRecentRevision:
 		atomic.AddUint64(&h.atomicRecentRevisionCalls, 1)
 		var so storageObligation
@@ -359,6 +362,7 @@ func (h *Host) NetworkMetrics() modules.HostNetworkMetrics {
 		DownloadCalls:     atomic.LoadUint64(&h.atomicDownloadCalls),
 		ErrorCalls:        atomic.LoadUint64(&h.atomicErroredCalls),
 		FormContractCalls: atomic.LoadUint64(&h.atomicFormContractCalls),
+		ProveSectorCalls:  atomic.LoadUint64(&h.atomicProveSectorCalls),
 		RenewCalls:        atomic.LoadUint64(&h.atomicRenewCalls),
 		ReviseCalls:       atomic.LoadUint64(&h.atomicReviseCalls),
 		SettingsCalls:     atomic.LoadUint64(&h.atomicSettingsCalls),
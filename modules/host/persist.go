@@ -21,14 +21,18 @@ type persistence struct {
 	RecentChange modules.ConsensusChangeID `json:"recentchange"`
 
 	// Host Identity.
-	Announced        bool                         `json:"announced"`
-	AutoAddress      modules.NetAddress           `json:"autoaddress"`
-	FinancialMetrics modules.HostFinancialMetrics `json:"financialmetrics"`
-	PublicKey        types.SiaPublicKey           `json:"publickey"`
-	RevisionNumber   uint64                       `json:"revisionnumber"`
-	SecretKey        crypto.SecretKey             `json:"secretkey"`
-	Settings         modules.HostInternalSettings `json:"settings"`
-	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+	Announced               bool                         `json:"announced"`
+	AutoAddress             modules.NetAddress           `json:"autoaddress"`
+	FinancialMetrics        modules.HostFinancialMetrics `json:"financialmetrics"`
+	MaxRevisionsPerMinute   int                          `json:"maxrevisionsperminute"`
+	MinRenterCompletionRate float64                      `json:"minrentercompletionrate"`
+	PublicKey               types.SiaPublicKey           `json:"publickey"`
+	RenterAllowlist         []types.SiaPublicKey         `json:"renterallowlist"`
+	RenterHistory           map[string]renterHistory     `json:"renterhistory"`
+	RevisionNumber          uint64                       `json:"revisionnumber"`
+	SecretKey               crypto.SecretKey             `json:"secretkey"`
+	Settings                modules.HostInternalSettings `json:"settings"`
+	UnlockHash              types.UnlockHash             `json:"unlockhash"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -39,14 +43,18 @@ func (h *Host) persistData() persistence {
 		RecentChange: h.recentChange,
 
 		// Host Identity.
-		Announced:        h.announced,
-		AutoAddress:      h.autoAddress,
-		FinancialMetrics: h.financialMetrics,
-		PublicKey:        h.publicKey,
-		RevisionNumber:   h.revisionNumber,
-		SecretKey:        h.secretKey,
-		Settings:         h.settings,
-		UnlockHash:       h.unlockHash,
+		Announced:               h.announced,
+		AutoAddress:             h.autoAddress,
+		FinancialMetrics:        h.financialMetrics,
+		MaxRevisionsPerMinute:   h.maxRevisionsPerMinute,
+		MinRenterCompletionRate: h.minRenterCompletionRate,
+		PublicKey:               h.publicKey,
+		RenterAllowlist:         h.renterAllowlistSlice(),
+		RenterHistory:           h.renterHistory,
+		RevisionNumber:          h.revisionNumber,
+		SecretKey:               h.secretKey,
+		Settings:                h.settings,
+		UnlockHash:              h.unlockHash,
 	}
 }
 
@@ -98,7 +106,11 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.autoAddress = ""
 	}
 	h.financialMetrics = p.FinancialMetrics
+	h.maxRevisionsPerMinute = p.MaxRevisionsPerMinute
+	h.minRenterCompletionRate = p.MinRenterCompletionRate
 	h.publicKey = p.PublicKey
+	h.setRenterAllowlist(p.RenterAllowlist)
+	h.renterHistory = p.RenterHistory
 	h.revisionNumber = p.RevisionNumber
 	h.secretKey = p.SecretKey
 	h.settings = p.Settings
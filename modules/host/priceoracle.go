@@ -0,0 +1,99 @@
+package host
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// errInvalidOracleInterval is returned by SetPriceOracle if the polling
+// interval is not positive.
+var errInvalidOracleInterval = errors.New("price oracle interval must be positive")
+
+// SetPriceOracle causes the host to call oracle every interval and replace its
+// internal settings with the result. Settings that fail validation are
+// ignored. If the host has already announced itself and the oracle changes its
+// prices or net address, the host re-announces so that renters rescan it and
+// learn the new settings. Calling SetPriceOracle replaces any previously set
+// oracle, and a nil oracle stops polling.
+func (h *Host) SetPriceOracle(oracle func() (modules.HostInternalSettings, error), interval time.Duration) error {
+	if oracle != nil && interval <= 0 {
+		return errInvalidOracleInterval
+	}
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.priceOracleStop != nil {
+		close(h.priceOracleStop)
+		h.priceOracleStop = nil
+	}
+	if oracle != nil {
+		h.priceOracleStop = make(chan struct{})
+		go h.threadedPollPriceOracle(oracle, interval, h.priceOracleStop)
+	}
+	return nil
+}
+
+// threadedPollPriceOracle applies the settings returned by oracle every
+// interval until stop is closed or the host shuts down.
+func (h *Host) threadedPollPriceOracle(oracle func() (modules.HostInternalSettings, error), interval time.Duration, stop <-chan struct{}) {
+	for {
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			return
+		case <-h.tg.StopChan():
+			return
+		}
+		if h.tg.Add() != nil {
+			return
+		}
+		h.managedApplyPriceOracle(oracle)
+		h.tg.Done()
+	}
+}
+
+// managedApplyPriceOracle fetches settings from oracle and applies them,
+// re-announcing the host if its prices or net address changed.
+func (h *Host) managedApplyPriceOracle(oracle func() (modules.HostInternalSettings, error)) {
+	settings, err := oracle()
+	if err != nil {
+		h.log.Println("WARN: price oracle failed:", err)
+		return
+	}
+	if err := h.ValidateSettings(settings); err != nil {
+		h.log.Println("WARN: price oracle returned invalid settings:", err)
+		return
+	}
+
+	h.mu.RLock()
+	announced := h.announced
+	changed := pricesChanged(h.settings, settings) || h.settings.NetAddress != settings.NetAddress
+	h.mu.RUnlock()
+	if err := h.SetInternalSettings(settings); err != nil {
+		h.log.Println("WARN: could not apply settings from price oracle:", err)
+		return
+	}
+	if announced && changed {
+		if err := h.Announce(); err != nil {
+			h.log.Println("WARN: could not re-announce after price oracle changed settings:", err)
+		}
+	}
+}
+
+// pricesChanged returns true if any of the prices or collateral settings
+// differ between old and updated.
+func pricesChanged(old, updated modules.HostInternalSettings) bool {
+	return !old.Collateral.Equals(updated.Collateral) ||
+		!old.MaxCollateral.Equals(updated.MaxCollateral) ||
+		!old.MinContractPrice.Equals(updated.MinContractPrice) ||
+		!old.MinDownloadBandwidthPrice.Equals(updated.MinDownloadBandwidthPrice) ||
+		!old.MinStoragePrice.Equals(updated.MinStoragePrice) ||
+		!old.MinUploadBandwidthPrice.Equals(updated.MinUploadBandwidthPrice)
+}
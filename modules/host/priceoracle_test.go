@@ -0,0 +1,77 @@
+package host
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPriceOracle checks that the price oracle is polled at the requested
+// interval, that its settings are applied to the host, and that the host
+// re-announces when its prices change.
+func TestPriceOracle(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Announce the host so that price changes trigger a re-announcement.
+	if err := ht.host.Announce(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ht.host.SetPriceOracle(func() (modules.HostInternalSettings, error) {
+		return modules.HostInternalSettings{}, nil
+	}, 0); err != errInvalidOracleInterval {
+		t.Fatal("expected errInvalidOracleInterval, got", err)
+	}
+
+	var calls uint64
+	settings := ht.host.InternalSettings()
+	settings.MinStoragePrice = settings.MinStoragePrice.Add(types.SiacoinPrecision)
+	oracle := func() (modules.HostInternalSettings, error) {
+		atomic.AddUint64(&calls, 1)
+		return settings, nil
+	}
+	const interval = 50 * time.Millisecond
+	if err := ht.host.SetPriceOracle(oracle, interval); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oracle should not be polled before the first interval elapses.
+	if atomic.LoadUint64(&calls) != 0 {
+		t.Fatal("oracle was polled immediately")
+	}
+	time.Sleep(interval * 5)
+	if n := atomic.LoadUint64(&calls); n < 2 || n > 5 {
+		t.Fatal("expected oracle to be polled 2-5 times, got", n)
+	}
+	if !ht.host.ExternalSettings().StoragePrice.Equals(settings.MinStoragePrice) {
+		t.Fatal("oracle settings were not applied")
+	}
+	if len(ht.tpool.TransactionList()) == 0 {
+		t.Fatal("host did not re-announce after the oracle changed its prices")
+	}
+
+	// Removing the oracle should stop polling.
+	if err := ht.host.SetPriceOracle(nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(interval)
+	n := atomic.LoadUint64(&calls)
+	time.Sleep(interval * 3)
+	if atomic.LoadUint64(&calls) != n {
+		t.Fatal("oracle was polled after being removed")
+	}
+}
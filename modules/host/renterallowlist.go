@@ -0,0 +1,65 @@
+package host
+
+import (
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// renterAllowed reports whether the renter with public key pk may form or
+// renew contracts with the host. Every renter is allowed if the host does not
+// have an allowlist.
+//
+// pk is the renter key that appears in the contract's unlock conditions. The
+// siad renter signs all of its new contracts with the same key, which it
+// reports as its public key.
+func (h *Host) renterAllowed(pk types.SiaPublicKey) bool {
+	if len(h.renterAllowlist) == 0 {
+		return true
+	}
+	_, exists := h.renterAllowlist[pk.String()]
+	return exists
+}
+
+// renterAllowlistSlice returns the host's renter allowlist as a slice.
+func (h *Host) renterAllowlistSlice() []types.SiaPublicKey {
+	pks := make([]types.SiaPublicKey, 0, len(h.renterAllowlist))
+	for _, pk := range h.renterAllowlist {
+		pks = append(pks, pk)
+	}
+	return pks
+}
+
+// setRenterAllowlist replaces the host's renter allowlist with pks.
+func (h *Host) setRenterAllowlist(pks []types.SiaPublicKey) {
+	h.renterAllowlist = make(map[string]types.SiaPublicKey, len(pks))
+	for _, pk := range pks {
+		h.renterAllowlist[pk.String()] = pk
+	}
+}
+
+// RenterAllowlist returns the public keys of the renters that are allowed to
+// form contracts with the host. An empty list means that the host is public.
+func (h *Host) RenterAllowlist() []types.SiaPublicKey {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.renterAllowlistSlice()
+}
+
+// SetRenterAllowlist restricts contract formation and renewal to the renters
+// whose public keys are in pks, so that a private host can serve only renters
+// that it knows. An empty list allows any renter to form contracts. Existing
+// contracts are not affected.
+//
+// The keys are matched against the renter key of each contract. A siad
+// renter's key is reported by its GET /renter endpoint.
+func (h *Host) SetRenterAllowlist(pks []types.SiaPublicKey) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.setRenterAllowlist(pks)
+	return h.saveSync()
+}
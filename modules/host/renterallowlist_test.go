@@ -0,0 +1,104 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// newTestContractSet returns a transaction set containing a new file contract
+// that the host will accept from the renter with public key renterPK.
+func (ht *hostTester) newTestContractSet(renterPK crypto.PublicKey) []types.Transaction {
+	ht.host.mu.RLock()
+	blockHeight := ht.host.blockHeight
+	hostPK := ht.host.publicKey
+	settings := ht.host.settings
+	unlockHash := ht.host.unlockHash
+	ht.host.mu.RUnlock()
+
+	windowStart := blockHeight + revisionSubmissionBuffer + 1
+	fc := types.FileContract{
+		WindowStart: windowStart,
+		WindowEnd:   windowStart + settings.WindowSize,
+		ValidProofOutputs: []types.SiacoinOutput{
+			{Value: types.SiacoinPrecision},
+			{Value: settings.MinContractPrice, UnlockHash: unlockHash},
+		},
+		MissedProofOutputs: []types.SiacoinOutput{
+			{Value: types.SiacoinPrecision},
+			{Value: settings.MinContractPrice, UnlockHash: unlockHash},
+			{},
+		},
+		UnlockHash: types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(renterPK), hostPK},
+			SignaturesRequired: 2,
+		}.UnlockHash(),
+	}
+	return []types.Transaction{{
+		FileContracts: []types.FileContract{fc},
+		MinerFees:     []types.Currency{types.SiacoinPrecision},
+	}}
+}
+
+// TestRenterAllowlist checks that a host with a renter allowlist only accepts
+// contracts from the renters on the list, and that the list is persisted.
+func TestRenterAllowlist(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	_, allowedPK := crypto.GenerateKeyPair()
+	_, otherPK := crypto.GenerateKeyPair()
+
+	// Without an allowlist, any renter can form a contract.
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(otherPK), otherPK); err != nil {
+		t.Fatal("public host rejected contract:", err)
+	}
+
+	// With an allowlist, only the listed renter can form a contract.
+	err = ht.host.SetRenterAllowlist([]types.SiaPublicKey{types.Ed25519PublicKey(allowedPK)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(allowedPK), allowedPK); err != nil {
+		t.Fatal("host rejected contract from allowlisted renter:", err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(otherPK), otherPK); err != errRenterNotAllowed {
+		t.Fatal("expected errRenterNotAllowed, got", err)
+	}
+
+	// The allowlist should persist across restarts.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowedSPK := types.Ed25519PublicKey(allowedPK)
+	if allowlist := ht.host.RenterAllowlist(); len(allowlist) != 1 || allowlist[0].String() != allowedSPK.String() {
+		t.Fatal("allowlist was not persisted:", allowlist)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(otherPK), otherPK); err != errRenterNotAllowed {
+		t.Fatal("expected errRenterNotAllowed after restart, got", err)
+	}
+
+	// Clearing the allowlist makes the host public again.
+	err = ht.host.SetRenterAllowlist(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(otherPK), otherPK); err != nil {
+		t.Fatal("public host rejected contract:", err)
+	}
+}
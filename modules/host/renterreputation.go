@@ -0,0 +1,92 @@
+package host
+
+import (
+	"errors"
+	"math"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errInvalidCompletionRate is returned by SetMinRenterCompletionRate if
+	// the rate is not between 0 and 1.
+	errInvalidCompletionRate = errors.New("minimum renter completion rate must be between 0 and 1")
+
+	// errRenterUnreliable is returned if a renter tries to form a contract
+	// with the host, but has abandoned too many of its previous contracts.
+	errRenterUnreliable = ErrorCommunication("rejected because the renter has abandoned too many contracts with the host")
+)
+
+// renterHistory records how the contracts that a renter formed with the host
+// have ended. A contract is abandoned if its origin transaction never
+// confirmed, or if the renter never stored any data in it. Renters are keyed
+// by the public key they sign contracts with, which siad renters keep for
+// all of their contracts.
+type renterHistory struct {
+	Completed uint64 `json:"completed"`
+	Abandoned uint64 `json:"abandoned"`
+}
+
+// completionRate returns the fraction of the renter's resolved contracts that
+// were completed. A renter with no resolved contracts has a rate of 1.
+func (rh renterHistory) completionRate() float64 {
+	total := rh.Completed + rh.Abandoned
+	if total == 0 {
+		return 1
+	}
+	return float64(rh.Completed) / float64(total)
+}
+
+// recordRenterOutcome updates the history of the renter of so, which has just
+// been resolved with status sos. Obligations that failed despite containing
+// data are not counted, since the failure was the host's.
+func (h *Host) recordRenterOutcome(so storageObligation, sos storageObligationStatus) {
+	pk := so.renterKey()
+	if len(pk.Key) == 0 {
+		return
+	}
+	rh := h.renterHistory[pk.String()]
+	switch {
+	case sos == obligationSucceeded:
+		rh.Completed++
+	case sos == obligationRejected, sos == obligationFailed && len(so.SectorRoots) == 0:
+		rh.Abandoned++
+	default:
+		return
+	}
+	if h.renterHistory == nil {
+		h.renterHistory = make(map[string]renterHistory)
+	}
+	h.renterHistory[pk.String()] = rh
+}
+
+// renterReliable reports whether the renter with public key pk meets the
+// host's minimum completion rate. Renters with fewer than
+// renterHistoryMinContracts resolved contracts are always considered
+// reliable, so that new renters can form contracts.
+func (h *Host) renterReliable(pk types.SiaPublicKey) bool {
+	rh := h.renterHistory[pk.String()]
+	if rh.Completed+rh.Abandoned < renterHistoryMinContracts {
+		return true
+	}
+	return rh.completionRate() >= h.minRenterCompletionRate
+}
+
+// SetMinRenterCompletionRate sets the fraction of its previous contracts with
+// the host that a renter must have completed in order to form a new
+// contract. A ratio of 0 accepts every renter.
+func (h *Host) SetMinRenterCompletionRate(ratio float64) error {
+	if math.IsNaN(ratio) || ratio < 0 || ratio > 1 {
+		return errInvalidCompletionRate
+	}
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.minRenterCompletionRate = ratio
+	return h.saveSync()
+}
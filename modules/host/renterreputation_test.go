@@ -0,0 +1,105 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestRenterCompletionRate checks that a host with a minimum renter
+// completion rate rejects contracts from renters that have abandoned too
+// many contracts, and that the renter history is persisted.
+func TestRenterCompletionRate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	_, goodPK := crypto.GenerateKeyPair()
+	_, badPK := crypto.GenerateKeyPair()
+	_, newPK := crypto.GenerateKeyPair()
+
+	// resolve records the resolution of an obligation formed by the renter
+	// with public key pk.
+	resolve := func(pk crypto.PublicKey, sos storageObligationStatus, sectors int) {
+		so := storageObligation{
+			SectorRoots: make([]crypto.Hash, sectors),
+			RevisionTransactionSet: []types.Transaction{{
+				FileContractRevisions: []types.FileContractRevision{{
+					UnlockConditions: types.UnlockConditions{
+						PublicKeys: []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+					},
+				}},
+			}},
+		}
+		ht.host.mu.Lock()
+		ht.host.recordRenterOutcome(so, sos)
+		ht.host.mu.Unlock()
+	}
+	for i := 0; i < 4; i++ {
+		resolve(goodPK, obligationSucceeded, 1)
+		// A failure with data is the host's fault, and is not counted.
+		resolve(goodPK, obligationFailed, 1)
+	}
+	resolve(badPK, obligationSucceeded, 1)
+	resolve(badPK, obligationRejected, 0)
+	resolve(badPK, obligationFailed, 0)
+	resolve(badPK, obligationRejected, 0)
+	goodSPK, badSPK := types.Ed25519PublicKey(goodPK), types.Ed25519PublicKey(badPK)
+	ht.host.mu.RLock()
+	goodHistory := ht.host.renterHistory[goodSPK.String()]
+	badHistory := ht.host.renterHistory[badSPK.String()]
+	ht.host.mu.RUnlock()
+	if goodHistory != (renterHistory{Completed: 4}) {
+		t.Fatalf("wrong history for good renter: %+v", goodHistory)
+	} else if badHistory != (renterHistory{Completed: 1, Abandoned: 3}) {
+		t.Fatalf("wrong history for bad renter: %+v", badHistory)
+	}
+
+	// Without a minimum, any renter can form a contract.
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(badPK), badPK); err != nil {
+		t.Fatal("host rejected contract without a minimum completion rate:", err)
+	}
+
+	// With a minimum, the bad renter is rejected, while the good renter and
+	// renters without a history are accepted.
+	if err := ht.host.SetMinRenterCompletionRate(0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(badPK), badPK); err != errRenterUnreliable {
+		t.Fatal("expected errRenterUnreliable, got", err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(goodPK), goodPK); err != nil {
+		t.Fatal("host rejected contract from reliable renter:", err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(newPK), newPK); err != nil {
+		t.Fatal("host rejected contract from new renter:", err)
+	}
+
+	// The history and the minimum should persist across restarts.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.managedVerifyNewContract(ht.newTestContractSet(badPK), badPK); err != errRenterUnreliable {
+		t.Fatal("expected errRenterUnreliable after restart, got", err)
+	}
+
+	for _, ratio := range []float64{-0.1, 1.1} {
+		if err := ht.host.SetMinRenterCompletionRate(ratio); err != errInvalidCompletionRate {
+			t.Errorf("expected errInvalidCompletionRate for %v, got %v", ratio, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package host
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errInvalidRevisionRate is returned by SetMaxRevisionsPerMinute if the
+	// rate is negative.
+	errInvalidRevisionRate = errors.New("max revisions per minute cannot be negative")
+
+	// errRevisionRateLimited is returned if a renter revises a contract more
+	// often than the host allows.
+	errRevisionRateLimited = ErrorCommunication("rejected for exceeding the revision rate limit of the host")
+)
+
+// managedRevisionAllowed reports whether the contract with the given id may be
+// revised at time now, and if so, records the revision. Revisions are allowed
+// if fewer than maxRevisionsPerMinute revisions of the contract were made in
+// the minute before now.
+func (h *Host) managedRevisionAllowed(id types.FileContractID, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.maxRevisionsPerMinute == 0 {
+		return true
+	}
+	if h.recentRevisions == nil {
+		h.recentRevisions = make(map[types.FileContractID][]time.Time)
+	}
+
+	// Once a minute, remove the contracts without any recent revisions so
+	// that the map does not grow with every contract that has ever been
+	// revised.
+	if now.Sub(h.recentRevisionsPruned) >= time.Minute {
+		for fcid, times := range h.recentRevisions {
+			if len(times) == 0 || now.Sub(times[len(times)-1]) >= time.Minute {
+				delete(h.recentRevisions, fcid)
+			}
+		}
+		h.recentRevisionsPruned = now
+	}
+
+	// Forget the revisions that are more than a minute old.
+	times := h.recentRevisions[id]
+	for len(times) > 0 && now.Sub(times[0]) >= time.Minute {
+		times = times[1:]
+	}
+	if len(times) >= h.maxRevisionsPerMinute {
+		h.recentRevisions[id] = times
+		return false
+	}
+	h.recentRevisions[id] = append(times, now)
+	return true
+}
+
+// SetMaxRevisionsPerMinute limits the number of revisions that each contract
+// may make per minute. Revisions beyond the limit are rejected, and the renter
+// may try again later. A value of 0 removes the limit.
+func (h *Host) SetMaxRevisionsPerMinute(n int) error {
+	if n < 0 {
+		return errInvalidRevisionRate
+	}
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.maxRevisionsPerMinute = n
+	h.recentRevisions = nil
+	return h.saveSync()
+}
@@ -0,0 +1,93 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestRevisionRateLimit checks that a contract revised more often than the
+// host allows is throttled, while contracts that stay under the limit are not.
+func TestRevisionRateLimit(t *testing.T) {
+	h := &Host{maxRevisionsPerMinute: 3}
+	spammer, other := types.FileContractID{1}, types.FileContractID{2}
+	start := time.Now()
+
+	// Three revisions within a minute are allowed; the fourth is not.
+	for i := 0; i < 3; i++ {
+		if !h.managedRevisionAllowed(spammer, start.Add(time.Duration(i)*time.Second)) {
+			t.Fatal("revision under the limit was throttled")
+		}
+	}
+	if h.managedRevisionAllowed(spammer, start.Add(10*time.Second)) {
+		t.Fatal("revision over the limit was allowed")
+	}
+
+	// Other contracts are unaffected.
+	if !h.managedRevisionAllowed(other, start.Add(10*time.Second)) {
+		t.Fatal("revision of another contract was throttled")
+	}
+
+	// Once the first revision is a minute old, another is allowed.
+	if !h.managedRevisionAllowed(spammer, start.Add(time.Minute)) {
+		t.Fatal("revision was throttled after the window passed")
+	}
+	if h.managedRevisionAllowed(spammer, start.Add(time.Minute)) {
+		t.Fatal("revision over the limit was allowed")
+	}
+
+	// Contracts that have not been revised for a minute are forgotten.
+	if !h.managedRevisionAllowed(spammer, start.Add(2*time.Minute)) {
+		t.Fatal("revision was throttled after the window passed")
+	}
+	if _, ok := h.recentRevisions[other]; ok {
+		t.Fatal("contract without recent revisions was not pruned")
+	} else if len(h.recentRevisions[spammer]) != 1 {
+		t.Fatal("expected 1 recent revision, got", len(h.recentRevisions[spammer]))
+	}
+
+	// Removing the limit allows any number of revisions.
+	h.maxRevisionsPerMinute = 0
+	for i := 0; i < 10; i++ {
+		if !h.managedRevisionAllowed(spammer, start.Add(time.Minute)) {
+			t.Fatal("revision was throttled without a limit")
+		}
+	}
+}
+
+// TestSetMaxRevisionsPerMinute checks that the revision rate limit is
+// validated and persisted.
+func TestSetMaxRevisionsPerMinute(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	if err := ht.host.SetMaxRevisionsPerMinute(-1); err != errInvalidRevisionRate {
+		t.Fatal("expected errInvalidRevisionRate, got", err)
+	}
+	if err := ht.host.SetMaxRevisionsPerMinute(5); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload the host and check that the limit was persisted.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ht.host.maxRevisionsPerMinute != 5 {
+		t.Fatal("revision rate limit was not persisted:", ht.host.maxRevisionsPerMinute)
+	}
+}
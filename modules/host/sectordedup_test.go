@@ -0,0 +1,94 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestSectorDeduplication checks that a sector stored by two storage
+// obligations occupies a single sector on disk, and that the disk space is
+// only freed once both obligations have removed the sector.
+func TestSectorDeduplication(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// usedSectors returns the number of sectors occupied on disk.
+	usedSectors := func() uint64 {
+		var used uint64
+		for _, sf := range ht.host.StorageFolders() {
+			used += sf.Capacity - sf.CapacityRemaining
+		}
+		return used / modules.SectorSize
+	}
+
+	// Create two storage obligations.
+	var sos []storageObligation
+	for i := 0; i < 2; i++ {
+		so, err := ht.newTesterStorageObligation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ht.host.managedLockStorageObligation(so.id())
+		err = ht.host.managedAddStorageObligation(so)
+		ht.host.managedUnlockStorageObligation(so.id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sos = append(sos, so)
+	}
+
+	// modify updates the sector roots of an obligation, adding or removing
+	// the sector.
+	root, data := randSector()
+	modify := func(so *storageObligation, add bool) {
+		ht.host.managedLockStorageObligation(so.id())
+		defer ht.host.managedUnlockStorageObligation(so.id())
+		var err error
+		if add {
+			so.SectorRoots = []crypto.Hash{root}
+			err = ht.host.modifyStorageObligation(*so, nil, []crypto.Hash{root}, [][]byte{data})
+		} else {
+			so.SectorRoots = nil
+			err = ht.host.modifyStorageObligation(*so, []crypto.Hash{root}, nil, nil)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Store the same sector in both obligations. Only one sector of disk
+	// space should be used.
+	modify(&sos[0], true)
+	modify(&sos[1], true)
+	if used := usedSectors(); used != 1 {
+		t.Fatal("expected the sector to be stored once, but it occupies", used, "sectors")
+	}
+
+	// Removing the sector from one obligation should not free the disk space,
+	// as the other obligation still references it.
+	modify(&sos[0], false)
+	if used := usedSectors(); used != 1 {
+		t.Fatal("expected 1 sector to be used, got", used)
+	}
+	if _, err := ht.host.ReadSector(root); err != nil {
+		t.Fatal("sector referenced by an obligation could not be read:", err)
+	}
+
+	// Once the last reference is removed, the space is freed.
+	modify(&sos[1], false)
+	if used := usedSectors(); used != 0 {
+		t.Fatal("expected no sectors to be used, got", used)
+	}
+	if _, err := ht.host.ReadSector(root); err == nil {
+		t.Fatal("sector was still readable after every reference was removed")
+	}
+}
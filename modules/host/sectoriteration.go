@@ -0,0 +1,102 @@
+package host
+
+import (
+	"encoding/json"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules/host/contractmanager"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// storedSectorRoots returns the roots of the sectors referenced by the host's
+// unresolved storage obligations. Each root appears once, in the order in
+// which it was first encountered.
+func (h *Host) storedSectorRoots() (roots []crypto.Hash, err error) {
+	seen := make(map[crypto.Hash]struct{})
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation:", err)
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				// The sectors of resolved obligations have been removed.
+				return nil
+			}
+			for _, root := range so.SectorRoots {
+				if _, ok := seen[root]; !ok {
+					seen[root] = struct{}{}
+					roots = append(roots, root)
+				}
+			}
+			return nil
+		})
+	})
+	return roots, err
+}
+
+// sectorReferenced reports whether root is referenced by any of the host's
+// unresolved storage obligations.
+func (h *Host) sectorReferenced(root crypto.Hash) (referenced bool, err error) {
+	roots, err := h.storedSectorRoots()
+	if err != nil {
+		return false, err
+	}
+	for _, r := range roots {
+		if r == root {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IterateSectors calls fn on the Merkle root and data of each sector stored by
+// the host, reading the data from disk. Each sector is visited once, even if it
+// is referenced by multiple storage obligations. The data is passed to fn as
+// read, without verification, so that fn can detect corruption by comparing
+// crypto.MerkleRoot(data) to root. Sectors that are no longer referenced by
+// any storage obligation by the time they are read are skipped. A sector that
+// is still referenced but missing from storage, e.g. because its storage
+// folder is unavailable, is passed to fn with nil data. Iteration stops if fn
+// returns an error or a sector cannot be read.
+func (h *Host) IterateSectors(fn func(root crypto.Hash, data []byte) error) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	roots, err := h.storedSectorRoots()
+	h.mu.RUnlock()
+	if err != nil {
+		return build.ExtendErr("unable to load sector roots:", err)
+	}
+
+	// The host lock is not held while reading sectors, as reading every
+	// sector may take a long time.
+	for _, root := range roots {
+		data, err := h.ReadSector(root)
+		if err == contractmanager.ErrSectorNotFound {
+			// The sector may have been removed after the roots were loaded,
+			// in which case it no longer needs to be checked. Otherwise, the
+			// host has lost the sector, and fn must be told about it.
+			h.mu.RLock()
+			referenced, err := h.sectorReferenced(root)
+			h.mu.RUnlock()
+			if err != nil {
+				return build.ExtendErr("unable to load sector roots:", err)
+			} else if !referenced {
+				continue
+			}
+			data = nil
+		} else if err != nil {
+			return build.ExtendErr("unable to read sector "+root.String()+":", err)
+		}
+		if err := fn(root, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package host
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+)
+
+// TestIterateSectors checks that IterateSectors visits every stored sector
+// exactly once, and that corrupted sectors can be detected.
+func TestIterateSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Create two storage obligations that share one sector.
+	sectors := make(map[crypto.Hash][]byte)
+	var roots []crypto.Hash
+	for i := 0; i < 3; i++ {
+		root, data := randSector()
+		sectors[root] = data
+		roots = append(roots, root)
+	}
+	for _, soRoots := range [][]crypto.Hash{roots[:2], roots[1:]} {
+		so, err := ht.newTesterStorageObligation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var soData [][]byte
+		for _, root := range soRoots {
+			soData = append(soData, sectors[root])
+		}
+		so.SectorRoots = soRoots
+		ht.host.managedLockStorageObligation(so.id())
+		err = ht.host.managedAddStorageObligation(so)
+		if err == nil {
+			err = ht.host.modifyStorageObligation(so, nil, soRoots, soData)
+		}
+		ht.host.managedUnlockStorageObligation(so.id())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every sector should be visited once, with the correct data.
+	visited := make(map[crypto.Hash]int)
+	err = ht.host.IterateSectors(func(root crypto.Hash, data []byte) error {
+		visited[root]++
+		if !bytes.Equal(data, sectors[root]) {
+			t.Error("wrong data for sector", root)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(visited) != len(sectors) {
+		t.Fatalf("expected %v sectors to be visited, got %v", len(sectors), len(visited))
+	}
+	for root, n := range visited {
+		if n != 1 {
+			t.Fatalf("sector %v was visited %v times", root, n)
+		}
+	}
+
+	// Errors returned by the callback should stop the iteration.
+	errStop := errors.New("stop")
+	var calls int
+	err = ht.host.IterateSectors(func(crypto.Hash, []byte) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Fatal("iteration did not stop after an error:", err, calls)
+	}
+
+	// Corrupt one of the sectors on disk. The host tester has multiple
+	// storage folders, so search each of them for the sector. A scan should
+	// surface the sector whose data no longer matches its root.
+	var corrupted bool
+	for _, sf := range ht.host.StorageFolders() {
+		sectorFile := filepath.Join(sf.Path, "siahostdata.dat")
+		contents, err := ioutil.ReadFile(sectorFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i := bytes.Index(contents, sectors[roots[1]])
+		if i == -1 {
+			continue
+		}
+		contents[i] ^= 1
+		if err := ioutil.WriteFile(sectorFile, contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+		corrupted = true
+		break
+	}
+	if !corrupted {
+		t.Fatal("could not find sector on disk")
+	}
+	var corrupt []crypto.Hash
+	err = ht.host.IterateSectors(func(root crypto.Hash, data []byte) error {
+		if crypto.MerkleRoot(data) != root {
+			corrupt = append(corrupt, root)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(corrupt) != 1 || corrupt[0] != roots[1] {
+		t.Fatal("expected the corrupted sector to be surfaced, got", corrupt)
+	}
+
+	// A sector that is removed from storage while it is still referenced
+	// should be reported with nil data rather than skipped.
+	if err := ht.host.RemoveSector(roots[0]); err != nil {
+		t.Fatal(err)
+	}
+	visited = make(map[crypto.Hash]int)
+	var missing []crypto.Hash
+	err = ht.host.IterateSectors(func(root crypto.Hash, data []byte) error {
+		visited[root]++
+		if data == nil {
+			missing = append(missing, root)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(visited) != len(sectors) {
+		t.Fatalf("expected %v sectors to be visited, got %v", len(sectors), len(visited))
+	} else if len(missing) != 1 || missing[0] != roots[0] {
+		t.Fatal("expected the removed sector to be reported as missing, got", missing)
+	}
+}
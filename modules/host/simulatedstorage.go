@@ -0,0 +1,199 @@
+package host
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/host/contractmanager"
+)
+
+var (
+	// errSimulatedStorageFolders is returned when trying to change the
+	// storage folders of a host that uses simulated storage.
+	errSimulatedStorageFolders = errors.New("storage folders cannot be changed while using simulated storage")
+
+	// errSimulatedStorageFull is returned when adding a sector to simulated
+	// storage that has no capacity remaining.
+	errSimulatedStorageFull = errors.New("not enough simulated storage remaining to accept sector")
+)
+
+// simulatedSector is a sector held in simulated storage. count is the number
+// of times that the sector has been added, mirroring the virtual sectors of
+// the contract manager.
+type simulatedSector struct {
+	data  []byte
+	count int
+}
+
+// simulatedStorage is a modules.StorageManager that holds sectors in memory.
+// It presents a single storage folder with a fixed capacity.
+type simulatedStorage struct {
+	capacity uint64
+	sectors  map[crypto.Hash]*simulatedSector
+	reads    uint64
+	writes   uint64
+	mu       sync.Mutex
+}
+
+// simulatedStorageDependencies are the production dependencies, except that
+// the host stores its sectors in simulated storage.
+type simulatedStorageDependencies struct {
+	productionDependencies
+	capacity uint64
+}
+
+// newStorageManager returns empty simulated storage instead of a contract
+// manager.
+func (d simulatedStorageDependencies) newStorageManager(string) (modules.StorageManager, error) {
+	return newSimulatedStorage(d.capacity), nil
+}
+
+// NewSimulated returns an initialized Host that holds up to capacity bytes of
+// sectors in memory instead of using storage folders. It is intended for
+// integration tests and demos, and is available in all builds: stored sectors
+// are lost when the host shuts down.
+func NewSimulated(cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, address string, persistDir string, capacity uint64) (*Host, error) {
+	return newHost(simulatedStorageDependencies{capacity: capacity}, cs, tpool, wallet, address, persistDir)
+}
+
+// newSimulatedStorage returns an empty simulatedStorage with the given
+// capacity in bytes.
+func newSimulatedStorage(capacity uint64) *simulatedStorage {
+	return &simulatedStorage{
+		capacity: capacity,
+		sectors:  make(map[crypto.Hash]*simulatedSector),
+	}
+}
+
+// used returns the number of bytes of simulated storage in use.
+func (ss *simulatedStorage) used() uint64 {
+	return uint64(len(ss.sectors)) * modules.SectorSize
+}
+
+// removeSector removes one instance of a sector, deleting the sector once no
+// instances remain.
+func (ss *simulatedStorage) removeSector(root crypto.Hash) error {
+	s, ok := ss.sectors[root]
+	if !ok {
+		return contractmanager.ErrSectorNotFound
+	}
+	s.count--
+	if s.count == 0 {
+		delete(ss.sectors, root)
+	}
+	return nil
+}
+
+// AddSector implements modules.StorageManager.
+func (ss *simulatedStorage) AddSector(root crypto.Hash, data []byte) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if s, ok := ss.sectors[root]; ok {
+		s.count++
+		return nil
+	} else if ss.used()+modules.SectorSize > ss.capacity {
+		return errSimulatedStorageFull
+	}
+	ss.sectors[root] = &simulatedSector{
+		data:  append([]byte(nil), data...),
+		count: 1,
+	}
+	ss.writes++
+	return nil
+}
+
+// AddSectorBatch implements modules.StorageManager. Like the contract manager,
+// it only adds instances of sectors that are already stored.
+func (ss *simulatedStorage) AddSectorBatch(roots []crypto.Hash) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for _, root := range roots {
+		if s, ok := ss.sectors[root]; ok {
+			s.count++
+		}
+	}
+	return nil
+}
+
+// AddStorageFolder implements modules.StorageManager.
+func (ss *simulatedStorage) AddStorageFolder(string, uint64) error {
+	return errSimulatedStorageFolders
+}
+
+// Close implements modules.StorageManager.
+func (ss *simulatedStorage) Close() error {
+	return nil
+}
+
+// DeleteSector implements modules.StorageManager.
+func (ss *simulatedStorage) DeleteSector(root crypto.Hash) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if _, ok := ss.sectors[root]; !ok {
+		return contractmanager.ErrSectorNotFound
+	}
+	delete(ss.sectors, root)
+	return nil
+}
+
+// ReadSector implements modules.StorageManager.
+func (ss *simulatedStorage) ReadSector(root crypto.Hash) ([]byte, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s, ok := ss.sectors[root]
+	if !ok {
+		return nil, contractmanager.ErrSectorNotFound
+	}
+	ss.reads++
+	return append([]byte(nil), s.data...), nil
+}
+
+// RemoveSector implements modules.StorageManager.
+func (ss *simulatedStorage) RemoveSector(root crypto.Hash) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.removeSector(root)
+}
+
+// RemoveSectorBatch implements modules.StorageManager.
+func (ss *simulatedStorage) RemoveSectorBatch(roots []crypto.Hash) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for _, root := range roots {
+		// Like the contract manager, missing sectors are skipped.
+		ss.removeSector(root)
+	}
+	return nil
+}
+
+// RemoveStorageFolder implements modules.StorageManager.
+func (ss *simulatedStorage) RemoveStorageFolder(uint16, bool) error {
+	return errSimulatedStorageFolders
+}
+
+// ResetStorageFolderHealth implements modules.StorageManager. Simulated
+// storage never fails, so there is nothing to reset.
+func (ss *simulatedStorage) ResetStorageFolderHealth(uint16) error {
+	return nil
+}
+
+// ResizeStorageFolder implements modules.StorageManager. The capacity of
+// simulated storage is fixed when the host is created.
+func (ss *simulatedStorage) ResizeStorageFolder(uint16, uint64, bool) error {
+	return errSimulatedStorageFolders
+}
+
+// StorageFolders implements modules.StorageManager.
+func (ss *simulatedStorage) StorageFolders() []modules.StorageFolderMetadata {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return []modules.StorageFolderMetadata{{
+		Capacity:          ss.capacity,
+		CapacityRemaining: ss.capacity - ss.used(),
+		Path:              "simulated",
+		SuccessfulReads:   ss.reads,
+		SuccessfulWrites:  ss.writes,
+	}}
+}
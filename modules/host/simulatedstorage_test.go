@@ -0,0 +1,100 @@
+package host
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestSimulatedStorage checks that a host using simulated storage can store
+// and read sectors, and that the capacity of the simulated storage is
+// accounted for.
+func TestSimulatedStorage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	capacity := 3 * modules.SectorSize
+	ht, err := blankMockHostTester(simulatedStorageDependencies{capacity: capacity}, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+	if err := ht.initWallet(); err != nil {
+		t.Fatal(err)
+	}
+	for i := types.BlockHeight(0); i <= types.MaturityDelay; i++ {
+		if _, err := ht.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sfs := ht.host.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Capacity != capacity || sfs[0].CapacityRemaining != capacity {
+		t.Fatal("simulated storage folder has wrong capacity:", sfs)
+	}
+	if err := ht.host.AddStorageFolder(ht.persistDir, modules.SectorSize*64); err != errSimulatedStorageFolders {
+		t.Fatal("expected errSimulatedStorageFolders, got", err)
+	}
+
+	// Store a sector in a storage obligation.
+	root, data := randSector()
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err == nil {
+		err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{root}, [][]byte{data})
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sector, err := ht.host.ReadSector(root); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(sector, data) {
+		t.Fatal("simulated storage returned wrong sector data")
+	}
+	sfs = ht.host.StorageFolders()
+	if sfs[0].CapacityRemaining != capacity-modules.SectorSize || sfs[0].SuccessfulWrites != 1 {
+		t.Fatal("sector was not accounted for:", sfs[0])
+	}
+
+	// Adding the same sector again should not use more capacity, but
+	// different sectors should only fit until the capacity is used up.
+	if err := ht.host.AddSector(root, data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		root2, data2 := randSector()
+		if err := ht.host.AddSector(root2, data2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root3, data3 := randSector()
+	if err := ht.host.AddSector(root3, data3); err != errSimulatedStorageFull {
+		t.Fatal("expected errSimulatedStorageFull, got", err)
+	}
+
+	// Removing a sector frees its capacity once every instance is removed.
+	if err := ht.host.RemoveSector(root); err != nil {
+		t.Fatal(err)
+	} else if _, err := ht.host.ReadSector(root); err != nil {
+		t.Fatal("sector was removed while an instance remained:", err)
+	}
+	if err := ht.host.RemoveSector(root); err != nil {
+		t.Fatal(err)
+	} else if _, err := ht.host.ReadSector(root); err == nil {
+		t.Fatal("sector was not removed")
+	}
+	if sfs = ht.host.StorageFolders(); sfs[0].CapacityRemaining != modules.SectorSize {
+		t.Fatal("removed sector is still using capacity:", sfs[0])
+	} else if err := ht.host.AddSector(root3, data3); err != nil {
+		t.Fatal(err)
+	}
+}
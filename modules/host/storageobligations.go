@@ -127,6 +127,11 @@ type storageObligation struct {
 	RiskedCollateral         types.Currency
 	TransactionFeesAdded     types.Currency
 
+	// The number of bytes that the renter has uploaded to and downloaded from
+	// the host under this storage obligation.
+	BytesDownloaded uint64
+	BytesUploaded   uint64
+
 	// The negotiation height specifies the block height at which the file
 	// contract was negotiated. If the origin transaction set is not accepted
 	// onto the blockchain quickly enough, the contract is pruned from the
@@ -275,6 +280,34 @@ func (so storageObligation) proofDeadline() types.BlockHeight {
 	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0].WindowEnd
 }
 
+// proofWindowOpen returns true if the host should attempt a storage proof for
+// the obligation at the given height. Proof windows are defined in blocks, so
+// the decision depends only on the consensus height and never on the host's
+// clock.
+func (so storageObligation) proofWindowOpen(height types.BlockHeight) bool {
+	return !so.ProofConfirmed && height >= so.expiration()+resubmissionTimeout
+}
+
+// proofWindowClosed returns true if the proof window of the obligation has
+// closed at the given height, meaning that a storage proof can no longer be
+// submitted.
+func (so storageObligation) proofWindowClosed(height types.BlockHeight) bool {
+	return so.proofDeadline() < height
+}
+
+// renterKey returns the public key of the renter that formed the storage
+// obligation. The key is only known once the obligation has a revision; an
+// empty key is returned otherwise.
+func (so storageObligation) renterKey() types.SiaPublicKey {
+	if len(so.RevisionTransactionSet) > 0 {
+		uc := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0].UnlockConditions
+		if len(uc.PublicKeys) > 0 {
+			return uc.PublicKeys[0]
+		}
+	}
+	return types.SiaPublicKey{}
+}
+
 // value returns the value of fulfilling the storage obligation to the host.
 func (so storageObligation) value() types.Currency {
 	return so.ContractCost.Add(so.PotentialDownloadRevenue).Add(so.PotentialStorageRevenue).Add(so.PotentialUploadRevenue).Add(so.RiskedCollateral)
@@ -506,6 +539,15 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 		_ = h.RemoveSector(sectorsRemoved[k])
 	}
 
+	// Record the sector accesses in the access log.
+	renterKey := so.renterKey()
+	for _, root := range sectorsGained {
+		h.logSectorAccess(renterKey, root, sectorAccessWrite)
+	}
+	for _, root := range sectorsRemoved {
+		h.logSectorAccess(renterKey, root, sectorAccessRemove)
+	}
+
 	// Update the financial information for the storage obligation - remove the
 	// old values.
 	h.financialMetrics.PotentialContractCompensation = h.financialMetrics.PotentialContractCompensation.Sub(oldSO.ContractCost)
@@ -588,6 +630,7 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 	// obligation status is updated so that the user can see how the obligation
 	// ended up, and the sector roots are removed because they are large
 	// objects with little purpose once storage proofs are no longer needed.
+	h.recordRenterOutcome(so, sos)
 	h.financialMetrics.ContractCount--
 	so.ObligationStatus = sos
 	so.SectorRoots = nil
@@ -740,12 +783,12 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 
 	// Check whether a storage proof is ready to be provided, and whether it
 	// has been accepted. Check for death.
-	if !so.ProofConfirmed && blockHeight >= so.expiration()+resubmissionTimeout {
+	if so.proofWindowOpen(blockHeight) {
 		h.log.Debugln("Host is attempting a storage proof for", so.id())
 
 		// If the window has closed, the host has failed and the obligation can
 		// be removed.
-		if so.proofDeadline() < blockHeight || len(so.SectorRoots) == 0 {
+		if so.proofWindowClosed(blockHeight) || len(so.SectorRoots) == 0 {
 			h.log.Debugln("storage proof not confirmed by deadline, id", so.id())
 			h.mu.Lock()
 			err := h.removeStorageObligation(so, obligationFailed)
@@ -855,6 +898,27 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	}
 }
 
+// ContractBandwidthReport returns the number of bytes uploaded and downloaded
+// under the storage obligation with the given id, along with the revenue that
+// the host stands to earn from it. The revenue does not include the host's
+// collateral. Zero values are returned if the host has no such obligation.
+func (h *Host) ContractBandwidthReport(id types.FileContractID) (uploaded, downloaded uint64, revenue types.Currency) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var so storageObligation
+	err := h.db.View(func(tx *bolt.Tx) error {
+		var err error
+		so, err = getStorageObligation(tx, id)
+		return err
+	})
+	if err != nil {
+		return 0, 0, types.ZeroCurrency
+	}
+	revenue = so.ContractCost.Add(so.PotentialDownloadRevenue).Add(so.PotentialStorageRevenue).Add(so.PotentialUploadRevenue)
+	return so.BytesUploaded, so.BytesDownloaded, revenue
+}
+
 // StorageObligations fetches the set of storage obligations in the host and
 // returns metadata on them.
 func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
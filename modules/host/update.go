@@ -6,6 +6,7 @@ package host
 import (
 	"encoding/binary"
 	"encoding/json"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
@@ -197,7 +198,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 			// the default height is 0 and the genesis block height is 0. If
 			// removing the genesis block, height will already be at height 0 and
 			// should not update, lest an underflow occur.
-			if block.ID() != types.GenesisID {
+			if !block.IsGenesis() {
 				h.blockHeight--
 			}
 		}
@@ -267,7 +268,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 			// the default height is 0 and the genesis block height is 0. If adding
 			// the genesis block, height will already be at height 0 and should not
 			// update.
-			if block.ID() != types.GenesisID {
+			if !block.IsGenesis() {
 				h.blockHeight++
 			}
 
@@ -300,6 +301,12 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 		go h.threadedHandleActionItem(actionItems[i])
 	}
 
+	// Compare the timestamp of the newest block to the host's clock. Blocks
+	// received during the initial sync are old, so they are not sampled.
+	if cc.Synced && len(cc.AppliedBlocks) > 0 {
+		h.recordClockSample(cc.AppliedBlocks[len(cc.AppliedBlocks)-1].Timestamp, time.Now())
+	}
+
 	// Update the host's recent change pointer to point to the most recent
 	// change.
 	h.recentChange = cc.ID
@@ -0,0 +1,60 @@
+package host
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errMaxCollateralExceedsBudget is returned by ValidateSettings if the
+	// maximum collateral of a single contract exceeds the collateral budget,
+	// in which case the budget would be exhausted by a single contract.
+	errMaxCollateralExceedsBudget = errors.New("max collateral exceeds the collateral budget")
+
+	// errZeroMaxCollateral is returned by ValidateSettings if the host offers
+	// collateral, but does not allow any collateral to be put into a
+	// contract.
+	errZeroMaxCollateral = errors.New("collateral is nonzero, but max collateral is zero")
+
+	// errZeroBatchSize is returned by ValidateSettings if either of the batch
+	// sizes is zero, which would prevent renters from downloading or
+	// revising.
+	errZeroBatchSize = errors.New("download and revise batch sizes must be nonzero")
+
+	// errZeroWindowSize is returned by ValidateSettings if the proof window
+	// is empty.
+	errZeroWindowSize = errors.New("window size must be nonzero")
+)
+
+// validateSettings checks that the settings are internally consistent.
+func validateSettings(settings modules.HostInternalSettings) error {
+	if settings.NetAddress != "" {
+		if err := settings.NetAddress.IsValid(); err != nil {
+			return errors.New("invalid NetAddress: " + err.Error())
+		}
+	}
+	if settings.MaxDuration == 0 || settings.MinDuration > settings.MaxDuration {
+		return errInvalidDurationBounds
+	}
+	if settings.WindowSize == 0 {
+		return errZeroWindowSize
+	}
+	if settings.MaxDownloadBatchSize == 0 || settings.MaxReviseBatchSize == 0 {
+		return errZeroBatchSize
+	}
+	if settings.MaxCollateral.Cmp(settings.CollateralBudget) > 0 {
+		return errMaxCollateralExceedsBudget
+	}
+	if !settings.Collateral.IsZero() && settings.MaxCollateral.IsZero() {
+		return errZeroMaxCollateral
+	}
+	return nil
+}
+
+// ValidateSettings checks that the provided settings are internally
+// consistent, without applying them. It is intended to be called before
+// SetInternalSettings, which does not perform these checks.
+func (h *Host) ValidateSettings(settings modules.HostInternalSettings) error {
+	return validateSettings(settings)
+}
@@ -0,0 +1,61 @@
+package host
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestValidateSettings checks that ValidateSettings reports inconsistent
+// settings without applying them.
+func TestValidateSettings(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	defaults := ht.host.InternalSettings()
+	if err := ht.host.ValidateSettings(defaults); err != nil {
+		t.Fatal("default settings should be valid:", err)
+	}
+
+	tests := []struct {
+		modify func(*modules.HostInternalSettings)
+		err    error
+	}{
+		{func(s *modules.HostInternalSettings) { s.MinDuration = s.MaxDuration + 1 }, errInvalidDurationBounds},
+		{func(s *modules.HostInternalSettings) { s.MinDuration, s.MaxDuration = 0, 0 }, errInvalidDurationBounds},
+		{func(s *modules.HostInternalSettings) { s.WindowSize = 0 }, errZeroWindowSize},
+		{func(s *modules.HostInternalSettings) { s.MaxDownloadBatchSize = 0 }, errZeroBatchSize},
+		{func(s *modules.HostInternalSettings) { s.MaxReviseBatchSize = 0 }, errZeroBatchSize},
+		{func(s *modules.HostInternalSettings) {
+			s.MaxCollateral = s.CollateralBudget.Add(types.NewCurrency64(1))
+		}, errMaxCollateralExceedsBudget},
+		{func(s *modules.HostInternalSettings) { s.MaxCollateral = types.ZeroCurrency }, errZeroMaxCollateral},
+		{func(s *modules.HostInternalSettings) {
+			s.MaxCollateral, s.Collateral = types.ZeroCurrency, types.ZeroCurrency
+		}, nil},
+	}
+	for i, test := range tests {
+		settings := defaults
+		test.modify(&settings)
+		if err := ht.host.ValidateSettings(settings); err != test.err {
+			t.Errorf("%v: expected %v, got %v", i, test.err, err)
+		}
+	}
+	if err := ht.host.ValidateSettings(modules.HostInternalSettings{NetAddress: "foo"}); err == nil {
+		t.Error("expected invalid net address to be rejected")
+	}
+
+	// Validation should not change the host's settings.
+	if !reflect.DeepEqual(ht.host.InternalSettings(), defaults) {
+		t.Fatal("validating settings changed the host's settings")
+	}
+}
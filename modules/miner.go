@@ -2,6 +2,7 @@ package modules
 
 import (
 	"io"
+	"time"
 
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -12,6 +13,14 @@ const (
 	MinerDir = "miner"
 )
 
+// A TimeWindow is a daily period of time during which the cpu miner is allowed
+// to run. Start and End are offsets from midnight in the local time zone. If
+// End is before Start, the window wraps around midnight.
+type TimeWindow struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
 // BlockManager contains functions that can interface with external miners,
 // providing and receiving blocks that have experienced nonce grinding.
 type BlockManager interface {
@@ -43,6 +52,18 @@ type CPUMiner interface {
 
 	// StopMining turns off the miner, but keeps the same number of threads.
 	StopCPUMining()
+
+	// Pause temporarily stops the cpu miner from hashing without turning it
+	// off. Work that is in progress when the miner is paused is discarded.
+	Pause()
+
+	// Resume undoes a call to Pause.
+	Resume()
+
+	// SetMiningSchedule restricts the cpu miner to the provided time windows.
+	// Outside of the windows, the miner is paused. An empty schedule allows
+	// the miner to run at any time.
+	SetMiningSchedule([]TimeWindow) error
 }
 
 // TestMiner provides direct access to block fetching, solving, and
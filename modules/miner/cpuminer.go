@@ -46,19 +46,38 @@ func (m *Miner) threadedMine() {
 			return
 		}
 
+		// Wait if the miner is paused or outside of its mining schedule.
+		if !m.miningAllowed() {
+			m.hashRate = 0
+			m.mu.Unlock()
+			select {
+			case <-m.tg.StopChan():
+			case <-time.After(scheduleCheckInterval):
+			}
+			cycleStart = time.Now()
+			continue
+		}
+
 		// Prepare the work and release the miner lock.
 		bfw := m.blockForWork()
 		target := m.persist.Target
 		m.mu.Unlock()
 
-		// Solve the block.
+		// Solve the block. If the miner was paused while solving, the block
+		// is discarded; a fresh block will be prepared once mining resumes.
 		b, solved := solveBlock(bfw, target)
-		if solved {
+		m.mu.RLock()
+		allowed := m.miningAllowed()
+		m.mu.RUnlock()
+		if solved && allowed {
 			err := m.managedSubmitBlock(b)
 			if err != nil {
 				m.log.Println("ERROR: An error occurred while cpu mining:", err)
 			}
 		}
+		if !allowed {
+			continue
+		}
 
 		// Update the hashrate. If the block was solved, the full set of
 		// iterations was not completed, so the hashrate should not be updated.
@@ -94,6 +94,12 @@ type Miner struct {
 	mining   bool  // indicates if the miner is actually running
 	hashRate int64 // indicates hashes per second
 
+	// Scheduling variables. The cpu miner only hashes if it is not paused and
+	// the current time falls within the schedule.
+	paused   bool
+	schedule []modules.TimeWindow
+	clock    func() time.Time
+
 	// Utils
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -167,6 +173,8 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, w modules.Walle
 		fullSets:  make(map[modules.TransactionSetID][]int),
 		splitSets: make(map[int]*splitSet),
 
+		clock: time.Now,
+
 		persistDir: persistDir,
 	}
 
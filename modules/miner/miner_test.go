@@ -44,6 +44,11 @@ func createMinerTester(name string) (*minerTester, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The consensus set marks itself as synced in a background thread. Wait
+	// for it, so that the miner refreshes its source block on every new block.
+	for !cs.Synced() {
+		time.Sleep(time.Millisecond)
+	}
 	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
 	if err != nil {
 		return nil, err
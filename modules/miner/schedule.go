@@ -0,0 +1,105 @@
+package miner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errInvalidTimeWindow is returned by SetMiningSchedule if a window does
+	// not fall within a single day or is empty.
+	errInvalidTimeWindow = errors.New("time window must be non-empty and fall within a single day")
+
+	// scheduleCheckInterval is how often a paused cpu miner checks whether it
+	// is allowed to resume.
+	scheduleCheckInterval = build.Select(build.Var{
+		Standard: 10 * time.Second,
+		Dev:      time.Second,
+		Testing:  10 * time.Millisecond,
+	}).(time.Duration)
+)
+
+// timeOfDay returns the time that has elapsed since midnight on the day of t.
+func timeOfDay(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// inWindow returns true if t falls within the time window w.
+func inWindow(w modules.TimeWindow, t time.Time) bool {
+	d := timeOfDay(t)
+	if w.Start <= w.End {
+		return w.Start <= d && d < w.End
+	}
+	// The window wraps around midnight.
+	return w.Start <= d || d < w.End
+}
+
+// miningAllowed returns true if the cpu miner is neither paused nor outside
+// of its mining schedule.
+func (m *Miner) miningAllowed() bool {
+	if m.paused {
+		return false
+	}
+	if len(m.schedule) == 0 {
+		return true
+	}
+	now := m.clock()
+	for _, w := range m.schedule {
+		if inWindow(w, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pause temporarily stops the cpu miner from hashing without turning it off.
+// Any block that is being worked on when the miner is paused is discarded.
+func (m *Miner) Pause() {
+	if err := m.tg.Add(); err != nil {
+		build.Critical(err)
+	}
+	defer m.tg.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = true
+	m.hashRate = 0
+}
+
+// Resume undoes a call to Pause. If the cpu miner is on and the current time
+// falls within the mining schedule, hashing restarts on a fresh block.
+func (m *Miner) Resume() {
+	if err := m.tg.Add(); err != nil {
+		build.Critical(err)
+	}
+	defer m.tg.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = false
+}
+
+// SetMiningSchedule restricts the cpu miner to the provided daily time
+// windows. Outside of the windows, the miner behaves as if it were paused. An
+// empty schedule allows the miner to run at any time.
+func (m *Miner) SetMiningSchedule(schedule []modules.TimeWindow) error {
+	if err := m.tg.Add(); err != nil {
+		return err
+	}
+	defer m.tg.Done()
+
+	for _, w := range schedule {
+		if w.Start < 0 || w.End < 0 || w.Start >= 24*time.Hour || w.End > 24*time.Hour || w.Start == w.End {
+			return errInvalidTimeWindow
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedule = append([]modules.TimeWindow(nil), schedule...)
+	return nil
+}
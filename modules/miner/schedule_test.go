@@ -0,0 +1,129 @@
+package miner
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// mockClock is a clock whose time is set manually.
+type mockClock struct {
+	t  time.Time
+	mu sync.Mutex
+}
+
+func (c *mockClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *mockClock) set(hour, min int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = time.Date(2017, time.January, 1, hour, min, 0, 0, time.Local)
+}
+
+// TestInWindow probes the inWindow function.
+func TestInWindow(t *testing.T) {
+	tests := []struct {
+		start, end time.Duration
+		hour, min  int
+		in         bool
+	}{
+		{time.Hour, 2 * time.Hour, 0, 59, false},
+		{time.Hour, 2 * time.Hour, 1, 0, true},
+		{time.Hour, 2 * time.Hour, 1, 59, true},
+		{time.Hour, 2 * time.Hour, 2, 0, false},
+		{22 * time.Hour, 6 * time.Hour, 23, 0, true},
+		{22 * time.Hour, 6 * time.Hour, 3, 0, true},
+		{22 * time.Hour, 6 * time.Hour, 12, 0, false},
+	}
+	for _, test := range tests {
+		w := modules.TimeWindow{Start: test.start, End: test.end}
+		now := time.Date(2017, time.January, 1, test.hour, test.min, 0, 0, time.Local)
+		if inWindow(w, now) != test.in {
+			t.Errorf("inWindow(%v, %02d:%02d) should be %v", w, test.hour, test.min, test.in)
+		}
+	}
+}
+
+// TestMiningSchedule checks that the cpu miner stops and starts at the
+// boundaries of its mining schedule, and when it is paused and resumed.
+func TestMiningSchedule(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mt.miner.Close()
+
+	clock := new(mockClock)
+	clock.set(3, 0)
+	mt.miner.mu.Lock()
+	mt.miner.clock = clock.now
+	mt.miner.mu.Unlock()
+
+	err = mt.miner.SetMiningSchedule([]modules.TimeWindow{{Start: time.Hour, End: time.Hour}})
+	if err != errInvalidTimeWindow {
+		t.Fatal("expected errInvalidTimeWindow, got", err)
+	}
+	err = mt.miner.SetMiningSchedule([]modules.TimeWindow{{Start: time.Hour, End: 2 * time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expectMining checks whether blocks are being mined.
+	expectMining := func(mining bool) {
+		// Give any in-progress work time to finish.
+		time.Sleep(50 * time.Millisecond)
+		start := mt.cs.Height()
+		for i := 0; i < 50; i++ {
+			time.Sleep(20 * time.Millisecond)
+			if mt.cs.Height() > start {
+				break
+			}
+		}
+		if mined := mt.cs.Height() > start; mined != mining {
+			t.Fatalf("expected mining to be %v, but blocks mined was %v", mining, mined)
+		}
+	}
+
+	// The miner should not mine outside of the schedule.
+	mt.miner.StartCPUMining()
+	defer mt.miner.StopCPUMining()
+	expectMining(false)
+	if !mt.miner.CPUMining() {
+		t.Fatal("miner should still be on outside of the schedule")
+	}
+
+	// Entering the window should start mining, and leaving it should stop
+	// mining.
+	clock.set(1, 0)
+	expectMining(true)
+	clock.set(2, 0)
+	expectMining(false)
+	clock.set(1, 30)
+	expectMining(true)
+
+	// Pausing should stop mining even within the window.
+	mt.miner.Pause()
+	expectMining(false)
+	if hr := mt.miner.CPUHashrate(); hr != 0 {
+		t.Fatal("expected hashrate of 0 while paused, got", hr)
+	}
+	mt.miner.Resume()
+	expectMining(true)
+
+	// Clearing the schedule should allow mining at any time.
+	clock.set(12, 0)
+	expectMining(false)
+	if err := mt.miner.SetMiningSchedule(nil); err != nil {
+		t.Fatal(err)
+	}
+	expectMining(true)
+}
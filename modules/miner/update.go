@@ -101,7 +101,7 @@ func (m *Miner) ProcessConsensusChange(cc modules.ConsensusChange) {
 	for _, block := range cc.RevertedBlocks {
 		// Only doing the block check if the height is above zero saves hashing
 		// and saves a nontrivial amount of time during IBD.
-		if m.persist.Height > 0 || block.ID() != types.GenesisID {
+		if m.persist.Height > 0 || !block.IsGenesis() {
 			m.persist.Height--
 		} else if m.persist.Height != 0 {
 			// Sanity check - if the current block is the genesis block, the
@@ -113,7 +113,7 @@ func (m *Miner) ProcessConsensusChange(cc modules.ConsensusChange) {
 	for _, block := range cc.AppliedBlocks {
 		// Only doing the block check if the height is above zero saves hashing
 		// and saves a nontrivial amount of time during IBD.
-		if m.persist.Height > 0 || block.ID() != types.GenesisID {
+		if m.persist.Height > 0 || !block.IsGenesis() {
 			m.persist.Height++
 		} else if m.persist.Height != 0 {
 			// Sanity check - if the current block is the genesis block, the
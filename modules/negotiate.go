@@ -3,7 +3,10 @@ package modules
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"math/bits"
+	"strings"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -17,6 +20,11 @@ const (
 	// acceptance, i.e. that the sender wishes to continue communication.
 	AcceptResponse = "accept"
 
+	// collateralLimitPrefix begins the error sent by a host that rejects a
+	// proposed contract because it expects too much collateral. It is
+	// followed by the host's limit for the contract, in hastings.
+	collateralLimitPrefix = "file contract proposal expects the host to pay more than its collateral limit of "
+
 	// StopResponse is the response given to an RPC call to indicate graceful
 	// termination, i.e. that the sender wishes to cease communication, but
 	// not due to an error.
@@ -54,6 +62,11 @@ const (
 	// tree calculations that may be involved with renewing a file contract.
 	NegotiateRenewContractTime = 600 * time.Second
 
+	// NegotiateProveSectorTime establishes the minimum amount of time that the
+	// connection deadline is expected to be set to when a renter is requesting
+	// a proof that the host is storing a sector.
+	NegotiateProveSectorTime = 120 * time.Second
+
 	// NegotiateSettingsTime establishes the minimum amount of time that the
 	// connection deadline is expected to be set to when settings are being
 	// requested from the host. The deadline is long enough that the connection
@@ -66,6 +79,10 @@ const (
 	// data being requested.
 	NegotiateMaxDownloadActionRequestSize = 50e3
 
+	// NegotiateMaxProveSectorRequestSize defines the maximum size that a
+	// ProveSector request can be.
+	NegotiateMaxProveSectorRequestSize = 1e3
+
 	// NegotiateMaxErrorSize indicates the maximum number of bytes that can be
 	// used to encode an error being sent during negotiation.
 	NegotiateMaxErrorSize = 256
@@ -156,6 +173,10 @@ var (
 	// contract.
 	RPCReviseContract = types.Specifier{'R', 'e', 'v', 'i', 's', 'e', 'C', 'o', 'n', 't', 'r', 'a', 'c', 't', 2}
 
+	// RPCProveSector is the specifier for requesting a proof that the host is
+	// storing a sector.
+	RPCProveSector = types.Specifier{'P', 'r', 'o', 'v', 'e', 'S', 'e', 'c', 't', 'o', 'r'}
+
 	// RPCRecentRevision is the specifier for getting the most recent file
 	// contract revision for a given file contract.
 	RPCRecentRevision = types.Specifier{'R', 'e', 'c', 'e', 'n', 't', 'R', 'e', 'v', 'i', 's', 'i', 'o', 'n', 2}
@@ -258,6 +279,22 @@ type (
 		Version        string `json:"version"`
 	}
 
+	// A ProveSectorRequest asks the host to prove that it is storing the
+	// sector with the given Merkle root. The host responds with the segment at
+	// SegmentIndex and the Merkle proof that connects it to the root, which
+	// is much cheaper than downloading the full sector. Renters should pick
+	// SegmentIndex at random so that the host cannot get away with storing
+	// only part of the sector. The request is sent after the
+	// RPCRecentRevision exchange, and the sector must belong to the contract
+	// used in that exchange. The request is followed by a file contract
+	// revision that pays the host for ProveSectorResponseSize bytes at its
+	// download bandwidth price, and the payment is completed with the same
+	// signature exchange as a download.
+	ProveSectorRequest struct {
+		MerkleRoot   crypto.Hash
+		SegmentIndex uint64
+	}
+
 	// A RevisionAction is a description of an edit to be performed on a file
 	// contract. Three types are allowed, 'ActionDelete', 'ActionInsert', and
 	// 'ActionModify'. ActionDelete just takes a sector index, indicating which
@@ -278,6 +315,14 @@ type (
 	}
 )
 
+// ProveSectorResponseSize returns the number of bytes of proof data that the
+// host sends in response to a ProveSectorRequest: one segment, plus one hash
+// for each level of the sector's Merkle tree.
+func ProveSectorResponseSize() uint64 {
+	numSegments := SectorSize / crypto.SegmentSize
+	return crypto.SegmentSize + crypto.HashSize*uint64(bits.Len64(numSegments-1))
+}
+
 // ReadNegotiationAcceptance reads an accept/reject response from r (usually a
 // net.Conn). If the response is not AcceptResponse, ReadNegotiationAcceptance
 // returns the response as an error. If the response is StopResponse,
@@ -308,6 +353,33 @@ func WriteNegotiationAcceptance(w io.Writer) error {
 	return encoding.WriteObject(w, AcceptResponse)
 }
 
+// NewCollateralLimitError returns the error that a host sends to reject a
+// proposed contract that expects the host to add more collateral than limit.
+// Renters can recover the limit with CollateralLimit and propose the contract
+// again with the collateral capped to it.
+func NewCollateralLimitError(limit types.Currency) error {
+	return errors.New(collateralLimitPrefix + limit.String() + " hastings")
+}
+
+// CollateralLimit returns the limit reported by a host that rejected a
+// proposed contract with an error created by NewCollateralLimitError. The
+// error may have been wrapped with additional context.
+func CollateralLimit(err error) (types.Currency, bool) {
+	if err == nil {
+		return types.Currency{}, false
+	}
+	s := err.Error()
+	i := strings.Index(s, collateralLimitPrefix)
+	if i == -1 {
+		return types.Currency{}, false
+	}
+	var limit types.Currency
+	if _, err := fmt.Sscan(s[i+len(collateralLimitPrefix):], &limit); err != nil {
+		return types.Currency{}, false
+	}
+	return limit, true
+}
+
 // WriteNegotiationRejection will write a rejection response to w (usually a
 // net.Conn) and return the input error. If the write fails, the write error
 // is joined with the input error.
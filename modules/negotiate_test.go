@@ -2,6 +2,7 @@ package modules
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
@@ -125,3 +126,24 @@ func TestNegotiationResponses(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestCollateralLimit checks that CollateralLimit recovers the limit from
+// errors created by NewCollateralLimitError, even after they have been sent
+// as a rejection and wrapped.
+func TestCollateralLimit(t *testing.T) {
+	limit := types.SiacoinPrecision.Mul64(123)
+	buf := new(bytes.Buffer)
+	WriteNegotiationRejection(buf, NewCollateralLimitError(limit))
+	err := ReadNegotiationAcceptance(buf)
+	err = errors.New("host did not accept our proposed contract: " + err.Error())
+	if got, ok := CollateralLimit(err); !ok || !got.Equals(limit) {
+		t.Fatal("wrong limit:", got, ok)
+	}
+
+	if _, ok := CollateralLimit(ErrLowBalance); ok {
+		t.Fatal("limit returned for unrelated error")
+	}
+	if _, ok := CollateralLimit(nil); ok {
+		t.Fatal("limit returned for nil error")
+	}
+}
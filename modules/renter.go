@@ -83,6 +83,31 @@ type FileInfo struct {
 	Expiration     types.BlockHeight `json:"expiration"`
 }
 
+// A FileLayout describes where each piece of a file is stored.
+type FileLayout struct {
+	SiaPath string        `json:"siapath"`
+	Chunks  []ChunkLayout `json:"chunks"`
+}
+
+// A ChunkLayout lists the stored pieces of one chunk of a file, sorted by
+// piece index. A piece may be stored on more than one host, and pieces that
+// have not been uploaded are absent.
+type ChunkLayout struct {
+	Index  uint64        `json:"index"`
+	Pieces []PieceLayout `json:"pieces"`
+}
+
+// A PieceLayout identifies the host storing a piece of a chunk. HostPublicKey
+// is empty if the renter no longer has a contract with the host. Offline is
+// true if the piece is currently unavailable.
+type PieceLayout struct {
+	Index         uint64             `json:"index"`
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	NetAddress    NetAddress         `json:"netaddress"`
+	MerkleRoot    crypto.Hash        `json:"merkleroot"`
+	Offline       bool               `json:"offline"`
+}
+
 // A HostDBEntry represents one host entry in the Renter's host DB. It
 // aggregates the host's external settings and metrics with its public key.
 type HostDBEntry struct {
@@ -107,6 +132,10 @@ type HostDBEntry struct {
 type HostDBScan struct {
 	Timestamp time.Time `json:"timestamp"`
 	Success   bool      `json:"success"`
+
+	// Latency is the time it took the host to respond with its settings.
+	// It is zero for failed scans.
+	Latency time.Duration `json:"latency,omitempty"`
 }
 
 // HostScoreBreakdown provides a piece-by-piece explanation of why a host has
@@ -123,6 +152,7 @@ type HostScoreBreakdown struct {
 	AgeAdjustment              float64 `json:"ageadjustment"`
 	BurnAdjustment             float64 `json:"burnadjustment"`
 	CollateralAdjustment       float64 `json:"collateraladjustment"`
+	LatencyAdjustment          float64 `json:"latencyadjustment"`
 	PriceAdjustment            float64 `json:"pricesmultiplier"`
 	StorageRemainingAdjustment float64 `json:"storageremainingadjustment"`
 	UptimeAdjustment           float64 `json:"uptimeadjustment"`
@@ -235,6 +265,36 @@ func (rc *RenterContract) RenterFunds() types.Currency {
 	return rc.LastRevision.NewValidProofOutputs[0].Value
 }
 
+// RenterContractHealth summarizes the state of the renter's contracts.
+type RenterContractHealth struct {
+	// Good contracts are usable and are not yet due for renewal.
+	Good int `json:"good"`
+	// Renewing contracts have entered the renew window, and will be renewed
+	// by the renter.
+	Renewing int `json:"renewing"`
+	// Expiring contracts have entered the renew window, but will not be
+	// renewed because the renter has no allowance.
+	Expiring int `json:"expiring"`
+	// Failed contracts are with hosts that are offline or blocked. Data
+	// stored under them is migrated to other hosts.
+	Failed int `json:"failed"`
+
+	// RemainingFunds is the total amount of money left in the contracts for
+	// the renter to spend on uploads and downloads.
+	RemainingFunds types.Currency `json:"remainingfunds"`
+}
+
+// AlertCauseContractFunds is the cause of alerts raised when a contract is
+// running out of funds.
+const AlertCauseContractFunds = "contractfunds"
+
+// A RenterAlert warns the user about a condition that requires their
+// attention.
+type RenterAlert struct {
+	Cause string `json:"cause"`
+	Msg   string `json:"msg"`
+}
+
 // A Renter uploads, tracks, repairs, and downloads a set of files for the
 // user.
 type Renter interface {
@@ -245,12 +305,31 @@ type Renter interface {
 	// AllHosts returns the full list of hosts known to the renter.
 	AllHosts() []HostDBEntry
 
+	// Alerts returns the renter's active alerts.
+	Alerts() []RenterAlert
+
+	// BlockHost adds a host to the renter's blocklist. Blocked hosts are
+	// never used to form or renew contracts, and data stored on them is
+	// migrated to other hosts. The blocklist is persisted.
+	BlockHost(pk types.SiaPublicKey) error
+
+	// BlockedHosts returns the public keys of the blocked hosts.
+	BlockedHosts() []types.SiaPublicKey
+
 	// Close closes the Renter.
 	Close() error
 
 	// Contracts returns the contracts formed by the renter.
 	Contracts() []RenterContract
 
+	// ContractHealth returns a summary of the state of the renter's
+	// contracts.
+	ContractHealth() RenterContractHealth
+
+	// CorruptPiecesDetected returns the number of downloaded pieces that
+	// failed verification and were requested from another host.
+	CorruptPiecesDetected() uint64
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
@@ -262,15 +341,41 @@ type Renter interface {
 	// downloads of `offset` and `length` type.
 	Download(params RenterDownloadParameters) error
 
+	// DownloadWithPriority performs a download like Download, but allows the
+	// caller to specify a priority. Downloads with a higher priority are
+	// serviced before downloads with a lower priority. Download uses a
+	// priority of 0.
+	DownloadWithPriority(params RenterDownloadParameters, priority int) error
+
 	// DownloadQueue lists all the files that have been scheduled for download.
 	DownloadQueue() []DownloadInfo
 
+	// DownloadProgress returns a channel that receives the progress of the
+	// most recent download of a file, as a fraction between 0 and 1. The
+	// channel is closed when the download finishes; a successful download
+	// always reports 1 before the channel is closed.
+	DownloadProgress(siaPath string) (<-chan float64, error)
+
+	// ExportContracts writes an encrypted backup of the renter's contracts,
+	// including their latest revisions and secret keys. The backup is
+	// encrypted with a key derived from the wallet seed.
+	ExportContracts(w io.Writer) error
+
+	// FileLayout returns the hosts storing each piece of each chunk of a
+	// file.
+	FileLayout(siaPath string) (FileLayout, error)
+
 	// FileList returns information on all of the files stored by the renter.
 	FileList() []FileInfo
 
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool)
 
+	// ImportContracts restores the contracts from a backup created by
+	// ExportContracts. The wallet must use the same seed as the wallet that
+	// created the backup.
+	ImportContracts(r io.Reader) error
+
 	// LoadSharedFiles loads a '.sia' file into the renter. A .sia file may
 	// contain multiple files. The paths of the added files are returned.
 	LoadSharedFiles(source string) ([]string, error)
@@ -279,13 +384,30 @@ type Renter interface {
 	// renter.
 	LoadSharedFilesAscii(asciiSia string) ([]string, error)
 
+	// NormalizeSiaPath returns the canonical form of a siapath, or an error
+	// if the siapath is invalid.
+	NormalizeSiaPath(siaPath string) (string, error)
+
 	// PriceEstimation estimates the cost in siacoins of performing various
 	// storage and data operations.
 	PriceEstimation() RenterPriceEstimation
 
+	// PublicKey returns the public key that the renter signs new contracts
+	// with. Hosts can use it to identify the renter, e.g. to allowlist it.
+	PublicKey() types.SiaPublicKey
+
+	// EstimateUploadCost estimates the cost of uploading and storing a file
+	// of the given size for 'duration' blocks at the given redundancy.
+	EstimateUploadCost(fileSize uint64, duration types.BlockHeight, redundancy float64) (types.Currency, error)
+
 	// RenameFile changes the path of a file.
 	RenameFile(path, newPath string) error
 
+	// RotateFileKey re-encrypts a file under a new key by downloading it
+	// and uploading it again. The original file remains accessible if the
+	// rotation fails.
+	RotateFileKey(siaPath string) error
+
 	// EstimateHostScore will return the score for a host with the provided
 	// settings, assuming perfect age and uptime adjustments
 	EstimateHostScore(entry HostDBEntry) HostScoreBreakdown
@@ -294,18 +416,61 @@ type Renter interface {
 	// hostdb's weighting algorithm.
 	ScoreBreakdown(entry HostDBEntry) HostScoreBreakdown
 
+	// SetLatencyPreference sets how strongly the measured latency of a host
+	// affects its score. A weight of zero ignores latency.
+	SetLatencyPreference(weight float64) error
+
 	// Settings returns the Renter's current settings.
 	Settings() RenterSettings
 
+	// UploadProgress returns a channel that receives the upload progress of
+	// a file, as a fraction between 0 and 1. The channel is closed once the
+	// file is fully uploaded or deleted.
+	UploadProgress(siaPath string) (<-chan float64, error)
+
 	// SetSettings sets the Renter's settings.
 	SetSettings(RenterSettings) error
 
+	// SetFundsAlertThreshold sets the fraction of a contract's initial funds
+	// below which an alert is raised. A value of 0 disables the alert.
+	SetFundsAlertThreshold(float64) error
+
+	// SetRepairThreshold sets the redundancy below which a file chunk is
+	// repaired. A value of 0 repairs any chunk that is missing pieces. New
+	// uploads always reach full redundancy.
+	SetRepairThreshold(float64) error
+
+	// PauseUpload stops uploading the file at siaPath until ResumeUpload is
+	// called. Uploaded pieces are kept, and the upload stays paused across
+	// restarts.
+	PauseUpload(siaPath string) error
+
+	// ResumeUpload resumes uploading a file paused with PauseUpload.
+	ResumeUpload(siaPath string) error
+
+	// SetContractFormationConcurrency sets the maximum number of contracts
+	// that are formed simultaneously. Higher values form a large contract
+	// set faster, but put more load on the gateway and transaction pool.
+	SetContractFormationConcurrency(n int) error
+
+	// SetDownloadRetries sets the number of times that a failed piece
+	// download is retried on the same host before switching hosts. Each
+	// retry waits for a short backoff.
+	SetDownloadRetries(perHost int) error
+
+	// SetUploadWorkers sets the maximum number of pieces that are uploaded
+	// in parallel. A value of 0 uploads to every host at once.
+	SetUploadWorkers(int) error
+
 	// ShareFiles creates a '.sia' file that can be shared with others.
 	ShareFiles(paths []string, shareDest string) error
 
 	// ShareFilesAscii creates an ASCII-encoded '.sia' file.
 	ShareFilesAscii(paths []string) (asciiSia string, err error)
 
+	// UnblockHost removes a host from the renter's blocklist.
+	UnblockHost(pk types.SiaPublicKey) error
+
 	// Upload uploads a file using the input parameters.
 	Upload(FileUploadParams) error
 }
@@ -0,0 +1,130 @@
+package renter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errInvalidFundsAlertThreshold is returned when the funds alert
+	// threshold is not a fraction of the contract's funds.
+	errInvalidFundsAlertThreshold = errors.New("funds alert threshold must be at least 0 and less than 1")
+)
+
+// fundsAlertID returns the key under which the low funds alert for a contract
+// is stored.
+func fundsAlertID(id types.FileContractID) string {
+	return "contractfunds-" + id.String()
+}
+
+// remainingFundsRatio returns the fraction of its initial renter funds that a
+// contract has left to spend.
+func remainingFundsRatio(c modules.RenterContract) float64 {
+	if len(c.FileContract.ValidProofOutputs) == 0 {
+		return 0
+	}
+	initial := c.FileContract.ValidProofOutputs[0].Value
+	if initial.IsZero() {
+		return 0
+	}
+	ratio, _ := new(big.Rat).SetFrac(c.RenterFunds().Big(), initial.Big()).Float64()
+	return ratio
+}
+
+// registerAlert adds an alert to the renter, replacing any existing alert
+// with the same id.
+func (r *Renter) registerAlert(id string, a modules.RenterAlert) {
+	r.alerts[id] = a
+}
+
+// unregisterAlert removes an alert from the renter.
+func (r *Renter) unregisterAlert(id string) {
+	delete(r.alerts, id)
+}
+
+// managedCheckContractFunds raises an alert for every contract whose
+// remaining funds have dropped below the funds alert threshold, and clears the
+// alerts of contracts that have been refunded or that no longer exist.
+func (r *Renter) managedCheckContractFunds() {
+	contracts := r.hostContractor.Contracts()
+
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	active := make(map[string]struct{})
+	for _, c := range contracts {
+		aid := fundsAlertID(c.ID)
+		ratio := remainingFundsRatio(c)
+		if r.fundsAlertThreshold == 0 || ratio >= r.fundsAlertThreshold {
+			continue
+		}
+		active[aid] = struct{}{}
+		r.registerAlert(aid, modules.RenterAlert{
+			Cause: modules.AlertCauseContractFunds,
+			Msg:   fmt.Sprintf("contract with %v has %.1f%% of its funds remaining", c.NetAddress, ratio*100),
+		})
+	}
+	for aid, a := range r.alerts {
+		if _, ok := active[aid]; !ok && a.Cause == modules.AlertCauseContractFunds {
+			r.unregisterAlert(aid)
+		}
+	}
+}
+
+// threadedMonitorContractFunds periodically checks the renter's contracts for
+// low funds.
+func (r *Renter) threadedMonitorContractFunds() {
+	for {
+		if r.tg.Add() != nil {
+			return
+		}
+		r.managedCheckContractFunds()
+		r.tg.Done()
+
+		select {
+		case <-time.After(fundsAlertCheckInterval):
+		case <-r.tg.StopChan():
+			return
+		}
+	}
+}
+
+// Alerts returns the renter's active alerts.
+func (r *Renter) Alerts() []modules.RenterAlert {
+	id := r.mu.RLock()
+	defer r.mu.RUnlock(id)
+	ids := make([]string, 0, len(r.alerts))
+	for aid := range r.alerts {
+		ids = append(ids, aid)
+	}
+	sort.Strings(ids)
+	alerts := make([]modules.RenterAlert, 0, len(ids))
+	for _, aid := range ids {
+		alerts = append(alerts, r.alerts[aid])
+	}
+	return alerts
+}
+
+// SetFundsAlertThreshold sets the fraction of a contract's initial funds
+// below which the renter raises an alert. For example, a threshold of 0.1
+// raises an alert once a contract has spent 90% of its funds. A threshold of 0
+// disables the alert.
+func (r *Renter) SetFundsAlertThreshold(ratio float64) error {
+	if !(ratio >= 0 && ratio < 1) {
+		return errInvalidFundsAlertThreshold
+	}
+	id := r.mu.Lock()
+	r.fundsAlertThreshold = ratio
+	err := r.saveSync()
+	r.mu.Unlock(id)
+	if err != nil {
+		return err
+	}
+	r.managedCheckContractFunds()
+	return nil
+}
@@ -0,0 +1,67 @@
+package renter
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// fundedContract returns a contract that started with 100 siacoins of renter
+// funds and has the provided amount remaining.
+func fundedContract(remaining uint64) modules.RenterContract {
+	var c modules.RenterContract
+	c.ID[0] = 1
+	c.FileContract.ValidProofOutputs = []types.SiacoinOutput{{Value: types.NewCurrency64(100)}, {}}
+	c.LastRevision.NewValidProofOutputs = []types.SiacoinOutput{{Value: types.NewCurrency64(remaining)}, {}}
+	return c
+}
+
+// TestContractFundsAlert checks that an alert is raised when a contract's
+// funds drop below the alert threshold, and cleared once it is refunded.
+func TestContractFundsAlert(t *testing.T) {
+	r := &Renter{
+		alerts:              make(map[string]modules.RenterAlert),
+		fundsAlertThreshold: 0.2,
+		mu:                  sync.New(modules.SafeMutexDelay, 1),
+	}
+
+	// A contract with half of its funds remaining should not raise an alert.
+	r.hostContractor = contractsContractor{contracts: []modules.RenterContract{fundedContract(50)}}
+	r.managedCheckContractFunds()
+	if alerts := r.Alerts(); len(alerts) != 0 {
+		t.Fatal("expected no alerts, got", alerts)
+	}
+
+	// Drawing the balance below the threshold should raise an alert.
+	r.hostContractor = contractsContractor{contracts: []modules.RenterContract{fundedContract(10)}}
+	r.managedCheckContractFunds()
+	if alerts := r.Alerts(); len(alerts) != 1 {
+		t.Fatal("expected 1 alert, got", alerts)
+	} else if alerts[0].Cause != modules.AlertCauseContractFunds {
+		t.Fatal("alert has wrong cause:", alerts[0].Cause)
+	}
+
+	// Refunding the contract should clear the alert.
+	r.hostContractor = contractsContractor{contracts: []modules.RenterContract{fundedContract(80)}}
+	r.managedCheckContractFunds()
+	if alerts := r.Alerts(); len(alerts) != 0 {
+		t.Fatal("expected no alerts, got", alerts)
+	}
+
+	// A threshold of 0 disables the alert.
+	r.hostContractor = contractsContractor{contracts: []modules.RenterContract{fundedContract(0)}}
+	r.fundsAlertThreshold = 0
+	r.managedCheckContractFunds()
+	if alerts := r.Alerts(); len(alerts) != 0 {
+		t.Fatal("expected no alerts, got", alerts)
+	}
+
+	// Thresholds outside of [0, 1) are rejected.
+	for _, ratio := range []float64{-0.1, 1, 2} {
+		if err := r.SetFundsAlertThreshold(ratio); err != errInvalidFundsAlertThreshold {
+			t.Errorf("expected errInvalidFundsAlertThreshold for %v, got %v", ratio, err)
+		}
+	}
+}
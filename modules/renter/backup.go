@@ -0,0 +1,105 @@
+package renter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// contractBackupSpecifier identifies a file as a renter contract backup.
+	contractBackupSpecifier = types.Specifier{'C', 'o', 'n', 't', 'r', 'a', 'c', 't', 'B', 'a', 'c', 'k', 'u', 'p'}
+
+	// errBadContractBackup is returned when the data passed to
+	// ImportContracts is not a contract backup.
+	errBadContractBackup = errors.New("data is not a renter contract backup")
+
+	// errContractBackupKey is returned when a contract backup cannot be
+	// decrypted, usually because it was created by a wallet with a different
+	// seed.
+	errContractBackupKey = errors.New("could not decrypt contract backup; was it created with the same wallet seed?")
+)
+
+// maxContractBackupSize is the maximum size of an encrypted contract backup.
+const maxContractBackupSize = 1 << 30 // 1 GiB
+
+// contractBackupFile is the on-disk format of a contract backup. The contracts
+// are encrypted with a key derived from the wallet's primary seed.
+type contractBackupFile struct {
+	Specifier types.Specifier
+	Contracts crypto.Ciphertext
+}
+
+// contractBackupKey derives the key used to encrypt contract backups from the
+// wallet's primary seed.
+func (r *Renter) contractBackupKey() (crypto.TwofishKey, error) {
+	seed, _, err := r.wallet.PrimarySeed()
+	if err != nil {
+		return crypto.TwofishKey{}, err
+	}
+	return crypto.TwofishKey(crypto.HashAll(seed, contractBackupSpecifier)), nil
+}
+
+// ExportContracts writes an encrypted backup of the renter's contracts to w.
+// The backup can be restored with ImportContracts on any node whose wallet
+// was created from the same seed. The wallet must be unlocked.
+func (r *Renter) ExportContracts(w io.Writer) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	key, err := r.contractBackupKey()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	err = r.hostContractor.ExportContracts(&buf)
+	if err != nil {
+		return err
+	}
+	return encoding.WriteObject(w, contractBackupFile{
+		Specifier: contractBackupSpecifier,
+		Contracts: key.EncryptBytes(buf.Bytes()),
+	})
+}
+
+// ImportContracts reads an encrypted contract backup written by
+// ExportContracts and adds its contracts to the renter. The wallet must be
+// unlocked and created from the same seed as the wallet that made the backup.
+func (r *Renter) ImportContracts(rd io.Reader) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	var backup contractBackupFile
+	err := encoding.ReadObject(rd, &backup, maxContractBackupSize)
+	if err != nil {
+		return err
+	} else if backup.Specifier != contractBackupSpecifier {
+		return errBadContractBackup
+	}
+	key, err := r.contractBackupKey()
+	if err != nil {
+		return err
+	}
+	plaintext, err := key.DecryptBytes(backup.Contracts)
+	if err != nil {
+		return errContractBackupKey
+	}
+	err = r.hostContractor.ImportContracts(bytes.NewReader(plaintext))
+	if err != nil {
+		return err
+	}
+
+	// Spin up workers for the imported contracts.
+	id := r.mu.Lock()
+	r.updateWorkerPool()
+	r.mu.Unlock(id)
+	return nil
+}
@@ -35,6 +35,44 @@ var (
 		Testing:  10 * time.Second,
 	}).(time.Duration)
 
+	// fundsAlertCheckInterval is how often the renter checks its contracts
+	// for low funds.
+	fundsAlertCheckInterval = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 10 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// downloadRetryBackoff is how long a worker that failed to fetch a piece
+	// of a chunk waits before retrying the piece.
+	downloadRetryBackoff = build.Select(build.Var{
+		Dev:      time.Second,
+		Standard: 5 * time.Second,
+		Testing:  20 * time.Millisecond,
+	}).(time.Duration)
+
+	// progressPollInterval is how often the channels returned by
+	// UploadProgress and DownloadProgress are updated.
+	progressPollInterval = build.Select(build.Var{
+		Dev:      time.Second,
+		Standard: time.Second,
+		Testing:  50 * time.Millisecond,
+	}).(time.Duration)
+
+	// rotationPollInterval is how often RotateFileKey checks the progress of
+	// the re-encrypted upload, and rotationTimeout is how long it waits for
+	// the upload to complete.
+	rotationPollInterval = build.Select(build.Var{
+		Dev:      time.Second,
+		Standard: 10 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+	rotationTimeout = build.Select(build.Var{
+		Dev:      10 * time.Minute,
+		Standard: 24 * time.Hour,
+		Testing:  time.Minute,
+	}).(time.Duration)
+
 	// maxChunkCacheSize determines the maximum number of chunks that will be
 	// cached in memory.
 	maxChunkCacheSize = build.Select(build.Var{
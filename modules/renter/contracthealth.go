@@ -0,0 +1,29 @@
+package renter
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// ContractHealth returns a summary of the state of the renter's contracts.
+// Each contract is counted in exactly one state, with failed taking priority
+// over renewing and expiring.
+func (r *Renter) ContractHealth() modules.RenterContractHealth {
+	allowance := r.hostContractor.Allowance()
+	height := r.cs.Height()
+
+	var health modules.RenterContractHealth
+	for _, c := range r.hostContractor.Contracts() {
+		health.RemainingFunds = health.RemainingFunds.Add(c.RenterFunds())
+		switch {
+		case r.hostContractor.IsOffline(c.ID):
+			health.Failed++
+		case height+allowance.RenewWindow < c.EndHeight():
+			health.Good++
+		case allowance.Funds.IsZero():
+			health.Expiring++
+		default:
+			health.Renewing++
+		}
+	}
+	return health
+}
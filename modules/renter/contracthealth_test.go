@@ -0,0 +1,71 @@
+package renter
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// healthContractor is a hostContractor with a fixed set of contracts, some of
+// which are offline.
+type healthContractor struct {
+	contractsContractor
+	allowance modules.Allowance
+	offline   map[types.FileContractID]bool
+}
+
+func (hc healthContractor) Allowance() modules.Allowance           { return hc.allowance }
+func (hc healthContractor) IsOffline(id types.FileContractID) bool { return hc.offline[id] }
+
+// heightCS is a consensus set that only reports its height.
+type heightCS struct {
+	modules.ConsensusSet
+	height types.BlockHeight
+}
+
+func (cs heightCS) Height() types.BlockHeight { return cs.height }
+
+// TestContractHealth checks that ContractHealth counts contracts in each
+// state and sums their remaining funds.
+func TestContractHealth(t *testing.T) {
+	// newContract returns a contract that ends at endHeight with the provided
+	// remaining funds.
+	newContract := func(id byte, endHeight types.BlockHeight, funds uint64) modules.RenterContract {
+		c := fundedContract(funds)
+		c.ID[0] = id
+		c.LastRevision.NewWindowStart = endHeight
+		return c
+	}
+	hc := healthContractor{
+		contractsContractor: contractsContractor{contracts: []modules.RenterContract{
+			newContract(1, 200, 10), // good
+			newContract(2, 300, 20), // good
+			newContract(3, 120, 30), // in the renew window
+			newContract(4, 200, 40), // offline
+			newContract(5, 110, 50), // offline and in the renew window
+		}},
+		allowance: modules.Allowance{Funds: types.NewCurrency64(1000), RenewWindow: 50},
+		offline:   map[types.FileContractID]bool{{4}: true, {5}: true},
+	}
+	r := &Renter{
+		cs:             heightCS{height: 100},
+		hostContractor: hc,
+	}
+
+	health := r.ContractHealth()
+	if health.Good != 2 || health.Renewing != 1 || health.Expiring != 0 || health.Failed != 2 {
+		t.Fatalf("wrong contract counts: %+v", health)
+	}
+	if !health.RemainingFunds.Equals(types.NewCurrency64(150)) {
+		t.Fatal("wrong remaining funds:", health.RemainingFunds)
+	}
+
+	// Without an allowance, contracts in the renew window will expire.
+	hc.allowance.Funds = types.ZeroCurrency
+	r.hostContractor = hc
+	health = r.ContractHealth()
+	if health.Good != 2 || health.Renewing != 0 || health.Expiring != 1 || health.Failed != 2 {
+		t.Fatalf("wrong contract counts: %+v", health)
+	}
+}
@@ -105,9 +105,14 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 	}
 
 	c.mu.RLock()
-	// gather contracts to renew
+	// gather contracts to renew; contracts with blocked hosts are not
+	// renewed, and new contracts are formed in their place
 	var renewSet []modules.RenterContract
 	for _, contract := range c.contracts {
+		if _, blocked := c.blockedHosts[contract.HostPublicKey.String()]; blocked {
+			remaining++
+			continue
+		}
 		renewSet = append(renewSet, contract)
 	}
 
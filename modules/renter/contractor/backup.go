@@ -0,0 +1,81 @@
+package contractor
+
+import (
+	"errors"
+	"io"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// maxContractBackupSize is the maximum size of a contract backup that will be
+// read by ImportContracts. Contracts include their Merkle roots, so a backup
+// of a large contract set can be quite large.
+const maxContractBackupSize = 1 << 30 // 1 GiB
+
+// errNoContractsImported is returned by ImportContracts when none of the
+// contracts in the backup could be imported.
+var errNoContractsImported = errors.New("backup did not contain any contracts that could be imported")
+
+// contractBackup is the serialized form of a single contract in a contract
+// backup. The cached revision is included so that an unconfirmed upload
+// revision can be recovered on the new node.
+type contractBackup struct {
+	Contract       modules.RenterContract
+	CachedRevision cachedRevision
+}
+
+// ExportContracts writes the contractor's current contract set to w. The
+// backup contains the secret keys of the contracts and must be kept private.
+func (c *Contractor) ExportContracts(w io.Writer) error {
+	c.mu.RLock()
+	backups := make([]contractBackup, 0, len(c.contracts))
+	for id, contract := range c.contracts {
+		backups = append(backups, contractBackup{
+			Contract:       contract,
+			CachedRevision: c.cachedRevisions[id],
+		})
+	}
+	c.mu.RUnlock()
+	return encoding.WriteObject(w, backups)
+}
+
+// ImportContracts reads a contract backup written by ExportContracts and adds
+// the contracts to the contractor. Contracts that are already known or that
+// have already ended are skipped.
+func (c *Contractor) ImportContracts(r io.Reader) error {
+	var backups []contractBackup
+	err := encoding.ReadObject(r, &backups, maxContractBackupSize)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var imported int
+	for _, b := range backups {
+		contract := b.Contract
+		if _, exists := c.contracts[contract.ID]; exists {
+			continue
+		} else if c.blockHeight > contract.EndHeight() {
+			continue
+		}
+		c.contracts[contract.ID] = contract
+		// Every contract that has been revised is expected to have a cached
+		// revision. If the backup does not include one, the latest revision
+		// is the best available substitute.
+		if b.CachedRevision.Revision.ParentID == contract.ID {
+			c.cachedRevisions[contract.ID] = b.CachedRevision
+		} else {
+			c.cachedRevisions[contract.ID] = cachedRevision{
+				Revision:    contract.LastRevision,
+				MerkleRoots: contract.MerkleRoots,
+			}
+		}
+		imported++
+	}
+	if imported == 0 && len(backups) > 0 {
+		return errNoContractsImported
+	}
+	return c.saveSync()
+}
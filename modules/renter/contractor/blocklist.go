@@ -0,0 +1,50 @@
+package contractor
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// errHostNotBlocked is returned by UnblockHost if the host is not blocked.
+var errHostNotBlocked = errors.New("host is not blocked")
+
+// BlockHost adds a host to the blocklist. Blocked hosts are never used to
+// form or renew contracts, and existing contracts with them are treated as
+// offline so that their data is migrated to other hosts. The blocklist is
+// persisted.
+func (c *Contractor) BlockHost(pk types.SiaPublicKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blockedHosts[pk.String()] = pk
+	return c.saveSync()
+}
+
+// UnblockHost removes a host from the blocklist.
+func (c *Contractor) UnblockHost(pk types.SiaPublicKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.blockedHosts[pk.String()]; !ok {
+		return errHostNotBlocked
+	}
+	delete(c.blockedHosts, pk.String())
+	return c.saveSync()
+}
+
+// BlockedHosts returns the public keys of the blocked hosts, sorted by their
+// string representation.
+func (c *Contractor) BlockedHosts() []types.SiaPublicKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.blockedHosts))
+	for s := range c.blockedHosts {
+		keys = append(keys, s)
+	}
+	sort.Strings(keys)
+	pks := make([]types.SiaPublicKey, len(keys))
+	for i, s := range keys {
+		pks[i] = c.blockedHosts[s]
+	}
+	return pks
+}
@@ -0,0 +1,119 @@
+package contractor
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// rankedHostDB is a hostDB whose RandomHosts method always returns hosts in
+// descending order of score, skipping excluded hosts. It records the hosts it
+// returns.
+type rankedHostDB struct {
+	stubHostDB
+	hosts    []modules.HostDBEntry
+	returned []modules.HostDBEntry
+}
+
+func (hdb *rankedHostDB) Host(pk types.SiaPublicKey) (modules.HostDBEntry, bool) {
+	for _, h := range hdb.hosts {
+		if h.PublicKey.String() == pk.String() {
+			return h, true
+		}
+	}
+	return modules.HostDBEntry{}, false
+}
+
+func (hdb *rankedHostDB) RandomHosts(n int, exclude []types.SiaPublicKey) (hs []modules.HostDBEntry) {
+	excluded := make(map[string]bool)
+	for _, pk := range exclude {
+		excluded[pk.String()] = true
+	}
+	for _, h := range hdb.hosts {
+		if len(hs) < n && !excluded[h.PublicKey.String()] {
+			hs = append(hs, h)
+		}
+	}
+	hdb.returned = append(hdb.returned, hs...)
+	return hs
+}
+
+// TestBlockHost checks that blocked hosts are never selected for contract
+// formation, and that contracts with blocked hosts are treated as offline.
+func TestBlockHost(t *testing.T) {
+	best := types.SiaPublicKey{Key: []byte("best")}
+	other := types.SiaPublicKey{Key: []byte("other")}
+	hdb := &rankedHostDB{
+		hosts: []modules.HostDBEntry{
+			{PublicKey: best},
+			{PublicKey: other},
+		},
+	}
+	c := &Contractor{
+		hdb:          hdb,
+		persist:      new(memPersist),
+		blockedHosts: make(map[string]types.SiaPublicKey),
+		contracts: map[types.FileContractID]modules.RenterContract{
+			{1}: {ID: types.FileContractID{1}, HostPublicKey: best},
+		},
+	}
+	if c.IsOffline(types.FileContractID{1}) {
+		t.Fatal("contract should not be offline before its host is blocked")
+	}
+
+	if err := c.BlockHost(best); err != nil {
+		t.Fatal(err)
+	}
+	if bh := c.BlockedHosts(); len(bh) != 1 || bh[0].String() != best.String() {
+		t.Fatal("BlockedHosts returned wrong hosts:", bh)
+	}
+
+	// The existing contract should be flagged for migration and excluded
+	// from renewal.
+	if !c.IsOffline(types.FileContractID{1}) {
+		t.Fatal("contract with blocked host should be offline")
+	}
+	if len(c.onlineContracts()) != 0 {
+		t.Fatal("contract with blocked host should not be considered online")
+	}
+
+	// Ask for more contracts than there are hosts, so that formation fails
+	// after host selection. The blocked host must not have been selected,
+	// even though it has the highest score.
+	c.contracts = make(map[types.FileContractID]modules.RenterContract)
+	if _, err := c.managedFormContracts(10, 0, 0); err == nil {
+		t.Fatal("expected formation to fail")
+	}
+	if len(hdb.returned) == 0 {
+		t.Fatal("no hosts were selected")
+	}
+	for _, h := range hdb.returned {
+		if h.PublicKey.String() == best.String() {
+			t.Fatal("blocked host was selected for contract formation")
+		}
+	}
+
+	// The blocklist should be persisted.
+	c.blockedHosts = make(map[string]types.SiaPublicKey)
+	if err := c.load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.blockedHosts[best.String()]; !ok {
+		t.Fatal("blocklist was not persisted")
+	}
+
+	// Unblocking the host should make it available again.
+	if err := c.UnblockHost(best); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UnblockHost(best); err != errHostNotBlocked {
+		t.Fatal("expected errHostNotBlocked, got", err)
+	}
+	hdb.returned = nil
+	c.contracts = make(map[types.FileContractID]modules.RenterContract)
+	c.managedFormContracts(10, 0, 0)
+	if len(hdb.returned) == 0 || hdb.returned[0].PublicKey.String() != best.String() {
+		t.Fatal("unblocked host was not selected")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
 	siasync "github.com/NebulousLabs/Sia/sync"
@@ -50,6 +51,11 @@ type Contractor struct {
 	// won't try to simultaneously edit the contract set.
 	editLock siasync.TryMutex
 
+	// secretKey is the key that new contracts are signed with. Using the
+	// same key for every contract lets hosts recognize the renter, e.g. to
+	// allowlist it.
+	secretKey crypto.SecretKey
+
 	allowance     modules.Allowance
 	blockHeight   types.BlockHeight
 	currentPeriod types.BlockHeight
@@ -60,12 +66,25 @@ type Contractor struct {
 	renewing    map[types.FileContractID]bool // prevent revising during renewal
 	revising    map[types.FileContractID]bool // prevent overlapping revisions
 
+	// formationConcurrency is the maximum number of contracts that are
+	// formed simultaneously. A value of 0 is equivalent to 1.
+	formationConcurrency int
+
+	blockedHosts    map[string]types.SiaPublicKey
 	cachedRevisions map[types.FileContractID]cachedRevision
 	contracts       map[types.FileContractID]modules.RenterContract
 	oldContracts    map[types.FileContractID]modules.RenterContract
 	renewedIDs      map[types.FileContractID]types.FileContractID
 }
 
+// PublicKey returns the public key that the contractor signs new contracts
+// with. Hosts see this key as the renter key of each contract.
+func (c *Contractor) PublicKey() types.SiaPublicKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return types.Ed25519PublicKey(c.secretKey.PublicKey())
+}
+
 // Allowance returns the current allowance.
 func (c *Contractor) Allowance() modules.Allowance {
 	c.mu.RLock()
@@ -170,6 +189,7 @@ func newContractor(cs consensusSet, w wallet, tp transactionPool, hdb hostDB, p
 		tpool:   tp,
 		wallet:  w,
 
+		blockedHosts:    make(map[string]types.SiaPublicKey),
 		cachedRevisions: make(map[types.FileContractID]cachedRevision),
 		contracts:       make(map[types.FileContractID]modules.RenterContract),
 		downloaders:     make(map[types.FileContractID]*hostDownloader),
@@ -192,6 +212,11 @@ func newContractor(cs consensusSet, w wallet, tp transactionPool, hdb hostDB, p
 	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	// Generate the contract signing key if this is a new contractor, or one
+	// that was created before the key was persisted. The key is saved below.
+	if c.secretKey == (crypto.SecretKey{}) {
+		c.secretKey, _ = crypto.GenerateKeyPair()
+	}
 	// Close the persist (provided as a dependency) upon shutdown.
 	c.tg.AfterStop(func() {
 		if err := c.persist.Close(); err != nil {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
@@ -23,6 +24,10 @@ var (
 	// than the amount necessary to store at least one sector
 	ErrInsufficientAllowance = errors.New("allowance is not large enough to cover fees of contract creation")
 	errTooExpensive          = errors.New("host price was too high")
+
+	// errInvalidFormationConcurrency is returned by
+	// SetContractFormationConcurrency if the concurrency is not positive.
+	errInvalidFormationConcurrency = errors.New("contract formation concurrency must be at least 1")
 )
 
 // maxSectors is the estimated maximum number of sectors that the allowance
@@ -98,6 +103,7 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, numSectors uin
 		StartHeight:   c.blockHeight,
 		EndHeight:     endHeight,
 		RefundAddress: uc.UnlockHash(),
+		SecretKey:     c.secretKey,
 	}
 	c.mu.RUnlock()
 
@@ -105,6 +111,15 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, numSectors uin
 	txnBuilder := c.wallet.StartTransaction()
 
 	contract, err := proto.FormContract(params, txnBuilder, c.tpool, c.tg.StopChan())
+	if limit, ok := modules.CollateralLimit(err); ok && limit.Cmp(params.Host.MaxCollateral) < 0 {
+		// return unused outputs to wallet
+		txnBuilder.Drop()
+		// try again within the collateral limit reported by the host
+		c.log.Printf("host %v limits collateral to %v; retrying with less collateral", host.NetAddress, limit)
+		params.Host.MaxCollateral = limit
+		txnBuilder = c.wallet.StartTransaction()
+		contract, err = proto.FormContract(params, txnBuilder, c.tpool, c.tg.StopChan())
+	}
 	if err != nil {
 		txnBuilder.Drop()
 		return modules.RenterContract{}, err
@@ -115,6 +130,76 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, numSectors uin
 	return contract, nil
 }
 
+// formContractsConcurrently calls newContract on hosts, in order, until n
+// contracts have been formed or every host has been tried. At most concurrency
+// calls are in progress at once, and no new call is started if the calls in
+// progress could already form the remaining contracts. To alleviate potential
+// block propagation issues, a formation is not started until
+// contractFormationInterval/concurrency has passed since the last successful
+// formation; failed formations do not delay the next attempt. The formed
+// contracts are returned, along with a description of each failure.
+func formContractsConcurrently(hosts []modules.HostDBEntry, n, concurrency int, newContract func(modules.HostDBEntry) (modules.RenterContract, error)) (contracts []modules.RenterContract, errs []string) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	var inProgress int
+	var lastSuccess time.Time
+	for _, h := range hosts {
+		mu.Lock()
+		for inProgress >= concurrency || (len(contracts) < n && len(contracts)+inProgress >= n) {
+			cond.Wait()
+		}
+		if len(contracts) >= n {
+			mu.Unlock()
+			break
+		}
+		inProgress++
+		wait := lastSuccess.Add(contractFormationInterval / time.Duration(concurrency)).Sub(time.Now())
+		mu.Unlock()
+
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		go func(h modules.HostDBEntry) {
+			contract, err := newContract(h)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("\t%v: %v", h.NetAddress, err))
+			} else {
+				contracts = append(contracts, contract)
+				lastSuccess = time.Now()
+			}
+			inProgress--
+			cond.Broadcast()
+		}(h)
+	}
+
+	// wait for the remaining formations to finish
+	mu.Lock()
+	for inProgress > 0 {
+		cond.Wait()
+	}
+	mu.Unlock()
+	return contracts, errs
+}
+
+// SetContractFormationConcurrency sets the maximum number of contracts that
+// the contractor forms simultaneously. Higher values form a large contract
+// set faster, at the cost of more load on the gateway and transaction pool.
+// The default is 1.
+func (c *Contractor) SetContractFormationConcurrency(n int) error {
+	if n < 1 {
+		return errInvalidFormationConcurrency
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.formationConcurrency = n
+	return c.saveSync()
+}
+
 // managedFormContracts forms contracts with n hosts using the allowance
 // parameters.
 func (c *Contractor) managedFormContracts(n int, numSectors uint64, endHeight types.BlockHeight) ([]modules.RenterContract, error) {
@@ -133,28 +218,21 @@ func (c *Contractor) managedFormContracts(n int, numSectors uint64, endHeight ty
 	for _, contract := range c.contracts {
 		exclude = append(exclude, contract.HostPublicKey)
 	}
+	for _, pk := range c.blockedHosts {
+		exclude = append(exclude, pk)
+	}
 	c.mu.RUnlock()
 	hosts := c.hdb.RandomHosts(nRandomHosts, exclude)
 	if len(hosts) < n {
 		return nil, fmt.Errorf("not enough hosts in hostdb for contract formation, got %v but needed %v", len(hosts), n)
 	}
 
-	var contracts []modules.RenterContract
-	var errs []string
-	for _, h := range hosts {
-		contract, err := c.managedNewContract(h, numSectors, endHeight)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("\t%v: %v", h.NetAddress, err))
-			continue
-		}
-		contracts = append(contracts, contract)
-		if len(contracts) >= n {
-			break
-		}
-		// sleep between forming each contract to alleviate potential block
-		// propagation issues
-		time.Sleep(contractFormationInterval)
-	}
+	c.mu.RLock()
+	concurrency := c.formationConcurrency
+	c.mu.RUnlock()
+	contracts, errs := formContractsConcurrently(hosts, n, concurrency, func(h modules.HostDBEntry) (modules.RenterContract, error) {
+		return c.managedNewContract(h, numSectors, endHeight)
+	})
 	// If we couldn't form any contracts, return an error. Otherwise, just log
 	// the failures.
 	//
@@ -0,0 +1,106 @@
+package contractor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestFormContractsConcurrently checks that formContractsConcurrently never
+// forms more than the allowed number of contracts at once, and that it forms
+// all of the requested contracts even if some formations fail.
+func TestFormContractsConcurrently(t *testing.T) {
+	hosts := make([]modules.HostDBEntry, 20)
+	for i := range hosts {
+		hosts[i].NetAddress = modules.NetAddress(fmt.Sprintf("host%v:1234", i))
+	}
+
+	for _, concurrency := range []int{1, 3, 8} {
+		var mu sync.Mutex
+		var running, maxRunning, calls int
+		newContract := func(h modules.HostDBEntry) (modules.RenterContract, error) {
+			mu.Lock()
+			calls++
+			call := calls
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			// every third formation fails
+			if call%3 == 0 {
+				return modules.RenterContract{}, errors.New("formation failed")
+			}
+			return modules.RenterContract{NetAddress: h.NetAddress}, nil
+		}
+
+		contracts, errs := formContractsConcurrently(hosts, 10, concurrency, newContract)
+		if len(contracts) != 10 {
+			t.Fatalf("concurrency %v: expected 10 contracts, got %v (errors: %v)", concurrency, len(contracts), errs)
+		} else if maxRunning > concurrency {
+			t.Fatalf("concurrency %v: %v formations ran at once", concurrency, maxRunning)
+		} else if concurrency > 1 && maxRunning < 2 {
+			t.Fatalf("concurrency %v: formations did not run concurrently", concurrency)
+		}
+		seen := make(map[modules.NetAddress]bool)
+		for _, c := range contracts {
+			if seen[c.NetAddress] {
+				t.Fatal("formed two contracts with the same host")
+			}
+			seen[c.NetAddress] = true
+		}
+	}
+}
+
+// TestFormContractsConcurrentlyFailures checks that failed formations do not
+// delay the next attempt.
+func TestFormContractsConcurrentlyFailures(t *testing.T) {
+	hosts := make([]modules.HostDBEntry, 20)
+	newContract := func(modules.HostDBEntry) (modules.RenterContract, error) {
+		return modules.RenterContract{}, errors.New("formation failed")
+	}
+	start := time.Now()
+	contracts, errs := formContractsConcurrently(hosts, 10, 1, newContract)
+	if len(contracts) != 0 || len(errs) != len(hosts) {
+		t.Fatalf("expected %v failures, got %v contracts and %v failures", len(hosts), len(contracts), len(errs))
+	}
+	if elapsed := time.Since(start); elapsed >= time.Duration(len(hosts)-1)*contractFormationInterval {
+		t.Fatal("failed formations were spaced out:", elapsed)
+	}
+}
+
+// TestSetContractFormationConcurrency checks that the formation concurrency
+// is validated and persisted.
+func TestSetContractFormationConcurrency(t *testing.T) {
+	c := &Contractor{
+		persist:         new(memPersist),
+		blockedHosts:    make(map[string]types.SiaPublicKey),
+		cachedRevisions: make(map[types.FileContractID]cachedRevision),
+		contracts:       make(map[types.FileContractID]modules.RenterContract),
+		oldContracts:    make(map[types.FileContractID]modules.RenterContract),
+		renewedIDs:      make(map[types.FileContractID]types.FileContractID),
+	}
+	if err := c.SetContractFormationConcurrency(0); err != errInvalidFormationConcurrency {
+		t.Fatal("expected errInvalidFormationConcurrency, got", err)
+	}
+	if err := c.SetContractFormationConcurrency(4); err != nil {
+		t.Fatal(err)
+	}
+	c.formationConcurrency = 0
+	if err := c.load(); err != nil {
+		t.Fatal(err)
+	} else if c.formationConcurrency != 4 {
+		t.Fatal("formation concurrency was not persisted:", c.formationConcurrency)
+	}
+}
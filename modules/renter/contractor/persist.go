@@ -11,26 +11,31 @@ import (
 
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
-	Allowance       modules.Allowance                 `json:"allowance"`
-	BlockHeight     types.BlockHeight                 `json:"blockheight"`
-	CachedRevisions map[string]cachedRevision         `json:"cachedrevisions"`
-	Contracts       map[string]modules.RenterContract `json:"contracts"`
-	CurrentPeriod   types.BlockHeight                 `json:"currentperiod"`
-	LastChange      modules.ConsensusChangeID         `json:"lastchange"`
-	OldContracts    []modules.RenterContract          `json:"oldcontracts"`
-	RenewedIDs      map[string]string                 `json:"renewedids"`
+	Allowance            modules.Allowance                 `json:"allowance"`
+	BlockHeight          types.BlockHeight                 `json:"blockheight"`
+	BlockedHosts         []types.SiaPublicKey              `json:"blockedhosts"`
+	CachedRevisions      map[string]cachedRevision         `json:"cachedrevisions"`
+	Contracts            map[string]modules.RenterContract `json:"contracts"`
+	CurrentPeriod        types.BlockHeight                 `json:"currentperiod"`
+	FormationConcurrency int                               `json:"formationconcurrency"`
+	LastChange           modules.ConsensusChangeID         `json:"lastchange"`
+	OldContracts         []modules.RenterContract          `json:"oldcontracts"`
+	RenewedIDs           map[string]string                 `json:"renewedids"`
+	SecretKey            crypto.SecretKey                  `json:"secretkey"`
 }
 
 // persistData returns the data in the Contractor that will be saved to disk.
 func (c *Contractor) persistData() contractorPersist {
 	data := contractorPersist{
-		Allowance:       c.allowance,
-		BlockHeight:     c.blockHeight,
-		CachedRevisions: make(map[string]cachedRevision),
-		Contracts:       make(map[string]modules.RenterContract),
-		CurrentPeriod:   c.currentPeriod,
-		LastChange:      c.lastChange,
-		RenewedIDs:      make(map[string]string),
+		Allowance:            c.allowance,
+		BlockHeight:          c.blockHeight,
+		CachedRevisions:      make(map[string]cachedRevision),
+		Contracts:            make(map[string]modules.RenterContract),
+		CurrentPeriod:        c.currentPeriod,
+		FormationConcurrency: c.formationConcurrency,
+		LastChange:           c.lastChange,
+		RenewedIDs:           make(map[string]string),
+		SecretKey:            c.secretKey,
 	}
 	for _, rev := range c.cachedRevisions {
 		data.CachedRevisions[rev.Revision.ParentID.String()] = rev
@@ -38,6 +43,9 @@ func (c *Contractor) persistData() contractorPersist {
 	for _, contract := range c.contracts {
 		data.Contracts[contract.ID.String()] = contract
 	}
+	for _, pk := range c.blockedHosts {
+		data.BlockedHosts = append(data.BlockedHosts, pk)
+	}
 	for _, contract := range c.oldContracts {
 		contract.MerkleRoots = []crypto.Hash{} // prevent roots from being saved to disk twice
 		data.OldContracts = append(data.OldContracts, contract)
@@ -57,9 +65,14 @@ func (c *Contractor) load() error {
 	}
 	c.allowance = data.Allowance
 	c.blockHeight = data.BlockHeight
+	for _, pk := range data.BlockedHosts {
+		c.blockedHosts[pk.String()] = pk
+	}
 	for _, rev := range data.CachedRevisions {
 		c.cachedRevisions[rev.Revision.ParentID] = rev
 	}
+	c.formationConcurrency = data.FormationConcurrency
+	c.secretKey = data.SecretKey
 	c.currentPeriod = data.CurrentPeriod
 	if c.currentPeriod == 0 {
 		// COMPATv1.0.4-lts
@@ -48,6 +48,8 @@ func TestSaveLoad(t *testing.T) {
 		{1}: {ID: types.FileContractID{1}, HostPublicKey: types.SiaPublicKey{Key: []byte("bar")}},
 		{2}: {ID: types.FileContractID{2}, HostPublicKey: types.SiaPublicKey{Key: []byte("baz")}},
 	}
+	sk, _ := crypto.GenerateKeyPair()
+	c.secretKey = sk
 
 	// save, clear, and reload
 	err := c.save()
@@ -59,10 +61,14 @@ func TestSaveLoad(t *testing.T) {
 	c.renewedIDs = make(map[types.FileContractID]types.FileContractID)
 	c.cachedRevisions = make(map[types.FileContractID]cachedRevision)
 	c.oldContracts = make(map[types.FileContractID]modules.RenterContract)
+	c.secretKey = crypto.SecretKey{}
 	err = c.load()
 	if err != nil {
 		t.Fatal(err)
 	}
+	if c.secretKey != sk {
+		t.Fatal("secret key was not restored")
+	}
 	// check that all fields were restored
 	_, ok0 := c.contracts[types.FileContractID{0}]
 	_, ok1 := c.contracts[types.FileContractID{1}]
@@ -65,6 +65,15 @@ func (c *Contractor) managedRenew(contract modules.RenterContract, numSectors ui
 		txnBuilder = c.wallet.StartTransaction()
 		newContract, err = proto.Renew(contract, params, txnBuilder, c.tpool, c.tg.StopChan())
 	}
+	if limit, ok := modules.CollateralLimit(err); ok && limit.Cmp(params.Host.MaxCollateral) < 0 {
+		// return unused outputs to wallet
+		txnBuilder.Drop()
+		// try again within the collateral limit reported by the host
+		c.log.Printf("host %v limits collateral to %v; retrying with less collateral", contract.NetAddress, limit)
+		params.Host.MaxCollateral = limit
+		txnBuilder = c.wallet.StartTransaction()
+		newContract, err = proto.Renew(contract, params, txnBuilder, c.tpool, c.tg.StopChan())
+	}
 	if err != nil {
 		txnBuilder.Drop() // return unused outputs to wallet
 		return modules.RenterContract{}, err
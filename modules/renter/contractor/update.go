@@ -10,12 +10,12 @@ import (
 func (c *Contractor) ProcessConsensusChange(cc modules.ConsensusChange) {
 	c.mu.Lock()
 	for _, block := range cc.RevertedBlocks {
-		if block.ID() != types.GenesisID {
+		if !block.IsGenesis() {
 			c.blockHeight--
 		}
 	}
 	for _, block := range cc.AppliedBlocks {
-		if block.ID() != types.GenesisID {
+		if !block.IsGenesis() {
 			c.blockHeight++
 		}
 	}
@@ -33,8 +33,9 @@ func TestProcessConsensusUpdate(t *testing.T) {
 
 	// process 20 blocks; contract should remain
 	cc := modules.ConsensusChange{
-		// just need to increment blockheight by 1
-		AppliedBlocks: []types.Block{{}},
+		// just need to increment blockheight by 1; blocks without a parent
+		// are treated as the genesis block
+		AppliedBlocks: []types.Block{{ParentID: types.BlockID{1}}},
 	}
 	for i := 0; i < 20; i++ {
 		c.ProcessConsensusChange(cc)
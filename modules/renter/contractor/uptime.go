@@ -41,6 +41,11 @@ func (c *Contractor) isOffline(id types.FileContractID) bool {
 	if !ok {
 		return true
 	}
+	// Contracts with blocked hosts are treated as offline, so that they are
+	// not renewed and their data is migrated to other hosts.
+	if _, blocked := c.blockedHosts[contract.HostPublicKey.String()]; blocked {
+		return true
+	}
 	host, ok := c.hdb.Host(contract.HostPublicKey)
 	if !ok {
 		return true
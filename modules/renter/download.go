@@ -10,6 +10,7 @@ import (
 	"io"
 	"math"
 	"os"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -30,6 +31,10 @@ var (
 	errInsufficientHosts  = errors.New("insufficient hosts to recover file")
 	errInsufficientPieces = errors.New("couldn't fetch enough pieces to recover data")
 
+	// errInvalidDownloadRetries is returned when the number of download
+	// retries is negative.
+	errInvalidDownloadRetries = errors.New("number of download retries cannot be negative")
+
 	// maxActiveDownloadPieces determines the maximum number of pieces that are
 	// allowed to be concurrently downloading. More pieces means more
 	// parallelism, but also more RAM usage.
@@ -55,8 +60,14 @@ type (
 		// workerAttempts contains a list of workers that are able to fetch a
 		// piece of the chunk, mapped to an indication of whether or not they
 		// have tried to fetch a piece of the chunk.
-		completedPieces map[uint64][]byte
-		workerAttempts  map[types.FileContractID]bool
+		//
+		// workerFailures counts the failed attempts of each worker to fetch
+		// its piece of the chunk, and workerRetryTimes contains the earliest
+		// time at which a failed worker may retry its piece.
+		completedPieces  map[uint64][]byte
+		workerAttempts   map[types.FileContractID]bool
+		workerFailures   map[types.FileContractID]int
+		workerRetryTimes map[types.FileContractID]time.Time
 	}
 
 	// A download is a file download that has been queued by the renter.
@@ -69,6 +80,10 @@ type (
 		offset             uint64
 		length             uint64
 
+		// priority determines the order in which the chunks of queued
+		// downloads are scheduled. Higher values are scheduled first.
+		priority int
+
 		// Timestamp information.
 		completeTime time.Time
 		startTime    time.Time
@@ -242,23 +257,8 @@ func (cd *chunkDownload) recoverChunk() error {
 		return build.ComposeErrors(errPrevErr, prevErr)
 	}
 
-	// Decrypt the chunk pieces.
-	for i := range chunk {
-		// Skip pieces that were not downloaded.
-		if chunk[i] == nil {
-			continue
-		}
-
-		// Decrypt the piece.
-		key := deriveKey(cd.download.masterKey, cd.index, uint64(i))
-		decryptedPiece, err := key.DecryptBytes(chunk[i])
-		if err != nil {
-			return build.ExtendErr("unable to decrypt piece", err)
-		}
-		chunk[i] = decryptedPiece
-	}
-
-	// Recover the chunk into a byte slice.
+	// Recover the chunk into a byte slice. The pieces were decrypted and
+	// verified as they arrived.
 	recoverWriter := new(bytes.Buffer)
 	recoverSize := cd.download.chunkSize
 	if cd.index == cd.download.numChunks-1 && cd.download.fileSize%cd.download.chunkSize != 0 {
@@ -349,16 +349,32 @@ func (r *Renter) addDownloadToChunkQueue(d *download) {
 			download: d,
 			index:    uint64(i),
 
-			completedPieces: make(map[uint64][]byte),
-			workerAttempts:  make(map[types.FileContractID]bool),
+			completedPieces:  make(map[uint64][]byte),
+			workerAttempts:   make(map[types.FileContractID]bool),
+			workerFailures:   make(map[types.FileContractID]int),
+			workerRetryTimes: make(map[types.FileContractID]time.Time),
 		}
 		for fcid := range d.pieceSet[i] {
 			cd.workerAttempts[fcid] = false
 		}
-		r.chunkQueue = append(r.chunkQueue, cd)
+		r.chunkQueue = insertChunkByPriority(r.chunkQueue, cd)
 	}
 }
 
+// insertChunkByPriority inserts a chunk into the queue after all chunks of
+// equal or higher priority, keeping the queue sorted from highest to lowest
+// priority. Chunks of equal priority are kept in the order they were queued.
+func insertChunkByPriority(queue []*chunkDownload, cd *chunkDownload) []*chunkDownload {
+	i := len(queue)
+	for i > 0 && queue[i-1].download.priority < cd.download.priority {
+		i--
+	}
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = cd
+	return queue
+}
+
 // downloadIteration performs one iteration of the download loop.
 func (r *Renter) managedDownloadIteration(ds *downloadState) {
 	// Check for sleep and break conditions.
@@ -419,6 +435,11 @@ func (r *Renter) managedDownloadIteration(ds *downloadState) {
 // managedScheduleIncompleteChunks also checks wheter a chunk is unable to be
 // completed.
 func (r *Renter) managedScheduleIncompleteChunks(ds *downloadState) {
+	// Give higher priority chunks the first pick of the available workers.
+	sort.SliceStable(ds.incompleteChunks, func(i, j int) bool {
+		return ds.incompleteChunks[i].download.priority > ds.incompleteChunks[j].download.priority
+	})
+
 	var newIncompleteChunks []*chunkDownload
 loop:
 	for _, incompleteChunk := range ds.incompleteChunks {
@@ -439,7 +460,13 @@ loop:
 		}
 
 		// Try to find a worker that is able to pick up the slack on the
-		// incomplete download from the set of available workers.
+		// incomplete download from the set of available workers. A worker
+		// that failed to fetch its piece of this chunk is retried once its
+		// backoff has passed, before switching hosts. Other workers are only
+		// used if no failed worker is waiting to retry; workers that exhaust
+		// their retries are put on cooldown and are no longer available.
+		best := -1
+		retrying, waiting := false, false
 		for i, worker := range ds.availableWorkers {
 			scheduled, exists := incompleteChunk.workerAttempts[worker.contractID]
 			if scheduled || !exists {
@@ -448,12 +475,33 @@ loop:
 				// piece for this chunk.
 				continue
 			}
-
-			piece, exists := incompleteChunk.download.pieceSet[incompleteChunk.index][worker.contractID]
-			if !exists {
+			if _, exists := incompleteChunk.download.pieceSet[incompleteChunk.index][worker.contractID]; !exists {
 				continue
 			}
-
+			failures := incompleteChunk.workerFailures[worker.contractID]
+			if failures == 0 {
+				if best == -1 {
+					best = i
+				}
+				continue
+			}
+			if time.Now().Before(incompleteChunk.workerRetryTimes[worker.contractID]) {
+				waiting = true
+				continue
+			}
+			if !retrying || failures > incompleteChunk.workerFailures[ds.availableWorkers[best].contractID] {
+				best = i
+				retrying = true
+			}
+		}
+		if waiting && !retrying {
+			// Wait for the failed worker's backoff to pass instead of
+			// switching hosts.
+			best = -1
+		}
+		if best != -1 {
+			worker := ds.availableWorkers[best]
+			piece := incompleteChunk.download.pieceSet[incompleteChunk.index][worker.contractID]
 			dw := downloadWork{
 				dataRoot:      piece.MerkleRoot,
 				pieceIndex:    piece.Piece,
@@ -461,7 +509,7 @@ loop:
 				resultChan:    ds.resultChan,
 			}
 			incompleteChunk.workerAttempts[worker.contractID] = true
-			ds.availableWorkers = append(ds.availableWorkers[:i], ds.availableWorkers[i+1:]...)
+			ds.availableWorkers = append(ds.availableWorkers[:best], ds.availableWorkers[best+1:]...)
 			ds.activeWorkers[worker.contractID] = struct{}{}
 			select {
 			case worker.priorityDownloadChan <- dw:
@@ -487,6 +535,15 @@ loop:
 			}
 		}
 
+		// Likewise, keep the chunk if a failed worker is waiting to retry its
+		// piece.
+		for fcid, retryTime := range incompleteChunk.workerRetryTimes {
+			if !incompleteChunk.workerAttempts[fcid] && time.Now().Before(retryTime) {
+				newIncompleteChunks = append(newIncompleteChunks, incompleteChunk)
+				continue loop
+			}
+		}
+
 		// TODO: Determine whether any of the workers not in the available set
 		// or the active set is able to pick up the slack. Verify that they are
 		// safe to be scheduled, and then schedule them if so.
@@ -551,8 +608,18 @@ func (r *Renter) managedScheduleNewChunks(ds *downloadState) {
 // managedWaitOnDownloadWork will wait for workers to return after attempting to
 // download a piece.
 func (r *Renter) managedWaitOnDownloadWork(ds *downloadState) {
-	// If there are no workers performing work, return early.
+	// If there are no workers performing work, return early. If chunks are
+	// waiting for a failed worker to retry, sleep for the retry backoff first
+	// so that the download loop does not spin.
 	if len(ds.activeWorkers) == 0 {
+		if len(ds.incompleteChunks) > 0 {
+			select {
+			case <-r.tg.StopChan():
+			case d := <-r.newDownloads:
+				r.addDownloadToChunkQueue(d)
+			case <-time.After(downloadRetryBackoff):
+			}
+		}
 		return
 	}
 
@@ -575,22 +642,52 @@ func (r *Renter) managedWaitOnDownloadWork(ds *downloadState) {
 	// Fetch the corresponding worker.
 	id := r.mu.RLock()
 	worker, exists := r.workerPool[workerID]
+	retries := r.downloadRetries
 	r.mu.RUnlock(id)
 	if !exists {
 		return
 	}
 
-	// Check for an error.
+	// Check for an error. Errors are assumed to be transient until the
+	// worker has failed more than the allowed number of retries, after which
+	// the worker is put on cooldown. Until then, the worker may retry its
+	// piece after a short backoff.
 	cd := finishedDownload.chunkDownload
 	if finishedDownload.err != nil {
 		r.log.Debugln("Error when downloading a piece:", finishedDownload.err)
+		if cd.workerFailures == nil {
+			cd.workerFailures = make(map[types.FileContractID]int)
+		}
+		if cd.workerRetryTimes == nil {
+			cd.workerRetryTimes = make(map[types.FileContractID]time.Time)
+		}
+		cd.workerFailures[workerID]++
+		if cd.workerFailures[workerID] <= retries {
+			cd.workerAttempts[workerID] = false
+			cd.workerRetryTimes[workerID] = time.Now().Add(downloadRetryBackoff)
+		} else {
+			worker.recentDownloadFailure = time.Now()
+		}
+		ds.incompleteChunks = append(ds.incompleteChunks, cd)
+		return
+	}
+
+	// Verify the piece as soon as it arrives. Pieces are encrypted with an
+	// authenticated cipher, so a corrupt piece fails to decrypt. Instead of
+	// failing the whole chunk at recovery time, discard the piece and
+	// reschedule the chunk so that the piece is fetched from another host.
+	key := deriveKey(cd.download.masterKey, cd.index, finishedDownload.pieceIndex)
+	piece, err := crypto.DecryptAEAD(key, finishedDownload.data, nil)
+	if err != nil {
+		r.log.Printf("WARN: piece %v of chunk %v from contract %v is corrupt", finishedDownload.pieceIndex, cd.index, workerID)
+		atomic.AddUint64(&r.atomicCorruptPieces, 1)
 		worker.recentDownloadFailure = time.Now()
 		ds.incompleteChunks = append(ds.incompleteChunks, cd)
 		return
 	}
 
 	// Add this returned piece to the appropriate chunk.
-	cd.completedPieces[finishedDownload.pieceIndex] = finishedDownload.data
+	cd.completedPieces[finishedDownload.pieceIndex] = piece
 	atomic.AddUint64(&cd.download.atomicDataReceived, cd.download.reportedPieceSize)
 
 	// If the chunk has completed, perform chunk recovery.
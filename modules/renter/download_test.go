@@ -0,0 +1,326 @@
+package renter
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestDownloadPriorityScheduling checks that the chunks of a high-priority
+// download that is queued after several low-priority downloads are scheduled
+// first when there are only enough resources to download one chunk at a time.
+func TestDownloadPriorityScheduling(t *testing.T) {
+	// Use an erasure code that requires every available piece slot, so that
+	// only one chunk can be active at a time.
+	rsc, err := NewRSCode(maxActiveDownloadPieces, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newFile("foo", rsc, 64, 64*uint64(maxActiveDownloadPieces))
+
+	// newQueuedDownload creates a single-chunk download with the given
+	// priority and adds it to the chunk queue.
+	r := new(Renter)
+	newQueuedDownload := func(priority int) *download {
+		d := newDownload(f, NewDownloadBufferWriter(f.size))
+		d.finishedChunks[0] = false
+		d.priority = priority
+		r.addDownloadToChunkQueue(d)
+		return d
+	}
+	var lows []*download
+	for i := 0; i < 3; i++ {
+		lows = append(lows, newQueuedDownload(0))
+	}
+	high := newQueuedDownload(1)
+
+	ds := &downloadState{
+		activeWorkers: make(map[types.FileContractID]struct{}),
+	}
+	r.managedScheduleNewChunks(ds)
+	if len(ds.incompleteChunks) == 0 {
+		t.Fatal("no chunks were scheduled")
+	}
+	for _, cd := range ds.incompleteChunks {
+		if cd.download != high {
+			t.Fatal("high-priority download was not scheduled first")
+		}
+	}
+
+	// Free up the resources after each chunk. The low-priority downloads
+	// should be scheduled in the order they were queued.
+	for i, low := range lows {
+		ds.activePieces = 0
+		ds.incompleteChunks = nil
+		r.managedScheduleNewChunks(ds)
+		if len(ds.incompleteChunks) == 0 || ds.incompleteChunks[0].download != low {
+			t.Fatalf("low-priority download %v was not scheduled in order", i)
+		}
+	}
+}
+
+// TestInsertChunkByPriority checks that insertChunkByPriority keeps the queue
+// ordered by priority and preserves the queueing order within a priority.
+func TestInsertChunkByPriority(t *testing.T) {
+	var queue []*chunkDownload
+	priorities := []int{0, 2, 1, 2, 0, 1}
+	for i, p := range priorities {
+		queue = insertChunkByPriority(queue, &chunkDownload{
+			download: &download{priority: p},
+			index:    uint64(i),
+		})
+	}
+	expected := []uint64{1, 3, 2, 5, 0, 4}
+	for i, cd := range queue {
+		if cd.index != expected[i] {
+			t.Fatalf("queue position %v: expected chunk %v, got %v", i, expected[i], cd.index)
+		}
+	}
+}
+
+// TestCorruptPieceRerequested checks that a corrupt piece is detected as soon
+// as it arrives, and that the chunk is rescheduled on another host.
+func TestCorruptPieceRerequested(t *testing.T) {
+	rsc, err := NewRSCode(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newFile("foo", rsc, 64, 128)
+	d := newDownload(f, NewDownloadBufferWriter(f.size))
+	d.finishedChunks[0] = false
+
+	// Contract A holds piece 0 and returns corrupt data; contract B holds
+	// piece 1.
+	badID, goodID := types.FileContractID{1}, types.FileContractID{2}
+	d.pieceSet = map[uint64]map[types.FileContractID]pieceData{
+		0: {
+			badID:  {Chunk: 0, Piece: 0},
+			goodID: {Chunk: 0, Piece: 1},
+		},
+	}
+	cd := &chunkDownload{
+		download:        d,
+		completedPieces: make(map[uint64][]byte),
+		workerAttempts:  map[types.FileContractID]bool{badID: true, goodID: false},
+	}
+	badWorker := &worker{contractID: badID}
+	goodWorker := &worker{contractID: goodID, priorityDownloadChan: make(chan downloadWork, 1)}
+	r := &Renter{
+		log:        persist.NewLogger(ioutil.Discard),
+		mu:         sync.New(modules.SafeMutexDelay, 1),
+		tg:         new(sync.ThreadGroup),
+		workerPool: map[types.FileContractID]*worker{badID: badWorker, goodID: goodWorker},
+	}
+	ds := &downloadState{
+		activePieces:  1,
+		activeWorkers: map[types.FileContractID]struct{}{badID: {}},
+		resultChan:    make(chan finishedDownload, 1),
+	}
+
+	// Return a corrupt piece from contract A.
+	piece := fastrand.Bytes(64)
+	corrupt := crypto.EncryptAEAD(deriveKey(f.masterKey, 0, 0), piece, nil)
+	corrupt[len(corrupt)-1] ^= 1
+	ds.resultChan <- finishedDownload{cd, corrupt, nil, 0, badID}
+	r.managedWaitOnDownloadWork(ds)
+	if n := r.CorruptPiecesDetected(); n != 1 {
+		t.Fatal("expected 1 corrupt piece, got", n)
+	}
+	if len(cd.completedPieces) != 0 {
+		t.Fatal("corrupt piece was added to the chunk")
+	}
+	if len(ds.incompleteChunks) != 1 || ds.incompleteChunks[0] != cd {
+		t.Fatal("chunk was not rescheduled")
+	}
+
+	// The chunk should be scheduled on the other host.
+	ds.availableWorkers = []*worker{goodWorker}
+	r.managedScheduleIncompleteChunks(ds)
+	select {
+	case dw := <-goodWorker.priorityDownloadChan:
+		if dw.pieceIndex != 1 || dw.chunkDownload != cd {
+			t.Fatal("wrong piece was requested from the other host")
+		}
+	default:
+		t.Fatal("piece was not requested from the other host")
+	}
+
+	// A valid piece from contract B should be decrypted and accepted.
+	piece = fastrand.Bytes(64)
+	ds.resultChan <- finishedDownload{cd, crypto.EncryptAEAD(deriveKey(f.masterKey, 0, 1), piece, nil), nil, 1, goodID}
+	r.managedWaitOnDownloadWork(ds)
+	if !bytes.Equal(cd.completedPieces[1], piece) {
+		t.Fatal("valid piece was not added to the chunk")
+	}
+	if n := r.CorruptPiecesDetected(); n != 1 {
+		t.Fatal("expected 1 corrupt piece, got", n)
+	}
+}
+
+// TestDownloadRetries checks that a failed piece is retried on the same host
+// after a backoff until the retry budget is exhausted, after which the piece
+// is fetched from another host.
+func TestDownloadRetries(t *testing.T) {
+	rsc, err := NewRSCode(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newFile("foo", rsc, 64, 128)
+	flakyID, goodID := types.FileContractID{1}, types.FileContractID{2}
+
+	// setup creates a renter with a flaky worker and, if withGood is set, a
+	// healthy worker, along with a single-chunk download that both of them
+	// hold a piece of.
+	type setup struct {
+		r           *Renter
+		ds          *downloadState
+		cd          *chunkDownload
+		flaky, good *worker
+	}
+	newSetup := func(retries int, withGood bool) setup {
+		d := newDownload(f, NewDownloadBufferWriter(f.size))
+		d.finishedChunks[0] = false
+		d.pieceSet = map[uint64]map[types.FileContractID]pieceData{
+			0: {flakyID: {Chunk: 0, Piece: 0}},
+		}
+		cd := &chunkDownload{
+			download:         d,
+			completedPieces:  make(map[uint64][]byte),
+			workerAttempts:   map[types.FileContractID]bool{flakyID: false},
+			workerFailures:   make(map[types.FileContractID]int),
+			workerRetryTimes: make(map[types.FileContractID]time.Time),
+		}
+		flaky := &worker{contractID: flakyID, priorityDownloadChan: make(chan downloadWork, 1)}
+		good := &worker{contractID: goodID, priorityDownloadChan: make(chan downloadWork, 1)}
+		pool := map[types.FileContractID]*worker{flakyID: flaky}
+		if withGood {
+			d.pieceSet[0][goodID] = pieceData{Chunk: 0, Piece: 1}
+			cd.workerAttempts[goodID] = false
+			pool[goodID] = good
+		}
+		return setup{
+			r: &Renter{
+				log:             persist.NewLogger(ioutil.Discard),
+				mu:              sync.New(modules.SafeMutexDelay, 1),
+				tg:              new(sync.ThreadGroup),
+				workerPool:      pool,
+				downloadRetries: retries,
+			},
+			ds: &downloadState{
+				activePieces:     1,
+				activeWorkers:    make(map[types.FileContractID]struct{}),
+				incompleteChunks: []*chunkDownload{cd},
+				resultChan:       make(chan finishedDownload, 1),
+			},
+			cd:    cd,
+			flaky: flaky,
+			good:  good,
+		}
+	}
+
+	// schedule makes every worker that is not on cooldown available and
+	// schedules the incomplete chunks, returning the worker that received
+	// work, if any.
+	schedule := func(s setup) (*worker, downloadWork) {
+		s.ds.availableWorkers = nil
+		for _, w := range []*worker{s.flaky, s.good} {
+			if _, ok := s.r.workerPool[w.contractID]; ok && w.recentDownloadFailure.IsZero() {
+				s.ds.availableWorkers = append(s.ds.availableWorkers, w)
+			}
+		}
+		s.r.managedScheduleIncompleteChunks(s.ds)
+		select {
+		case dw := <-s.flaky.priorityDownloadChan:
+			return s.flaky, dw
+		case dw := <-s.good.priorityDownloadChan:
+			return s.good, dw
+		default:
+			return nil, downloadWork{}
+		}
+	}
+	fail := func(s setup, w *worker, dw downloadWork) {
+		s.ds.resultChan <- finishedDownload{s.cd, nil, errors.New("host failure"), dw.pieceIndex, w.contractID}
+		s.r.managedWaitOnDownloadWork(s.ds)
+	}
+
+	// A failed piece should be retried on the same host after the backoff,
+	// even though a healthy host is available. The healthy host should only
+	// be used once the flaky host has exhausted its retries.
+	s := newSetup(1, true)
+	if w, dw := schedule(s); w != s.flaky {
+		t.Fatal("expected the chunk to be scheduled on the first worker")
+	} else {
+		fail(s, w, dw)
+	}
+	if w, _ := schedule(s); w != nil {
+		t.Fatal("switched hosts before the failed host's backoff passed")
+	}
+	time.Sleep(downloadRetryBackoff)
+	if w, dw := schedule(s); w != s.flaky {
+		t.Fatal("failed host was not retried before switching hosts")
+	} else {
+		fail(s, w, dw)
+	}
+	if s.flaky.recentDownloadFailure.IsZero() {
+		t.Fatal("host should be on cooldown after exhausting its retries")
+	}
+	if w, _ := schedule(s); w != s.good {
+		t.Fatal("expected the piece to be fetched from the healthy host")
+	}
+
+	// When only the flaky host holds a piece, it should be retried after the
+	// backoff, and the chunk should be kept in the meantime.
+	s = newSetup(3, false)
+	for attempt := 0; attempt < 3; attempt++ {
+		w, dw := schedule(s)
+		if w != s.flaky {
+			t.Fatal("chunk was not scheduled on attempt", attempt)
+		}
+		fail(s, w, dw)
+		if w, _ := schedule(s); w != nil {
+			t.Fatal("failed host was retried before the backoff passed")
+		} else if len(s.ds.incompleteChunks) != 1 || s.cd.download.downloadComplete {
+			t.Fatal("chunk should be kept while the failed host backs off")
+		}
+		time.Sleep(downloadRetryBackoff)
+	}
+	w, dw := schedule(s)
+	if w != s.flaky {
+		t.Fatal("chunk was not scheduled after the backoff")
+	}
+	piece := fastrand.Bytes(64)
+	s.ds.resultChan <- finishedDownload{s.cd, crypto.EncryptAEAD(deriveKey(f.masterKey, 0, dw.pieceIndex), piece, nil), nil, dw.pieceIndex, flakyID}
+	s.r.managedWaitOnDownloadWork(s.ds)
+	if !bytes.Equal(s.cd.completedPieces[dw.pieceIndex], piece) {
+		t.Fatal("piece was not added to the chunk")
+	}
+
+	// Once the budget is exhausted, the host should be put on cooldown, and
+	// the download should fail if no other host can serve the chunk.
+	s = newSetup(1, false)
+	for attempt := 0; attempt < 2; attempt++ {
+		time.Sleep(downloadRetryBackoff)
+		w, dw := schedule(s)
+		if w != s.flaky {
+			t.Fatal("chunk was not scheduled on attempt", attempt)
+		}
+		fail(s, w, dw)
+	}
+	if s.flaky.recentDownloadFailure.IsZero() {
+		t.Fatal("host should be on cooldown after exhausting its retries")
+	}
+	if w, _ := schedule(s); w != nil {
+		t.Fatal("host on cooldown should not be scheduled")
+	} else if s.cd.download.downloadErr != errInsufficientHosts {
+		t.Fatal("expected errInsufficientHosts, got", s.cd.download.downloadErr)
+	}
+}
@@ -12,7 +12,19 @@ import (
 
 // Download performs a file download using the passed parameters.
 func (r *Renter) Download(p modules.RenterDownloadParameters) error {
+	return r.DownloadWithPriority(p, 0)
+}
+
+// DownloadWithPriority performs a file download using the passed parameters.
+// Chunks belonging to downloads with a higher priority are scheduled before
+// chunks belonging to downloads with a lower priority.
+func (r *Renter) DownloadWithPriority(p modules.RenterDownloadParameters, priority int) error {
 	// lookup the file associated with the nickname.
+	siaPath, err := r.managedResolveSiaPath(p.Siapath)
+	if err != nil {
+		return err
+	}
+	p.Siapath = siaPath
 	lockID := r.mu.RLock()
 	file, exists := r.files[p.Siapath]
 	r.mu.RUnlock(lockID)
@@ -70,6 +82,7 @@ func (r *Renter) Download(p modules.RenterDownloadParameters) error {
 
 	// Create the download object and add it to the queue.
 	d := r.newSectionDownload(file, dw, currentContracts, p.Offset, p.Length)
+	d.priority = priority
 
 	lockID = r.mu.Lock()
 	r.downloadQueue = append(r.downloadQueue, d)
@@ -112,3 +125,10 @@ func (r *Renter) DownloadQueue() []modules.DownloadInfo {
 	}
 	return downloads
 }
+
+// CorruptPiecesDetected returns the number of downloaded pieces that failed
+// verification. Each corrupt piece is discarded and requested from another
+// host.
+func (r *Renter) CorruptPiecesDetected() uint64 {
+	return atomic.LoadUint64(&r.atomicCorruptPieces)
+}
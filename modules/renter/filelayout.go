@@ -0,0 +1,71 @@
+package renter
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// FileLayout returns the hosts storing each piece of each chunk of the file at
+// siaPath. It is intended for debugging and for tools that inspect the
+// placement of a file's data.
+func (r *Renter) FileLayout(siaPath string) (modules.FileLayout, error) {
+	siaPath, err := r.managedResolveSiaPath(siaPath)
+	if err != nil {
+		return modules.FileLayout{}, err
+	}
+	lockID := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	r.mu.RUnlock(lockID)
+	if !exists {
+		return modules.FileLayout{}, ErrUnknownPath
+	}
+
+	// Contracts may have been renewed since the pieces were uploaded, so the
+	// host of a piece is found using the most recent renewal of its contract.
+	hostKeys := make(map[types.FileContractID]types.SiaPublicKey)
+	for _, c := range r.hostContractor.Contracts() {
+		hostKeys[c.ID] = c.HostPublicKey
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	layout := modules.FileLayout{
+		SiaPath: f.name,
+		Chunks:  make([]modules.ChunkLayout, f.numChunks()),
+	}
+	for i := range layout.Chunks {
+		layout.Chunks[i].Index = uint64(i)
+	}
+	for _, fc := range f.contracts {
+		hostKey := hostKeys[r.hostContractor.ResolveID(fc.ID)]
+		offline := r.hostContractor.IsOffline(fc.ID)
+		for _, p := range fc.Pieces {
+			if p.Chunk >= uint64(len(layout.Chunks)) {
+				continue
+			}
+			layout.Chunks[p.Chunk].Pieces = append(layout.Chunks[p.Chunk].Pieces, modules.PieceLayout{
+				Index:         p.Piece,
+				HostPublicKey: hostKey,
+				NetAddress:    fc.IP,
+				MerkleRoot:    p.MerkleRoot,
+				Offline:       offline,
+			})
+		}
+	}
+
+	// The contracts are stored in a map, so sort the pieces to give a
+	// deterministic layout.
+	for _, chunk := range layout.Chunks {
+		pieces := chunk.Pieces
+		sort.Slice(pieces, func(i, j int) bool {
+			if pieces[i].Index != pieces[j].Index {
+				return pieces[i].Index < pieces[j].Index
+			}
+			return bytes.Compare(pieces[i].HostPublicKey.Key, pieces[j].HostPublicKey.Key) < 0
+		})
+	}
+	return layout, nil
+}
@@ -0,0 +1,149 @@
+package renter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/contractor"
+	"github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// layoutEditor is a contractor.Editor that accepts every upload.
+type layoutEditor struct {
+	contractor.Editor
+	id types.FileContractID
+}
+
+func (e layoutEditor) Upload(data []byte) (crypto.Hash, error) { return crypto.MerkleRoot(data), nil }
+func (e layoutEditor) Address() modules.NetAddress             { return modules.NetAddress(e.id.String()) }
+func (e layoutEditor) EndHeight() types.BlockHeight            { return 0 }
+func (e layoutEditor) Close() error                            { return nil }
+
+// layoutContractor is a hostContractor with a fixed set of contracts, some of
+// which are offline, that hands out layoutEditors.
+type layoutContractor struct {
+	contractsContractor
+	offline map[types.FileContractID]bool
+}
+
+func (lc layoutContractor) IsOffline(id types.FileContractID) bool                 { return lc.offline[id] }
+func (lc layoutContractor) ResolveID(id types.FileContractID) types.FileContractID { return id }
+func (lc layoutContractor) Editor(id types.FileContractID, _ <-chan struct{}) (contractor.Editor, error) {
+	return layoutEditor{id: id}, nil
+}
+
+// TestFileLayout checks that FileLayout reports the hosts that a file was
+// uploaded to, and that it reflects pieces moved by a repair.
+func TestFileLayout(t *testing.T) {
+	persistDir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(persistDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a file with two chunks of two pieces each, spread across two
+	// hosts. A third host has a contract but no pieces.
+	hosts := []types.SiaPublicKey{
+		{Key: []byte("host0")},
+		{Key: []byte("host1")},
+		{Key: []byte("host2")},
+	}
+	var contracts []modules.RenterContract
+	for i, pk := range hosts {
+		contracts = append(contracts, modules.RenterContract{
+			ID:            types.FileContractID{byte(i)},
+			HostPublicKey: pk,
+		})
+	}
+	lc := layoutContractor{
+		contractsContractor: contractsContractor{contracts: contracts},
+		offline:             make(map[types.FileContractID]bool),
+	}
+	rsc, _ := NewRSCode(1, 2)
+	f := newFile("foo", rsc, 64, 128)
+	for i := 0; i < 2; i++ {
+		id := contracts[i].ID
+		f.contracts[id] = fileContract{
+			ID: id,
+			Pieces: []pieceData{
+				{Chunk: 0, Piece: uint64(i)},
+				{Chunk: 1, Piece: uint64(i)},
+			},
+		}
+	}
+	r := &Renter{
+		files:          map[string]*file{"foo": f},
+		hostContractor: lc,
+		mu:             sync.New(modules.SafeMutexDelay, 1),
+		persistDir:     persistDir,
+		tg:             new(sync.ThreadGroup),
+	}
+
+	// checkPiece checks that a piece is stored on exactly the given hosts.
+	checkPiece := func(layout modules.FileLayout, chunk, piece uint64, want ...types.SiaPublicKey) {
+		var got []types.SiaPublicKey
+		for _, p := range layout.Chunks[chunk].Pieces {
+			if p.Index == piece {
+				got = append(got, p.HostPublicKey)
+			}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("chunk %v piece %v: expected %v hosts, got %v", chunk, piece, len(want), got)
+		}
+		for i := range got {
+			if got[i].String() != want[i].String() {
+				t.Fatalf("chunk %v piece %v: expected host %v, got %v", chunk, piece, want[i], got[i])
+			}
+		}
+	}
+
+	layout, err := r.FileLayout("foo")
+	if err != nil {
+		t.Fatal(err)
+	} else if layout.SiaPath != "foo" || len(layout.Chunks) != 2 {
+		t.Fatalf("wrong layout: %+v", layout)
+	}
+	for chunk := uint64(0); chunk < 2; chunk++ {
+		checkPiece(layout, chunk, 0, hosts[0])
+		checkPiece(layout, chunk, 1, hosts[1])
+	}
+
+	// Take the second host offline and repair the first chunk, moving its
+	// piece to the third host.
+	lc.offline[contracts[1].ID] = true
+	w := &worker{contractID: contracts[2].ID, renter: r}
+	resultChan := make(chan finishedUpload, 1)
+	w.upload(uploadWork{
+		chunkID:    chunkID{index: 0, filename: "foo"},
+		data:       make([]byte, f.pieceSize),
+		file:       f,
+		pieceIndex: 1,
+		resultChan: resultChan,
+	})
+	if res := <-resultChan; res.err != nil {
+		t.Fatal(res.err)
+	}
+
+	layout, err = r.FileLayout("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPiece(layout, 0, 0, hosts[0])
+	checkPiece(layout, 0, 1, hosts[1], hosts[2])
+	checkPiece(layout, 1, 1, hosts[1])
+	for _, p := range layout.Chunks[0].Pieces {
+		if p.Offline != (p.HostPublicKey.String() == hosts[1].String()) {
+			t.Fatalf("wrong offline status for piece on %v", p.HostPublicKey)
+		}
+	}
+	if layout.Chunks[0].Pieces[2].MerkleRoot != crypto.MerkleRoot(make([]byte, f.pieceSize)) {
+		t.Fatal("repaired piece has the wrong Merkle root")
+	}
+
+	if _, err := r.FileLayout("bar"); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+}
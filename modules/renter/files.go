@@ -179,6 +179,10 @@ func newFile(name string, code modules.ErasureCoder, pieceSize, fileSize uint64)
 // TODO: The data is not cleared from any contracts where the host is not
 // immediately online.
 func (r *Renter) DeleteFile(nickname string) error {
+	nickname, err := r.managedResolveSiaPath(nickname)
+	if err != nil {
+		return err
+	}
 	lockID := r.mu.Lock()
 	f, exists := r.files[nickname]
 	if !exists {
@@ -186,6 +190,7 @@ func (r *Renter) DeleteFile(nickname string) error {
 		return ErrUnknownPath
 	}
 	delete(r.files, nickname)
+	delete(r.pausedUploads, nickname)
 	os.RemoveAll(filepath.Join(r.persistDir, f.name+ShareExtension))
 	r.saveSync()
 	r.mu.Unlock(lockID)
@@ -226,28 +231,32 @@ func (r *Renter) FileList() []modules.FileInfo {
 // file must exist, and there must not be any file that already has the
 // replacement nickname.
 func (r *Renter) RenameFile(currentName, newName string) error {
+	// Check that both names are valid.
+	currentName, err := r.managedResolveSiaPath(currentName)
+	if err != nil {
+		return err
+	}
+	newName, err = normalizeSiaPath(newName)
+	if err != nil {
+		return err
+	}
+
 	lockID := r.mu.Lock()
 	defer r.mu.Unlock(lockID)
 
-	// Check that newName is nonempty.
-	if newName == "" {
-		return ErrEmptyFilename
-	}
-
 	// Check that currentName exists and newName doesn't.
 	file, exists := r.files[currentName]
 	if !exists {
 		return ErrUnknownPath
 	}
-	_, exists = r.files[newName]
-	if exists {
+	if r.siaPathInUse(newName) {
 		return ErrPathOverload
 	}
 
 	// Modify the file and save it to disk.
 	file.mu.Lock()
 	file.name = newName
-	err := r.saveFile(file)
+	err = r.saveFile(file)
 	file.mu.Unlock()
 	if err != nil {
 		return err
@@ -260,6 +269,10 @@ func (r *Renter) RenameFile(currentName, newName string) error {
 		delete(r.tracking, currentName)
 		r.tracking[newName] = t
 	}
+	if _, ok := r.pausedUploads[currentName]; ok {
+		delete(r.pausedUploads, currentName)
+		r.pausedUploads[newName] = struct{}{}
+	}
 	err = r.saveSync()
 	if err != nil {
 		return err
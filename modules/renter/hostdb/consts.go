@@ -29,6 +29,14 @@ const (
 	// saveFrequency defines how frequently the hostdb will save to disk. Hostdb
 	// will also save immediately prior to shutdown.
 	saveFrequency = 2 * time.Minute
+
+	// minHostLatency is the latency below which hosts are not rewarded for
+	// being faster. Differences at this scale are mostly noise.
+	minHostLatency = 10 * time.Millisecond
+
+	// unmeasuredHostLatency is the latency assumed for hosts that have not
+	// been successfully scanned since latencies started being recorded.
+	unmeasuredHostLatency = 250 * time.Millisecond
 )
 
 var (
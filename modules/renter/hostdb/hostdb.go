@@ -54,6 +54,11 @@ type HostDB struct {
 
 	blockHeight types.BlockHeight
 	lastChange  modules.ConsensusChangeID
+
+	// latencyWeight holds the bits of the float64 latency weight. It is
+	// accessed atomically, because it is read by the host tree's weight
+	// function, which may be called while hdb.mu is held.
+	latencyWeight uint64
 }
 
 // New returns a new HostDB.
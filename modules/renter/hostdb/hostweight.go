@@ -347,9 +347,10 @@ func (hdb *HostDB) calculateHostWeight(entry modules.HostDBEntry) types.Currency
 	versionPenalty := versionAdjustments(entry)
 	lifetimePenalty := hdb.lifetimeAdjustments(entry)
 	uptimePenalty := hdb.uptimeAdjustments(entry)
+	latencyPenalty := hdb.latencyAdjustments(entry)
 
 	// Combine the adjustments.
-	fullPenalty := collateralReward * pricePenalty * storageRemainingPenalty * versionPenalty * lifetimePenalty * uptimePenalty
+	fullPenalty := collateralReward * pricePenalty * storageRemainingPenalty * versionPenalty * lifetimePenalty * uptimePenalty * latencyPenalty
 
 	// Return a types.Currency.
 	weight := baseWeight.MulFloat(fullPenalty)
@@ -399,6 +400,7 @@ func (hdb *HostDB) EstimateHostScore(entry modules.HostDBEntry) modules.HostScor
 		AgeAdjustment:              1,
 		BurnAdjustment:             1,
 		CollateralAdjustment:       collateralReward,
+		LatencyAdjustment:          1,
 		PriceAdjustment:            pricePenalty,
 		StorageRemainingAdjustment: storageRemainingPenalty,
 		UptimeAdjustment:           1,
@@ -417,6 +419,7 @@ func (hdb *HostDB) ScoreBreakdown(entry modules.HostDBEntry) modules.HostScoreBr
 		AgeAdjustment:              hdb.lifetimeAdjustments(entry),
 		BurnAdjustment:             1,
 		CollateralAdjustment:       hdb.collateralAdjustments(entry),
+		LatencyAdjustment:          hdb.latencyAdjustments(entry),
 		PriceAdjustment:            hdb.priceAdjustments(entry),
 		StorageRemainingAdjustment: storageRemainingAdjustments(entry),
 		UptimeAdjustment:           hdb.uptimeAdjustments(entry),
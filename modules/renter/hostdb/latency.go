@@ -0,0 +1,80 @@
+package hostdb
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// errInvalidLatencyWeight is returned by SetLatencyPreference if the weight
+// is negative or not a number.
+var errInvalidLatencyWeight = errors.New("latency weight must be a non-negative number")
+
+// latencyPreference returns the weight given to host latency.
+func (hdb *HostDB) latencyPreference() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&hdb.latencyWeight))
+}
+
+// setLatencyPreference sets the weight given to host latency without
+// updating the weights in the host tree.
+func (hdb *HostDB) setLatencyPreference(weight float64) {
+	atomic.StoreUint64(&hdb.latencyWeight, math.Float64bits(weight))
+}
+
+// hostLatency returns the average latency of the host's successful scans.
+func hostLatency(entry modules.HostDBEntry) time.Duration {
+	var total time.Duration
+	var n int
+	for _, scan := range entry.ScanHistory {
+		if scan.Success && scan.Latency > 0 {
+			total += scan.Latency
+			n++
+		}
+	}
+	if n == 0 {
+		return unmeasuredHostLatency
+	}
+	return total / time.Duration(n)
+}
+
+// latencyAdjustments penalizes the host for being slow to respond to scans,
+// according to the latency weight. With a weight of 1, a host's weight is
+// inversely proportional to its latency; higher weights penalize latency more
+// severely.
+func (hdb *HostDB) latencyAdjustments(entry modules.HostDBEntry) float64 {
+	weight := hdb.latencyPreference()
+	if weight == 0 {
+		return 1
+	}
+	latency := hostLatency(entry)
+	if latency < minHostLatency {
+		latency = minHostLatency
+	}
+	return math.Pow(float64(minHostLatency)/float64(latency), weight)
+}
+
+// SetLatencyPreference sets how strongly the measured latency of a host
+// affects its weight, trading price for speed. A weight of zero, the default,
+// ignores latency. The weights of all known hosts are recalculated.
+func (hdb *HostDB) SetLatencyPreference(weight float64) error {
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return errInvalidLatencyWeight
+	}
+	if err := hdb.tg.Add(); err != nil {
+		return err
+	}
+	defer hdb.tg.Done()
+
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+	hdb.setLatencyPreference(weight)
+	for _, entry := range hdb.hostTree.All() {
+		if err := hdb.hostTree.Modify(entry); err != nil {
+			hdb.log.Println("ERROR: unable to reweight host:", err)
+		}
+	}
+	return hdb.saveSync()
+}
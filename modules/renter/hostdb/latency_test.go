@@ -0,0 +1,77 @@
+package hostdb
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestLatencyPreference checks that raising the latency weight favors hosts
+// with lower latency over cheaper hosts with higher latency.
+func TestLatencyPreference(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+	hdb.deps = prodDependencies{}
+	hdb.persistDir = build.TempDir("HostDB", t.Name())
+	if err := os.MkdirAll(hdb.persistDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// The slow host is slightly cheaper than the fast host.
+	scans := func(latency time.Duration) modules.HostDBScans {
+		return modules.HostDBScans{
+			{Timestamp: time.Now().Add(-time.Hour), Success: true, Latency: latency},
+			{Timestamp: time.Now(), Success: true, Latency: latency},
+		}
+	}
+	fast := makeHostDBEntry()
+	fast.RemainingStorage = 250e3
+	fast.StoragePrice = types.SiacoinPrecision.Mul64(1000).Div64(tbMonth)
+	fast.ScanHistory = scans(20 * time.Millisecond)
+	slow := makeHostDBEntry()
+	slow.RemainingStorage = 250e3
+	slow.StoragePrice = types.SiacoinPrecision.Mul64(900).Div64(tbMonth)
+	slow.ScanHistory = scans(400 * time.Millisecond)
+	for _, entry := range []modules.HostDBEntry{fast, slow} {
+		if err := hdb.hostTree.Insert(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// By default, latency is ignored, so the cheaper host is preferred.
+	if sb := hdb.ScoreBreakdown(slow); sb.LatencyAdjustment != 1 {
+		t.Fatal("latency should not affect the score by default:", sb.LatencyAdjustment)
+	}
+	// AllHosts is sorted by increasing weight.
+	if hosts := hdb.AllHosts(); hosts[1].PublicKey.String() != slow.PublicKey.String() {
+		t.Fatal("cheaper host should be preferred when latency is ignored")
+	}
+
+	if err := hdb.SetLatencyPreference(-1); err != errInvalidLatencyWeight {
+		t.Fatal("expected errInvalidLatencyWeight, got", err)
+	}
+	if err := hdb.SetLatencyPreference(1); err != nil {
+		t.Fatal(err)
+	}
+	if hosts := hdb.AllHosts(); hosts[1].PublicKey.String() != fast.PublicKey.String() {
+		t.Fatal("faster host should be preferred when latency is weighted")
+	}
+	if hdb.ScoreBreakdown(fast).LatencyAdjustment <= hdb.ScoreBreakdown(slow).LatencyAdjustment {
+		t.Fatal("slow host should be penalized more than fast host")
+	}
+
+	// The preference should be persisted.
+	hdb.setLatencyPreference(0)
+	if err := hdb.load(); err != nil {
+		t.Fatal(err)
+	}
+	if w := hdb.latencyPreference(); w != 1 {
+		t.Fatal("latency preference was not persisted:", w)
+	}
+}
@@ -24,9 +24,10 @@ var (
 
 // hdbPersist defines what HostDB data persists across sessions.
 type hdbPersist struct {
-	AllHosts    []modules.HostDBEntry
-	BlockHeight types.BlockHeight
-	LastChange  modules.ConsensusChangeID
+	AllHosts      []modules.HostDBEntry
+	BlockHeight   types.BlockHeight
+	LastChange    modules.ConsensusChangeID
+	LatencyWeight float64
 }
 
 // persistData returns the data in the hostdb that will be saved to disk.
@@ -34,6 +35,7 @@ func (hdb *HostDB) persistData() (data hdbPersist) {
 	data.AllHosts = hdb.hostTree.All()
 	data.BlockHeight = hdb.blockHeight
 	data.LastChange = hdb.lastChange
+	data.LatencyWeight = hdb.latencyPreference()
 	return data
 }
 
@@ -54,6 +56,7 @@ func (hdb *HostDB) load() error {
 	// Set the hostdb internal values.
 	hdb.blockHeight = data.BlockHeight
 	hdb.lastChange = data.LastChange
+	hdb.setLatencyPreference(data.LatencyWeight)
 
 	// Load each of the hosts into the host tree.
 	for _, host := range data.AllHosts {
@@ -90,7 +90,11 @@ func (hdb *HostDB) queueScan(entry modules.HostDBEntry) {
 // to give that host some base uptime. This makes this function co-dependent
 // with the host weight functions. Adjustment of the host weight functions need
 // to keep this function in mind, and vice-versa.
-func (hdb *HostDB) updateEntry(entry modules.HostDBEntry, netErr error) {
+func (hdb *HostDB) updateEntry(entry modules.HostDBEntry, latency time.Duration, netErr error) {
+	if netErr != nil {
+		latency = 0
+	}
+
 	// If the scan failed because we don't have Internet access, toss out this update.
 	if netErr != nil && !hdb.online {
 		return
@@ -116,7 +120,7 @@ func (hdb *HostDB) updateEntry(entry modules.HostDBEntry, netErr error) {
 		}
 		newEntry.ScanHistory = modules.HostDBScans{
 			{Timestamp: suggestedStartTime, Success: netErr == nil},
-			{Timestamp: time.Now(), Success: netErr == nil},
+			{Timestamp: time.Now(), Success: netErr == nil, Latency: latency},
 		}
 	} else {
 		if newEntry.ScanHistory[len(newEntry.ScanHistory)-1].Success && netErr != nil {
@@ -135,7 +139,7 @@ func (hdb *HostDB) updateEntry(entry modules.HostDBEntry, netErr error) {
 		// Before appending, make sure that the scan we just performed is
 		// timestamped after the previous scan performed. It may not be if the
 		// system clock has changed.
-		newEntry.ScanHistory = append(newEntry.ScanHistory, modules.HostDBScan{Timestamp: newTimestamp, Success: netErr == nil})
+		newEntry.ScanHistory = append(newEntry.ScanHistory, modules.HostDBScan{Timestamp: newTimestamp, Success: netErr == nil, Latency: latency})
 	}
 
 	// Check whether any of the recent scans demonstrate uptime. The pruning and
@@ -200,6 +204,7 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 	hdb.log.Debugf("Scanning host %v at %v", pubKey, netAddr)
 
 	var settings modules.HostExternalSettings
+	start := time.Now()
 	err := func() error {
 		dialer := &net.Dialer{
 			Cancel:  hdb.tg.StopChan(),
@@ -228,6 +233,7 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 		copy(pubkey[:], pubKey.Key)
 		return crypto.ReadSignedObject(conn, &settings, maxSettingsLen, pubkey)
 	}()
+	latency := time.Since(start)
 	if err != nil {
 		hdb.log.Debugf("Scan of host at %v failed: %v", netAddr, err)
 	} else {
@@ -238,7 +244,7 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 	// Update the host tree to have a new entry, including the new error. Then
 	// delete the entry from the scan map as the scan has been successful.
 	hdb.mu.Lock()
-	hdb.updateEntry(entry, err)
+	hdb.updateEntry(entry, latency, err)
 	hdb.mu.Unlock()
 }
 
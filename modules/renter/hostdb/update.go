@@ -82,7 +82,7 @@ func (hdb *HostDB) ProcessConsensusChange(cc modules.ConsensusChange) {
 	for _, block := range cc.RevertedBlocks {
 		// Only doing the block check if the height is above zero saves hashing
 		// and saves a nontrivial amount of time during IBD.
-		if hdb.blockHeight > 0 || block.ID() != types.GenesisID {
+		if hdb.blockHeight > 0 || !block.IsGenesis() {
 			hdb.blockHeight--
 		} else if hdb.blockHeight != 0 {
 			// Sanity check - if the current block is the genesis block, the
@@ -94,7 +94,7 @@ func (hdb *HostDB) ProcessConsensusChange(cc modules.ConsensusChange) {
 	for _, block := range cc.AppliedBlocks {
 		// Only doing the block check if the height is above zero saves hashing
 		// and saves a nontrivial amount of time during IBD.
-		if hdb.blockHeight > 0 || block.ID() != types.GenesisID {
+		if hdb.blockHeight > 0 || !block.IsGenesis() {
 			hdb.blockHeight++
 		} else if hdb.blockHeight != 0 {
 			// Sanity check - if the current block is the genesis block, the
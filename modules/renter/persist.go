@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -29,7 +30,8 @@ var (
 	ErrBadFile        = errors.New("not a .sia file")
 	ErrIncompatible   = errors.New("file is not compatible with current version")
 
-	shareHeader  = [15]byte{'S', 'i', 'a', ' ', 'S', 'h', 'a', 'r', 'e', 'd', ' ', 'F', 'i', 'l', 'e'}
+	shareHeader = [15]byte{'S', 'i', 'a', ' ', 'S', 'h', 'a', 'r', 'e', 'd', ' ', 'F', 'i', 'l', 'e'}
+
 	shareVersion = "0.4"
 
 	saveMetadata = persist.Metadata{
@@ -181,8 +183,13 @@ func (r *Renter) saveFile(f *file) error {
 // saveSync stores the current renter data to disk and then syncs to disk.
 func (r *Renter) saveSync() error {
 	data := struct {
-		Tracking map[string]trackedFile
-	}{r.tracking}
+		Tracking            map[string]trackedFile
+		RepairThreshold     float64
+		FundsAlertThreshold float64
+		UploadWorkers       int
+		DownloadRetries     int
+		PausedUploads       map[string]struct{}
+	}{r.tracking, r.repairThreshold, r.fundsAlertThreshold, r.uploadWorkers, r.downloadRetries, r.pausedUploads}
 
 	return persist.SaveJSON(saveMetadata, data, filepath.Join(r.persistDir, PersistFilename))
 }
@@ -204,6 +211,15 @@ func (r *Renter) load() error {
 			return nil
 		}
 
+		// Remove the copies left behind by interrupted key rotations.
+		if rel, err := filepath.Rel(r.persistDir, path); err == nil && strings.HasPrefix(filepath.ToSlash(rel), rotatingPrefix) {
+			r.log.Println("Removing copy left by an interrupted key rotation:", rel)
+			if err := os.Remove(path); err != nil {
+				r.log.Println("WARN: could not remove interrupted key rotation:", err)
+			}
+			return nil
+		}
+
 		// Open the file.
 		file, err := os.Open(path)
 		if err != nil {
@@ -226,8 +242,13 @@ func (r *Renter) load() error {
 
 	// Load contracts, repair set, and entropy.
 	data := struct {
-		Tracking  map[string]trackedFile
-		Repairing map[string]string // COMPATv0.4.8
+		Tracking            map[string]trackedFile
+		Repairing           map[string]string // COMPATv0.4.8
+		RepairThreshold     float64
+		FundsAlertThreshold float64
+		UploadWorkers       int
+		DownloadRetries     int
+		PausedUploads       map[string]struct{}
 	}{}
 	err = persist.LoadJSON(saveMetadata, &data, filepath.Join(r.persistDir, PersistFilename))
 	if err != nil {
@@ -236,6 +257,20 @@ func (r *Renter) load() error {
 	if data.Tracking != nil {
 		r.tracking = data.Tracking
 	}
+	for name := range r.tracking {
+		if strings.HasPrefix(name, rotatingPrefix) {
+			delete(r.tracking, name)
+		}
+	}
+	r.repairThreshold = data.RepairThreshold
+	r.fundsAlertThreshold = data.FundsAlertThreshold
+	r.uploadWorkers = data.UploadWorkers
+	r.downloadRetries = data.DownloadRetries
+	for name := range data.PausedUploads {
+		if _, exists := r.files[name]; exists {
+			r.pausedUploads[name] = struct{}{}
+		}
+	}
 
 	return nil
 }
@@ -287,6 +322,10 @@ func (r *Renter) ShareFiles(nicknames []string, shareDest string) error {
 	// Load files from renter.
 	files := make([]*file, len(nicknames))
 	for i, name := range nicknames {
+		name, err := r.resolveSiaPath(name)
+		if err != nil {
+			return err
+		}
 		f, exists := r.files[name]
 		if !exists {
 			return ErrUnknownPath
@@ -311,6 +350,10 @@ func (r *Renter) ShareFilesAscii(nicknames []string) (string, error) {
 	// Load files from renter.
 	files := make([]*file, len(nicknames))
 	for i, name := range nicknames {
+		name, err := r.resolveSiaPath(name)
+		if err != nil {
+			return "", err
+		}
 		f, exists := r.files[name]
 		if !exists {
 			return "", ErrUnknownPath
@@ -362,6 +405,9 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
+		if strings.HasPrefix(files[i].name, rotatingPrefix) {
+			return nil, errReservedSiapath
+		}
 
 		// Make sure the file's name does not conflict with existing files.
 		dupCount := 0
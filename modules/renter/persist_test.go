@@ -205,18 +205,27 @@ func TestRenterSaveLoad(t *testing.T) {
 	rt.renter.saveFile(f2)
 	rt.renter.saveFile(f3)
 
+	// Pause the upload of f1, and of a file that does not exist.
+	id := rt.renter.mu.Lock()
+	rt.renter.pausedUploads[f1.name] = struct{}{}
+	rt.renter.pausedUploads["missing"] = struct{}{}
 	err = rt.renter.saveSync() // save metadata
+	rt.renter.pausedUploads = make(map[string]struct{})
+	rt.renter.mu.Unlock(id)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// load should now load the files into memory.
-	id := rt.renter.mu.Lock()
+	id = rt.renter.mu.Lock()
 	err = rt.renter.load()
 	rt.renter.mu.Unlock(id)
 	if err != nil && !os.IsNotExist(err) {
 		t.Fatal(err)
 	}
+	if _, paused := rt.renter.pausedUploads[f1.name]; !paused || len(rt.renter.pausedUploads) != 1 {
+		t.Fatal("expected only the upload of f1 to be paused after loading, got", rt.renter.pausedUploads)
+	}
 
 	if err := equalFiles(f1, rt.renter.files[f1.name]); err != nil {
 		t.Fatal(err)
@@ -294,6 +303,59 @@ func TestRenterPaths(t *testing.T) {
 	}
 }
 
+// TestRenterLoadInterruptedRotation checks that copies left behind by an
+// interrupted key rotation are removed when the renter loads, and that users
+// cannot create siapaths with the reserved rotation prefix.
+func TestRenterLoadInterruptedRotation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	f1 := newTestingFile()
+	f1.name = "foo"
+	f2 := newTestingFile()
+	f2.name = rotatingPrefix + "foo"
+	rt.renter.saveFile(f1)
+	rt.renter.saveFile(f2)
+	rt.renter.tracking[f2.name] = trackedFile{RepairPath: "foo"}
+	if err := rt.renter.saveSync(); err != nil {
+		t.Fatal(err)
+	}
+
+	id := rt.renter.mu.Lock()
+	rt.renter.files = make(map[string]*file)
+	rt.renter.tracking = make(map[string]trackedFile)
+	err = rt.renter.load()
+	rt.renter.mu.Unlock(id)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if err := equalFiles(f1, rt.renter.files[f1.name]); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := rt.renter.files[f2.name]; exists {
+		t.Fatal("interrupted rotation was loaded")
+	} else if _, exists := rt.renter.tracking[f2.name]; exists {
+		t.Fatal("interrupted rotation is still tracked")
+	}
+	if _, err := os.Stat(filepath.Join(rt.renter.persistDir, f2.name+ShareExtension)); !os.IsNotExist(err) {
+		t.Fatal("interrupted rotation was not removed from disk:", err)
+	}
+
+	// Users should not be able to create files with the reserved prefix.
+	if _, err := normalizeSiaPath(f2.name); err != errReservedSiapath {
+		t.Fatal("expected errReservedSiapath, got", err)
+	}
+	if err := rt.renter.RenameFile("foo", f2.name); err != errReservedSiapath {
+		t.Fatal("expected errReservedSiapath, got", err)
+	}
+}
+
 // TestSiafileCompatibility tests that the renter is able to load v0.4.8 .sia files.
 func TestSiafileCompatibility(t *testing.T) {
 	if testing.Short() {
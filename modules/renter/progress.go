@@ -0,0 +1,130 @@
+package renter
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// errNoDownload is returned by DownloadProgress if the file has never been
+// downloaded.
+var errNoDownload = errors.New("no download of that file has been queued")
+
+// threadedReportProgress sends the values returned by progress on c until
+// progress reports that the transfer is done, and then closes c. A value is
+// only sent if it is larger than the previous value, so the values received
+// on c are strictly increasing. c must have a buffer of 1. If the consumer has
+// not read the previous value, it is replaced, so that the thread never blocks
+// on a consumer that has stopped reading.
+func (r *Renter) threadedReportProgress(c chan float64, progress func() (float64, bool)) {
+	defer close(c)
+	last := -1.0
+	for {
+		if r.tg.Add() != nil {
+			return
+		}
+		p, done := progress()
+		r.tg.Done()
+
+		if p > 1 {
+			p = 1
+		}
+		if p > last {
+			select {
+			case c <- p:
+			default:
+				// Discard the unread value. Only this thread sends on c, so
+				// the buffer has room once it has been drained.
+				select {
+				case <-c:
+				default:
+				}
+				c <- p
+			}
+			last = p
+		}
+		if done {
+			return
+		}
+
+		select {
+		case <-time.After(progressPollInterval):
+		case <-r.tg.StopChan():
+			return
+		}
+	}
+}
+
+// UploadProgress returns a channel that receives the upload progress of a
+// file, as a fraction between 0 and 1. The channel is closed once the file is
+// fully uploaded or deleted, or when the renter shuts down. Only the latest
+// value is buffered, so a slow reader may skip values. Unlike the
+// UploadProgress field of FileInfo, the progress is capped at 1.
+func (r *Renter) UploadProgress(siaPath string) (<-chan float64, error) {
+	siaPath, err := r.managedResolveSiaPath(siaPath)
+	if err != nil {
+		return nil, err
+	}
+	lockID := r.mu.RLock()
+	_, exists := r.files[siaPath]
+	r.mu.RUnlock(lockID)
+	if !exists {
+		return nil, ErrUnknownPath
+	}
+
+	c := make(chan float64, 1)
+	go r.threadedReportProgress(c, func() (float64, bool) {
+		lockID := r.mu.RLock()
+		f, exists := r.files[siaPath]
+		r.mu.RUnlock(lockID)
+		if !exists {
+			return 0, true
+		}
+		f.mu.RLock()
+		p := f.uploadProgress() / 100
+		f.mu.RUnlock()
+		return p, p >= 1
+	})
+	return c, nil
+}
+
+// DownloadProgress returns a channel that receives the progress of the most
+// recent download of a file, as a fraction between 0 and 1. The channel is
+// closed when the download finishes, or when the renter shuts down. Only the
+// latest value is buffered, so a slow reader may skip values, but a successful
+// download always reports 1 before the channel is closed.
+func (r *Renter) DownloadProgress(siaPath string) (<-chan float64, error) {
+	siaPath, err := r.managedResolveSiaPath(siaPath)
+	if err != nil {
+		return nil, err
+	}
+	lockID := r.mu.RLock()
+	var d *download
+	for i := len(r.downloadQueue) - 1; i >= 0; i-- {
+		if r.downloadQueue[i].siapath == siaPath {
+			d = r.downloadQueue[i]
+			break
+		}
+	}
+	r.mu.RUnlock(lockID)
+	if d == nil {
+		return nil, errNoDownload
+	}
+
+	c := make(chan float64, 1)
+	go r.threadedReportProgress(c, func() (float64, bool) {
+		select {
+		case <-d.downloadFinished:
+			if d.Err() != nil {
+				return 0, true
+			}
+			return 1, true
+		default:
+		}
+		if d.length == 0 {
+			return 0, false
+		}
+		return float64(atomic.LoadUint64(&d.atomicDataReceived)) / float64(d.length), false
+	})
+	return c, nil
+}
@@ -0,0 +1,148 @@
+package renter
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// receiveProgress reads the next value from a progress channel, failing the
+// test if the channel is closed or no value arrives in time.
+func receiveProgress(t *testing.T, c <-chan float64) float64 {
+	select {
+	case p, ok := <-c:
+		if !ok {
+			t.Fatal("progress channel closed early")
+		}
+		return p
+	case <-time.After(10 * progressPollInterval):
+		t.Fatal("no progress received")
+	}
+	return 0
+}
+
+// expectClosed checks that a progress channel is closed without sending any
+// further values.
+func expectClosed(t *testing.T, c <-chan float64) {
+	select {
+	case p, ok := <-c:
+		if ok {
+			t.Fatal("expected channel to be closed, got", p)
+		}
+	case <-time.After(10 * progressPollInterval):
+		t.Fatal("progress channel was not closed")
+	}
+}
+
+// TestUploadProgress checks that the channel returned by UploadProgress
+// reports increasing values ending at 1, and is closed afterward.
+func TestUploadProgress(t *testing.T) {
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, 10, 10)
+	r := &Renter{
+		files: map[string]*file{"foo": f},
+		mu:    sync.New(modules.SafeMutexDelay, 1),
+		tg:    new(sync.ThreadGroup),
+	}
+	defer r.tg.Stop()
+
+	if _, err := r.UploadProgress("bar"); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+	c, err := r.UploadProgress("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := receiveProgress(t, c); p != 0 {
+		t.Fatal("expected initial progress of 0, got", p)
+	}
+
+	// Upload the file's two pieces, one at a time.
+	for i, want := range []float64{0.5, 1} {
+		f.mu.Lock()
+		f.contracts[types.FileContractID{byte(i)}] = fileContract{
+			Pieces: []pieceData{{Chunk: 0, Piece: uint64(i)}},
+		}
+		f.mu.Unlock()
+		if p := receiveProgress(t, c); p != want {
+			t.Fatalf("expected progress of %v, got %v", want, p)
+		}
+	}
+	expectClosed(t, c)
+}
+
+// TestDownloadProgress checks that the channel returned by DownloadProgress
+// reports increasing values ending at 1, and is closed afterward.
+func TestDownloadProgress(t *testing.T) {
+	rsc, _ := NewRSCode(1, 1)
+	d := newDownload(newFile("foo", rsc, 10, 100), nil)
+	d.length = 100
+	r := &Renter{
+		downloadQueue: []*download{d},
+		mu:            sync.New(modules.SafeMutexDelay, 1),
+		tg:            new(sync.ThreadGroup),
+	}
+	defer r.tg.Stop()
+
+	if _, err := r.DownloadProgress("bar"); err != errNoDownload {
+		t.Fatal("expected errNoDownload, got", err)
+	}
+	c, err := r.DownloadProgress("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := receiveProgress(t, c); p != 0 {
+		t.Fatal("expected initial progress of 0, got", p)
+	}
+	atomic.AddUint64(&d.atomicDataReceived, 50)
+	if p := receiveProgress(t, c); p != 0.5 {
+		t.Fatal("expected progress of 0.5, got", p)
+	}
+
+	// Completing the download should report 1 and close the channel.
+	atomic.AddUint64(&d.atomicDataReceived, 50)
+	d.mu.Lock()
+	d.fail(nil)
+	d.mu.Unlock()
+	if p := receiveProgress(t, c); p != 1 {
+		t.Fatal("expected final progress of 1, got", p)
+	}
+	expectClosed(t, c)
+}
+
+// TestProgressUnreadValues checks that progress reporting does not block on a
+// consumer that stops reading, and that only the latest value is kept.
+func TestProgressUnreadValues(t *testing.T) {
+	rsc, _ := NewRSCode(1, 1)
+	d := newDownload(newFile("foo", rsc, 10, 100), nil)
+	d.length = 100
+	r := &Renter{
+		downloadQueue: []*download{d},
+		mu:            sync.New(modules.SafeMutexDelay, 1),
+		tg:            new(sync.ThreadGroup),
+	}
+	defer r.tg.Stop()
+
+	c, err := r.DownloadProgress("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Make progress without reading from the channel.
+	atomic.AddUint64(&d.atomicDataReceived, 50)
+	time.Sleep(3 * progressPollInterval)
+	atomic.AddUint64(&d.atomicDataReceived, 50)
+	d.mu.Lock()
+	d.fail(nil)
+	d.mu.Unlock()
+	time.Sleep(3 * progressPollInterval)
+
+	// The earlier values should have been replaced by the final one.
+	if p := receiveProgress(t, c); p != 1 {
+		t.Fatal("expected only the final progress of 1, got", p)
+	}
+	expectClosed(t, c)
+}
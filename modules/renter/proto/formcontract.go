@@ -22,8 +22,12 @@ func FormContract(params ContractParams, txnBuilder transactionBuilder, tpool tr
 	// Extract vars from params, for convenience.
 	host, filesize, startHeight, endHeight, refundAddress := params.Host, params.Filesize, params.StartHeight, params.EndHeight, params.RefundAddress
 
-	// Create our key.
-	ourSK, ourPK := crypto.GenerateKeyPair()
+	// Create our key, unless one was provided.
+	ourSK := params.SecretKey
+	if ourSK == (crypto.SecretKey{}) {
+		ourSK, _ = crypto.GenerateKeyPair()
+	}
+	ourPK := ourSK.PublicKey()
 	// Create unlock conditions.
 	uc := types.UnlockConditions{
 		PublicKeys: []types.SiaPublicKey{
@@ -36,7 +36,10 @@ type ContractParams struct {
 	StartHeight   types.BlockHeight
 	EndHeight     types.BlockHeight
 	RefundAddress types.UnlockHash
-	// TODO: add optional keypair
+
+	// SecretKey is the key that the renter signs the contract with. If it is
+	// empty, a new key is generated for the contract.
+	SecretKey crypto.SecretKey
 }
 
 // A revisionSaver is called just before we send our revision signature to the host; this
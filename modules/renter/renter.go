@@ -9,6 +9,7 @@ package renter
 
 import (
 	"errors"
+	"io"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -24,6 +25,9 @@ var (
 	errNilCS         = errors.New("cannot create renter with nil consensus set")
 	errNilTpool      = errors.New("cannot create renter with nil transaction pool")
 	errNilHdb        = errors.New("cannot create renter with nil hostdb")
+
+	errInvalidRedundancy = errors.New("redundancy must be at least 1")
+	errNoHostPrices      = errors.New("no hosts are available to estimate prices")
 )
 
 var (
@@ -70,6 +74,10 @@ type hostDB interface {
 	// EstimateHostScore returns the estimated score breakdown of a host with the
 	// provided settings.
 	EstimateHostScore(modules.HostDBEntry) modules.HostScoreBreakdown
+
+	// SetLatencyPreference sets how strongly the measured latency of a host
+	// affects its weight.
+	SetLatencyPreference(weight float64) error
 }
 
 // A hostContractor negotiates, revises, renews, and provides access to file
@@ -84,6 +92,13 @@ type hostContractor interface {
 	// Allowance returns the current allowance
 	Allowance() modules.Allowance
 
+	// BlockHost prevents the contractor from forming or renewing contracts
+	// with a host.
+	BlockHost(types.SiaPublicKey) error
+
+	// BlockedHosts returns the hosts that have been blocked.
+	BlockedHosts() []types.SiaPublicKey
+
 	// Close closes the hostContractor.
 	Close() error
 
@@ -108,8 +123,25 @@ type hostContractor interface {
 	// allowing the retrieval of sectors.
 	Downloader(types.FileContractID, <-chan struct{}) (contractor.Downloader, error)
 
+	// PublicKey returns the public key that new contracts are signed with.
+	PublicKey() types.SiaPublicKey
+
 	// ResolveID returns the most recent renewal of the specified ID.
 	ResolveID(types.FileContractID) types.FileContractID
+
+	// SetContractFormationConcurrency sets the maximum number of contracts
+	// that are formed simultaneously.
+	SetContractFormationConcurrency(int) error
+
+	// UnblockHost removes a host from the blocklist.
+	UnblockHost(types.SiaPublicKey) error
+
+	// ExportContracts writes the contract set to a writer.
+	ExportContracts(io.Writer) error
+
+	// ImportContracts adds the contracts read from a reader to the contract
+	// set.
+	ImportContracts(io.Reader) error
 }
 
 // A trackedFile contains metadata about files being tracked by the Renter.
@@ -124,6 +156,11 @@ type trackedFile struct {
 // A Renter is responsible for tracking all of the files that a user has
 // uploaded to Sia, as well as the locations and health of these files.
 type Renter struct {
+	// atomicCorruptPieces counts the downloaded pieces that failed
+	// verification. It is accessed atomically, and must be the first field in
+	// the struct to guarantee 64-bit alignment.
+	atomicCorruptPieces uint64
+
 	// File management.
 	//
 	// tracking contains a list of files that the user intends to maintain. By
@@ -145,6 +182,31 @@ type Renter struct {
 	newRepairs    chan *file
 	workerPool    map[types.FileContractID]*worker
 
+	// repairThreshold is the redundancy below which a chunk is queued for
+	// repair. A value of 0 means that any chunk missing pieces is repaired.
+	repairThreshold float64
+
+	// pausedUploads is the set of files whose uploads have been paused by
+	// PauseUpload. Paused files are not added to the repair loop.
+	pausedUploads map[string]struct{}
+
+	// uploadWorkers is the maximum number of pieces that the renter will
+	// upload in parallel, each to a different host. A value of 0 means that
+	// every host with a contract can be uploaded to at once.
+	uploadWorkers int
+
+	// downloadRetries is the number of times that a failed piece download
+	// may be retried on the same host before the host is put on cooldown.
+	downloadRetries int
+
+	// fundsAlertThreshold is the fraction of a contract's initial funds below
+	// which an alert is raised. A value of 0 disables the alert.
+	//
+	// alerts contains the renter's active alerts, keyed by an identifier of
+	// the condition that raised them.
+	fundsAlertThreshold float64
+	alerts              map[string]modules.RenterAlert
+
 	// Utilities.
 	cs             modules.ConsensusSet
 	hostContractor hostContractor
@@ -154,6 +216,7 @@ type Renter struct {
 	mu             *sync.RWMutex
 	tg             *sync.ThreadGroup
 	tpool          modules.TransactionPool
+	wallet         modules.Wallet
 }
 
 // New returns an initialized renter.
@@ -167,11 +230,11 @@ func New(g modules.Gateway, cs modules.ConsensusSet, wallet modules.Wallet, tpoo
 		return nil, err
 	}
 
-	return newRenter(cs, tpool, hdb, hc, persistDir)
+	return newRenter(cs, wallet, tpool, hdb, hc, persistDir)
 }
 
 // newRenter initializes a renter and returns it.
-func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostDB, hc hostContractor, persistDir string) (*Renter, error) {
+func newRenter(cs modules.ConsensusSet, wallet modules.Wallet, tpool modules.TransactionPool, hdb hostDB, hc hostContractor, persistDir string) (*Renter, error) {
 	if cs == nil {
 		return nil, errNilCS
 	}
@@ -190,6 +253,9 @@ func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostD
 		newRepairs: make(chan *file),
 		files:      make(map[string]*file),
 		tracking:   make(map[string]trackedFile),
+		alerts:     make(map[string]modules.RenterAlert),
+
+		pausedUploads: make(map[string]struct{}),
 
 		newDownloads: make(chan *download),
 		workerPool:   make(map[types.FileContractID]*worker),
@@ -201,6 +267,7 @@ func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostD
 		mu:             sync.New(modules.SafeMutexDelay, 1),
 		tg:             new(sync.ThreadGroup),
 		tpool:          tpool,
+		wallet:         wallet,
 	}
 	if err := r.initPersist(); err != nil {
 		return nil, err
@@ -211,6 +278,7 @@ func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostD
 	go r.threadedRepairLoop()
 	go r.threadedDownloadLoop()
 	go r.threadedQueueRepairs()
+	go r.threadedMonitorContractFunds()
 
 	// Kill workers on shutdown.
 	r.tg.OnStop(func() {
@@ -288,6 +356,44 @@ func (r *Renter) PriceEstimation() modules.RenterPriceEstimation {
 	}
 }
 
+// EstimateUploadCost estimates the cost of uploading a file of size
+// 'fileSize' with the given redundancy and storing it for 'duration' blocks.
+// The estimate uses the current storage and upload prices of the hosts that
+// the renter has contracts with, or of a random set of hosts if there are no
+// contracts yet. Contract formation fees are not included.
+func (r *Renter) EstimateUploadCost(fileSize uint64, duration types.BlockHeight, redundancy float64) (types.Currency, error) {
+	if !(redundancy >= 1) {
+		return types.Currency{}, errInvalidRedundancy
+	}
+
+	// Grab the hosts that the renter has contracts with.
+	var hosts []modules.HostDBEntry
+	for _, c := range r.hostContractor.Contracts() {
+		if host, ok := r.hostDB.Host(c.HostPublicKey); ok {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = r.hostDB.RandomHosts(priceEstimationScope, nil)
+	}
+	if len(hosts) == 0 {
+		return types.Currency{}, errNoHostPrices
+	}
+
+	// Sum the cost of storing and uploading a single byte for the duration
+	// at each host, then average the result.
+	var totalCost types.Currency
+	for _, host := range hosts {
+		storageCost := host.StoragePrice.Mul64(uint64(duration))
+		totalCost = totalCost.Add(storageCost).Add(host.UploadBandwidthPrice)
+	}
+	totalCost = totalCost.Div64(uint64(len(hosts)))
+
+	// Every byte of the file is stored 'redundancy' times.
+	storedBytes := types.NewCurrency64(fileSize).MulFloat(redundancy)
+	return totalCost.Mul(storedBytes), nil
+}
+
 // SetSettings will update the settings for the renter.
 func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	err := r.hostContractor.SetAllowance(s.Allowance)
@@ -301,6 +407,51 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	return nil
 }
 
+// SetRepairThreshold sets the redundancy below which the renter repairs a
+// chunk. For example, a threshold of 1.5 repairs a chunk once fewer than 1.5
+// times the minimum number of pieces are available. Lower thresholds save
+// bandwidth, while higher ones improve durability. A threshold of 0 restores
+// the default behavior of repairing any chunk that is missing pieces. The
+// threshold does not apply to new uploads, which always reach full
+// redundancy.
+func (r *Renter) SetRepairThreshold(ratio float64) error {
+	if ratio != 0 && !(ratio >= 1) {
+		return errInvalidRepairThreshold
+	}
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	r.repairThreshold = ratio
+	return r.saveSync()
+}
+
+// SetUploadWorkers sets the maximum number of pieces that the renter uploads
+// in parallel. Each piece is uploaded to a different host, so more workers
+// help when hosts have high latency. A value of 0 restores the default
+// behavior of uploading to every host with a contract at once.
+func (r *Renter) SetUploadWorkers(n int) error {
+	if n < 0 {
+		return errInvalidUploadWorkers
+	}
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	r.uploadWorkers = n
+	return r.saveSync()
+}
+
+// SetDownloadRetries sets the number of times that the renter may retry a
+// failed piece download on the same host before switching hosts. A failed
+// host is retried after a short backoff. A value of 0 puts a host on cooldown
+// after its first failure, so that the piece is fetched from another host.
+func (r *Renter) SetDownloadRetries(perHost int) error {
+	if perHost < 0 {
+		return errInvalidDownloadRetries
+	}
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	r.downloadRetries = perHost
+	return r.saveSync()
+}
+
 // hostdb passthroughs
 func (r *Renter) ActiveHosts() []modules.HostDBEntry                      { return r.hostDB.ActiveHosts() }
 func (r *Renter) AllHosts() []modules.HostDBEntry                         { return r.hostDB.AllHosts() }
@@ -312,9 +463,23 @@ func (r *Renter) EstimateHostScore(e modules.HostDBEntry) modules.HostScoreBreak
 	return r.hostDB.EstimateHostScore(e)
 }
 
+// SetLatencyPreference sets how strongly the measured latency of a host
+// affects the likelihood of it being selected for new contracts and repairs.
+// Higher weights favor nearby hosts at the expense of price.
+func (r *Renter) SetLatencyPreference(weight float64) error {
+	return r.hostDB.SetLatencyPreference(weight)
+}
+
 // contractor passthroughs
-func (r *Renter) Contracts() []modules.RenterContract { return r.hostContractor.Contracts() }
-func (r *Renter) CurrentPeriod() types.BlockHeight    { return r.hostContractor.CurrentPeriod() }
+func (r *Renter) BlockHost(pk types.SiaPublicKey) error   { return r.hostContractor.BlockHost(pk) }
+func (r *Renter) BlockedHosts() []types.SiaPublicKey      { return r.hostContractor.BlockedHosts() }
+func (r *Renter) Contracts() []modules.RenterContract     { return r.hostContractor.Contracts() }
+func (r *Renter) CurrentPeriod() types.BlockHeight        { return r.hostContractor.CurrentPeriod() }
+func (r *Renter) PublicKey() types.SiaPublicKey           { return r.hostContractor.PublicKey() }
+func (r *Renter) UnblockHost(pk types.SiaPublicKey) error { return r.hostContractor.UnblockHost(pk) }
+func (r *Renter) SetContractFormationConcurrency(n int) error {
+	return r.hostContractor.SetContractFormationConcurrency(n)
+}
 func (r *Renter) Settings() modules.RenterSettings {
 	return modules.RenterSettings{
 		Allowance: r.hostContractor.Allowance(),
@@ -2,6 +2,7 @@ package renter
 
 import (
 	"path/filepath"
+	"testing"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -125,7 +126,7 @@ func newContractorTester(name string, hdb hostDB, hc hostContractor) (*renterTes
 	if err != nil {
 		return nil, err
 	}
-	r, err := newRenter(cs, tp, hdb, hc, filepath.Join(testdir, modules.RenterDir))
+	r, err := newRenter(cs, w, tp, hdb, hc, filepath.Join(testdir, modules.RenterDir))
 	if err != nil {
 		return nil, err
 	}
@@ -182,3 +183,101 @@ func (stubContractor) Editor(types.FileContractID) (contractor.Editor, error) {
 func (stubContractor) Downloader(types.FileContractID) (contractor.Downloader, error) {
 	return nil, nil
 }
+
+// pricedHostDB is a hostDB that knows about a fixed set of hosts.
+type pricedHostDB struct {
+	hostDB
+	hosts []modules.HostDBEntry
+}
+
+func (hdb pricedHostDB) Host(pk types.SiaPublicKey) (modules.HostDBEntry, bool) {
+	for _, h := range hdb.hosts {
+		if h.PublicKey.String() == pk.String() {
+			return h, true
+		}
+	}
+	return modules.HostDBEntry{}, false
+}
+
+func (hdb pricedHostDB) RandomHosts(n int, _ []types.SiaPublicKey) []modules.HostDBEntry {
+	if n > len(hdb.hosts) {
+		n = len(hdb.hosts)
+	}
+	return hdb.hosts[:n]
+}
+
+// contractsContractor is a hostContractor with a fixed set of contracts.
+type contractsContractor struct {
+	hostContractor
+	contracts []modules.RenterContract
+}
+
+func (hc contractsContractor) Contracts() []modules.RenterContract { return hc.contracts }
+
+// TestEstimateUploadCost checks that the upload cost estimate scales with the
+// size, duration, and redundancy of the upload.
+func TestEstimateUploadCost(t *testing.T) {
+	// Create two hosts with different prices. The renter has a contract with
+	// only the first.
+	var hosts []modules.HostDBEntry
+	for i, price := range []uint64{10, 30} {
+		var h modules.HostDBEntry
+		h.PublicKey = types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{byte(i)}}
+		h.StoragePrice = types.NewCurrency64(price)
+		h.UploadBandwidthPrice = types.NewCurrency64(price * 100)
+		hosts = append(hosts, h)
+	}
+	r := &Renter{
+		hostDB: pricedHostDB{hosts: hosts},
+		hostContractor: contractsContractor{
+			contracts: []modules.RenterContract{{HostPublicKey: hosts[0].PublicKey}},
+		},
+	}
+
+	// The estimate should use the prices of the contracted host.
+	cost, err := r.EstimateUploadCost(1000, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := types.NewCurrency64(1000 * (10*100 + 1000)); cost.Cmp(exp) != 0 {
+		t.Fatalf("expected %v, got %v", exp, cost)
+	}
+
+	// The estimate should scale linearly with size and with the storage
+	// duration, and should account for redundancy.
+	estimate := func(size uint64, duration types.BlockHeight, redundancy float64) types.Currency {
+		cost, err := r.EstimateUploadCost(size, duration, redundancy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cost
+	}
+	if estimate(2000, 100, 1).Cmp(estimate(1000, 100, 1).Mul64(2)) != 0 {
+		t.Error("estimate does not scale linearly with size")
+	}
+	storageCost := estimate(1000, 200, 1).Sub(estimate(1000, 100, 1))
+	if estimate(1000, 300, 1).Sub(estimate(1000, 200, 1)).Cmp(storageCost) != 0 {
+		t.Error("estimate does not scale linearly with duration")
+	}
+	if estimate(1000, 100, 3).Cmp(estimate(1000, 100, 1).Mul64(3)) != 0 {
+		t.Error("estimate does not account for redundancy")
+	}
+	if estimate(1000, 100, 1.5).Cmp(estimate(2000, 100, 1).Mul64(3).Div64(4)) != 0 {
+		t.Error("estimate does not account for fractional redundancy")
+	}
+
+	// Without contracts, the estimate should average over the host set.
+	r.hostContractor = contractsContractor{}
+	if exp := types.NewCurrency64(1000 * (20*100 + 2000)); estimate(1000, 100, 1).Cmp(exp) != 0 {
+		t.Errorf("expected %v, got %v", exp, estimate(1000, 100, 1))
+	}
+
+	// Invalid redundancies and empty host sets should be rejected.
+	if _, err := r.EstimateUploadCost(1000, 100, 0.5); err != errInvalidRedundancy {
+		t.Error("expected errInvalidRedundancy, got", err)
+	}
+	r.hostDB = pricedHostDB{}
+	if _, err := r.EstimateUploadCost(1000, 100, 1); err != errNoHostPrices {
+		t.Error("expected errNoHostPrices, got", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -20,6 +21,18 @@ var (
 	// errFileDeleted indicates that a chunk which is trying to be repaired
 	// cannot be found in the renter.
 	errFileDeleted = errors.New("cannot repair chunk as the file is not being tracked by the renter")
+
+	// errInvalidRepairThreshold is returned when the repair threshold is set
+	// to a redundancy at which a file could already be unrecoverable.
+	errInvalidRepairThreshold = errors.New("repair threshold must be 0 or at least 1")
+
+	// errInvalidUploadWorkers is returned when the number of upload workers
+	// is set to a negative value.
+	errInvalidUploadWorkers = errors.New("number of upload workers cannot be negative")
+
+	// errUploadPaused indicates that a chunk cannot be repaired because the
+	// upload of its file has been paused.
+	errUploadPaused = errors.New("cannot repair chunk as the upload of its file is paused")
 )
 
 type (
@@ -70,12 +83,16 @@ type (
 		// from hosts.
 		//
 		// workerSet tracks the set of workers which can be used for uploading.
+		//
+		// maxActiveWorkers limits the number of workers that may be uploading
+		// at once. A value of 0 means that there is no limit.
 		activeWorkers     map[types.FileContractID]*worker
 		availableWorkers  map[types.FileContractID]*worker
 		gapCounts         map[int]int
 		incompleteChunks  map[chunkID]*chunkStatus
 		downloadingChunks map[chunkID]struct{}
 		cachedChunks      map[chunkID][]byte
+		maxActiveWorkers  int
 		resultChan        chan finishedUpload
 	}
 )
@@ -99,15 +116,29 @@ func (cs *chunkStatus) numGaps(rs *repairState) int {
 	return pieceGaps
 }
 
+// uploadSlots returns the number of additional workers that can be given
+// upload work without exceeding maxActiveWorkers, or -1 if there is no limit.
+func (rs *repairState) uploadSlots() int {
+	if rs.maxActiveWorkers == 0 {
+		return -1
+	}
+	if len(rs.activeWorkers) >= rs.maxActiveWorkers {
+		return 0
+	}
+	return rs.maxActiveWorkers - len(rs.activeWorkers)
+}
+
 // addFileToRepairState will take a file and add each of the incomplete chunks
 // to the repair state, along with data about which pieces need attention.
 func (r *Renter) addFileToRepairState(rs *repairState, file *file) {
 	// Check that the file is being tracked, and therefor candidate for repair.
 	file.mu.Lock()
 	_, exists := r.tracking[file.name]
+	_, paused := r.pausedUploads[file.name]
 	file.mu.Unlock()
-	if !exists {
-		// File is not being tracked, don't add it to the repair state.
+	if !exists || paused {
+		// File is not being tracked or is paused, don't add it to the repair
+		// state.
 		return
 	}
 
@@ -124,9 +155,11 @@ func (r *Renter) addFileToRepairState(rs *repairState, file *file) {
 	// chunk.
 	chunkCount := file.numChunks()
 	availablePieces := make([]map[uint64]struct{}, chunkCount)
+	uploadedPieces := make([]map[uint64]struct{}, chunkCount)
 	utilizedContracts := make([]map[types.FileContractID]struct{}, chunkCount)
 	for i := uint64(0); i < chunkCount; i++ {
 		availablePieces[i] = make(map[uint64]struct{})
+		uploadedPieces[i] = make(map[uint64]struct{})
 		utilizedContracts[i] = make(map[types.FileContractID]struct{})
 	}
 
@@ -140,6 +173,7 @@ func (r *Renter) addFileToRepairState(rs *repairState, file *file) {
 		// Scan all of the pieces of the contract.
 		for _, piece := range contract.Pieces {
 			utilizedContracts[piece.Chunk][contract.ID] = struct{}{}
+			uploadedPieces[piece.Chunk][piece.Piece] = struct{}{}
 
 			// Only mark the piece as complete if the piece can be recovered.
 			//
@@ -155,10 +189,19 @@ func (r *Renter) addFileToRepairState(rs *repairState, file *file) {
 	// Create the chunkStatus object for each chunk and add it to the set of
 	// incomplete chunks.
 	for i := uint64(0); i < chunkCount; i++ {
-		// Skip this chunk if all pieces have been uploaded.
+		// Skip this chunk if all pieces are available.
 		if len(availablePieces[i]) >= file.erasureCode.NumPieces() {
 			continue
 		}
+		// The repair threshold only applies to chunks that have been fully
+		// uploaded at some point. Chunks that are still being uploaded for
+		// the first time are always queued, so that they reach full
+		// redundancy.
+		fullyUploaded := len(uploadedPieces[i]) >= file.erasureCode.NumPieces()
+		redundancy := float64(len(availablePieces[i])) / float64(file.erasureCode.MinPieces())
+		if fullyUploaded && r.repairThreshold > 0 && redundancy >= r.repairThreshold {
+			continue
+		}
 
 		// Skip this chunk if it's already in the set of incomplete chunks.
 		cid := chunkID{i, file.name}
@@ -229,6 +272,7 @@ func (r *Renter) managedRepairIteration(rs *repairState) {
 
 		rs.availableWorkers[id] = worker
 	}
+	rs.maxActiveWorkers = r.uploadWorkers
 	r.mu.Unlock(id)
 
 	// Determine the maximum number of gaps of any chunk in the repair matrix.
@@ -250,6 +294,11 @@ func (r *Renter) managedRepairIteration(rs *repairState) {
 	// Scan through the chunks until a candidate for uploads is found.
 	var chunksToDelete []chunkID
 	for chunkID, chunkStatus := range rs.incompleteChunks {
+		// Stop scheduling work once the limit on concurrent uploads has been
+		// reached.
+		if rs.uploadSlots() == 0 {
+			break
+		}
 		// check if the chunk is currently being downloaded for recovery
 		if _, downloading := rs.downloadingChunks[chunkID]; downloading {
 			continue
@@ -296,7 +345,11 @@ func (r *Renter) managedRepairIteration(rs *repairState) {
 
 		// Send off the work.
 		err := r.managedScheduleChunkRepair(rs, chunkID, chunkStatus, usefulWorkers)
-		if err != nil {
+		if err == errUploadPaused {
+			// The chunk will be added again when the upload is resumed.
+			chunksToDelete = append(chunksToDelete, chunkID)
+			continue
+		} else if err != nil {
 			r.log.Println("Unable to repair chunk:", err)
 			chunksToDelete = append(chunksToDelete, chunkID)
 			continue
@@ -380,9 +433,12 @@ func (r *Renter) managedScheduleChunkRepair(rs *repairState, chunkID chunkID, ch
 	id := r.mu.RLock()
 	file, exists1 := r.files[filename]
 	meta, exists2 := r.tracking[filename]
+	_, paused := r.pausedUploads[filename]
 	r.mu.RUnlock(id)
 	if !exists1 || !exists2 {
 		return errFileDeleted
+	} else if paused {
+		return errUploadPaused
 	}
 
 	// read the chunk into memory
@@ -414,15 +470,23 @@ func (r *Renter) managedScheduleChunkRepair(rs *repairState, chunkID chunkID, ch
 		}
 	}
 
-	// Truncate the pieces so that they match the size of the useful workers.
+	// Truncate the useful workers so that the limit on concurrent uploads is
+	// respected, and then truncate the pieces so that they match the size of
+	// the useful workers.
+	if slots := rs.uploadSlots(); slots >= 0 && len(usefulWorkers) > slots {
+		usefulWorkers = usefulWorkers[:slots]
+	}
 	if len(usefulWorkers) < len(missingPieces) {
 		missingPieces = missingPieces[:len(usefulWorkers)]
 	}
 
-	// Encrypt the missing pieces.
+	// Encrypt the missing pieces. The key of each piece is derived from its
+	// chunk and piece index, so no additional data is needed to bind the
+	// ciphertext to its position in the file. This also keeps the format
+	// compatible with pieces uploaded before AEAD was introduced.
 	for _, missingPiece := range missingPieces {
 		key := deriveKey(file.masterKey, chunkID.index, uint64(missingPiece))
-		pieces[missingPiece] = key.EncryptBytes(pieces[missingPiece])
+		pieces[missingPiece] = crypto.EncryptAEAD(key, pieces[missingPiece], nil)
 	}
 
 	// Give each piece to a worker in the set of useful workers.
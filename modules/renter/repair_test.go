@@ -0,0 +1,311 @@
+package renter
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// onlineContractor is a hostContractor that reports every contract as online.
+type onlineContractor struct {
+	hostContractor
+}
+
+func (onlineContractor) IsOffline(types.FileContractID) bool { return false }
+
+// offlineContractor is a hostContractor that reports the contracts in its set
+// as offline.
+type offlineContractor struct {
+	hostContractor
+	offline map[types.FileContractID]bool
+}
+
+func (oc offlineContractor) IsOffline(id types.FileContractID) bool { return oc.offline[id] }
+
+// TestRepairThreshold checks that only uploaded chunks whose redundancy has
+// fallen below the repair threshold are added to the repair state.
+func TestRepairThreshold(t *testing.T) {
+	// Create a file with three fully uploaded chunks and 3x redundancy. The
+	// first chunk is complete, but some of the hosts of the second and third
+	// chunks are offline, leaving them with 1.6x and 1.4x redundancy.
+	rsc, _ := NewRSCode(10, 20)
+	f := newFile("foo", rsc, 1, 30)
+	oc := offlineContractor{offline: make(map[types.FileContractID]bool)}
+	for chunk, numOnline := range []int{30, 16, 14} {
+		for piece := 0; piece < rsc.NumPieces(); piece++ {
+			var id types.FileContractID
+			id[0], id[1] = byte(chunk), byte(piece)
+			f.contracts[id] = fileContract{
+				ID:     id,
+				Pieces: []pieceData{{Chunk: uint64(chunk), Piece: uint64(piece)}},
+			}
+			oc.offline[id] = piece >= numOnline
+		}
+	}
+	r := &Renter{
+		tracking:       map[string]trackedFile{"foo": {}},
+		hostContractor: oc,
+	}
+
+	// incompleteChunks returns the indices of the chunks that would be
+	// repaired with the provided threshold.
+	incompleteChunks := func(threshold float64) map[uint64]bool {
+		r.repairThreshold = threshold
+		rs := &repairState{
+			gapCounts:        make(map[int]int),
+			incompleteChunks: make(map[chunkID]*chunkStatus),
+		}
+		r.addFileToRepairState(rs, f)
+		chunks := make(map[uint64]bool)
+		for cid := range rs.incompleteChunks {
+			chunks[cid.index] = true
+		}
+		return chunks
+	}
+
+	// By default, any chunk missing pieces is repaired.
+	if chunks := incompleteChunks(0); len(chunks) != 2 || !chunks[1] || !chunks[2] {
+		t.Error("expected chunks 1 and 2 to be repaired, got", chunks)
+	}
+	// With a threshold of 1.5, only the third chunk is repaired.
+	if chunks := incompleteChunks(1.5); len(chunks) != 1 || !chunks[2] {
+		t.Error("expected chunk 2 to be repaired, got", chunks)
+	}
+	// With a threshold of 1.2, no chunks are repaired.
+	if chunks := incompleteChunks(1.2); len(chunks) != 0 {
+		t.Error("expected no chunks to be repaired, got", chunks)
+	}
+	// A threshold above the file's redundancy repairs every incomplete chunk.
+	if chunks := incompleteChunks(5); len(chunks) != 2 || !chunks[1] || !chunks[2] {
+		t.Error("expected chunks 1 and 2 to be repaired, got", chunks)
+	}
+
+	// Thresholds below 1 are rejected.
+	for _, ratio := range []float64{-1, 0.5, math.NaN()} {
+		if err := r.SetRepairThreshold(ratio); err != errInvalidRepairThreshold {
+			t.Errorf("expected errInvalidRepairThreshold for %v, got %v", ratio, err)
+		}
+	}
+}
+
+// TestRepairThresholdNewUpload checks that the repair threshold does not stop
+// a new upload before it reaches full redundancy.
+func TestRepairThresholdNewUpload(t *testing.T) {
+	rsc, _ := NewRSCode(10, 20)
+	f := newFile("foo", rsc, 1, 10)
+	r := &Renter{
+		tracking:        map[string]trackedFile{"foo": {}},
+		hostContractor:  onlineContractor{},
+		repairThreshold: 1.5,
+	}
+
+	// queued reports whether the chunk is added to the repair state.
+	queued := func() bool {
+		rs := &repairState{
+			gapCounts:        make(map[int]int),
+			incompleteChunks: make(map[chunkID]*chunkStatus),
+		}
+		r.addFileToRepairState(rs, f)
+		_, exists := rs.incompleteChunks[chunkID{0, "foo"}]
+		return exists
+	}
+
+	// Upload the pieces of the chunk one at a time. The chunk must be queued
+	// for repair until every piece has been uploaded, even once its
+	// redundancy is above the threshold.
+	for piece := 0; piece < rsc.NumPieces(); piece++ {
+		if !queued() {
+			t.Fatalf("chunk with %v of %v pieces uploaded was not queued", piece, rsc.NumPieces())
+		}
+		var id types.FileContractID
+		id[0] = byte(piece)
+		f.contracts[id] = fileContract{
+			ID:     id,
+			Pieces: []pieceData{{Chunk: 0, Piece: uint64(piece)}},
+		}
+	}
+	if queued() {
+		t.Fatal("fully uploaded chunk was queued")
+	}
+}
+
+// TestUploadWorkers checks that the number of pieces uploaded in parallel
+// grows with the number of upload workers, and never exceeds it.
+func TestUploadWorkers(t *testing.T) {
+	persistDir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(persistDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := NewRSCode(2, 10)
+	f := newFile("foo", rsc, 64, 128)
+	r := &Renter{
+		files:      map[string]*file{"foo": f},
+		tracking:   map[string]trackedFile{"foo": {}},
+		mu:         sync.New(modules.SafeMutexDelay, 1),
+		persistDir: persistDir,
+	}
+
+	// activeUploads schedules the repair of a chunk with no pieces, using 10
+	// available workers and the provided number of busy workers, and returns
+	// the number of workers that are uploading afterwards.
+	cid := chunkID{index: 0, filename: "foo"}
+	activeUploads := func(busy int) int {
+		id := r.mu.RLock()
+		limit := r.uploadWorkers
+		r.mu.RUnlock(id)
+		rs := &repairState{
+			activeWorkers:    make(map[types.FileContractID]*worker),
+			availableWorkers: make(map[types.FileContractID]*worker),
+			gapCounts:        make(map[int]int),
+			cachedChunks:     map[chunkID][]byte{cid: make([]byte, f.chunkSize())},
+			maxActiveWorkers: limit,
+		}
+		var usefulWorkers []types.FileContractID
+		for i := 0; i < 10+busy; i++ {
+			var wid types.FileContractID
+			wid[0] = byte(i)
+			w := &worker{contractID: wid, uploadChan: make(chan uploadWork, 1)}
+			if i < busy {
+				rs.activeWorkers[wid] = w
+			} else {
+				rs.availableWorkers[wid] = w
+				usefulWorkers = append(usefulWorkers, wid)
+			}
+		}
+		cs := &chunkStatus{
+			contracts:   make(map[types.FileContractID]struct{}),
+			pieces:      make(map[uint64]struct{}),
+			totalPieces: rsc.NumPieces(),
+		}
+		if err := r.managedScheduleChunkRepair(rs, cid, cs, usefulWorkers); err != nil {
+			t.Fatal(err)
+		}
+		return len(rs.activeWorkers)
+	}
+
+	// By default, every available worker is used.
+	if n := activeUploads(0); n != 10 {
+		t.Fatal("expected 10 active uploads, got", n)
+	}
+	// Increasing the number of workers increases the number of concurrent
+	// uploads, up to the limit.
+	for _, limit := range []int{1, 3, 6} {
+		if err := r.SetUploadWorkers(limit); err != nil {
+			t.Fatal(err)
+		}
+		if n := activeUploads(0); n != limit {
+			t.Fatalf("expected %v active uploads, got %v", limit, n)
+		}
+	}
+	// Workers that are already uploading count towards the limit.
+	if n := activeUploads(4); n != 6 {
+		t.Fatal("expected 6 active uploads, got", n)
+	}
+	if n := activeUploads(8); n != 8 {
+		t.Fatal("expected no new uploads once the limit is exceeded, got", n-8)
+	}
+
+	if err := r.SetUploadWorkers(-1); err != errInvalidUploadWorkers {
+		t.Fatal("expected errInvalidUploadWorkers, got", err)
+	}
+}
+
+// TestPauseUpload checks that no pieces of a paused upload are given to
+// workers, and that resuming the upload only uploads the missing pieces.
+func TestPauseUpload(t *testing.T) {
+	// Create a file with two chunks, the first of which is fully uploaded.
+	rsc, _ := NewRSCode(1, 2)
+	f := newFile("foo", rsc, 64, 128)
+	for piece := 0; piece < rsc.NumPieces(); piece++ {
+		var id types.FileContractID
+		id[0] = byte(piece)
+		f.contracts[id] = fileContract{
+			ID:     id,
+			Pieces: []pieceData{{Chunk: 0, Piece: uint64(piece)}},
+		}
+	}
+	r := &Renter{
+		files:          map[string]*file{"foo": f},
+		tracking:       map[string]trackedFile{"foo": {}},
+		pausedUploads:  make(map[string]struct{}),
+		newRepairs:     make(chan *file, 1),
+		hostContractor: onlineContractor{},
+		mu:             sync.New(modules.SafeMutexDelay, 1),
+		tg:             new(sync.ThreadGroup),
+	}
+	newRepairState := func() (*repairState, *worker) {
+		var wid types.FileContractID
+		wid[0] = 0xFF
+		w := &worker{contractID: wid, uploadChan: make(chan uploadWork, 1)}
+		rs := &repairState{
+			activeWorkers:    make(map[types.FileContractID]*worker),
+			availableWorkers: map[types.FileContractID]*worker{wid: w},
+			gapCounts:        make(map[int]int),
+			incompleteChunks: make(map[chunkID]*chunkStatus),
+			cachedChunks:     map[chunkID][]byte{{1, "foo"}: make([]byte, f.chunkSize())},
+		}
+		return rs, w
+	}
+
+	// Queue the file for repair, then pause it. The chunk that was queued
+	// before the pause must not be given to a worker.
+	rs, w := newRepairState()
+	id := r.mu.Lock()
+	r.addFileToRepairState(rs, f)
+	r.mu.Unlock(id)
+	if len(rs.incompleteChunks) != 1 {
+		t.Fatal("expected 1 incomplete chunk, got", len(rs.incompleteChunks))
+	}
+	if err := r.PauseUpload("foo"); err != nil {
+		t.Fatal(err)
+	}
+	cid := chunkID{1, "foo"}
+	err := r.managedScheduleChunkRepair(rs, cid, rs.incompleteChunks[cid], []types.FileContractID{w.contractID})
+	if err != errUploadPaused {
+		t.Fatal("expected errUploadPaused, got", err)
+	}
+	if len(w.uploadChan) != 0 {
+		t.Fatal("paused upload was given to a worker")
+	}
+
+	// While paused, the file should not be added to the repair state.
+	rs, w = newRepairState()
+	id = r.mu.Lock()
+	r.addFileToRepairState(rs, f)
+	r.mu.Unlock(id)
+	if len(rs.incompleteChunks) != 0 {
+		t.Fatal("paused file was added to the repair state")
+	}
+
+	// Resuming the upload should send the file back to the repair loop, which
+	// only repairs the chunk that is missing pieces.
+	if err := r.ResumeUpload("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ResumeUpload("foo"); err != errUploadNotPaused {
+		t.Fatal("expected errUploadNotPaused, got", err)
+	}
+	resumed := <-r.newRepairs
+	id = r.mu.Lock()
+	r.addFileToRepairState(rs, resumed)
+	r.mu.Unlock(id)
+	if _, ok := rs.incompleteChunks[cid]; len(rs.incompleteChunks) != 1 || !ok {
+		t.Fatal("expected only chunk 1 to be repaired, got", rs.incompleteChunks)
+	}
+	err = r.managedScheduleChunkRepair(rs, cid, rs.incompleteChunks[cid], []types.FileContractID{w.contractID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uw := <-w.uploadChan; uw.chunkID != cid {
+		t.Fatal("worker was given the wrong chunk:", uw.chunkID)
+	}
+
+	if err := r.PauseUpload("bar"); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+}
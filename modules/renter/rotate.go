@@ -0,0 +1,141 @@
+package renter
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// rotatingPrefix is prepended to the siapath of a file while it is being
+// re-uploaded under a new key. Users cannot create siapaths with this prefix,
+// and copies left behind by interrupted rotations are removed when the renter
+// starts.
+const rotatingPrefix = ".rotating/"
+
+var (
+	// errRotationInProgress is returned by RotateFileKey if the key of the
+	// file is already being rotated.
+	errRotationInProgress = errors.New("the key of this file is already being rotated")
+
+	// errRotationTimeout is returned by RotateFileKey if the re-encrypted
+	// copy of the file does not reach the redundancy of the original in time.
+	errRotationTimeout = errors.New("timed out waiting for the re-encrypted file to upload")
+)
+
+// RotateFileKey re-encrypts a file under a new master key. The file is
+// downloaded, re-uploaded under a new key to a temporary siapath, and once the
+// new copy is as redundant as the original, the new copy replaces the
+// original. If any step fails, the original file is left untouched and the new
+// copy is deleted. The sectors of the original are not removed from the hosts.
+func (r *Renter) RotateFileKey(siaPath string) (err error) {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	siaPath, err = r.managedResolveSiaPath(siaPath)
+	if err != nil {
+		return err
+	}
+	tmpPath := rotatingPrefix + siaPath
+	lockID := r.mu.RLock()
+	oldFile, exists := r.files[siaPath]
+	_, rotating := r.files[tmpPath]
+	r.mu.RUnlock(lockID)
+	if !exists {
+		return ErrUnknownPath
+	} else if rotating {
+		return errRotationInProgress
+	}
+	oldFile.mu.RLock()
+	targetRedundancy := oldFile.redundancy(r.hostContractor.IsOffline)
+	oldFile.mu.RUnlock()
+
+	// Download the file to a temporary location.
+	tmpFile := filepath.Join(r.persistDir, "rotate-"+hex.EncodeToString(fastrand.Bytes(8)))
+	defer os.Remove(tmpFile)
+	err = r.Download(modules.RenterDownloadParameters{
+		Siapath:     siaPath,
+		Destination: tmpFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Upload it again. Upload generates a new master key for the file.
+	err = r.managedUpload(modules.FileUploadParams{
+		Source:      tmpFile,
+		SiaPath:     tmpPath,
+		ErasureCode: oldFile.erasureCode,
+	})
+	if err != nil {
+		return err
+	}
+	// Delete the new copy if it does not replace the original.
+	defer func() {
+		if err != nil {
+			r.DeleteFile(tmpPath)
+		}
+	}()
+
+	// Wait for the new copy to be as redundant as the original.
+	deadline := time.After(rotationTimeout)
+	for {
+		lockID := r.mu.RLock()
+		newFile, exists := r.files[tmpPath]
+		r.mu.RUnlock(lockID)
+		if !exists {
+			return errors.New("re-encrypted copy of the file was removed")
+		}
+		newFile.mu.RLock()
+		redundancy := newFile.redundancy(r.hostContractor.IsOffline)
+		newFile.mu.RUnlock()
+		if redundancy >= targetRedundancy {
+			break
+		}
+		select {
+		case <-time.After(rotationPollInterval):
+		case <-deadline:
+			return errRotationTimeout
+		case <-r.tg.StopChan():
+			return errors.New("key rotation interrupted by shutdown")
+		}
+	}
+
+	// Replace the original file with the new copy. The new copy continues to
+	// be repaired from the source of the original.
+	return r.managedReplaceRotatedFile(siaPath, tmpPath, oldFile)
+}
+
+// managedReplaceRotatedFile replaces oldFile, stored at siaPath, with the
+// re-encrypted copy stored at tmpPath.
+func (r *Renter) managedReplaceRotatedFile(siaPath, tmpPath string, oldFile *file) error {
+	lockID := r.mu.Lock()
+	defer r.mu.Unlock(lockID)
+	newFile, exists := r.files[tmpPath]
+	if !exists || r.files[siaPath] != oldFile {
+		return errors.New("file was modified during key rotation")
+	}
+	newFile.mu.Lock()
+	newFile.name = siaPath
+	newFile.mode = oldFile.mode
+	err := r.saveFile(newFile)
+	if err != nil {
+		newFile.name = tmpPath
+	}
+	newFile.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	r.files[siaPath] = newFile
+	delete(r.files, tmpPath)
+	delete(r.tracking, tmpPath)
+	delete(r.pausedUploads, tmpPath)
+	return build.ComposeErrors(r.saveSync(), os.RemoveAll(filepath.Join(r.persistDir, tmpPath+ShareExtension)))
+}
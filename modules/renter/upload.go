@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -12,6 +14,18 @@ import (
 )
 
 var (
+	// errUploadNotPaused is returned by ResumeUpload if the upload is not
+	// paused.
+	errUploadNotPaused = errors.New("upload is not paused")
+
+	// errInvalidSiapathChars is returned when a siapath contains control
+	// characters or invalid UTF-8.
+	errInvalidSiapathChars = errors.New("siapath contains invalid characters")
+
+	// errReservedSiapath is returned when a siapath begins with a prefix that
+	// the renter reserves for its own use.
+	errReservedSiapath = errors.New("siapaths beginning with " + rotatingPrefix + " are reserved")
+
 	errInsufficientContracts = errors.New("not enough contracts to upload file")
 	errUploadDirectory       = errors.New("cannot upload directory")
 
@@ -66,9 +80,77 @@ func validateSiapath(siapath string) error {
 		return errors.New("siapath contains invalid characters")
 	}
 
+	if strings.HasPrefix(siapath, rotatingPrefix) {
+		return errReservedSiapath
+	}
+
 	return nil
 }
 
+// normalizeSiaPath returns the canonical form of siapath. Backslashes are
+// treated as separators, repeated separators are collapsed, and trailing
+// separators are removed, so that e.g. "foo/bar/", "foo//bar", and "foo\bar"
+// all refer to "foo/bar". The normalized path is then validated.
+func normalizeSiaPath(siapath string) (string, error) {
+	if !utf8.ValidString(siapath) {
+		return "", errInvalidSiapathChars
+	}
+	for _, c := range siapath {
+		if unicode.IsControl(c) {
+			return "", errInvalidSiapathChars
+		}
+	}
+	siapath = strings.Replace(siapath, "\\", "/", -1)
+	for strings.Contains(siapath, "//") {
+		siapath = strings.Replace(siapath, "//", "/", -1)
+	}
+	siapath = strings.TrimSuffix(siapath, "/")
+	if err := validateSiapath(siapath); err != nil {
+		return "", err
+	}
+	return siapath, nil
+}
+
+// NormalizeSiaPath returns the canonical form of siaPath, or an error if
+// siaPath is invalid. All siapaths passed to the renter are normalized before
+// use.
+func (r *Renter) NormalizeSiaPath(siaPath string) (string, error) {
+	return normalizeSiaPath(siaPath)
+}
+
+// resolveSiaPath returns the siapath under which the renter tracks the file
+// at siaPath. Files tracked before siapaths were normalized may be stored
+// under a siapath that is not in canonical form, or that is no longer valid,
+// so an exact match is preferred over the normalized siapath.
+func (r *Renter) resolveSiaPath(siaPath string) (string, error) {
+	if _, exists := r.files[siaPath]; exists {
+		return siaPath, nil
+	}
+	return normalizeSiaPath(siaPath)
+}
+
+// managedResolveSiaPath calls resolveSiaPath while holding the renter lock.
+func (r *Renter) managedResolveSiaPath(siaPath string) (string, error) {
+	lockID := r.mu.RLock()
+	defer r.mu.RUnlock(lockID)
+	return r.resolveSiaPath(siaPath)
+}
+
+// siaPathInUse reports whether any tracked file has a siapath that
+// normalizes to siaPath. Files tracked before siapaths were normalized may
+// be stored under a non-canonical siapath, so every file must be checked.
+func (r *Renter) siaPathInUse(siaPath string) bool {
+	if _, exists := r.files[siaPath]; exists {
+		return true
+	}
+	for name := range r.files {
+		if n, err := normalizeSiaPath(name); err == nil && n == siaPath {
+			return true
+		}
+	}
+	return false
+}
+
 // validateSource verifies that a sourcePath meets the
 // requirements for upload.
 func validateSource(sourcePath string) error {
@@ -87,10 +169,17 @@ func validateSource(sourcePath string) error {
 // automatically upload and repair tracked files using a background loop.
 func (r *Renter) Upload(up modules.FileUploadParams) error {
 	// Enforce nickname rules.
-	if err := validateSiapath(up.SiaPath); err != nil {
+	siaPath, err := normalizeSiaPath(up.SiaPath)
+	if err != nil {
 		return err
 	}
+	up.SiaPath = siaPath
+	return r.managedUpload(up)
+}
 
+// managedUpload starts tracking a file at up.SiaPath, which must already be
+// normalized. Unlike Upload, it accepts reserved siapaths.
+func (r *Renter) managedUpload(up modules.FileUploadParams) error {
 	// Enforce source rules.
 	if err := validateSource(up.Source); err != nil {
 		return err
@@ -98,7 +187,7 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 
 	// Check for a nickname conflict.
 	lockID := r.mu.RLock()
-	_, exists := r.files[up.SiaPath]
+	exists := r.siaPathInUse(up.SiaPath)
 	r.mu.RUnlock(lockID)
 	if exists {
 		return ErrPathOverload
@@ -141,3 +230,56 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 	r.newRepairs <- f
 	return nil
 }
+
+// PauseUpload stops the renter from uploading pieces of the file at siaPath.
+// Pieces that are already being uploaded are allowed to finish, and the
+// pieces that have been uploaded are kept. Other uploads are unaffected.
+func (r *Renter) PauseUpload(siaPath string) error {
+	siaPath, err := r.managedResolveSiaPath(siaPath)
+	if err != nil {
+		return err
+	}
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	if _, exists := r.files[siaPath]; !exists {
+		return ErrUnknownPath
+	}
+	r.pausedUploads[siaPath] = struct{}{}
+	return r.saveSync()
+}
+
+// ResumeUpload resumes uploading a file that was paused with PauseUpload. Only
+// the pieces that are still missing are uploaded.
+func (r *Renter) ResumeUpload(siaPath string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	siaPath, err := r.managedResolveSiaPath(siaPath)
+	if err != nil {
+		return err
+	}
+	id := r.mu.Lock()
+	f, exists := r.files[siaPath]
+	_, paused := r.pausedUploads[siaPath]
+	delete(r.pausedUploads, siaPath)
+	err = r.saveSync()
+	r.mu.Unlock(id)
+	if !exists {
+		return ErrUnknownPath
+	} else if !paused {
+		return errUploadNotPaused
+	} else if err != nil {
+		return err
+	}
+
+	// Send the file back to the repair loop.
+	go func() {
+		select {
+		case r.newRepairs <- f:
+		case <-r.tg.StopChan():
+		}
+	}()
+	return nil
+}
@@ -72,3 +72,127 @@ func TestRenterUploadInode(t *testing.T) {
 		t.Fatal("expected errUploadDirectory, got", err)
 	}
 }
+
+// TestNormalizeSiaPath checks that equivalent siapaths normalize to the same
+// path, and that invalid siapaths are rejected.
+func TestNormalizeSiaPath(t *testing.T) {
+	var pathtests = []struct {
+		in  string
+		out string
+	}{
+		{"foo", "foo"},
+		{"foo/", "foo"},
+		{"foo//", "foo"},
+		{"foo/bar", "foo/bar"},
+		{"foo//bar/", "foo/bar"},
+		{"foo\\bar", "foo/bar"},
+		{"foo/bar\\", "foo/bar"},
+	}
+	for _, pathtest := range pathtests {
+		out, err := normalizeSiaPath(pathtest.in)
+		if err != nil {
+			t.Fatalf("normalizing %q failed: %v", pathtest.in, err)
+		} else if out != pathtest.out {
+			t.Fatalf("expected %q to normalize to %q, got %q", pathtest.in, pathtest.out, out)
+		}
+	}
+
+	for _, in := range []string{"", "/", "//", "/foo", "\\foo", "foo/../bar", "foo\x00bar", "foo\nbar", "foo\xffbar"} {
+		if _, err := normalizeSiaPath(in); err == nil {
+			t.Fatalf("normalizing %q should have failed", in)
+		}
+	}
+}
+
+// TestRenterUploadPathCollision checks that uploading to a siapath that
+// normalizes to the siapath of an existing file is rejected.
+func TestRenterUploadPathCollision(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	source, err := ioutil.TempFile("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(source.Name())
+	source.Write([]byte("foo"))
+	source.Close()
+
+	ec, err := NewRSCode(defaultDataPieces, defaultParityPieces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := modules.FileUploadParams{
+		Source:      source.Name(),
+		SiaPath:     "foo/bar/",
+		ErasureCode: ec,
+	}
+	if err := rt.renter.Upload(params); err != nil {
+		t.Fatal(err)
+	}
+	files := rt.renter.FileList()
+	if len(files) != 1 || files[0].SiaPath != "foo/bar" {
+		t.Fatal("file was not stored under its normalized siapath:", files)
+	}
+
+	for _, siaPath := range []string{"foo/bar", "foo//bar", "foo\\bar/"} {
+		params.SiaPath = siaPath
+		if err := rt.renter.Upload(params); err != ErrPathOverload {
+			t.Fatalf("uploading to %q: expected ErrPathOverload, got %v", siaPath, err)
+		}
+	}
+
+	// Other methods should also accept non-canonical siapaths.
+	if err := rt.renter.RenameFile("foo//bar/", "baz/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.renter.DeleteFile("baz"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRenterLegacySiaPath checks that files tracked under siapaths that are
+// not in canonical form remain reachable by their exact siapath.
+func TestRenterLegacySiaPath(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	legacyPaths := []string{"foo\\bar", "foo//bar", "foo/bar/", "foo\tbar"}
+	for _, siaPath := range legacyPaths {
+		f := newTestingFile()
+		f.name = siaPath
+		rt.renter.files[siaPath] = f
+	}
+
+	for _, siaPath := range legacyPaths {
+		if _, err := rt.renter.FileLayout(siaPath); err != nil {
+			t.Fatalf("could not look up %q: %v", siaPath, err)
+		}
+	}
+	if err := rt.renter.RenameFile("foo\tbar", "foo/baz"); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := rt.renter.files["foo/baz"]; !exists {
+		t.Fatal("file was not renamed")
+	}
+	for _, siaPath := range legacyPaths[:3] {
+		if err := rt.renter.DeleteFile(siaPath); err != nil {
+			t.Fatalf("could not delete %q: %v", siaPath, err)
+		}
+	}
+	if len(rt.renter.files) != 1 {
+		t.Fatal("legacy files were not deleted:", len(rt.renter.files))
+	}
+}
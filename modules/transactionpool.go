@@ -19,6 +19,28 @@ const (
 	TransactionSetSizeLimit = 250e3
 )
 
+// The reasons reported to eviction callbacks for transactions that are
+// removed from the transaction pool without being confirmed.
+const (
+	// EvictionReasonConflict indicates that the transaction conflicts with
+	// the consensus set, e.g. because one of its inputs was spent by a
+	// confirmed transaction.
+	EvictionReasonConflict = "conflict"
+
+	// EvictionReasonExpired indicates that the transaction stayed in the pool
+	// for too long without being confirmed.
+	EvictionReasonExpired = "expired"
+
+	// EvictionReasonInvalid indicates that the transaction is no longer
+	// acceptable to the pool for a reason other than a conflict, such as
+	// insufficient fees.
+	EvictionReasonInvalid = "invalid"
+
+	// EvictionReasonPurged indicates that the transaction was purged from the
+	// pool on request.
+	EvictionReasonPurged = "purged"
+)
+
 var (
 	// ErrDuplicateTransactionSet is the error that gets returned if a
 	// duplicate transaction set is given to the transaction pool.
@@ -117,6 +139,13 @@ type (
 		// within 10 blocks.
 		FeeEstimation() (minimumRecommended, maximumRecommended types.Currency)
 
+		// PackageFeeRate returns the fee rate, in currency per byte, of the
+		// unconfirmed transaction with the provided id combined with its
+		// unconfirmed ancestors, and combined with its unconfirmed
+		// descendants. Both rates are zero if the transaction is not in the
+		// pool.
+		PackageFeeRate(id types.TransactionID) (ancestorRate, descendantRate types.Currency)
+
 		// PurgeTransactionPool is a temporary function available to the miner. In
 		// the event that a miner mines an unacceptable block, the transaction pool
 		// will be purged to clear out the transaction pool and get rid of the
@@ -124,6 +153,21 @@ type (
 		// that make this condition necessary.
 		PurgeTransactionPool()
 
+		// PurgeByAddress removes all transactions that spend from or pay to
+		// the provided address, along with their dependents, from the local
+		// transaction pool. Peers are not informed of the removal.
+		PurgeByAddress(types.UnlockHash) error
+
+		// RegisterEvictionCallback registers a function that is called with
+		// the transactions that are removed from the pool without being
+		// confirmed, along with the reason for their eviction.
+		RegisterEvictionCallback(func(txns []types.Transaction, reason string))
+
+		// SetMinRelayFee sets the minimum fee, in currency per byte, that a
+		// transaction set must pay to be accepted into the pool and relayed
+		// to peers.
+		SetMinRelayFee(feePerByte types.Currency)
+
 		// TransactionList returns a list of all transactions in the transaction
 		// pool. The transactions are provided in an order that can acceptably be
 		// put into a block.
@@ -20,6 +20,7 @@ var (
 	errObjectConflict      = errors.New("transaction set conflicts with an existing transaction set")
 	errFullTransactionPool = errors.New("transaction pool cannot accept more transactions")
 	errLowMinerFees        = errors.New("transaction set needs more miner fees to be accepted")
+	errBelowMinRelayFee    = errors.New("transaction set pays less than the minimum relay fee")
 	errEmptySet            = errors.New("transaction set is empty")
 )
 
@@ -73,6 +74,15 @@ func (tp *TransactionPool) requiredFeesToExtendTpool() types.Currency {
 	return types.SiacoinPrecision.MulFloat(feeFactor).Div64(1000) // Divide by 1000 to get SC / kb
 }
 
+// SetMinRelayFee sets the minimum fee, in currency per byte, that a
+// transaction set must pay to be accepted into the pool and relayed to peers.
+// Transaction sets already in the pool are not affected.
+func (tp *TransactionPool) SetMinRelayFee(feePerByte types.Currency) {
+	tp.mu.Lock()
+	tp.minRelayFee = feePerByte
+	tp.mu.Unlock()
+}
+
 // checkTransactionSetComposition checks if the transaction set is valid given
 // the state of the pool. It does not check that each individual transaction
 // would be legal in the next block, but does check things like miner fees and
@@ -157,7 +167,7 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 		supersetMap[conflict] = struct{}{}
 	}
 	for conflict := range supersetMap {
-		superset = append(superset, tp.transactionSets[conflict]...)
+		superset = append(superset, types.UnwrapTransactionSet(tp.transactionSets[conflict])...)
 	}
 	superset = append(superset, dedupSet...)
 
@@ -180,6 +190,9 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 			setFees = setFees.Add(fee)
 		}
 	}
+	if tp.minRelayFee.Mul64(setSize).Cmp(setFees) > 0 {
+		return errBelowMinRelayFee
+	}
 	if requiredFees.Cmp(setFees) > 0 {
 		// TODO: check if there is an existing set with lower fees that we can
 		// kick out.
@@ -194,7 +207,7 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 
 	// Remove the conflicts from the transaction pool.
 	for conflict := range supersetMap {
-		conflictSet := tp.transactionSets[conflict]
+		conflictSet := types.UnwrapTransactionSet(tp.transactionSets[conflict])
 		tp.transactionListSize -= len(encoding.Marshal(conflictSet))
 		delete(tp.transactionSets, conflict)
 		delete(tp.transactionSetDiffs, conflict)
@@ -202,7 +215,7 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 
 	// Add the transaction set to the pool.
 	setID := TransactionSetID(crypto.HashObject(superset))
-	tp.transactionSets[setID] = superset
+	tp.transactionSets[setID] = types.NewCachedTransactionSet(superset)
 	for _, diff := range cc.SiacoinOutputDiffs {
 		tp.knownObjects[ObjectID(diff.ID)] = setID
 	}
@@ -266,6 +279,9 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn fu
 			setFees = setFees.Add(fee)
 		}
 	}
+	if tp.minRelayFee.Mul64(setSize).Cmp(setFees) > 0 {
+		return errBelowMinRelayFee
+	}
 	if requiredFees.Cmp(setFees) > 0 {
 		// TODO: check if there is an existing set with lower fees that we can
 		// kick out.
@@ -293,7 +309,7 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn fu
 
 	// Add the transaction set to the pool.
 	setID := TransactionSetID(crypto.HashObject(ts))
-	tp.transactionSets[setID] = ts
+	tp.transactionSets[setID] = types.NewCachedTransactionSet(ts)
 	for _, oid := range oids {
 		tp.knownObjects[oid] = setID
 	}
@@ -3,6 +3,7 @@ package transactionpool
 import (
 	"testing"
 
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
@@ -226,6 +227,72 @@ func TestCheckMinerFees(t *testing.T) {
 	}
 }
 
+// TestMinRelayFee checks that transaction sets paying less than the minimum
+// relay fee are rejected, while those paying at least the minimum are
+// accepted.
+func TestMinRelayFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create an output to spend in a transaction graph.
+	fund := types.SiacoinPrecision.Mul64(1000)
+	txns, err := tpt.wallet.SendSiacoins(fund, types.UnlockConditions{}.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var source types.SiacoinOutputID
+	for i, sco := range txns[len(txns)-1].SiacoinOutputs {
+		if sco.UnlockHash == (types.UnlockConditions{}.UnlockHash()) && sco.Value.Equals(fund) {
+			source = txns[len(txns)-1].SiacoinOutputID(uint64(i))
+		}
+	}
+	fee := types.SiacoinPrecision
+	graph, err := types.TransactionGraph(source, []types.TransactionGraphEdge{{
+		Dest:   1,
+		Fee:    fee,
+		Source: 0,
+		Value:  fund.Sub(fee),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var size uint64
+	for _, txn := range graph {
+		size += uint64(len(encoding.Marshal(txn)))
+	}
+	feePerByte := fee.Div64(size)
+
+	// A floor just above the graph's fee rate should cause it to be rejected.
+	tpt.tpool.SetMinRelayFee(feePerByte.Add(types.NewCurrency64(1)))
+	err = tpt.tpool.AcceptTransactionSet(graph)
+	if err != errBelowMinRelayFee {
+		t.Fatal("expected errBelowMinRelayFee, got", err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Fatal("rejected transaction set was added to the pool")
+	}
+	if min, _ := tpt.tpool.FeeEstimation(); min.Cmp(feePerByte) <= 0 {
+		t.Fatal("fee estimation is below the minimum relay fee:", min)
+	}
+
+	// A floor equal to the graph's fee rate should allow it.
+	tpt.tpool.SetMinRelayFee(feePerByte)
+	err = tpt.tpool.AcceptTransactionSet(graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestTransactionGraph checks that the TransactionGraph method of the types
 // package is able to create transasctions that actually validate and can get
 // inserted into the tpool.
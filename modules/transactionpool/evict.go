@@ -0,0 +1,66 @@
+package transactionpool
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// evictions groups the transactions that were evicted from the pool by the
+// reason for their eviction.
+type evictions map[string][]types.Transaction
+
+// add records that txns were evicted for the provided reason.
+func (e evictions) add(reason string, txns ...types.Transaction) {
+	e[reason] = append(e[reason], txns...)
+}
+
+// evictionReason returns the eviction reason for a transaction that could not
+// be added back to the pool because of err.
+func evictionReason(err error) string {
+	if _, ok := err.(modules.ConsensusConflict); ok {
+		return modules.EvictionReasonConflict
+	}
+	return modules.EvictionReasonInvalid
+}
+
+// notifyEvictions passes the evicted transactions to the eviction callbacks.
+// The callbacks are called in a separate goroutine, so that they are free to
+// interact with the transaction pool and the consensus set. notifyEvictions
+// must be called while holding a lock on the transaction pool.
+func (tp *TransactionPool) notifyEvictions(e evictions) {
+	if len(e) == 0 || len(tp.evictionCallbacks) == 0 {
+		return
+	}
+	callbacks := make([]func([]types.Transaction, string), len(tp.evictionCallbacks))
+	copy(callbacks, tp.evictionCallbacks)
+	reasons := make([]string, 0, len(e))
+	for reason := range e {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	go func() {
+		if tp.tg.Add() != nil {
+			return
+		}
+		defer tp.tg.Done()
+		for _, reason := range reasons {
+			for _, fn := range callbacks {
+				fn(e[reason], reason)
+			}
+		}
+	}()
+}
+
+// RegisterEvictionCallback registers a function that is called whenever
+// transactions are removed from the pool without being confirmed, e.g.
+// because a conflicting transaction was confirmed, because they expired, or
+// because they were purged. The callback receives the evicted transactions
+// and one of the modules.EvictionReason constants.
+func (tp *TransactionPool) RegisterEvictionCallback(fn func(txns []types.Transaction, reason string)) {
+	tp.mu.Lock()
+	tp.evictionCallbacks = append(tp.evictionCallbacks, fn)
+	tp.mu.Unlock()
+}
@@ -0,0 +1,105 @@
+package transactionpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestEvictionCallback checks that a transaction which is invalidated by a
+// confirmed double-spend is reported to the eviction callbacks as a conflict.
+func TestEvictionCallback(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	type eviction struct {
+		txns   []types.Transaction
+		reason string
+	}
+	evictionChan := make(chan eviction, 10)
+	tpt.tpool.RegisterEvictionCallback(func(txns []types.Transaction, reason string) {
+		evictionChan <- eviction{txns, reason}
+	})
+
+	// Create an output that can be spent without signatures and confirm it.
+	txns, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockConditions{}.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputID := txns[len(txns)-1].SiacoinOutputID(0)
+
+	// Prepare a block that spends the output. The block is created before the
+	// pool transaction is added so that it does not include it.
+	doubleSpend := types.Transaction{
+		SiacoinInputs:  []types.SiacoinInput{{ParentID: outputID}},
+		SiacoinOutputs: []types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(100)}},
+	}
+	block, target, err := tpt.miner.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Transactions = append(block.Transactions, doubleSpend)
+	block, solved := tpt.miner.SolveBlock(block, target)
+	if !solved {
+		t.Fatal("failed to solve block")
+	}
+
+	// Add a transaction spending the same output to the pool.
+	txn := types.Transaction{
+		SiacoinInputs:  []types.SiacoinInput{{ParentID: outputID}},
+		SiacoinOutputs: []types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(90)}},
+		MinerFees:      []types.Currency{types.SiacoinPrecision.Mul64(10)},
+	}
+	err = tpt.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Confirming the double-spend should evict the pool transaction.
+	err = tpt.cs.AcceptBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-evictionChan:
+		if e.reason != modules.EvictionReasonConflict {
+			t.Fatalf("expected reason %q, got %q", modules.EvictionReasonConflict, e.reason)
+		} else if len(e.txns) != 1 || e.txns[0].ID() != txn.ID() {
+			t.Fatal("wrong transactions evicted:", e.txns)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("eviction callback was not called")
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Fatal("evicted transaction is still in the pool")
+	}
+
+	// Purging the pool should report the purged transactions.
+	txns, err = tpt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockConditions{}.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpt.tpool.PurgeTransactionPool()
+	select {
+	case e := <-evictionChan:
+		if e.reason != modules.EvictionReasonPurged {
+			t.Fatalf("expected reason %q, got %q", modules.EvictionReasonPurged, e.reason)
+		} else if len(e.txns) != len(txns) {
+			t.Fatalf("expected %v purged transactions, got %v", len(txns), len(e.txns))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("eviction callback was not called")
+	}
+}
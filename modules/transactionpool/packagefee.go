@@ -0,0 +1,122 @@
+package transactionpool
+
+import (
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// createdObjectIDs returns the ids of the objects created by a transaction.
+func createdObjectIDs(t types.Transaction) []ObjectID {
+	var oids []ObjectID
+	for i := range t.SiacoinOutputs {
+		oids = append(oids, ObjectID(t.SiacoinOutputID(uint64(i))))
+	}
+	for i := range t.FileContracts {
+		oids = append(oids, ObjectID(t.FileContractID(uint64(i))))
+	}
+	for i := range t.SiafundOutputs {
+		oids = append(oids, ObjectID(t.SiafundOutputID(uint64(i))))
+	}
+	return oids
+}
+
+// spentObjectIDs returns the ids of the objects consumed or modified by a
+// transaction.
+func spentObjectIDs(t types.Transaction) []ObjectID {
+	var oids []ObjectID
+	for _, sci := range t.SiacoinInputs {
+		oids = append(oids, ObjectID(sci.ParentID))
+	}
+	for _, fcr := range t.FileContractRevisions {
+		oids = append(oids, ObjectID(fcr.ParentID))
+	}
+	for _, sp := range t.StorageProofs {
+		oids = append(oids, ObjectID(sp.ParentID))
+	}
+	for _, sfi := range t.SiafundInputs {
+		oids = append(oids, ObjectID(sfi.ParentID))
+	}
+	return oids
+}
+
+// packageFeeRate returns the combined fee rate of the transactions in ts at
+// the provided indices.
+func packageFeeRate(ts []types.Transaction, indices map[int]struct{}) types.Currency {
+	var fees types.Currency
+	var size int
+	for i := range indices {
+		for _, fee := range ts[i].MinerFees {
+			fees = fees.Add(fee)
+		}
+		size += len(encoding.Marshal(ts[i]))
+	}
+	return fees.Div64(uint64(size))
+}
+
+// PackageFeeRate returns the fee rate, in currency per byte, of the
+// unconfirmed transaction with the provided id combined with its unconfirmed
+// ancestors, and combined with its unconfirmed descendants. The rates are the
+// total miner fees of each package divided by its total encoded size, which
+// allows a wallet to determine how much fee a child transaction must pay to
+// get a low-fee parent confirmed. Both rates are zero if the transaction is
+// not in the pool.
+//
+// Because dependent transactions are always grouped into the same
+// transaction set, only the set containing the transaction is considered.
+func (tp *TransactionPool) PackageFeeRate(id types.TransactionID) (ancestorRate, descendantRate types.Currency) {
+	tp.mu.Lock()
+	var ts []types.Transaction
+	index := -1
+	for _, tSet := range tp.transactionSets {
+		for i, t := range tSet {
+			if t.ID() == id {
+				ts = types.UnwrapTransactionSet(tSet)
+				index = i
+				break
+			}
+		}
+		if index != -1 {
+			break
+		}
+	}
+	tp.mu.Unlock()
+	if index == -1 {
+		return types.ZeroCurrency, types.ZeroCurrency
+	}
+
+	// Determine which transaction created each object in the set.
+	creators := make(map[ObjectID]int)
+	for i, t := range ts {
+		for _, oid := range createdObjectIDs(t) {
+			creators[oid] = i
+		}
+	}
+	parents := make([][]int, len(ts))
+	children := make([][]int, len(ts))
+	for i, t := range ts {
+		for _, oid := range spentObjectIDs(t) {
+			if j, ok := creators[oid]; ok && j != i {
+				parents[i] = append(parents[i], j)
+				children[j] = append(children[j], i)
+			}
+		}
+	}
+
+	// Walk the dependency graph in each direction from the transaction.
+	walk := func(edges [][]int) map[int]struct{} {
+		seen := map[int]struct{}{index: {}}
+		stack := []int{index}
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, j := range edges[i] {
+				if _, ok := seen[j]; !ok {
+					seen[j] = struct{}{}
+					stack = append(stack, j)
+				}
+			}
+		}
+		return seen
+	}
+	return packageFeeRate(ts, walk(parents)), packageFeeRate(ts, walk(children))
+}
@@ -0,0 +1,94 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPackageFeeRate checks that the package fee rates of a low-fee parent and
+// a high-fee child reflect the combined fees over the combined size.
+func TestPackageFeeRate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	txns, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockConditions{}.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphSourceOutputID := txns[len(txns)-1].SiacoinOutputID(0)
+
+	// Create a graph in which node 1 is a low-fee parent of the high-fee
+	// child node 3, and node 2 is an unrelated sibling.
+	var edges []types.TransactionGraphEdge
+	sources := []int{0, 0, 1, 2, 3}
+	dests := []int{1, 2, 3, 4, 5}
+	values := []uint64{40, 40, 39, 30, 9}
+	fees := []uint64{10, 10, 1, 10, 30}
+	for i := range sources {
+		edges = append(edges, types.TransactionGraphEdge{
+			Dest:   dests[i],
+			Fee:    types.SiacoinPrecision.Mul64(fees[i]),
+			Source: sources[i],
+			Value:  types.SiacoinPrecision.Mul64(values[i]),
+		})
+	}
+	graphTxns, err := types.TransactionGraph(graphSourceOutputID, edges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tpt.tpool.AcceptTransactionSet(graphTxns); err != nil {
+		t.Fatal(err)
+	}
+	parent, child := graphTxns[1], graphTxns[3]
+
+	// rate returns the combined fee rate of ts.
+	rate := func(ts ...types.Transaction) types.Currency {
+		var fees types.Currency
+		var size int
+		for _, t := range ts {
+			for _, fee := range t.MinerFees {
+				fees = fees.Add(fee)
+			}
+			size += len(encoding.Marshal(t))
+		}
+		return fees.Div64(uint64(size))
+	}
+
+	// The descendant rate of the parent should include the child, but not
+	// the sibling.
+	_, descendantRate := tpt.tpool.PackageFeeRate(parent.ID())
+	if !descendantRate.Equals(rate(parent, child)) {
+		t.Fatalf("wrong descendant rate: expected %v, got %v", rate(parent, child), descendantRate)
+	} else if descendantRate.Cmp(rate(parent)) <= 0 {
+		t.Fatal("high-fee child should raise the descendant rate of the parent")
+	}
+
+	// The ancestor rate of the child should include all of its unconfirmed
+	// ancestors, including the wallet transactions that funded the graph.
+	_, ancestors, exists := tpt.tpool.Transaction(child.ID())
+	if !exists {
+		t.Fatal("child is not in the transaction pool")
+	}
+	ancestorRate, descendantRate := tpt.tpool.PackageFeeRate(child.ID())
+	if !ancestorRate.Equals(rate(append(ancestors, child)...)) {
+		t.Fatalf("wrong ancestor rate: expected %v, got %v", rate(append(ancestors, child)...), ancestorRate)
+	} else if ancestorRate.Cmp(rate(child)) >= 0 {
+		t.Fatal("low-fee ancestors should lower the ancestor rate of the child")
+	} else if !descendantRate.Equals(rate(child)) {
+		t.Fatal("child without descendants should have a descendant rate equal to its own rate")
+	}
+
+	// Unknown transactions should have zero rates.
+	ancestorRate, descendantRate = tpt.tpool.PackageFeeRate(types.TransactionID{})
+	if !ancestorRate.IsZero() || !descendantRate.IsZero() {
+		t.Fatal("unknown transaction should have zero package fee rates")
+	}
+}
@@ -0,0 +1,157 @@
+package transactionpool
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// involvesAddress returns true if the transaction spends from or pays to the
+// provided address.
+func involvesAddress(txn types.Transaction, addr types.UnlockHash) bool {
+	for _, sci := range txn.SiacoinInputs {
+		if sci.UnlockConditions.UnlockHash() == addr {
+			return true
+		}
+	}
+	for _, sco := range txn.SiacoinOutputs {
+		if sco.UnlockHash == addr {
+			return true
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if sfi.UnlockConditions.UnlockHash() == addr {
+			return true
+		}
+	}
+	for _, sfo := range txn.SiafundOutputs {
+		if sfo.UnlockHash == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPurgedTransactions splits a transaction set into the transactions that
+// involve the provided address or depend on a transaction that does, and the
+// transactions that remain. The relative order of the remaining transactions
+// is preserved.
+func splitPurgedTransactions(ts []types.Transaction, addr types.UnlockHash) (purged, remaining []types.Transaction) {
+	purgedObjects := make(map[ObjectID]struct{})
+	isDependent := func(txn types.Transaction) bool {
+		for _, sci := range txn.SiacoinInputs {
+			if _, exists := purgedObjects[ObjectID(sci.ParentID)]; exists {
+				return true
+			}
+		}
+		for _, fcr := range txn.FileContractRevisions {
+			if _, exists := purgedObjects[ObjectID(fcr.ParentID)]; exists {
+				return true
+			}
+		}
+		for _, sp := range txn.StorageProofs {
+			if _, exists := purgedObjects[ObjectID(sp.ParentID)]; exists {
+				return true
+			}
+		}
+		for _, sfi := range txn.SiafundInputs {
+			if _, exists := purgedObjects[ObjectID(sfi.ParentID)]; exists {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Transaction sets are ordered such that parents come before their
+	// children, so a single pass is enough to find every dependent.
+	for _, txn := range ts {
+		if !involvesAddress(txn, addr) && !isDependent(txn) {
+			remaining = append(remaining, txn)
+			continue
+		}
+		purged = append(purged, txn)
+		for i := range txn.SiacoinOutputs {
+			purgedObjects[ObjectID(txn.SiacoinOutputID(uint64(i)))] = struct{}{}
+		}
+		for i := range txn.FileContracts {
+			purgedObjects[ObjectID(txn.FileContractID(uint64(i)))] = struct{}{}
+		}
+		for i := range txn.SiafundOutputs {
+			purgedObjects[ObjectID(txn.SiafundOutputID(uint64(i)))] = struct{}{}
+		}
+	}
+	return purged, remaining
+}
+
+// removeTransactionSet removes a transaction set and all of its bookkeeping
+// from the transaction pool.
+func (tp *TransactionPool) removeTransactionSet(setID TransactionSetID) {
+	ts := types.UnwrapTransactionSet(tp.transactionSets[setID])
+	for _, oid := range relatedObjectIDs(ts) {
+		if tp.knownObjects[oid] == setID {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	tp.transactionListSize -= len(encoding.Marshal(ts))
+	delete(tp.transactionSets, setID)
+	delete(tp.transactionSetDiffs, setID)
+}
+
+// PurgeByAddress removes all transactions that spend from or pay to the
+// provided address from the transaction pool, along with every transaction
+// that depends on them. Unrelated transactions that shared a set with a
+// purged transaction are added back to the pool. The purge is local; peers
+// that have already received the transactions are not informed.
+func (tp *TransactionPool) PurgeByAddress(addr types.UnlockHash) error {
+	// assert on consensus set to get special method
+	cs, ok := tp.consensusSet.(interface {
+		LockedTryTransactionSet(fn func(func(txns []types.Transaction) (modules.ConsensusChange, error)) error) error
+	})
+	if !ok {
+		return errors.New("consensus set does not support LockedTryTransactionSet method")
+	}
+
+	return cs.LockedTryTransactionSet(func(txnFn func(txns []types.Transaction) (modules.ConsensusChange, error)) error {
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+
+		// Dependent transactions are always merged into the same set as their
+		// parents, so each set can be purged independently.
+		var remainingSets [][]types.Transaction
+		evicted := make(evictions)
+		for setID, ts := range tp.transactionSets {
+			purged, remaining := splitPurgedTransactions(types.UnwrapTransactionSet(ts), addr)
+			if len(purged) == 0 {
+				continue
+			}
+			tp.removeTransactionSet(setID)
+			evicted.add(modules.EvictionReasonPurged, purged...)
+			for _, txn := range purged {
+				delete(tp.transactionHeights, txn.ID())
+			}
+			if len(remaining) > 0 {
+				remainingSets = append(remainingSets, remaining)
+			}
+		}
+
+		// Add the transactions that were not purged back to the pool. Sets
+		// that are no longer acceptable on their own are dropped.
+		for _, ts := range remainingSets {
+			err := tp.acceptTransactionSet(ts, txnFn)
+			if err != nil {
+				tp.log.Debugln("could not re-add transactions after purging by address:", err)
+				for _, txn := range ts {
+					delete(tp.transactionHeights, txn.ID())
+				}
+				evicted.add(evictionReason(err), ts...)
+			}
+		}
+
+		// Notify subscribers that the purged sets have been removed.
+		tp.updateSubscribersTransactions()
+		tp.notifyEvictions(evicted)
+		return nil
+	})
+}
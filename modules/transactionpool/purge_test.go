@@ -0,0 +1,108 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPurgeByAddress checks that purging by address removes the transactions
+// that involve the address along with their children, while leaving unrelated
+// transactions in the pool.
+func TestPurgeByAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Both addresses can be spent without signatures; the timelock of the
+	// target address has already passed.
+	targetUC := types.UnlockConditions{Timelock: 1}
+	target := targetUC.UnlockHash()
+	other := types.UnlockConditions{}.UnlockHash()
+
+	// Create an unrelated transaction set. It is created first so that the
+	// wallet cannot fund it with the change from the purged transactions.
+	otherTxns, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphTxns, err := types.TransactionGraph(otherTxns[len(otherTxns)-1].SiacoinOutputID(0), []types.TransactionGraphEdge{{
+		Dest:   1,
+		Fee:    types.SiacoinPrecision.Mul64(10),
+		Source: 0,
+		Value:  types.SiacoinPrecision.Mul64(90),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpt.tpool.AcceptTransactionSet(graphTxns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Send coins to the target address, then add a child that spends them
+	// and a grandchild that only depends on the child.
+	targetTxns, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := targetTxns[len(targetTxns)-1]
+	child := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         parent.SiacoinOutputID(0),
+			UnlockConditions: targetUC,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      types.SiacoinPrecision.Mul64(90),
+			UnlockHash: other,
+		}},
+		MinerFees: []types.Currency{types.SiacoinPrecision.Mul64(10)},
+	}
+	grandchild := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID: child.SiacoinOutputID(0),
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      types.SiacoinPrecision.Mul64(80),
+			UnlockHash: other,
+		}},
+		MinerFees: []types.Currency{types.SiacoinPrecision.Mul64(10)},
+	}
+	err = tpt.tpool.AcceptTransactionSet([]types.Transaction{child, grandchild})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tpt.tpool.PurgeByAddress(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inPool := make(map[types.TransactionID]struct{})
+	for _, txn := range tpt.tpool.TransactionList() {
+		inPool[txn.ID()] = struct{}{}
+	}
+	for _, txn := range []types.Transaction{parent, child, grandchild} {
+		if _, exists := inPool[txn.ID()]; exists {
+			t.Error("purged transaction is still in the pool")
+		}
+	}
+	for _, txn := range append(otherTxns, graphTxns...) {
+		if _, exists := inPool[txn.ID()]; !exists {
+			t.Error("unrelated transaction was purged")
+		}
+	}
+
+	// The pool should still accept the unrelated transactions being mined.
+	_, err = tpt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Fatal("transactions remain in the pool after mining a block")
+	}
+}
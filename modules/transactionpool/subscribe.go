@@ -42,7 +42,7 @@ func (tp *TransactionPool) updateSubscribersTransactions() {
 		ids := make([]types.TransactionID, 0, len(set))
 		sizes := make([]uint64, 0, len(set))
 		for i := range set {
-			encodedTxn := encoding.Marshal(set[i])
+			encodedTxn := encoding.Marshal(set[i].Transaction())
 			sizes = append(sizes, uint64(len(encodedTxn)))
 			ids = append(ids, set[i].ID())
 		}
@@ -52,7 +52,7 @@ func (tp *TransactionPool) updateSubscribersTransactions() {
 
 			IDs:          ids,
 			Sizes:        sizes,
-			Transactions: set,
+			Transactions: types.UnwrapTransactionSet(set),
 		}
 		// Add this diff to our set of subscriber diffs.
 		tp.subscriberSets[id] = ut
@@ -48,7 +48,7 @@ type (
 		knownObjects        map[ObjectID]TransactionSetID
 		subscriberSets      map[TransactionSetID]*modules.UnconfirmedTransactionSet
 		transactionHeights  map[types.TransactionID]types.BlockHeight
-		transactionSets     map[TransactionSetID][]types.Transaction
+		transactionSets     map[TransactionSetID][]types.CachedTransaction
 		transactionSetDiffs map[TransactionSetID]*modules.ConsensusChange
 		transactionListSize int
 
@@ -57,12 +57,20 @@ type (
 		recentMedians   []types.Currency
 		recentMedianFee types.Currency // SC per byte
 
+		// minRelayFee is the minimum fee per byte that a transaction set
+		// must pay to be accepted and relayed.
+		minRelayFee types.Currency
+
 		// The consensus change index tracks how many consensus changes have
 		// been sent to the transaction pool. When a new subscriber joins the
 		// transaction pool, all prior consensus changes are sent to the new
 		// subscriber.
 		subscribers []modules.TransactionPoolSubscriber
 
+		// evictionCallbacks are called with the transactions that are removed
+		// from the pool without being confirmed.
+		evictionCallbacks []func([]types.Transaction, string)
+
 		// Utilities.
 		db         *persist.BoltDatabase
 		dbTx       *bolt.Tx
@@ -91,7 +99,7 @@ func New(cs modules.ConsensusSet, g modules.Gateway, persistDir string) (*Transa
 		knownObjects:        make(map[ObjectID]TransactionSetID),
 		subscriberSets:      make(map[TransactionSetID]*modules.UnconfirmedTransactionSet),
 		transactionHeights:  make(map[types.TransactionID]types.BlockHeight),
-		transactionSets:     make(map[TransactionSetID][]types.Transaction),
+		transactionSets:     make(map[TransactionSetID][]types.CachedTransaction),
 		transactionSetDiffs: make(map[TransactionSetID]*modules.ConsensusChange),
 
 		persistDir: persistDir,
@@ -165,6 +173,14 @@ func (tp *TransactionPool) FeeEstimation() (min, max types.Currency) {
 		max = minEstimation.Mul64(maxMultiplier)
 	}
 
+	// Never recommend a fee that would be rejected by the pool.
+	if min.Cmp(tp.minRelayFee) < 0 {
+		min = tp.minRelayFee
+	}
+	if max.Cmp(tp.minRelayFee.Mul64(maxMultiplier)) < 0 {
+		max = tp.minRelayFee.Mul64(maxMultiplier)
+	}
+
 	return
 }
 
@@ -177,7 +193,7 @@ func (tp *TransactionPool) TransactionList() []types.Transaction {
 
 	var txns []types.Transaction
 	for _, tSet := range tp.transactionSets {
-		txns = append(txns, tSet...)
+		txns = append(txns, types.UnwrapTransactionSet(tSet)...)
 	}
 	return txns
 }
@@ -195,8 +211,8 @@ func (tp *TransactionPool) Transaction(id types.TransactionID) (types.Transactio
 	for _, tSet := range tp.transactionSets {
 		for i, t := range tSet {
 			if t.ID() == id {
-				txn = t
-				allParents = tSet[:i]
+				txn = t.Transaction()
+				allParents = types.UnwrapTransactionSet(tSet[:i])
 				exists = true
 				break
 			}
@@ -161,7 +161,7 @@ func findSets(ts []types.Transaction) [][]types.Transaction {
 // purge removes all transactions from the transaction pool.
 func (tp *TransactionPool) purge() {
 	tp.knownObjects = make(map[ObjectID]TransactionSetID)
-	tp.transactionSets = make(map[TransactionSetID][]types.Transaction)
+	tp.transactionSets = make(map[TransactionSetID][]types.CachedTransaction)
 	tp.transactionSetDiffs = make(map[TransactionSetID]*modules.ConsensusChange)
 	tp.transactionListSize = 0
 }
@@ -173,7 +173,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	// Update the database of confirmed transactions.
 	for _, block := range cc.RevertedBlocks {
-		if tp.blockHeight > 0 || block.ID() != types.GenesisID {
+		if tp.blockHeight > 0 || !block.IsGenesis() {
 			tp.blockHeight--
 		}
 		for _, txn := range block.Transactions {
@@ -193,7 +193,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 	}
 	for _, block := range cc.AppliedBlocks {
-		if tp.blockHeight > 0 || block.ID() != types.GenesisID {
+		if tp.blockHeight > 0 || !block.IsGenesis() {
 			tp.blockHeight++
 		}
 		for _, txn := range block.Transactions {
@@ -309,7 +309,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		for _, txn := range tSet {
 			_, exists := txids[txn.ID()]
 			if !exists {
-				newTSet = append(newTSet, txn)
+				newTSet = append(newTSet, txn.Transaction())
 			}
 		}
 		unconfirmedSets = append(unconfirmedSets, newTSet)
@@ -320,6 +320,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 	tp.purge()
 
 	// prune transactions older than maxTxnAge.
+	evicted := make(evictions)
 	for i, tSet := range unconfirmedSets {
 		var validTxns []types.Transaction
 		for _, txn := range tSet {
@@ -328,6 +329,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 				validTxns = append(validTxns, txn)
 			} else {
 				delete(tp.transactionHeights, txn.ID())
+				evicted.add(modules.EvictionReasonExpired, txn)
 			}
 		}
 		unconfirmedSets[i] = validTxns
@@ -346,7 +348,10 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 			}
 
 			// Try adding the transaction back into the transaction pool.
-			tp.acceptTransactionSet([]types.Transaction{txn}, cc.TryTransactionSet) // Error is ignored.
+			err := tp.acceptTransactionSet([]types.Transaction{txn}, cc.TryTransactionSet)
+			if err != nil && err != modules.ErrDuplicateTransactionSet {
+				evicted.add(evictionReason(err), txn)
+			}
 		}
 	}
 
@@ -371,6 +376,9 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 				// The transaction is no longer valid, delete it from the
 				// heights map to prevent a memory leak.
 				delete(tp.transactionHeights, txn.ID())
+				if err != modules.ErrDuplicateTransactionSet {
+					evicted.add(evictionReason(err), txn)
+				}
 			}
 		}
 	}
@@ -378,12 +386,18 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 	// Inform subscribers that an update has executed.
 	tp.mu.Demote()
 	tp.updateSubscribersTransactions()
+	tp.notifyEvictions(evicted)
 	tp.mu.DemotedUnlock()
 }
 
 // PurgeTransactionPool deletes all transactions from the transaction pool.
 func (tp *TransactionPool) PurgeTransactionPool() {
 	tp.mu.Lock()
+	evicted := make(evictions)
+	for _, ts := range tp.transactionSets {
+		evicted.add(modules.EvictionReasonPurged, types.UnwrapTransactionSet(ts)...)
+	}
 	tp.purge()
+	tp.notifyEvictions(evicted)
 	tp.mu.Unlock()
 }
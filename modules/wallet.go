@@ -3,10 +3,12 @@ package modules
 import (
 	"bytes"
 	"errors"
+	"io"
 
 	"github.com/NebulousLabs/entropy-mnemonics"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -23,6 +25,22 @@ const (
 	PublicKeysPerSeed = 2500
 )
 
+// The change address modes determine where a wallet sends the change of a
+// transaction.
+const (
+	// ChangeToNewAddress sends change to a fresh wallet address. This is the
+	// default, and provides the most privacy.
+	ChangeToNewAddress ChangeAddressMode = iota
+
+	// ChangeToInputAddress sends change back to the address of one of the
+	// outputs being spent.
+	ChangeToInputAddress
+
+	// ChangeToFixedAddress sends change to the address specified in the
+	// ChangeAddressPolicy.
+	ChangeToFixedAddress
+)
+
 var (
 	// ErrBadEncryptionKey is returned if the incorrect encryption key to a
 	// file is provided.
@@ -32,6 +50,10 @@ var (
 	// complete the desired action.
 	ErrLowBalance = errors.New("insufficient balance")
 
+	// ErrLargeSendNeedsConfirmation is returned if a send exceeds the
+	// wallet's large send threshold and was not explicitly confirmed.
+	ErrLargeSendNeedsConfirmation = errors.New("send exceeds the large send threshold and must be confirmed")
+
 	// ErrIncompleteTransactions is returned if the wallet has incomplete
 	// transactions being built that are using all of the current outputs, and
 	// therefore the wallet is unable to spend money despite it not technically
@@ -51,6 +73,16 @@ type (
 	// WalletTransactionID is a unique identifier for a wallet transaction.
 	WalletTransactionID crypto.Hash
 
+	// ChangeAddressMode identifies a ChangeAddressPolicy.
+	ChangeAddressMode int
+
+	// A ChangeAddressPolicy determines where a wallet sends the change of a
+	// transaction. Address is only used with ChangeToFixedAddress.
+	ChangeAddressPolicy struct {
+		Mode    ChangeAddressMode `json:"mode"`
+		Address types.UnlockHash  `json:"address"`
+	}
+
 	// A ProcessedInput represents funding to a transaction. The input is
 	// coming from an address and going to the outputs. The fund types are
 	// 'SiacoinInput', 'SiafundInput'.
@@ -81,6 +113,32 @@ type (
 		WalletAddress  bool              `json:"walletaddress"`
 		RelatedAddress types.UnlockHash  `json:"relatedaddress"`
 		Value          types.Currency    `json:"value"`
+
+		// Memo is a local note attached to the output when it was sent. It
+		// is never broadcast, and is not part of the output's encoding.
+		Memo string `json:"memo,omitempty"`
+	}
+
+	// A BalanceProjectionEntry is a future height at which the wallet's
+	// spendable balance increases, because delayed outputs such as miner
+	// payouts mature or because timelocked outputs unlock.
+	BalanceProjectionEntry struct {
+		Height         types.BlockHeight `json:"height"`
+		SpendableDelta types.Currency    `json:"spendabledelta"`
+	}
+
+	// A WalletContact is an entry in the wallet's address book.
+	WalletContact struct {
+		Name    string           `json:"name"`
+		Address types.UnlockHash `json:"address"`
+	}
+
+	// A MemoSend is a payment made by SendSiacoinsWithMemos. The memo is
+	// stored locally and attached to the corresponding ProcessedOutput.
+	MemoSend struct {
+		Dest   types.UnlockHash `json:"dest"`
+		Amount types.Currency   `json:"amount"`
+		Memo   string           `json:"memo"`
 	}
 
 	// A ProcessedTransaction is a transaction that has been processed into
@@ -100,6 +158,17 @@ type (
 		Outputs []ProcessedOutput `json:"outputs"`
 	}
 
+	// A Signer produces signatures for a single public key. By default, the
+	// wallet signs with secret keys held in memory; a Signer allows the keys
+	// to live elsewhere, such as on a hardware device.
+	Signer interface {
+		// PublicKey returns the public key that the Signer signs for.
+		PublicKey() types.SiaPublicKey
+
+		// SignHash signs the provided hash.
+		SignHash(crypto.Hash) (crypto.Signature, error)
+	}
+
 	// TransactionBuilder is used to construct custom transactions. A transaction
 	// builder is initialized via 'RegisterTransaction' and then can be modified by
 	// adding funds or other fields. The transaction is completed by calling
@@ -260,6 +329,19 @@ type (
 		// re-encrypting the wallet with the provided key.
 		ChangeKey(masterKey crypto.TwofishKey, newKey crypto.TwofishKey) error
 
+		// CheckKey returns ErrBadEncryptionKey if masterKey is not the key
+		// used to encrypt the wallet.
+		CheckKey(masterKey crypto.TwofishKey) error
+
+		// KDFParams returns the scrypt cost parameters used to derive the
+		// wallet's encryption key from a new master key.
+		KDFParams() crypto.ScryptParams
+
+		// SetKDFParams sets the scrypt cost parameters used to derive the
+		// wallet's encryption key the next time the wallet is encrypted or
+		// its key is changed.
+		SetKDFParams(params crypto.ScryptParams) error
+
 		// Unlocked returns true if the wallet is currently unlocked, false
 		// otherwise.
 		Unlocked() bool
@@ -284,6 +366,21 @@ type (
 		// filepath. The backup will have all seeds and keys.
 		CreateBackup(string) error
 
+		// ExportBackup writes an encrypted backup of the wallet's seeds,
+		// unseeded keys, locked outputs, contacts, memos, and settings to
+		// the provided writer. Only state that cannot be recovered from the blockchain
+		// is included.
+		ExportBackup(w io.Writer, passphrase []byte) error
+
+		// ExportBackupWithParams is like ExportBackup, but derives the
+		// encryption key from the passphrase using the supplied scrypt
+		// parameters instead of the defaults.
+		ExportBackupWithParams(w io.Writer, passphrase []byte, params crypto.ScryptParams) error
+
+		// ImportBackup restores a backup written by ExportBackup. Seeds in
+		// the backup are added as auxiliary seeds.
+		ImportBackup(masterKey crypto.TwofishKey, r io.Reader, passphrase []byte) error
+
 		// LoadBackup will load a backup of the wallet from the provided
 		// address. The backup wallet will be added as an auxiliary seed, not
 		// as a primary seed.
@@ -308,6 +405,49 @@ type (
 		// primary seed.
 		NextAddress() (types.UnlockConditions, error)
 
+		// AddSigner adds the standard address of an external Signer to the
+		// wallet. Outputs sent to the address are tracked by the wallet and
+		// signed for using the Signer. Signers are not persisted, and must be
+		// added again after the wallet is restarted.
+		AddSigner(Signer) (types.UnlockConditions, error)
+
+		// AddressReused reports whether a wallet address has received funds
+		// in more than one confirmed transaction. Reusing addresses reduces
+		// privacy.
+		AddressReused(types.UnlockHash) bool
+
+		// LockOutput prevents a siacoin output from being used to fund
+		// transactions until it is unlocked with UnlockOutput. Locks persist
+		// across restarts.
+		LockOutput(types.SiacoinOutputID) error
+
+		// UnlockOutput releases a lock placed by LockOutput.
+		UnlockOutput(types.SiacoinOutputID) error
+
+		// BalanceProjection returns the future heights at which the
+		// wallet's spendable balance increases, sorted by height.
+		BalanceProjection() ([]BalanceProjectionEntry, error)
+
+		// AddContact adds a named address to the wallet's address book.
+		// Names must be unique.
+		AddContact(name string, addr types.UnlockHash) error
+
+		// Contacts returns the entries of the wallet's address book, sorted
+		// by name.
+		Contacts() []WalletContact
+
+		// RemoveContact removes a named address from the wallet's address
+		// book.
+		RemoveContact(name string) error
+
+		// ChangeAddressPolicy returns the policy that determines where the
+		// wallet sends change.
+		ChangeAddressPolicy() ChangeAddressPolicy
+
+		// SetChangeAddressPolicy sets the policy that determines where the
+		// wallet sends change. The policy persists across restarts.
+		SetChangeAddressPolicy(ChangeAddressPolicy) error
+
 		// PrimarySeed returns the unencrypted primary seed of the wallet,
 		// along with a uint64 indicating how many addresses may be safely
 		// generated from the seed.
@@ -343,6 +483,19 @@ type (
 		// not considered in the unconfirmed balance.
 		UnconfirmedBalance() (outgoingSiacoins types.Currency, incomingSiacoins types.Currency)
 
+		// UnconfirmedIncoming returns the value of the incoming siacoins
+		// that have not yet reached the required number of confirmations,
+		// including those in unconfirmed transactions.
+		UnconfirmedIncoming() (types.Currency, error)
+
+		// RequiredConfirmations returns the number of confirmations an
+		// output needs before it is included in the confirmed balance.
+		RequiredConfirmations() int
+
+		// SetRequiredConfirmations sets the number of confirmations an
+		// output needs before it is included in the confirmed balance.
+		SetRequiredConfirmations(n int) error
+
 		// AddressTransactions returns all of the transactions that are related
 		// to a given address.
 		AddressTransactions(types.UnlockHash) []ProcessedTransaction
@@ -380,20 +533,76 @@ type (
 		// SendSiacoins is a tool for sending siacoins from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and
-		// are also returned to the caller.
+		// are also returned to the caller. Payments that need too many inputs
+		// to fit in one transaction are split across several transactions;
+		// if only some of them are accepted, they are returned along with
+		// the error.
 		SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
 
+		// PreviewSend returns the fee, the change, and the inputs that
+		// SendSiacoins would use for the same arguments, without building or
+		// broadcasting a transaction.
+		PreviewSend(dest types.UnlockHash, amount types.Currency) (fee, change types.Currency, inputs []types.SiacoinOutputID, err error)
+
 		// SendSiacoinsMulti sends coins to multiple addresses.
 		SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error)
 
+		// SendSiacoinsConfirmed and SendSiacoinsMultiConfirmed are like
+		// SendSiacoins and SendSiacoinsMulti, but are not subject to the
+		// large send threshold.
+		SendSiacoinsConfirmed(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
+		SendSiacoinsMultiConfirmed(outputs []types.SiacoinOutput) ([]types.Transaction, error)
+
+		// LargeSendThreshold returns the amount above which sends must be
+		// confirmed. A threshold of zero disables the check.
+		LargeSendThreshold() types.Currency
+
+		// SetLargeSendThreshold sets the amount above which sends made with
+		// SendSiacoins, SendSiacoinsMulti or SendSiacoinsWithMemos return
+		// ErrLargeSendNeedsConfirmation. A threshold of zero disables the
+		// check.
+		SetLargeSendThreshold(types.Currency) error
+
+		// SetOpportunisticConsolidation enables the automatic consolidation
+		// of the wallet's outputs whenever the recommended fee per byte is
+		// below feeThreshold. A threshold of zero disables consolidation.
+		SetOpportunisticConsolidation(feeThreshold types.Currency) error
+
+		// SendSiacoinsWithMemos sends coins to multiple addresses, storing
+		// a local memo for each output.
+		SendSiacoinsWithMemos(sends []MemoSend) ([]types.Transaction, error)
+
+		// SendSiacoinsWithMemosConfirmed is like SendSiacoinsWithMemos, but
+		// is not subject to the large send threshold.
+		SendSiacoinsWithMemosConfirmed(sends []MemoSend) ([]types.Transaction, error)
+
 		// SendSiafunds is a tool for sending siafunds from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and
 		// are also returned to the caller.
 		SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
+
+		// UnclaimedSiafundBalance returns the value of the siacoin claims
+		// accrued by the wallet's siafunds.
+		UnclaimedSiafundBalance() types.Currency
+
+		// ClaimSiafunds sends all of the wallet's siafunds to a fresh wallet
+		// address, realizing their accrued siacoin claims.
+		ClaimSiafunds() ([]types.Transaction, error)
 	}
 )
 
+// MarshalSia implements the encoding.SiaMarshaler interface. The Memo field
+// is omitted, so that the encoding matches that of earlier versions.
+func (po ProcessedOutput) MarshalSia(w io.Writer) error {
+	return encoding.NewEncoder(w).EncodeAll(po.ID, po.FundType, po.MaturityHeight, po.WalletAddress, po.RelatedAddress, po.Value)
+}
+
+// UnmarshalSia implements the encoding.SiaUnmarshaler interface.
+func (po *ProcessedOutput) UnmarshalSia(r io.Reader) error {
+	return encoding.NewDecoder(r).DecodeAll(&po.ID, &po.FundType, &po.MaturityHeight, &po.WalletAddress, &po.RelatedAddress, &po.Value)
+}
+
 // CalculateWalletTransactionID is a helper function for determining the id of
 // a wallet transaction.
 func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) WalletTransactionID {
@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// receivingAddresses returns the wallet addresses that receive funds from the
+// outputs of a processed transaction. Each address appears at most once, so
+// that a transaction with several outputs to the same address only counts as
+// a single receipt.
+func receivingAddresses(outputs []modules.ProcessedOutput) []types.UnlockHash {
+	seen := make(map[types.UnlockHash]struct{})
+	var addrs []types.UnlockHash
+	for _, po := range outputs {
+		if !po.WalletAddress {
+			continue
+		}
+		switch po.FundType {
+		case types.SpecifierSiacoinOutput, types.SpecifierSiafundOutput, types.SpecifierMinerPayout:
+		default:
+			continue
+		}
+		if _, exists := seen[po.RelatedAddress]; exists {
+			continue
+		}
+		seen[po.RelatedAddress] = struct{}{}
+		addrs = append(addrs, po.RelatedAddress)
+	}
+	return addrs
+}
+
+// applyAddressReceipts records that each wallet address paid by pt has
+// received funds, warning the user the first time an address is reused.
+func (w *Wallet) applyAddressReceipts(tx *bolt.Tx, pt modules.ProcessedTransaction) error {
+	for _, addr := range receivingAddresses(pt.Outputs) {
+		n, err := dbGetAddressReceipts(tx, addr)
+		if err != nil && err != errNoKey {
+			return err
+		}
+		n++
+		if n == 2 {
+			w.log.Println("WARN: wallet address", addr, "has received funds more than once. Reusing addresses reduces privacy; use a fresh address for each payment.")
+		}
+		err = dbPutAddressReceipts(tx, addr, n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertAddressReceipts undoes the receipts recorded by applyAddressReceipts
+// for a processed transaction that has been reverted.
+func (w *Wallet) revertAddressReceipts(tx *bolt.Tx, pt modules.ProcessedTransaction) error {
+	for _, addr := range receivingAddresses(pt.Outputs) {
+		n, err := dbGetAddressReceipts(tx, addr)
+		if err == errNoKey {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if n <= 1 {
+			err = dbDeleteAddressReceipts(tx, addr)
+		} else {
+			err = dbPutAddressReceipts(tx, addr, n-1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddressReused reports whether the provided wallet address has received
+// funds in more than one confirmed transaction.
+func (w *Wallet) AddressReused(addr types.UnlockHash) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := dbGetAddressReceipts(w.dbTx, addr)
+	return err == nil && n > 1
+}
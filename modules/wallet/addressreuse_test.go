@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestAddressReused checks that a wallet address is flagged as reused once it
+// has received funds in two separate transactions.
+func TestAddressReused(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := uc.UnlockHash()
+	if wt.wallet.AddressReused(addr) {
+		t.Fatal("fresh address is marked as reused")
+	}
+
+	// Send a payment to the address and confirm it. A single payment does not
+	// count as reuse.
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wt.wallet.AddressReused(addr) {
+		t.Fatal("address that received one payment is marked as reused")
+	}
+
+	// A second payment to the same address should flag it.
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wt.wallet.AddressReused(addr) {
+		t.Fatal("address that received two payments is not marked as reused")
+	}
+
+	// Addresses that are not tracked by the wallet are never flagged.
+	if wt.wallet.AddressReused(types.UnlockHash{}) {
+		t.Fatal("unknown address is marked as reused")
+	}
+}
@@ -0,0 +1,336 @@
+package wallet
+
+import (
+	"errors"
+	"io"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// walletBackupSpecifier identifies a file as a wallet backup whose key
+	// is derived using scrypt.
+	walletBackupSpecifier = types.Specifier{'W', 'a', 'l', 'l', 'e', 't', 'B', 'a', 'c', 'k', 'u', 'p', '2'}
+
+	// errBadWalletBackup is returned when the data passed to ImportBackup is
+	// not a wallet backup.
+	errBadWalletBackup = errors.New("data is not a wallet backup")
+
+	// errWalletBackupPassphrase is returned when a wallet backup cannot be
+	// decrypted, usually because the passphrase is wrong.
+	errWalletBackupPassphrase = errors.New("could not decrypt wallet backup; is the passphrase correct?")
+)
+
+// maxWalletBackupSize is the maximum size of an encrypted wallet backup.
+const maxWalletBackupSize = 1 << 24 // 16 MiB
+
+type (
+	// walletBackup contains the wallet state that cannot be recovered from
+	// the blockchain.
+	walletBackup struct {
+		PrimarySeed            modules.Seed
+		AuxiliarySeeds         []modules.Seed
+		UnseededKeys           []spendableKey
+		LockedOutputs          []types.SiacoinOutputID
+		Contacts               []modules.WalletContact
+		Memos                  []walletBackupMemo
+		ChangeAddressPolicy    modules.ChangeAddressPolicy
+		RequiredConfirmations  int
+		LargeSendThreshold     types.Currency
+		ConsolidationThreshold types.Currency
+	}
+
+	// walletBackupMemo is the memo attached to an output of the wallet.
+	walletBackupMemo struct {
+		ID   types.OutputID
+		Memo string
+	}
+
+	// walletBackupFile is the on-disk format of a wallet backup. The
+	// walletBackup is encrypted with a key derived from the passphrase using
+	// scrypt. The scrypt parameters are stored alongside the ciphertext so
+	// that the key can be derived again, even if the defaults change.
+	walletBackupFile struct {
+		Specifier types.Specifier
+		KDF       crypto.ScryptParams
+		Backup    crypto.Ciphertext
+	}
+)
+
+// walletBackupScryptKey derives the key used to encrypt a wallet backup from
+// passphrase, using scrypt with the supplied parameters.
+func walletBackupScryptKey(passphrase []byte, params crypto.ScryptParams) (key crypto.TwofishKey, err error) {
+	k, err := crypto.DeriveKeyScrypt(passphrase, params)
+	if err != nil {
+		return crypto.TwofishKey{}, err
+	}
+	copy(key[:], k)
+	return key, nil
+}
+
+// ExportBackup writes an encrypted backup of the wallet's seeds, unseeded
+// keys, locked outputs, contacts, memos, and settings to w. Unlike CreateBackup, the backup
+// does not contain any state that can be recovered by rescanning the
+// blockchain. The wallet must be unlocked.
+func (w *Wallet) ExportBackup(wr io.Writer, passphrase []byte) error {
+	return w.ExportBackupWithParams(wr, passphrase, crypto.DefaultScryptParams)
+}
+
+// ExportBackupWithParams is like ExportBackup, but derives the encryption key
+// using scrypt with the cost parameters of params, allowing the difficulty of
+// brute-forcing the passphrase to be increased. The salt of params is ignored;
+// a random salt is always used. A warning is logged if the parameters are
+// weak, and parameters exceeding crypto.MaxScryptParams are rejected, since
+// the backup could not be imported.
+func (w *Wallet) ExportBackupWithParams(wr io.Writer, passphrase []byte, params crypto.ScryptParams) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	params = crypto.NewScryptParams(params.N, params.R, params.P)
+	key, err := walletBackupScryptKey(passphrase, params)
+	if err != nil {
+		return err
+	}
+	if params.Weak() {
+		w.log.Println("WARN: wallet backup is being encrypted with weak scrypt parameters; the passphrase may be easy to brute-force")
+	}
+
+	w.mu.Lock()
+	if !w.unlocked {
+		w.mu.Unlock()
+		return modules.ErrLockedWallet
+	}
+	backup := walletBackup{
+		PrimarySeed:    w.primarySeed,
+		AuxiliarySeeds: append([]modules.Seed(nil), w.seeds...),
+	}
+	for uh := range w.unseededKeys {
+		backup.UnseededKeys = append(backup.UnseededKeys, w.keys[uh])
+	}
+	err = dbForEach(w.dbTx.Bucket(bucketLockedOutputs), func(id types.SiacoinOutputID, _ bool) {
+		backup.LockedOutputs = append(backup.LockedOutputs, id)
+	})
+	if err == nil {
+		err = dbForEachContact(w.dbTx, func(name string, addr types.UnlockHash) {
+			backup.Contacts = append(backup.Contacts, modules.WalletContact{Name: name, Address: addr})
+		})
+	}
+	if err == nil {
+		err = dbForEach(w.dbTx.Bucket(bucketMemos), func(id types.OutputID, memo string) {
+			backup.Memos = append(backup.Memos, walletBackupMemo{ID: id, Memo: memo})
+		})
+	}
+	if err == nil {
+		backup.ChangeAddressPolicy, err = dbGetChangeAddressPolicy(w.dbTx)
+	}
+	if err == nil {
+		backup.RequiredConfirmations, err = dbGetRequiredConfirmations(w.dbTx)
+	}
+	if err == nil {
+		backup.LargeSendThreshold, err = dbGetLargeSendThreshold(w.dbTx)
+	}
+	if err == nil {
+		backup.ConsolidationThreshold, err = dbGetConsolidationThreshold(w.dbTx)
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return encoding.WriteObject(wr, walletBackupFile{
+		Specifier: walletBackupSpecifier,
+		KDF:       params,
+		Backup:    crypto.EncryptAEAD(key, encoding.Marshal(backup), walletBackupSpecifier[:]),
+	})
+}
+
+// decryptWalletBackup reads an encrypted wallet backup from r and returns the
+// encoded walletBackup.
+func decryptWalletBackup(r io.Reader, passphrase []byte) ([]byte, error) {
+	b, err := encoding.ReadPrefix(r, maxWalletBackupSize)
+	if err != nil {
+		return nil, err
+	}
+	var bf walletBackupFile
+	if err := encoding.Unmarshal(b, &bf); err != nil {
+		return nil, err
+	} else if bf.Specifier != walletBackupSpecifier {
+		return nil, errBadWalletBackup
+	}
+	// The scrypt parameters come from the file, so they must be checked
+	// before the key is derived; otherwise a malicious backup could make
+	// the wallet allocate an arbitrary amount of memory.
+	if bf.KDF.Expensive() {
+		return nil, crypto.ErrExpensiveScryptParams
+	}
+	key, err := walletBackupScryptKey(passphrase, bf.KDF)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := crypto.DecryptAEAD(key, bf.Backup, bf.Specifier[:])
+	if err != nil {
+		return nil, errWalletBackupPassphrase
+	}
+	return plaintext, nil
+}
+
+// ImportBackup reads an encrypted backup written by ExportBackup and merges
+// it into the wallet. Seeds in the backup that are not already known are
+// added as auxiliary seeds, and the blockchain is rescanned if any new seeds
+// or keys were added. The backup's contacts and memos are merged into those of
+// the wallet, replacing entries with the same name or output, and its locked
+// outputs and settings replace those of the wallet. The wallet must be
+// unlocked.
+func (w *Wallet) ImportBackup(masterKey crypto.TwofishKey, r io.Reader, passphrase []byte) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	key, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
+	plaintext, err := decryptWalletBackup(r, passphrase)
+	if err != nil {
+		return err
+	}
+	var backup walletBackup
+	if err := encoding.Unmarshal(plaintext, &backup); err != nil {
+		return err
+	}
+
+	if !w.scanLock.TryLock() {
+		return errScanInProgress
+	}
+	defer w.scanLock.Unlock()
+
+	var rescan bool
+	err = func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		err := checkMasterKey(w.dbTx, key)
+		if err != nil {
+			return err
+		}
+
+		// add unknown seeds as auxiliary seeds
+		known := make(map[modules.Seed]struct{})
+		for _, seed := range append([]modules.Seed{w.primarySeed}, w.seeds...) {
+			known[seed] = struct{}{}
+		}
+		var current []seedFile
+		err = encoding.Unmarshal(w.dbTx.Bucket(bucketWallet).Get(keyAuxiliarySeedFiles), &current)
+		if err != nil {
+			return err
+		}
+		for _, seed := range append([]modules.Seed{backup.PrimarySeed}, backup.AuxiliarySeeds...) {
+			if _, ok := known[seed]; ok {
+				continue
+			}
+			known[seed] = struct{}{}
+			current = append(current, createSeedFile(key, seed))
+			w.integrateSeed(seed, modules.PublicKeysPerSeed)
+			w.seeds = append(w.seeds, seed)
+			rescan = true
+		}
+		err = w.dbTx.Bucket(bucketWallet).Put(keyAuxiliarySeedFiles, encoding.Marshal(current))
+		if err != nil {
+			return err
+		}
+
+		// add unseeded keys
+		for _, sk := range backup.UnseededKeys {
+			err = w.loadSpendableKey(key, sk)
+			if err == errDuplicateSpendableKey {
+				continue
+			} else if err != nil {
+				return err
+			}
+			w.integrateSpendableKey(key, sk)
+			rescan = true
+		}
+
+		// restore locked outputs, contacts, memos, and settings
+		for _, id := range backup.LockedOutputs {
+			if err = dbPutLockedOutput(w.dbTx, id); err != nil {
+				return err
+			}
+		}
+		for _, c := range backup.Contacts {
+			if err = dbPutContact(w.dbTx, c.Name, c.Address); err != nil {
+				return err
+			}
+		}
+		for _, m := range backup.Memos {
+			if err = dbPutMemo(w.dbTx, m.ID, m.Memo); err != nil {
+				return err
+			}
+		}
+		if err = dbPutChangeAddressPolicy(w.dbTx, backup.ChangeAddressPolicy); err != nil {
+			return err
+		}
+		if err = dbPutRequiredConfirmations(w.dbTx, backup.RequiredConfirmations); err != nil {
+			return err
+		}
+		if err = dbPutLargeSendThreshold(w.dbTx, backup.LargeSendThreshold); err != nil {
+			return err
+		}
+		if err = dbPutConsolidationThreshold(w.dbTx, backup.ConsolidationThreshold); err != nil {
+			return err
+		}
+		if !rescan {
+			w.syncDB()
+			return nil
+		}
+
+		// delete the set of processed transactions; they will be recreated
+		// when we rescan
+		if err = w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+			return err
+		}
+		if _, err = w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+			return err
+		}
+		if err = w.dbTx.DeleteBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
+		if _, err = w.dbTx.CreateBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
+		w.unconfirmedProcessedTransactions = nil
+
+		// reset the consensus change ID and height in preparation for rescan
+		err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
+		if err != nil {
+			return err
+		}
+		return dbPutConsensusHeight(w.dbTx, 0)
+	}()
+	if err != nil || !rescan {
+		return err
+	}
+
+	// rescan the blockchain
+	w.cs.Unsubscribe(w)
+	w.tpool.Unsubscribe(w)
+
+	done := make(chan struct{})
+	go w.rescanMessage(done)
+	defer close(done)
+
+	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning)
+	if err != nil {
+		return err
+	}
+	w.tpool.TransactionPoolSubscribe(w)
+	return nil
+}
@@ -0,0 +1,242 @@
+package wallet
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestExportImportBackup checks that exporting a backup and importing it into
+// a fresh wallet restores the seeds, unseeded keys, locked outputs, contacts,
+// memos, and settings of the original wallet.
+func TestExportImportBackup(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Add some non-chain state to the wallet.
+	lockedID := types.SiacoinOutputID{1, 2, 3}
+	if err := wt.wallet.LockOutput(lockedID); err != nil {
+		t.Fatal(err)
+	}
+	policy := modules.ChangeAddressPolicy{Mode: modules.ChangeToInputAddress}
+	if err := wt.wallet.SetChangeAddressPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	contact := modules.WalletContact{Name: "alice", Address: types.UnlockHash{7}}
+	if err := wt.wallet.AddContact(contact.Name, contact.Address); err != nil {
+		t.Fatal(err)
+	}
+	memoID := types.OutputID{8}
+	wt.wallet.mu.Lock()
+	err = dbPutMemo(wt.wallet.dbTx, memoID, "rent")
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetRequiredConfirmations(6); err != nil {
+		t.Fatal(err)
+	}
+	largeSend := types.SiacoinPrecision.Mul64(1000)
+	if err := wt.wallet.SetLargeSendThreshold(largeSend); err != nil {
+		t.Fatal(err)
+	}
+	consolidationFee := types.SiacoinPrecision.Div64(1e6)
+	if err := wt.wallet.SetOpportunisticConsolidation(consolidationFee); err != nil {
+		t.Fatal(err)
+	}
+	unseeded := generateSpendableKey(modules.Seed{4, 5, 6}, 0)
+	key, err := wt.wallet.managedWalletKey(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Lock()
+	err = wt.wallet.loadSpendableKey(key, unseeded)
+	wt.wallet.integrateSpendableKey(key, unseeded)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passphrase := []byte("correct horse battery staple")
+	var buf bytes.Buffer
+	if err := wt.wallet.ExportBackup(&buf, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	backup := buf.Bytes()
+
+	// Create a fresh wallet.
+	dir := filepath.Join(build.TempDir(modules.WalletDir, t.Name()+"1"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	newSeed, err := w.Encrypt(crypto.TwofishKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterKey := crypto.TwofishKey(crypto.HashObject(newSeed))
+	if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Importing with the wrong passphrase should fail.
+	err = w.ImportBackup(masterKey, bytes.NewReader(backup), []byte("wrong"))
+	if err != errWalletBackupPassphrase {
+		t.Fatal("expected errWalletBackupPassphrase, got", err)
+	}
+	// Importing a tampered backup should fail.
+	tampered := append([]byte(nil), backup...)
+	tampered[len(tampered)-1] ^= 1
+	err = w.ImportBackup(masterKey, bytes.NewReader(tampered), passphrase)
+	if err != errWalletBackupPassphrase {
+		t.Fatal("expected errWalletBackupPassphrase, got", err)
+	}
+
+	if err := w.ImportBackup(masterKey, bytes.NewReader(backup), passphrase); err != nil {
+		t.Fatal(err)
+	}
+	allSeeds, err := w.AllSeeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allSeeds) != 2 || allSeeds[0] != newSeed || allSeeds[1] != seed {
+		t.Fatal("backup seed was not added as an auxiliary seed")
+	}
+	if p := w.ChangeAddressPolicy(); p.Mode != policy.Mode {
+		t.Fatal("change address policy was not restored:", p.Mode)
+	}
+	if contacts := w.Contacts(); len(contacts) != 1 || contacts[0] != contact {
+		t.Fatal("contacts were not restored:", contacts)
+	}
+	if n := w.RequiredConfirmations(); n != 6 {
+		t.Fatal("required confirmations were not restored:", n)
+	}
+	if c := w.LargeSendThreshold(); !c.Equals(largeSend) {
+		t.Fatal("large send threshold was not restored:", c)
+	}
+	w.mu.Lock()
+	locked, err := dbGetLockedOutput(w.dbTx, lockedID)
+	_, hasKey := w.keys[unseeded.UnlockConditions.UnlockHash()]
+	memo, memoErr := dbGetMemo(w.dbTx, memoID)
+	consolidation, consolidationErr := dbGetConsolidationThreshold(w.dbTx)
+	w.mu.Unlock()
+	if memoErr != nil || memo != "rent" {
+		t.Fatal("memo was not restored:", memo, memoErr)
+	}
+	if consolidationErr != nil || !consolidation.Equals(consolidationFee) {
+		t.Fatal("consolidation threshold was not restored:", consolidation, consolidationErr)
+	}
+	if err != nil || !locked {
+		t.Fatal("locked output was not restored:", err)
+	}
+	if !hasKey {
+		t.Fatal("unseeded key was not restored")
+	}
+	bal, _, _ := w.ConfirmedBalance()
+	if bal.IsZero() {
+		t.Fatal("wallet balance should be nonzero after importing backup")
+	}
+
+	// Importing the same backup again should not add anything.
+	if err := w.ImportBackup(masterKey, bytes.NewReader(backup), passphrase); err != nil {
+		t.Fatal(err)
+	}
+	if allSeeds, _ := w.AllSeeds(); len(allSeeds) != 2 {
+		t.Fatal("reimporting the backup added a duplicate seed")
+	}
+}
+
+// TestBackupScryptParams checks that backups encrypted with different scrypt
+// parameters can be decrypted using the parameters stored alongside the
+// ciphertext, and that backups with overly expensive parameters are rejected.
+func TestBackupScryptParams(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	passphrase := []byte("correct horse battery staple")
+	var backups [][]byte
+	for _, params := range []crypto.ScryptParams{
+		{N: 1 << 10, R: 8, P: 1},
+		{N: 1 << 12, R: 4, P: 2},
+		crypto.MinScryptParams,
+	} {
+		var buf bytes.Buffer
+		if err := wt.wallet.ExportBackupWithParams(&buf, passphrase, params); err != nil {
+			t.Fatal(err)
+		}
+		backups = append(backups, buf.Bytes())
+	}
+	// Invalid parameters should be rejected.
+	var buf bytes.Buffer
+	err = wt.wallet.ExportBackupWithParams(&buf, passphrase, crypto.ScryptParams{N: 1000, R: 8, P: 1})
+	if err != crypto.ErrInvalidScryptParams {
+		t.Fatal("expected ErrInvalidScryptParams, got", err)
+	}
+	err = wt.wallet.ExportBackupWithParams(&buf, passphrase, crypto.ScryptParams{N: 1 << 30, R: 8, P: 1})
+	if err != crypto.ErrExpensiveScryptParams {
+		t.Fatal("expected ErrExpensiveScryptParams, got", err)
+	}
+
+	// A backup claiming overly expensive parameters should be rejected
+	// without deriving a key.
+	params := crypto.NewScryptParams(1<<30, 8, 1)
+	err = encoding.WriteObject(&buf, walletBackupFile{
+		Specifier: walletBackupSpecifier,
+		KDF:       params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decryptWalletBackup(&buf, passphrase); err != crypto.ErrExpensiveScryptParams {
+		t.Fatal("expected ErrExpensiveScryptParams, got", err)
+	}
+
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, backup := range backups {
+		plaintext, err := decryptWalletBackup(bytes.NewReader(backup), passphrase)
+		if err != nil {
+			t.Fatalf("could not decrypt backup %v: %v", i, err)
+		}
+		var wb walletBackup
+		if err := encoding.Unmarshal(plaintext, &wb); err != nil {
+			t.Fatal(err)
+		} else if wb.PrimarySeed != seed {
+			t.Fatalf("backup %v contains the wrong seed", i)
+		}
+		if _, err := decryptWalletBackup(bytes.NewReader(backup), []byte("wrong")); err != errWalletBackupPassphrase {
+			t.Fatalf("backup %v: expected errWalletBackupPassphrase, got %v", i, err)
+		}
+		if err := wt.wallet.ImportBackup(wt.walletMasterKey, bytes.NewReader(backup), passphrase); err != nil {
+			t.Fatalf("could not import backup %v: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	// errUnknownChangeAddressMode is returned by SetChangeAddressPolicy if
+	// the policy's mode is not recognized.
+	errUnknownChangeAddressMode = errors.New("unknown change address mode")
+
+	// errNoFixedChangeAddress is returned by SetChangeAddressPolicy if a
+	// fixed change address policy does not specify an address.
+	errNoFixedChangeAddress = errors.New("fixed change address policy requires an address")
+)
+
+// changeAddress returns the address that change from spending 'inputs' should
+// be sent to, according to the wallet's change address policy.
+func (w *Wallet) changeAddress(tx *bolt.Tx, inputs []types.SiacoinOutput) (types.UnlockHash, error) {
+	policy, err := dbGetChangeAddressPolicy(tx)
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	switch {
+	case policy.Mode == modules.ChangeToInputAddress && len(inputs) > 0:
+		return inputs[0].UnlockHash, nil
+	case policy.Mode == modules.ChangeToFixedAddress:
+		return policy.Address, nil
+	}
+	uc, err := w.nextPrimarySeedAddress(tx)
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	return uc.UnlockHash(), nil
+}
+
+// ChangeAddressPolicy returns the policy that determines where the wallet
+// sends the change of a transaction.
+func (w *Wallet) ChangeAddressPolicy() modules.ChangeAddressPolicy {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	policy, err := dbGetChangeAddressPolicy(w.dbTx)
+	if err != nil {
+		w.log.Println("ERROR: could not load change address policy:", err)
+	}
+	return policy
+}
+
+// SetChangeAddressPolicy sets the policy that determines where the wallet
+// sends the change of a transaction. Sending change to a new address, the
+// default, provides the most privacy. Sending change to an input address or
+// to a fixed address reduces the number of addresses the wallet uses, at the
+// cost of linking transactions together. A fixed address does not need to
+// belong to the wallet.
+func (w *Wallet) SetChangeAddressPolicy(policy modules.ChangeAddressPolicy) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	switch policy.Mode {
+	case modules.ChangeToNewAddress, modules.ChangeToInputAddress:
+	case modules.ChangeToFixedAddress:
+		if policy.Address == (types.UnlockHash{}) {
+			return errNoFixedChangeAddress
+		}
+	default:
+		return errUnknownChangeAddressMode
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := dbPutChangeAddressPolicy(w.dbTx, policy)
+	if err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
@@ -0,0 +1,89 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestChangeAddressPolicy checks that each change address policy sends the
+// change of a transaction to the expected address.
+func TestChangeAddressPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// send sends a small amount out of the wallet and returns the parent
+	// transaction, which contains the change output.
+	send := func() types.Transaction {
+		txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txns[0].SiacoinOutputs) != 2 {
+			t.Fatal("expected parent transaction to have a change output")
+		}
+		return txns[0]
+	}
+	inputAddrs := func(txn types.Transaction) map[types.UnlockHash]bool {
+		addrs := make(map[types.UnlockHash]bool)
+		for _, sci := range txn.SiacoinInputs {
+			addrs[sci.UnlockConditions.UnlockHash()] = true
+		}
+		return addrs
+	}
+
+	// By default, change goes to a new wallet address.
+	if p := wt.wallet.ChangeAddressPolicy(); p.Mode != modules.ChangeToNewAddress {
+		t.Fatal("expected default policy to be ChangeToNewAddress, got", p.Mode)
+	}
+	txn := send()
+	change := txn.SiacoinOutputs[1].UnlockHash
+	wt.wallet.mu.Lock()
+	ours := wt.wallet.isWalletAddress(change)
+	wt.wallet.mu.Unlock()
+	if !ours || inputAddrs(txn)[change] {
+		t.Fatal("change was not sent to a new wallet address")
+	}
+
+	// Change should go back to an input address.
+	err = wt.wallet.SetChangeAddressPolicy(modules.ChangeAddressPolicy{Mode: modules.ChangeToInputAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn = send()
+	if !inputAddrs(txn)[txn.SiacoinOutputs[1].UnlockHash] {
+		t.Fatal("change was not sent to an input address")
+	}
+
+	// Change should go to the fixed address.
+	fixed := types.UnlockHash{2, 3, 4}
+	err = wt.wallet.SetChangeAddressPolicy(modules.ChangeAddressPolicy{Mode: modules.ChangeToFixedAddress, Address: fixed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn = send()
+	if txn.SiacoinOutputs[1].UnlockHash != fixed {
+		t.Fatal("change was not sent to the fixed address")
+	}
+	if p := wt.wallet.ChangeAddressPolicy(); p.Mode != modules.ChangeToFixedAddress || p.Address != fixed {
+		t.Fatal("policy was not stored:", p)
+	}
+
+	// Invalid policies should be rejected.
+	err = wt.wallet.SetChangeAddressPolicy(modules.ChangeAddressPolicy{Mode: modules.ChangeToFixedAddress})
+	if err != errNoFixedChangeAddress {
+		t.Fatal("expected errNoFixedChangeAddress, got", err)
+	}
+	err = wt.wallet.SetChangeAddressPolicy(modules.ChangeAddressPolicy{Mode: 100})
+	if err != errUnknownChangeAddressMode {
+		t.Fatal("expected errUnknownChangeAddressMode, got", err)
+	}
+}
@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// errNegativeConfirmations is returned by SetRequiredConfirmations if the
+// number of confirmations is negative.
+var errNegativeConfirmations = errors.New("required confirmations cannot be negative")
+
+// pendingOutputs returns the wallet's unspent siacoin outputs that have been
+// confirmed, but have fewer confirmations than the wallet requires. Delayed
+// outputs, such as miner payouts, are subject to a maturity delay and are
+// never considered pending.
+func pendingOutputs(tx *bolt.Tx) (map[types.SiacoinOutputID]types.Currency, error) {
+	required, err := dbGetRequiredConfirmations(tx)
+	if err != nil {
+		return nil, err
+	}
+	height, err := dbGetConsensusHeight(tx)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[types.SiacoinOutputID]types.Currency)
+	if required <= 1 {
+		return pending, nil
+	}
+
+	// Processed transactions are stored in chronological order, so walk
+	// backwards until reaching a transaction with enough confirmations.
+	c := tx.Bucket(bucketProcessedTransactions).Cursor()
+	for _, ptBytes := c.Last(); ptBytes != nil; _, ptBytes = c.Prev() {
+		var pt modules.ProcessedTransaction
+		if err := encoding.Unmarshal(ptBytes, &pt); err != nil {
+			// COMPATv1.2.1: transactions in the old format are far too
+			// old to be pending.
+			break
+		}
+		if pt.ConfirmationHeight+types.BlockHeight(required) <= height+1 {
+			break
+		}
+		for _, output := range pt.Outputs {
+			if output.FundType != types.SpecifierSiacoinOutput || !output.WalletAddress {
+				continue
+			}
+			id := types.SiacoinOutputID(output.ID)
+			if _, err := dbGetSiacoinOutput(tx, id); err == nil {
+				pending[id] = output.Value
+			}
+		}
+	}
+	return pending, nil
+}
+
+// RequiredConfirmations returns the number of confirmations an output needs
+// before it is included in the wallet's confirmed balance.
+func (w *Wallet) RequiredConfirmations() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := dbGetRequiredConfirmations(w.dbTx)
+	if err != nil {
+		w.log.Println("ERROR: could not load required confirmations:", err)
+	}
+	return n
+}
+
+// SetRequiredConfirmations sets the number of confirmations an output needs
+// before it is included in the wallet's confirmed balance. Outputs with fewer
+// confirmations are reported by UnconfirmedIncoming instead. The default, 0,
+// counts every confirmed output; 0 and 1 are equivalent.
+func (w *Wallet) SetRequiredConfirmations(n int) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+	if n < 0 {
+		return errNegativeConfirmations
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := dbPutRequiredConfirmations(w.dbTx, n)
+	if err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
+
+// UnconfirmedIncoming returns the value of the incoming siacoins that have
+// not yet reached the required number of confirmations. This includes
+// outputs in unconfirmed transactions as well as confirmed outputs that are
+// excluded from the confirmed balance.
+func (w *Wallet) UnconfirmedIncoming() (types.Currency, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Currency{}, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pending, err := pendingOutputs(w.dbTx)
+	if err != nil {
+		return types.Currency{}, err
+	}
+	var incoming types.Currency
+	for _, value := range pending {
+		if value.Cmp(dustValue()) > 0 {
+			incoming = incoming.Add(value)
+		}
+	}
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		for _, output := range upt.Outputs {
+			if output.FundType == types.SpecifierSiacoinOutput && output.WalletAddress && output.Value.Cmp(dustValue()) > 0 {
+				incoming = incoming.Add(output.Value)
+			}
+		}
+	}
+	return incoming, nil
+}
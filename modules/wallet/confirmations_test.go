@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestRequiredConfirmations checks that outputs with fewer than the required
+// number of confirmations are excluded from the confirmed balance and
+// reported by UnconfirmedIncoming instead.
+func TestRequiredConfirmations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if err := wt.wallet.SetRequiredConfirmations(-1); err != errNegativeConfirmations {
+		t.Fatal("expected errNegativeConfirmations, got", err)
+	}
+	if err := wt.wallet.SetRequiredConfirmations(3); err != nil {
+		t.Fatal(err)
+	}
+	if n := wt.wallet.RequiredConfirmations(); n != 3 {
+		t.Fatal("wrong number of required confirmations:", n)
+	}
+
+	// Send coins to the wallet and confirm them in a single block.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendValue := types.SiacoinPrecision.Mul64(10)
+	if _, err := wt.wallet.SendSiacoins(sendValue, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := wt.wallet.UnconfirmedIncoming(); err != nil {
+		t.Fatal(err)
+	} else if pending.Cmp(sendValue) < 0 {
+		t.Fatal("unconfirmed payment should be pending:", pending)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The payment has one confirmation, so it should be pending and excluded
+	// from the confirmed balance.
+	pending, err := wt.wallet.UnconfirmedIncoming()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Cmp(sendValue) < 0 {
+		t.Fatal("payment with one confirmation should be pending:", pending)
+	}
+	bal, _, _ := wt.wallet.ConfirmedBalance()
+	if err := wt.wallet.SetRequiredConfirmations(0); err != nil {
+		t.Fatal(err)
+	}
+	total, _, _ := wt.wallet.ConfirmedBalance()
+	if !total.Sub(bal).Equals(pending) {
+		t.Fatalf("confirmed balance should exclude pending outputs: %v - %v != %v", total, bal, pending)
+	}
+	if pending, _ := wt.wallet.UnconfirmedIncoming(); !pending.IsZero() {
+		t.Fatal("no outputs should be pending when confirmations are not required:", pending)
+	}
+
+	// After two more blocks, the payment has three confirmations.
+	if err := wt.wallet.SetRequiredConfirmations(3); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pending, _ := wt.wallet.UnconfirmedIncoming(); !pending.IsZero() {
+		t.Fatal("payment with three confirmations should not be pending:", pending)
+	}
+	bal, _, _ = wt.wallet.ConfirmedBalance()
+	if bal.Cmp(total) < 0 {
+		t.Fatal("confirmed payment was not added to the confirmed balance")
+	}
+}
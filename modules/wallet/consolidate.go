@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var errConsolidationNotNeeded = errors.New("consolidation not needed, wallet has few outputs")
+
+// createOpportunisticConsolidation creates a transaction set that spends the
+// wallet's smallest outputs into a single new address, paying feePerByte. As
+// with createConsolidationTransaction, the transactions are returned unsigned.
+func (w *Wallet) createOpportunisticConsolidation(feePerByte types.Currency) ([]types.Transaction, [][]pendingSignature, error) {
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Collect a value-sorted set of siacoin outputs.
+	var so sortedOutputs
+	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if w.checkOutput(w.dbTx, consensusHeight, scoid, sco) == nil {
+			so.ids = append(so.ids, scoid)
+			so.outputs = append(so.outputs, sco)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(so)
+	if len(so.ids) <= consolidationThreshold {
+		return nil, nil, errConsolidationNotNeeded
+	}
+
+	// Consolidate the smallest outputs, as they are the most expensive to
+	// spend relative to their value. As with defragging, the
+	// 'defragStartIndex' largest outputs are left alone, so that the user can
+	// still reasonably use their wallet while the consolidation is pending.
+	n := len(so.ids) - defragStartIndex
+	if n > defragBatchSize {
+		n = defragBatchSize
+	}
+	batch := sortedOutputs{
+		ids:     so.ids[:n],
+		outputs: so.outputs[:n],
+	}
+	// The set contains one input per output, plus the input of the final
+	// transaction.
+	fee := feePerByte.Mul64(consolidationInputSize * uint64(n+1))
+	return w.createConsolidationTransaction(consensusHeight, batch, fee)
+}
+
+// threadedOpportunisticConsolidation consolidates the wallet's outputs if
+// the recommended fee is below the threshold set with
+// SetOpportunisticConsolidation. Consolidating while fees are low reduces the
+// fees paid by future transactions, which would otherwise need many inputs.
+func (w *Wallet) threadedOpportunisticConsolidation() {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	threshold, err := dbGetConsolidationThreshold(w.dbTx)
+	w.mu.Unlock()
+	if err != nil {
+		w.log.Println("WARN: couldn't load consolidation threshold:", err)
+		return
+	} else if threshold.IsZero() {
+		return
+	}
+	_, fee := w.tpool.FeeEstimation()
+	if fee.Cmp(threshold) >= 0 {
+		return
+	}
+
+	w.mu.Lock()
+	if !w.unlocked {
+		// Can't consolidate if the wallet is locked.
+		w.mu.Unlock()
+		return
+	}
+	txnSet, sigs, err := w.createOpportunisticConsolidation(fee)
+	w.mu.Unlock()
+	if err == errConsolidationNotNeeded || err == errConsolidationTooExpensive {
+		// benign
+		return
+	} else if err != nil {
+		w.log.Println("WARN: couldn't create consolidation transaction:", err)
+		return
+	}
+	if err := signTransactionSet(txnSet, sigs); err != nil {
+		w.managedUnmarkSpentOutputs(txnSet)
+		w.log.Println("WARN: couldn't sign consolidation transaction:", err)
+		return
+	}
+	err = w.tpool.AcceptTransactionSet(txnSet)
+	if err != nil {
+		w.log.Println("WARN: consolidation transaction was rejected:", err)
+		return
+	}
+	w.log.Println("Submitting a transaction set to consolidate the wallet's outputs, IDs:")
+	for _, txn := range txnSet {
+		w.log.Println("\t", txn.ID())
+	}
+}
+
+// SetOpportunisticConsolidation enables the automatic consolidation of the
+// wallet's outputs whenever the recommended fee per byte is below
+// feeThreshold. Consolidation is checked after each new block, and only
+// happens if the wallet has more than consolidationThreshold spendable
+// outputs. A threshold of zero, the default, disables consolidation.
+func (w *Wallet) SetOpportunisticConsolidation(feeThreshold types.Currency) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := dbPutConsolidationThreshold(w.dbTx, feeThreshold)
+	if err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestOpportunisticConsolidation checks that the wallet consolidates its
+// outputs when the recommended fee is below the consolidation threshold, and
+// stays idle when it is above it.
+func TestOpportunisticConsolidation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	numOutputs := func() int {
+		wt.wallet.mu.Lock()
+		defer wt.wallet.mu.Unlock()
+		// force a sync because bucket stats may not be reliable until commit
+		wt.wallet.syncDB()
+		return wt.wallet.dbTx.Bucket(bucketSiacoinOutputs).Stats().KeyN
+	}
+
+	// Set a threshold below any recommended fee, then mine enough blocks to
+	// exceed consolidationThreshold outputs. No consolidation should occur.
+	if err := wt.wallet.SetOpportunisticConsolidation(types.NewCurrency64(1)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= consolidationThreshold; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Second)
+	if len(wt.tpool.TransactionList()) != 0 {
+		t.Fatal("wallet consolidated outputs even though fees are above the threshold")
+	}
+	before := numOutputs()
+	if before <= consolidationThreshold {
+		t.Fatal("not enough outputs to trigger consolidation:", before)
+	}
+	// Record the wallet's outputs, of which the largest should be left alone
+	// by a consolidation.
+	outputs := make(map[types.SiacoinOutputID]bool)
+	wt.wallet.mu.Lock()
+	err = dbForEachSiacoinOutput(wt.wallet.dbTx, func(scoid types.SiacoinOutputID, _ types.SiacoinOutput) {
+		outputs[scoid] = true
+	})
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Raise the threshold above the recommended fee. The next block should
+	// trigger a consolidation.
+	if err := wt.wallet.SetOpportunisticConsolidation(types.SiacoinPrecision); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50 && len(wt.tpool.TransactionList()) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(wt.tpool.TransactionList()) == 0 {
+		t.Fatal("wallet did not consolidate outputs even though fees are below the threshold")
+	}
+	// The largest outputs are left out of the consolidation, so the wallet
+	// can still send coins while it is pending.
+	for _, txn := range wt.tpool.TransactionList() {
+		for _, sci := range txn.SiacoinInputs {
+			delete(outputs, sci.ParentID)
+		}
+	}
+	if len(outputs) < defragStartIndex {
+		t.Fatalf("consolidation left %v outputs unspent, expected at least %v", len(outputs), defragStartIndex)
+	}
+	for i := 0; i < defragStartIndex/2; i++ {
+		_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(1000), types.UnlockHash{})
+		if err != nil {
+			t.Fatal("wallet could not send coins during a consolidation:", err)
+		}
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	// The consolidated outputs are replaced by a single output, and mining
+	// two blocks matures at most two new outputs.
+	if after := numOutputs(); after >= before {
+		t.Fatalf("expected fewer than %v outputs after consolidation, got %v", before, after)
+	}
+
+	// Disabling consolidation should stop further consolidations.
+	if err := wt.wallet.SetOpportunisticConsolidation(types.ZeroCurrency); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= consolidationThreshold; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Second)
+	if len(wt.tpool.TransactionList()) != 0 {
+		t.Fatal("wallet consolidated outputs even though consolidation is disabled")
+	}
+}
@@ -2,6 +2,8 @@ package wallet
 
 import (
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -16,6 +18,37 @@ const (
 	// defragStartIndex is the number of outputs to skip over when performing a
 	// defrag.
 	defragStartIndex = 10
+
+	// consolidationThreshold is the number of outputs a wallet must have
+	// before it opportunistically consolidates them.
+	consolidationThreshold = 20
+
+	// consolidationInputSize is the encoded size in bytes of an input that
+	// spends a standard single-key address, including its signature: 112
+	// bytes for the input and 201 for the signature.
+	consolidationInputSize = 313
+
+	// splitSendOverhead is an upper bound on the encoded size in bytes of a
+	// split send transaction, excluding its inputs and their signatures.
+	splitSendOverhead = 750
+
+	// maxSendInputs is the maximum number of inputs in a transaction created
+	// by SendSiacoins. Sends that need more inputs are split across multiple
+	// transactions, so that each stays well below the transaction size limit.
+	maxSendInputs = 88
+)
+
+var (
+	// walletKDFParams are the default scrypt cost parameters used to derive
+	// the key that encrypts the wallet's seeds and keys from the master key.
+	// They can be changed with SetKDFParams. The parameters, along with a
+	// random salt, are stored in the wallet database when the wallet is
+	// encrypted.
+	walletKDFParams = build.Select(build.Var{
+		Dev:      crypto.DefaultScryptParams,
+		Standard: crypto.DefaultScryptParams,
+		Testing:  crypto.ScryptParams{N: 1 << 10, R: 8, P: 1},
+	}).(crypto.ScryptParams)
 )
 
 // dustValue is the quantity below which a Currency is considered to be Dust.
@@ -44,4 +77,14 @@ func init() {
 	if build.DEBUG && defragThreshold <= defragBatchSize+defragStartIndex {
 		panic("constants are incorrect, defragThreshold needs to be larger than the sum of defragBatchSize and defragStartIndex")
 	}
+	// Sanity check - a consolidation skips the same outputs as a defrag, and
+	// should still combine more than one output.
+	if build.DEBUG && consolidationThreshold <= defragStartIndex+1 {
+		panic("constants are incorrect, consolidationThreshold needs to be larger than defragStartIndex plus one")
+	}
+	// Sanity check - a split send transaction should leave a margin of at
+	// least 10% below the transaction size limit.
+	if build.DEBUG && splitSendOverhead+maxSendInputs*consolidationInputSize > modules.TransactionSizeLimit*9/10 {
+		panic("constants are incorrect, maxSendInputs inputs do not fit in a transaction with enough margin")
+	}
 }
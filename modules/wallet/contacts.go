@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errEmptyContactName is returned by AddContact if the name is empty.
+	errEmptyContactName = errors.New("contact name cannot be empty")
+
+	// errDuplicateContact is returned by AddContact if the address book
+	// already contains a contact with the same name.
+	errDuplicateContact = errors.New("a contact with that name already exists")
+
+	// errUnknownContact is returned by RemoveContact if the address book does
+	// not contain a contact with the given name.
+	errUnknownContact = errors.New("no contact with that name exists")
+)
+
+// AddContact adds a named address to the wallet's address book. The address
+// book is stored locally and is not derived from the seed, so it is not
+// restored when the wallet is recovered from its seed; it is included in
+// backups made with ExportBackup.
+func (w *Wallet) AddContact(name string, addr types.UnlockHash) error {
+	if name == "" {
+		return errEmptyContactName
+	}
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := dbGetContact(w.dbTx, name); err == nil {
+		return errDuplicateContact
+	}
+	if err := dbPutContact(w.dbTx, name, addr); err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
+
+// Contacts returns the entries of the wallet's address book, sorted by name.
+func (w *Wallet) Contacts() []modules.WalletContact {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var contacts []modules.WalletContact
+	err := dbForEachContact(w.dbTx, func(name string, addr types.UnlockHash) {
+		contacts = append(contacts, modules.WalletContact{Name: name, Address: addr})
+	})
+	if err != nil {
+		w.log.Println("ERROR: could not load contacts:", err)
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].Name < contacts[j].Name
+	})
+	return contacts
+}
+
+// RemoveContact removes a named address from the wallet's address book.
+func (w *Wallet) RemoveContact(name string) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := dbGetContact(w.dbTx, name); err == errNoKey {
+		return errUnknownContact
+	} else if err != nil {
+		return err
+	}
+	if err := dbDeleteContact(w.dbTx, name); err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
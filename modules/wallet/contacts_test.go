@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestContacts checks that contacts can be added, listed, and removed, that
+// duplicate names are rejected, and that the address book persists across
+// restarts.
+func TestContacts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	alice, bob := types.UnlockHash{1}, types.UnlockHash{2}
+	if err := wt.wallet.AddContact("bob", bob); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.AddContact("alice", alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.AddContact("bob", alice); err != errDuplicateContact {
+		t.Fatal("expected errDuplicateContact, got", err)
+	}
+	if err := wt.wallet.AddContact("", alice); err != errEmptyContactName {
+		t.Fatal("expected errEmptyContactName, got", err)
+	}
+
+	// The address book should survive a restart.
+	err = wt.wallet.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet, err = New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contacts := wt.wallet.Contacts()
+	if len(contacts) != 2 || contacts[0] != (modules.WalletContact{Name: "alice", Address: alice}) || contacts[1] != (modules.WalletContact{Name: "bob", Address: bob}) {
+		t.Fatal("wrong contacts after restart:", contacts)
+	}
+
+	if err := wt.wallet.RemoveContact("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.RemoveContact("alice"); err != errUnknownContact {
+		t.Fatal("expected errUnknownContact, got", err)
+	}
+	if contacts := wt.wallet.Contacts(); len(contacts) != 1 || contacts[0].Name != "bob" {
+		t.Fatal("wrong contacts after removal:", contacts)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -15,6 +16,21 @@ import (
 )
 
 var (
+	// bucketAddressReceipts maps an UnlockHash to the number of confirmed
+	// transactions that have sent funds to it. Only addresses that the wallet
+	// controls are tracked. The wallet uses these counts to detect address
+	// reuse.
+	bucketAddressReceipts = []byte("bucketAddressReceipts")
+	// bucketContacts maps the names in the wallet's address book to their
+	// addresses.
+	bucketContacts = []byte("bucketContacts")
+	// bucketLockedOutputs contains the IDs of SiacoinOutputs that the user has
+	// locked. Locked outputs are never used to fund transactions. The values
+	// of the bucket are unused.
+	bucketLockedOutputs = []byte("bucketLockedOutputs")
+	// bucketMemos maps an OutputID to the memo that was attached to it by
+	// SendSiacoinsWithMemos. Memos are never broadcast.
+	bucketMemos = []byte("bucketMemos")
 	// bucketProcessedTransactions stores ProcessedTransactions in
 	// chronological order. Only transactions relevant to the wallet are
 	// stored. The key of this bucket is an autoincrementing integer.
@@ -37,6 +53,10 @@ var (
 	bucketWallet = []byte("bucketWallet")
 
 	dbBuckets = [][]byte{
+		bucketAddressReceipts,
+		bucketContacts,
+		bucketLockedOutputs,
+		bucketMemos,
 		bucketProcessedTransactions,
 		bucketSiacoinOutputs,
 		bucketSiafundOutputs,
@@ -54,6 +74,12 @@ var (
 	keySpendableKeyFiles      = []byte("keySpendableKeyFiles")
 	keyAuxiliarySeedFiles     = []byte("keyAuxiliarySeedFiles")
 	keySiafundPool            = []byte("keySiafundPool")
+	keyChangeAddressPolicy    = []byte("keyChangeAddressPolicy")
+	keyRequiredConfirmations  = []byte("keyRequiredConfirmations")
+	keyLargeSendThreshold     = []byte("keyLargeSendThreshold")
+	keyConsolidationThreshold = []byte("keyConsolidationThreshold")
+	keyWalletKDF              = []byte("keyWalletKDF")
+	keyWalletKDFCost          = []byte("keyWalletKDFCost")
 
 	errNoKey = errors.New("key does not exist")
 )
@@ -204,6 +230,50 @@ func dbDeleteSpentOutput(tx *bolt.Tx, id types.OutputID) error {
 	return dbDelete(tx.Bucket(bucketSpentOutputs), id)
 }
 
+func dbPutAddressReceipts(tx *bolt.Tx, uh types.UnlockHash, n uint64) error {
+	return dbPut(tx.Bucket(bucketAddressReceipts), uh, n)
+}
+func dbGetAddressReceipts(tx *bolt.Tx, uh types.UnlockHash) (n uint64, err error) {
+	err = dbGet(tx.Bucket(bucketAddressReceipts), uh, &n)
+	return
+}
+func dbDeleteAddressReceipts(tx *bolt.Tx, uh types.UnlockHash) error {
+	return dbDelete(tx.Bucket(bucketAddressReceipts), uh)
+}
+
+func dbPutLockedOutput(tx *bolt.Tx, id types.SiacoinOutputID) error {
+	return dbPut(tx.Bucket(bucketLockedOutputs), id, true)
+}
+func dbGetLockedOutput(tx *bolt.Tx, id types.SiacoinOutputID) (locked bool, err error) {
+	err = dbGet(tx.Bucket(bucketLockedOutputs), id, &locked)
+	return
+}
+func dbDeleteLockedOutput(tx *bolt.Tx, id types.SiacoinOutputID) error {
+	return dbDelete(tx.Bucket(bucketLockedOutputs), id)
+}
+
+func dbPutContact(tx *bolt.Tx, name string, addr types.UnlockHash) error {
+	return dbPut(tx.Bucket(bucketContacts), name, addr)
+}
+func dbGetContact(tx *bolt.Tx, name string) (addr types.UnlockHash, err error) {
+	err = dbGet(tx.Bucket(bucketContacts), name, &addr)
+	return
+}
+func dbDeleteContact(tx *bolt.Tx, name string) error {
+	return dbDelete(tx.Bucket(bucketContacts), name)
+}
+func dbForEachContact(tx *bolt.Tx, fn func(string, types.UnlockHash)) error {
+	return dbForEach(tx.Bucket(bucketContacts), fn)
+}
+
+func dbPutMemo(tx *bolt.Tx, id types.OutputID, memo string) error {
+	return dbPut(tx.Bucket(bucketMemos), id, memo)
+}
+func dbGetMemo(tx *bolt.Tx, id types.OutputID) (memo string, err error) {
+	err = dbGet(tx.Bucket(bucketMemos), id, &memo)
+	return
+}
+
 // bucketProcessedTransactions works a little differently: the key is
 // meaningless, only used to order the transactions chronologically.
 
@@ -333,6 +403,112 @@ func dbPutSiafundPool(tx *bolt.Tx, pool types.Currency) error {
 	return tx.Bucket(bucketWallet).Put(keySiafundPool, encoding.Marshal(pool))
 }
 
+// dbGetChangeAddressPolicy returns the wallet's change address policy. If no
+// policy has been set, the default policy is returned.
+func dbGetChangeAddressPolicy(tx *bolt.Tx) (policy modules.ChangeAddressPolicy, err error) {
+	policyBytes := tx.Bucket(bucketWallet).Get(keyChangeAddressPolicy)
+	if policyBytes == nil {
+		return modules.ChangeAddressPolicy{Mode: modules.ChangeToNewAddress}, nil
+	}
+	err = encoding.Unmarshal(policyBytes, &policy)
+	return
+}
+
+// dbPutChangeAddressPolicy stores the wallet's change address policy.
+func dbPutChangeAddressPolicy(tx *bolt.Tx, policy modules.ChangeAddressPolicy) error {
+	return tx.Bucket(bucketWallet).Put(keyChangeAddressPolicy, encoding.Marshal(policy))
+}
+
+// dbGetRequiredConfirmations returns the number of confirmations an output
+// needs before it is included in the confirmed balance. If no value has been
+// set, 0 is returned.
+func dbGetRequiredConfirmations(tx *bolt.Tx) (n int, err error) {
+	nBytes := tx.Bucket(bucketWallet).Get(keyRequiredConfirmations)
+	if nBytes == nil {
+		return 0, nil
+	}
+	err = encoding.Unmarshal(nBytes, &n)
+	return
+}
+
+// dbPutRequiredConfirmations stores the number of confirmations an output
+// needs before it is included in the confirmed balance.
+func dbPutRequiredConfirmations(tx *bolt.Tx, n int) error {
+	return tx.Bucket(bucketWallet).Put(keyRequiredConfirmations, encoding.Marshal(n))
+}
+
+// dbGetLargeSendThreshold returns the amount above which sends must be
+// confirmed. A threshold of zero disables the check.
+func dbGetLargeSendThreshold(tx *bolt.Tx) (c types.Currency, err error) {
+	cBytes := tx.Bucket(bucketWallet).Get(keyLargeSendThreshold)
+	if cBytes == nil {
+		return types.ZeroCurrency, nil
+	}
+	err = encoding.Unmarshal(cBytes, &c)
+	return
+}
+
+// dbPutLargeSendThreshold stores the amount above which sends must be
+// confirmed.
+func dbPutLargeSendThreshold(tx *bolt.Tx, c types.Currency) error {
+	return tx.Bucket(bucketWallet).Put(keyLargeSendThreshold, encoding.Marshal(c))
+}
+
+// dbGetConsolidationThreshold returns the fee below which the wallet
+// opportunistically consolidates its outputs. A threshold of zero disables
+// consolidation.
+func dbGetConsolidationThreshold(tx *bolt.Tx) (c types.Currency, err error) {
+	cBytes := tx.Bucket(bucketWallet).Get(keyConsolidationThreshold)
+	if cBytes == nil {
+		return types.ZeroCurrency, nil
+	}
+	err = encoding.Unmarshal(cBytes, &c)
+	return
+}
+
+// dbPutConsolidationThreshold stores the fee below which the wallet
+// opportunistically consolidates its outputs.
+func dbPutConsolidationThreshold(tx *bolt.Tx, c types.Currency) error {
+	return tx.Bucket(bucketWallet).Put(keyConsolidationThreshold, encoding.Marshal(c))
+}
+
+// dbGetWalletKDF returns the scrypt parameters used to derive the wallet's
+// encryption key from the master key. ok is false for wallets that were
+// encrypted before scrypt was used, whose encryption key is the master key
+// itself.
+func dbGetWalletKDF(tx *bolt.Tx) (params crypto.ScryptParams, ok bool, err error) {
+	paramsBytes := tx.Bucket(bucketWallet).Get(keyWalletKDF)
+	if paramsBytes == nil {
+		return crypto.ScryptParams{}, false, nil
+	}
+	err = encoding.Unmarshal(paramsBytes, &params)
+	return params, err == nil, err
+}
+
+// dbPutWalletKDF stores the scrypt parameters used to derive the wallet's
+// encryption key.
+func dbPutWalletKDF(tx *bolt.Tx, params crypto.ScryptParams) error {
+	return tx.Bucket(bucketWallet).Put(keyWalletKDF, encoding.Marshal(params))
+}
+
+// dbGetWalletKDFCost returns the scrypt cost parameters used when the
+// wallet's encryption key is next derived from a new master key. If no value
+// has been set, walletKDFParams is returned.
+func dbGetWalletKDFCost(tx *bolt.Tx) (params crypto.ScryptParams, err error) {
+	paramsBytes := tx.Bucket(bucketWallet).Get(keyWalletKDFCost)
+	if paramsBytes == nil {
+		return walletKDFParams, nil
+	}
+	err = encoding.Unmarshal(paramsBytes, &params)
+	return
+}
+
+// dbPutWalletKDFCost stores the scrypt cost parameters used when the wallet's
+// encryption key is next derived from a new master key.
+func dbPutWalletKDFCost(tx *bolt.Tx, params crypto.ScryptParams) error {
+	return tx.Bucket(bucketWallet).Put(keyWalletKDFCost, encoding.Marshal(params))
+}
+
 // COMPATv121: these types were stored in the db in v1.2.2 and earlier.
 type (
 	v121ProcessedInput struct {
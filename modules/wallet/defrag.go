@@ -4,20 +4,22 @@ import (
 	"errors"
 	"sort"
 
-	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/types"
 )
 
 var (
 	errDefragNotNeeded = errors.New("defragging not needed, wallet is already sufficiently defragged")
+
+	errConsolidationTooExpensive = errors.New("fee would exceed the value of the consolidated outputs")
 )
 
 // createDefragTransaction creates a transaction that spends multiple existing
-// wallet outputs into a single new address.
-func (w *Wallet) createDefragTransaction() ([]types.Transaction, error) {
+// wallet outputs into a single new address. As with
+// createConsolidationTransaction, the transactions are returned unsigned.
+func (w *Wallet) createDefragTransaction() ([]types.Transaction, [][]pendingSignature, error) {
 	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Collect a value-sorted set of siacoin outputs.
@@ -29,21 +31,34 @@ func (w *Wallet) createDefragTransaction() ([]types.Transaction, error) {
 		}
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	sort.Sort(sort.Reverse(so))
 
 	// Only defrag if there are enough outputs to merit defragging.
 	if len(so.ids) <= defragThreshold {
-		return nil, errDefragNotNeeded
+		return nil, nil, errDefragNotNeeded
 	}
 
 	// Skip over the 'defragStartIndex' largest outputs, so that the user can
 	// still reasonably use their wallet while the defrag is happening.
+	batch := sortedOutputs{
+		ids:     so.ids[defragStartIndex : defragStartIndex+defragBatchSize],
+		outputs: so.outputs[defragStartIndex : defragStartIndex+defragBatchSize],
+	}
+	return w.createConsolidationTransaction(consensusHeight, batch, defragFee())
+}
+
+// createConsolidationTransaction creates a transaction set that spends the
+// provided outputs into a single new address, paying the specified fee. The
+// outputs are marked as spent. The transactions are returned unsigned, along
+// with the signatures that must be added to each of them with
+// signTransactionSet once w.mu has been released.
+func (w *Wallet) createConsolidationTransaction(consensusHeight types.BlockHeight, so sortedOutputs, fee types.Currency) ([]types.Transaction, [][]pendingSignature, error) {
 	var amount types.Currency
 	var parentTxn types.Transaction
 	var spentScoids []types.SiacoinOutputID
-	for i := defragStartIndex; i < defragStartIndex+defragBatchSize; i++ {
+	for i := range so.ids {
 		scoid := so.ids[i]
 		sco := so.outputs[i]
 
@@ -59,12 +74,15 @@ func (w *Wallet) createDefragTransaction() ([]types.Transaction, error) {
 		// Add the output to the total fund
 		amount = amount.Add(sco.Value)
 	}
+	if amount.Cmp(fee) <= 0 {
+		return nil, nil, errConsolidationTooExpensive
+	}
 
 	// Create and add the output that will be used to fund the defrag
 	// transaction.
 	parentUnlockConditions, err := w.nextPrimarySeedAddress(w.dbTx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	exactOutput := types.SiacoinOutput{
 		Value:      amount,
@@ -72,16 +90,10 @@ func (w *Wallet) createDefragTransaction() ([]types.Transaction, error) {
 	}
 	parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, exactOutput)
 
-	// Sign all of the inputs to the parent transaction.
-	for _, sci := range parentTxn.SiacoinInputs {
-		addSignatures(&parentTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), w.keys[sci.UnlockConditions.UnlockHash()])
-	}
-
 	// Create the defrag transaction.
-	fee := defragFee()
 	refundAddr, err := w.nextPrimarySeedAddress(w.dbTx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	txn := types.Transaction{
 		SiacoinInputs: []types.SiacoinInput{{
@@ -94,22 +106,24 @@ func (w *Wallet) createDefragTransaction() ([]types.Transaction, error) {
 		}},
 		MinerFees: []types.Currency{fee},
 	}
-	addSignatures(&txn, types.FullCoveredFields, parentUnlockConditions, crypto.Hash(parentTxn.SiacoinOutputID(0)), w.keys[parentUnlockConditions.UnlockHash()])
 
 	// Mark all outputs that were spent as spent.
 	for _, scoid := range spentScoids {
 		if err = dbPutSpentOutput(w.dbTx, types.OutputID(scoid), consensusHeight); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	// Mark the parent output as spent. Must be done after the transaction is
-	// finished because otherwise the txid and output id will change.
+	// Mark the parent output as spent. Signatures are not part of the
+	// transaction ID, so the output ID does not change once the parent is
+	// signed.
 	if err = dbPutSpentOutput(w.dbTx, types.OutputID(parentTxn.SiacoinOutputID(0)), consensusHeight); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Construct the final transaction set
-	return []types.Transaction{parentTxn, txn}, nil
+	txnSet := []types.Transaction{parentTxn, txn}
+	sigs := [][]pendingSignature{w.pendingSignatures(parentTxn), w.pendingSignatures(txn)}
+	return txnSet, sigs, nil
 }
 
 // threadedDefragWallet computes the sum of the 15 largest outputs in the wallet and
@@ -132,7 +146,7 @@ func (w *Wallet) threadedDefragWallet() {
 	}
 
 	// Create the defrag transaction.
-	txnSet, err := w.createDefragTransaction()
+	txnSet, sigs, err := w.createDefragTransaction()
 	w.mu.Unlock()
 	if err == errDefragNotNeeded {
 		// benign
@@ -141,6 +155,11 @@ func (w *Wallet) threadedDefragWallet() {
 		w.log.Println("WARN: couldn't create defrag transaction:", err)
 		return
 	}
+	if err := signTransactionSet(txnSet, sigs); err != nil {
+		w.managedUnmarkSpentOutputs(txnSet)
+		w.log.Println("WARN: couldn't sign defrag transaction:", err)
+		return
+	}
 	// Submit the defrag to the transaction pool.
 	err = w.tpool.AcceptTransactionSet(txnSet)
 	if err != nil {
@@ -34,6 +34,43 @@ func uidEncryptionKey(masterKey crypto.TwofishKey, uid uniqueID) crypto.TwofishK
 	return crypto.TwofishKey(crypto.HashAll(masterKey, uid))
 }
 
+// deriveWalletKey derives the key that encrypts the wallet's seeds and keys
+// from masterKey, using scrypt with the supplied parameters. The master key is
+// usually derived from a user's password, so it is stretched with a salted
+// KDF to make brute-forcing the password expensive.
+func deriveWalletKey(masterKey crypto.TwofishKey, params crypto.ScryptParams) (key crypto.TwofishKey, err error) {
+	k, err := crypto.DeriveKeyScrypt(masterKey[:], params)
+	if err != nil {
+		return crypto.TwofishKey{}, err
+	}
+	copy(key[:], k)
+	return key, nil
+}
+
+// managedWalletKey returns the key that encrypts the wallet's seeds and keys
+// for masterKey. For wallets that were encrypted before scrypt was used, this
+// is masterKey itself. The key is derived without holding the wallet's lock.
+func (w *Wallet) managedWalletKey(masterKey crypto.TwofishKey) (crypto.TwofishKey, error) {
+	w.mu.Lock()
+	params, ok, err := dbGetWalletKDF(w.dbTx)
+	w.mu.Unlock()
+	if err != nil {
+		return crypto.TwofishKey{}, err
+	} else if !ok {
+		return masterKey, nil
+	}
+	return deriveWalletKey(masterKey, params)
+}
+
+// newWalletKey derives a wallet encryption key from masterKey using the cost
+// parameters of cost and a fresh salt, returning the key and the parameters
+// that must be stored alongside it.
+func newWalletKey(masterKey crypto.TwofishKey, cost crypto.ScryptParams) (crypto.TwofishKey, crypto.ScryptParams, error) {
+	params := crypto.NewScryptParams(cost.N, cost.R, cost.P)
+	key, err := deriveWalletKey(masterKey, params)
+	return key, params, err
+}
+
 // verifyEncryption verifies that key properly decrypts the ciphertext to a
 // preset plaintext.
 func verifyEncryption(key crypto.TwofishKey, encrypted crypto.Ciphertext) error {
@@ -62,11 +99,25 @@ func (w *Wallet) initEncryption(masterKey crypto.TwofishKey, seed modules.Seed,
 		return modules.Seed{}, errReencrypt
 	}
 
+	// derive the wallet's encryption key from the masterKey
+	cost, err := dbGetWalletKDFCost(w.dbTx)
+	if err != nil {
+		return modules.Seed{}, err
+	}
+	key, params, err := newWalletKey(masterKey, cost)
+	if err != nil {
+		return modules.Seed{}, err
+	}
+	err = dbPutWalletKDF(w.dbTx, params)
+	if err != nil {
+		return modules.Seed{}, err
+	}
+
 	// create a seedFile for the seed
-	sf := createSeedFile(masterKey, seed)
+	sf := createSeedFile(key, seed)
 
 	// set this as the primary seedFile
-	err := wb.Put(keyPrimarySeedFile, encoding.Marshal(sf))
+	err = wb.Put(keyPrimarySeedFile, encoding.Marshal(sf))
 	if err != nil {
 		return modules.Seed{}, err
 	}
@@ -75,9 +126,9 @@ func (w *Wallet) initEncryption(masterKey crypto.TwofishKey, seed modules.Seed,
 		return modules.Seed{}, err
 	}
 
-	// Establish the encryption verification using the masterKey. After this
-	// point, the wallet is encrypted.
-	uk := uidEncryptionKey(masterKey, dbGetWalletUID(w.dbTx))
+	// Establish the encryption verification using the key. After this point,
+	// the wallet is encrypted.
+	uk := uidEncryptionKey(key, dbGetWalletUID(w.dbTx))
 	err = wb.Put(keyEncryptionVerification, uk.EncryptBytes(verificationPlaintext))
 	if err != nil {
 		return modules.Seed{}, err
@@ -102,6 +153,10 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 	} else if !encrypted {
 		return errUnencryptedWallet
 	}
+	key, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
 
 	// Load db objects into memory.
 	var lastChange modules.ConsensusChangeID
@@ -109,12 +164,12 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 	var primarySeedProgress uint64
 	var auxiliarySeedFiles []seedFile
 	var unseededKeyFiles []spendableKeyFile
-	err := func() error {
+	err = func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
 
-		// verify masterKey
-		err := checkMasterKey(w.dbTx, masterKey)
+		// verify key
+		err := checkMasterKey(w.dbTx, key)
 		if err != nil {
 			return err
 		}
@@ -157,7 +212,7 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 		defer w.mu.Unlock()
 
 		// primarySeedFile
-		primarySeed, err := decryptSeedFile(masterKey, primarySeedFile)
+		primarySeed, err := decryptSeedFile(key, primarySeedFile)
 		if err != nil {
 			return err
 		}
@@ -166,7 +221,7 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 
 		// auxiliarySeedFiles
 		for _, sf := range auxiliarySeedFiles {
-			auxSeed, err := decryptSeedFile(masterKey, sf)
+			auxSeed, err := decryptSeedFile(key, sf)
 			if err != nil {
 				return err
 			}
@@ -176,11 +231,11 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 
 		// unseededKeyFiles
 		for _, uk := range unseededKeyFiles {
-			sk, err := decryptSpendableKeyFile(masterKey, uk)
+			sk, err := decryptSpendableKeyFile(key, uk)
 			if err != nil {
 				return err
 			}
-			w.integrateSpendableKey(masterKey, sk)
+			w.integrateSpendableKey(key, sk)
 		}
 		return nil
 	}()
@@ -335,6 +390,8 @@ func (w *Wallet) Reset() error {
 	}
 	w.wipeSecrets()
 	w.keys = make(map[types.UnlockHash]spendableKey)
+	w.signers = make(map[types.UnlockHash][]modules.Signer)
+	w.unseededKeys = make(map[types.UnlockHash]struct{})
 	w.seeds = []modules.Seed{}
 	w.unconfirmedProcessedTransactions = []modules.ProcessedTransaction{}
 	w.unlocked = false
@@ -414,8 +471,10 @@ func (w *Wallet) Lock() error {
 }
 
 // managedChangeKey safely performs the database operations required to change
-// the wallet's encryption key.
-func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.TwofishKey) error {
+// the wallet's encryption key from key to newKey. newKey must have been
+// derived using newParams, which are stored alongside the re-encrypted seeds
+// and keys.
+func (w *Wallet) managedChangeKey(key, newKey crypto.TwofishKey, newParams crypto.ScryptParams) error {
 	w.mu.Lock()
 	encrypted := w.encrypted
 	w.mu.Unlock()
@@ -432,8 +491,8 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 		w.mu.Lock()
 		defer w.mu.Unlock()
 
-		// verify masterKey
-		err := checkMasterKey(w.dbTx, masterKey)
+		// verify key
+		err := checkMasterKey(w.dbTx, key)
 		if err != nil {
 			return err
 		}
@@ -469,19 +528,19 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 	var auxiliarySeeds []modules.Seed
 	var spendableKeys []spendableKey
 
-	primarySeed, err = decryptSeedFile(masterKey, primarySeedFile)
+	primarySeed, err = decryptSeedFile(key, primarySeedFile)
 	if err != nil {
 		return err
 	}
 	for _, sf := range auxiliarySeedFiles {
-		auxSeed, err := decryptSeedFile(masterKey, sf)
+		auxSeed, err := decryptSeedFile(key, sf)
 		if err != nil {
 			return err
 		}
 		auxiliarySeeds = append(auxiliarySeeds, auxSeed)
 	}
 	for _, uk := range unseededKeyFiles {
-		sk, err := decryptSpendableKeyFile(masterKey, uk)
+		sk, err := decryptSpendableKeyFile(key, uk)
 		if err != nil {
 			return err
 		}
@@ -534,7 +593,7 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 			return err
 		}
 
-		return nil
+		return dbPutWalletKDF(w.dbTx, newParams)
 	}()
 	if err != nil {
 		return err
@@ -543,14 +602,100 @@ func (w *Wallet) managedChangeKey(masterKey crypto.TwofishKey, newKey crypto.Two
 	return nil
 }
 
-// ChangeKey changes the wallet's encryption key from masterKey to newKey.
+// ChangeKey changes the wallet's encryption key from masterKey to newKey. The
+// new encryption key is derived using the cost parameters set with
+// SetKDFParams.
 func (w *Wallet) ChangeKey(masterKey crypto.TwofishKey, newKey crypto.TwofishKey) error {
 	if err := w.tg.Add(); err != nil {
 		return err
 	}
 	defer w.tg.Done()
 
-	return w.managedChangeKey(masterKey, newKey)
+	oldKey, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
+	cost, err := w.managedKDFCost()
+	if err != nil {
+		return err
+	}
+	key, params, err := newWalletKey(newKey, cost)
+	if err != nil {
+		return err
+	}
+	return w.managedChangeKey(oldKey, key, params)
+}
+
+// managedKDFCost returns the scrypt cost parameters used to derive new wallet
+// encryption keys.
+func (w *Wallet) managedKDFCost() (crypto.ScryptParams, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return dbGetWalletKDFCost(w.dbTx)
+}
+
+// KDFParams returns the scrypt cost parameters used to derive new wallet
+// encryption keys. The salt of the returned params is always zero.
+func (w *Wallet) KDFParams() crypto.ScryptParams {
+	cost, err := w.managedKDFCost()
+	if err != nil {
+		w.log.Println("ERROR: could not load wallet KDF parameters:", err)
+	}
+	return cost
+}
+
+// SetKDFParams sets the scrypt cost parameters used to derive the wallet's
+// encryption key from the master key, allowing operators to make the wallet
+// password harder to brute-force on stronger hardware. The salt of params is
+// ignored. The parameters apply the next time the encryption key is derived
+// from a new master key, i.e. by Encrypt, InitFromSeed, or ChangeKey; an
+// encrypted wallet can be re-encrypted with them by calling ChangeKey with its
+// current master key. A warning is logged if the parameters are weak, and
+// parameters exceeding crypto.MaxScryptParams are rejected.
+func (w *Wallet) SetKDFParams(params crypto.ScryptParams) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	cost := crypto.ScryptParams{N: params.N, R: params.R, P: params.P}
+	if err := cost.Validate(); err != nil {
+		return err
+	} else if cost.Expensive() {
+		return crypto.ErrExpensiveScryptParams
+	}
+	if cost.Weak() {
+		w.log.Println("WARN: wallet KDF parameters are weak; the wallet password may be easy to brute-force")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := dbPutWalletKDFCost(w.dbTx, cost); err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
+
+// CheckKey returns modules.ErrBadEncryptionKey if masterKey is not the key
+// used to encrypt the wallet. Unlike Unlock, it does not change the state of
+// the wallet.
+func (w *Wallet) CheckKey(masterKey crypto.TwofishKey) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	key, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.encrypted {
+		return errUnencryptedWallet
+	}
+	return checkMasterKey(w.dbTx, key)
 }
 
 // Unlock will decrypt the wallet seed and load all of the addresses into
@@ -576,5 +721,27 @@ func (w *Wallet) Unlock(masterKey crypto.TwofishKey) error {
 
 	// Initialize all of the keys in the wallet under a lock. While holding the
 	// lock, also grab the subscriber status.
-	return w.managedUnlock(masterKey)
+	if err := w.managedUnlock(masterKey); err != nil {
+		return err
+	}
+
+	// Wallets that were encrypted before scrypt was used are re-encrypted
+	// with a key derived using scrypt. This can only happen here, because the
+	// master key is not stored.
+	w.mu.Lock()
+	_, ok, err := dbGetWalletKDF(w.dbTx)
+	w.mu.Unlock()
+	if err != nil || ok {
+		return err
+	}
+	w.log.Println("INFO: Upgrading wallet encryption to use scrypt.")
+	cost, err := w.managedKDFCost()
+	if err != nil {
+		return err
+	}
+	newKey, newParams, err := newWalletKey(masterKey, cost)
+	if err != nil {
+		return err
+	}
+	return w.managedChangeKey(masterKey, newKey, newParams)
 }
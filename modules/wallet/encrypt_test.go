@@ -9,6 +9,7 @@ import (
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/miner"
 	"github.com/NebulousLabs/Sia/types"
@@ -476,3 +477,176 @@ func TestChangeKey(t *testing.T) {
 	}
 	postEncryptionTesting(wt.miner, wt.wallet, newKey)
 }
+
+// TestWalletKDF checks that the wallet's seeds are encrypted with a key
+// derived from the master key using scrypt, rather than with the master key
+// itself.
+func TestWalletKDF(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	wt.wallet.mu.Lock()
+	params, ok, err := dbGetWalletKDF(wt.wallet.dbTx)
+	if err == nil && ok {
+		err = checkMasterKey(wt.wallet.dbTx, wt.walletMasterKey)
+	}
+	wt.wallet.mu.Unlock()
+	if !ok {
+		t.Fatal("wallet does not have KDF parameters")
+	} else if params.Salt == ([crypto.ScryptSaltSize]byte{}) {
+		t.Fatal("wallet KDF parameters are not salted")
+	} else if err != modules.ErrBadEncryptionKey {
+		t.Fatal("wallet is encrypted with the master key itself")
+	}
+	if err := wt.wallet.CheckKey(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two wallets with the same master key should use different salts, and
+	// therefore different encryption keys.
+	dir := filepath.Join(build.TempDir(modules.WalletDir, t.Name()+"-new"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Encrypt(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	key1, err := wt.wallet.managedWalletKey(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, err := w.managedWalletKey(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key1 == key2 {
+		t.Fatal("wallets with the same master key derived the same encryption key")
+	}
+}
+
+// TestSetKDFParams checks that the wallet's encryption key is derived using
+// the cost parameters set with SetKDFParams the next time the key changes.
+func TestSetKDFParams(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if p := wt.wallet.KDFParams(); p.N != walletKDFParams.N || p.R != walletKDFParams.R || p.P != walletKDFParams.P {
+		t.Fatal("expected default KDF parameters, got", p)
+	}
+	if err := wt.wallet.SetKDFParams(crypto.ScryptParams{N: 3, R: 8, P: 1}); err != crypto.ErrInvalidScryptParams {
+		t.Fatal("expected ErrInvalidScryptParams, got", err)
+	}
+	expensive := crypto.MaxScryptParams
+	expensive.N *= 2
+	if err := wt.wallet.SetKDFParams(expensive); err != crypto.ErrExpensiveScryptParams {
+		t.Fatal("expected ErrExpensiveScryptParams, got", err)
+	}
+
+	cost := crypto.ScryptParams{N: 1 << 11, R: 8, P: 2}
+	if err := wt.wallet.SetKDFParams(cost); err != nil {
+		t.Fatal(err)
+	} else if p := wt.wallet.KDFParams(); p != cost {
+		t.Fatal("KDF parameters were not set:", p)
+	}
+
+	// Changing the key to itself should re-encrypt the wallet using the new
+	// parameters.
+	if err := wt.wallet.ChangeKey(wt.walletMasterKey, wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Lock()
+	params, _, err := dbGetWalletKDF(wt.wallet.dbTx)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	} else if params.N != cost.N || params.R != cost.R || params.P != cost.P {
+		t.Fatal("wallet was not re-encrypted with the new KDF parameters:", params)
+	}
+	if err := wt.wallet.CheckKey(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLegacyWalletKDF checks that a wallet encrypted with the master key
+// itself, as wallets were before scrypt was used, can still be unlocked, and
+// is upgraded to use scrypt when it is.
+func TestLegacyWalletKDF(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createBlankWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Encrypt the wallet the way that wallets were encrypted before scrypt
+	// was used.
+	var masterKey crypto.TwofishKey
+	fastrand.Read(masterKey[:])
+	var seed modules.Seed
+	fastrand.Read(seed[:])
+	w := wt.wallet
+	w.mu.Lock()
+	wb := w.dbTx.Bucket(bucketWallet)
+	err = wb.Put(keyPrimarySeedFile, encoding.Marshal(createSeedFile(masterKey, seed)))
+	if err == nil {
+		err = wb.Put(keyPrimarySeedProgress, encoding.Marshal(uint64(0)))
+	}
+	if err == nil {
+		uk := uidEncryptionKey(masterKey, dbGetWalletUID(w.dbTx))
+		err = wb.Put(keyEncryptionVerification, uk.EncryptBytes(verificationPlaintext))
+	}
+	w.encrypted = true
+	w.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unlocking the wallet should upgrade it.
+	if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	w.mu.Lock()
+	_, ok, err := dbGetWalletKDF(w.dbTx)
+	w.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("legacy wallet was not upgraded to use scrypt")
+	}
+	primarySeed, _, err := w.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	} else if primarySeed != seed {
+		t.Fatal("upgraded wallet has the wrong primary seed")
+	}
+
+	// The upgraded wallet should still unlock with the same master key.
+	if err := w.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	primarySeed, _, err = w.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	} else if primarySeed != seed {
+		t.Fatal("upgraded wallet has the wrong primary seed")
+	}
+}
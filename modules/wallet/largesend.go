@@ -0,0 +1,53 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// managedCheckLargeSend returns ErrLargeSendNeedsConfirmation if amount
+// exceeds the wallet's large send threshold.
+func (w *Wallet) managedCheckLargeSend(amount types.Currency) error {
+	w.mu.Lock()
+	threshold, err := dbGetLargeSendThreshold(w.dbTx)
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !threshold.IsZero() && amount.Cmp(threshold) > 0 {
+		return modules.ErrLargeSendNeedsConfirmation
+	}
+	return nil
+}
+
+// LargeSendThreshold returns the amount above which sends must be confirmed.
+func (w *Wallet) LargeSendThreshold() types.Currency {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	threshold, err := dbGetLargeSendThreshold(w.dbTx)
+	if err != nil {
+		w.log.Println("ERROR: could not load large send threshold:", err)
+	}
+	return threshold
+}
+
+// SetLargeSendThreshold sets the amount above which SendSiacoins,
+// SendSiacoinsMulti and SendSiacoinsWithMemos refuse to send, returning
+// ErrLargeSendNeedsConfirmation. Such sends must be made with their Confirmed
+// variants instead. Miner fees do not count towards the threshold. The default
+// threshold, zero, disables the check.
+func (w *Wallet) SetLargeSendThreshold(c types.Currency) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := dbPutLargeSendThreshold(w.dbTx, c)
+	if err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
@@ -0,0 +1,66 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestLargeSendThreshold checks that sends above the large send threshold are
+// rejected unless they are explicitly confirmed.
+func TestLargeSendThreshold(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	threshold := types.SiacoinPrecision.Mul64(100)
+	if err := wt.wallet.SetLargeSendThreshold(threshold); err != nil {
+		t.Fatal(err)
+	}
+	if !wt.wallet.LargeSendThreshold().Equals(threshold) {
+		t.Fatal("threshold was not set")
+	}
+	large := threshold.Add(types.NewCurrency64(1))
+
+	// Sends at or below the threshold do not need confirmation.
+	if _, err := wt.wallet.SendSiacoins(threshold, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Larger sends must be confirmed.
+	if _, err := wt.wallet.SendSiacoins(large, types.UnlockHash{}); err != modules.ErrLargeSendNeedsConfirmation {
+		t.Fatal("expected ErrLargeSendNeedsConfirmation, got", err)
+	}
+	outputs := []types.SiacoinOutput{{Value: threshold}, {Value: threshold}}
+	if _, err := wt.wallet.SendSiacoinsMulti(outputs); err != modules.ErrLargeSendNeedsConfirmation {
+		t.Fatal("expected ErrLargeSendNeedsConfirmation, got", err)
+	}
+	sends := []modules.MemoSend{{Amount: threshold, Memo: "a"}, {Amount: threshold, Memo: "b"}}
+	if _, err := wt.wallet.SendSiacoinsWithMemos(sends); err != modules.ErrLargeSendNeedsConfirmation {
+		t.Fatal("expected ErrLargeSendNeedsConfirmation, got", err)
+	}
+	if _, err := wt.wallet.SendSiacoinsWithMemosConfirmed(sends); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoinsConfirmed(large, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoinsMultiConfirmed(outputs); err != nil {
+		t.Fatal(err)
+	}
+
+	// A threshold of zero disables the check.
+	if err := wt.wallet.SetLargeSendThreshold(types.ZeroCurrency); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(large, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+}
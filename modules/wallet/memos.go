@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// SendSiacoinsWithMemos creates a transaction that pays each of the specified
+// destinations. The memo of each send is stored locally and attached to the
+// corresponding output of the wallet's ProcessedTransactions; memos are never
+// broadcast. This is useful for reconciling batched payments. If the total
+// amount exceeds the large send threshold, ErrLargeSendNeedsConfirmation is
+// returned instead; use SendSiacoinsWithMemosConfirmed to send it anyway.
+func (w *Wallet) SendSiacoinsWithMemos(sends []modules.MemoSend) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	var total types.Currency
+	for _, s := range sends {
+		total = total.Add(s.Amount)
+	}
+	if err := w.managedCheckLargeSend(total); err != nil {
+		return nil, err
+	}
+	return w.managedSendSiacoinsWithMemos(sends)
+}
+
+// SendSiacoinsWithMemosConfirmed is like SendSiacoinsWithMemos, but sends the
+// coins regardless of the large send threshold.
+func (w *Wallet) SendSiacoinsWithMemosConfirmed(sends []modules.MemoSend) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	return w.managedSendSiacoinsWithMemos(sends)
+}
+
+// managedSendSiacoinsWithMemos sends the coins and stores the memos of sends.
+func (w *Wallet) managedSendSiacoinsWithMemos(sends []modules.MemoSend) ([]types.Transaction, error) {
+	outputs := make([]types.SiacoinOutput, len(sends))
+	for i, s := range sends {
+		outputs[i] = types.SiacoinOutput{
+			Value:      s.Amount,
+			UnlockHash: s.Dest,
+		}
+	}
+	txnSet, ids, err := w.managedSendSiacoinsMulti(outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, s := range sends {
+		if s.Memo == "" {
+			continue
+		}
+		err = dbPutMemo(w.dbTx, types.OutputID(ids[i]), s.Memo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.syncDB()
+	return txnSet, nil
+}
+
+// attachMemos returns a copy of pt whose outputs carry their stored memos. pt
+// itself is not modified. It must be called with a lock.
+func (w *Wallet) attachMemos(pt modules.ProcessedTransaction) modules.ProcessedTransaction {
+	copied := false
+	for i, po := range pt.Outputs {
+		memo, err := dbGetMemo(w.dbTx, po.ID)
+		if err != nil {
+			continue
+		}
+		if !copied {
+			pt.Outputs = append([]modules.ProcessedOutput(nil), pt.Outputs...)
+			copied = true
+		}
+		pt.Outputs[i].Memo = memo
+	}
+	return pt
+}
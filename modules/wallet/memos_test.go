@@ -0,0 +1,91 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestSendSiacoinsWithMemos checks that SendSiacoinsWithMemos pays each
+// destination and that the memos are attached to the wallet's processed
+// transactions, both before and after confirmation.
+func TestSendSiacoinsWithMemos(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	sends := []modules.MemoSend{
+		{Dest: types.UnlockHash{1}, Amount: types.SiacoinPrecision.Mul64(3), Memo: "withdrawal 1"},
+		{Dest: types.UnlockHash{2}, Amount: types.SiacoinPrecision.Mul64(5), Memo: "withdrawal 2"},
+		{Dest: types.UnlockHash{3}, Amount: types.SiacoinPrecision.Mul64(7)},
+	}
+	txnSet, err := wt.wallet.SendSiacoinsWithMemos(sends)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each destination should be paid exactly once.
+	txn := txnSet[len(txnSet)-1]
+	for _, s := range sends {
+		var paid int
+		for _, sco := range txn.SiacoinOutputs {
+			if sco.UnlockHash == s.Dest && sco.Value.Equals(s.Amount) {
+				paid++
+			}
+		}
+		if paid != 1 {
+			t.Fatalf("destination %v was paid %v times", s.Dest, paid)
+		}
+	}
+
+	// checkMemos checks that the outputs of pt carry the right memos.
+	checkMemos := func(pt modules.ProcessedTransaction) {
+		var found int
+		for _, po := range pt.Outputs {
+			for _, s := range sends {
+				if po.RelatedAddress == s.Dest {
+					found++
+					if po.Memo != s.Memo {
+						t.Errorf("output to %v has memo %q, expected %q", s.Dest, po.Memo, s.Memo)
+					}
+				}
+			}
+		}
+		if found != len(sends) {
+			t.Errorf("expected %v outputs, found %v", len(sends), found)
+		}
+	}
+
+	var unconfirmed bool
+	for _, pt := range wt.wallet.UnconfirmedTransactions() {
+		if pt.TransactionID == txn.ID() {
+			unconfirmed = true
+			checkMemos(pt)
+		}
+	}
+	if !unconfirmed {
+		t.Fatal("transaction not found in unconfirmed transactions")
+	}
+
+	// The memos should still be attached once the transaction is confirmed.
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	pt, ok := wt.wallet.Transaction(txn.ID())
+	if !ok {
+		t.Fatal("transaction not found")
+	}
+	checkMemos(pt)
+	pts := wt.wallet.AddressTransactions(sends[0].Dest)
+	if len(pts) != 1 {
+		t.Fatal("expected 1 transaction for address, got", len(pts))
+	}
+	checkMemos(pts[0])
+}
@@ -14,7 +14,8 @@ type sortedOutputs struct {
 }
 
 // ConfirmedBalance returns the balance of the wallet according to all of the
-// confirmed transactions.
+// confirmed transactions. Siacoin outputs with fewer than the required number
+// of confirmations are not included.
 func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency, siafundClaimBalance types.Currency) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -22,7 +23,14 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 	// ensure durability of reported balance
 	w.syncDB()
 
-	dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+	pending, err := pendingOutputs(w.dbTx)
+	if err != nil {
+		w.log.Println("ERROR: could not determine pending outputs:", err)
+	}
+	dbForEachSiacoinOutput(w.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, ok := pending[id]; ok {
+			return
+		}
 		if sco.Value.Cmp(dustValue()) > 0 {
 			siacoinBalance = siacoinBalance.Add(sco.Value)
 		}
@@ -67,34 +75,78 @@ func (w *Wallet) UnconfirmedBalance() (outgoingSiacoins types.Currency, incoming
 	return
 }
 
+// sendSiacoinsFee returns the miner fee that SendSiacoins adds to a
+// transaction.
+func (w *Wallet) sendSiacoinsFee() types.Currency {
+	_, tpoolFee := w.tpool.FeeEstimation()
+	return tpoolFee.Mul64(750) // Estimated transaction size in bytes
+}
+
 // SendSiacoins creates a transaction sending 'amount' to 'dest'. The transaction
-// is submitted to the transaction pool and is also returned.
+// is submitted to the transaction pool and is also returned. If funding the
+// payment requires more than maxSendInputs inputs, the payment is split across
+// multiple transactions, which are all returned. If only some of them are
+// accepted by the transaction pool, the accepted transactions are returned
+// along with the error. If the amount
+// exceeds the large send threshold, ErrLargeSendNeedsConfirmation is returned
+// instead; use SendSiacoinsConfirmed to send it anyway.
 func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
 	if err := w.tg.Add(); err != nil {
 		return nil, err
 	}
 	defer w.tg.Done()
+	if err := w.managedCheckLargeSend(amount); err != nil {
+		return nil, err
+	}
+	return w.managedSendSiacoins(amount, dest)
+}
+
+// SendSiacoinsConfirmed creates a transaction sending 'amount' to 'dest',
+// regardless of the large send threshold.
+func (w *Wallet) SendSiacoinsConfirmed(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	return w.managedSendSiacoins(amount, dest)
+}
+
+// managedSendSiacoins creates and broadcasts a transaction sending 'amount' to
+// 'dest'.
+func (w *Wallet) managedSendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
 	if !w.unlocked {
 		w.log.Println("Attempt to send coins has failed - wallet is locked")
 		return nil, modules.ErrLockedWallet
 	}
 
-	_, tpoolFee := w.tpool.FeeEstimation()
-	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
+	// If the send needs more inputs than fit in one transaction, split it
+	// across multiple transactions.
+	txnSet, split, err := w.managedSplitSend(amount, dest)
+	if err != nil && split {
+		// Some of the transactions may have been submitted.
+		return txnSet, err
+	} else if err != nil {
+		w.log.Println("Attempt to send coins has failed - failed to split send:", err)
+		return nil, build.ExtendErr("unable to fund transaction", err)
+	} else if split {
+		return txnSet, nil
+	}
+
+	tpoolFee := w.sendSiacoinsFee()
 	output := types.SiacoinOutput{
 		Value:      amount,
 		UnlockHash: dest,
 	}
 
 	txnBuilder := w.StartTransaction()
-	err := txnBuilder.FundSiacoins(amount.Add(tpoolFee))
+	err = txnBuilder.FundSiacoins(amount.Add(tpoolFee))
 	if err != nil {
 		w.log.Println("Attempt to send coins has failed - failed to fund transaction:", err)
 		return nil, build.ExtendErr("unable to fund transaction", err)
 	}
 	txnBuilder.AddMinerFee(tpoolFee)
 	txnBuilder.AddSiacoinOutput(output)
-	txnSet, err := txnBuilder.Sign(true)
+	txnSet, err = txnBuilder.Sign(true)
 	if err != nil {
 		w.log.Println("Attempt to send coins has failed - failed to sign transaction:", err)
 		return nil, build.ExtendErr("unable to sign transaction", err)
@@ -111,17 +163,72 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]t
 	return txnSet, nil
 }
 
+// PreviewSend returns the fee, the change, and the inputs that SendSiacoins
+// would use to send 'amount' to 'dest' given the current state of the wallet.
+// Nothing is signed, broadcast, or marked as spent. The destination does not
+// affect coin selection.
+func (w *Wallet) PreviewSend(dest types.UnlockHash, amount types.Currency) (fee, change types.Currency, inputs []types.SiacoinOutputID, err error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Currency{}, types.Currency{}, nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return types.Currency{}, types.Currency{}, nil, modules.ErrLockedWallet
+	}
+
+	fee = w.sendSiacoinsFee()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Currency{}, types.Currency{}, nil, err
+	}
+	fund, selected, err := w.selectSiacoinOutputs(consensusHeight, amount.Add(fee))
+	if err != nil {
+		return types.Currency{}, types.Currency{}, nil, err
+	}
+	return fee, fund.Sub(amount.Add(fee)), selected.ids, nil
+}
+
 // SendSiacoinsMulti creates a transaction that includes the specified
 // outputs. The transaction is submitted to the transaction pool and is also
-// returned.
+// returned. If the total value of the outputs exceeds the large send
+// threshold, ErrLargeSendNeedsConfirmation is returned instead; use
+// SendSiacoinsMultiConfirmed to send it anyway.
 func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error) {
 	if err := w.tg.Add(); err != nil {
 		return nil, err
 	}
 	defer w.tg.Done()
+	var total types.Currency
+	for _, sco := range outputs {
+		total = total.Add(sco.Value)
+	}
+	if err := w.managedCheckLargeSend(total); err != nil {
+		return nil, err
+	}
+	txnSet, _, err := w.managedSendSiacoinsMulti(outputs)
+	return txnSet, err
+}
+
+// SendSiacoinsMultiConfirmed creates a transaction that includes the
+// specified outputs, regardless of the large send threshold.
+func (w *Wallet) SendSiacoinsMultiConfirmed(outputs []types.SiacoinOutput) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	txnSet, _, err := w.managedSendSiacoinsMulti(outputs)
+	return txnSet, err
+}
+
+// managedSendSiacoinsMulti creates and broadcasts a transaction that includes
+// the specified outputs. Along with the transaction set, it returns the IDs of
+// the outputs, in the order they were specified.
+func (w *Wallet) managedSendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, []types.SiacoinOutputID, error) {
 	if !w.unlocked {
 		w.log.Println("Attempt to send coins has failed - wallet is locked")
-		return nil, modules.ErrLockedWallet
+		return nil, nil, modules.ErrLockedWallet
 	}
 
 	txnBuilder := w.StartTransaction()
@@ -141,24 +248,32 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Trans
 	}
 	err := txnBuilder.FundSiacoins(totalCost)
 	if err != nil {
-		return nil, build.ExtendErr("unable to fund transaction", err)
+		return nil, nil, build.ExtendErr("unable to fund transaction", err)
 	}
 
-	for _, sco := range outputs {
-		txnBuilder.AddSiacoinOutput(sco)
+	indices := make([]uint64, len(outputs))
+	for i, sco := range outputs {
+		indices[i] = txnBuilder.AddSiacoinOutput(sco)
 	}
 
 	txnSet, err := txnBuilder.Sign(true)
 	if err != nil {
 		w.log.Println("Attempt to send coins has failed - failed to sign transaction:", err)
-		return nil, build.ExtendErr("unable to sign transaction", err)
+		return nil, nil, build.ExtendErr("unable to sign transaction", err)
 	}
 	err = w.tpool.AcceptTransactionSet(txnSet)
 	if err != nil {
 		w.log.Println("Attempt to send coins has failed - transaction pool rejected transaction:", err)
-		return nil, build.ExtendErr("unable to get transaction accepted", err)
+		return nil, nil, build.ExtendErr("unable to get transaction accepted", err)
 	}
-	return txnSet, nil
+
+	// the outputs were added to the final transaction in the set
+	txn := txnSet[len(txnSet)-1]
+	ids := make([]types.SiacoinOutputID, len(indices))
+	for i, index := range indices {
+		ids[i] = txn.SiacoinOutputID(index)
+	}
+	return txnSet, ids, nil
 }
 
 // SendSiafunds creates a transaction sending 'amount' to 'dest'. The transaction
@@ -70,6 +70,61 @@ func TestSendSiacoins(t *testing.T) {
 	}
 }
 
+// TestPreviewSend checks that the fee, change, and inputs reported by
+// PreviewSend match the ones used by SendSiacoins.
+func TestPreviewSend(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	sendValue := types.SiacoinPrecision.Mul64(3)
+	fee, change, inputs, err := wt.wallet.PreviewSend(types.UnlockHash{}, sendValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("preview did not select any inputs")
+	}
+
+	// The preview should not mark any outputs as spent.
+	fee2, change2, inputs2, err := wt.wallet.PreviewSend(types.UnlockHash{}, sendValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fee2.Equals(fee) || !change2.Equals(change) || len(inputs2) != len(inputs) {
+		t.Fatal("repeated preview returned a different result")
+	}
+
+	txns, err := wt.wallet.SendSiacoins(sendValue, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, txn := txns[0], txns[len(txns)-1]
+	if len(parent.SiacoinInputs) != len(inputs) {
+		t.Fatalf("preview selected %v inputs, send used %v", len(inputs), len(parent.SiacoinInputs))
+	}
+	for i, sci := range parent.SiacoinInputs {
+		if sci.ParentID != inputs[i] {
+			t.Error("preview input does not match send input", i)
+		}
+	}
+	if len(txn.MinerFees) != 1 || !txn.MinerFees[0].Equals(fee) {
+		t.Error("preview fee does not match send fee:", fee, txn.MinerFees)
+	}
+	var sendChange types.Currency
+	if len(parent.SiacoinOutputs) > 1 {
+		sendChange = parent.SiacoinOutputs[1].Value
+	}
+	if !sendChange.Equals(change) {
+		t.Error("preview change does not match send change:", change, sendChange)
+	}
+}
+
 // TestIntegrationSendOverUnder sends too many siacoins, resulting in an error,
 // followed by sending few enough siacoins that the send should complete.
 //
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestLockOutput checks that a locked output is not used to fund transactions,
+// even when it is the only output large enough, and that the lock persists
+// across restarts.
+func TestLockOutput(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Find the largest output in the wallet, and the total value of the
+	// others.
+	var largestID types.SiacoinOutputID
+	var largest, others types.Currency
+	wt.wallet.mu.Lock()
+	dbForEachSiacoinOutput(wt.wallet.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(largest) > 0 {
+			others = others.Add(largest)
+			largestID, largest = id, sco.Value
+		} else {
+			others = others.Add(sco.Value)
+		}
+	})
+	wt.wallet.mu.Unlock()
+	amount := largest.Div64(2)
+	if others.Cmp(amount) >= 0 {
+		t.Fatal("the wallet's other outputs can fund the transaction; test setup is invalid")
+	}
+
+	// With the output locked, there are not enough funds for the payment.
+	err = wt.wallet.LockOutput(largestID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SendSiacoins(amount, types.UnlockHash{})
+	if err == nil || !strings.Contains(err.Error(), modules.ErrLowBalance.Error()) {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+
+	// The lock should survive a restart.
+	err = wt.wallet.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet, err = New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.wallet.Unlock(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SendSiacoins(amount, types.UnlockHash{})
+	if err == nil || !strings.Contains(err.Error(), modules.ErrLowBalance.Error()) {
+		t.Fatal("expected ErrLowBalance after restart, got", err)
+	}
+
+	// Once unlocked, the output can be spent.
+	err = wt.wallet.UnlockOutput(largestID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SendSiacoins(amount, types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
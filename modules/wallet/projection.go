@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// BalanceProjection returns the future heights at which the wallet's
+// spendable balance increases, sorted by height. The balance increases when
+// delayed outputs, such as miner payouts and siafund claims, mature, and when
+// the timelocks of confirmed outputs expire. Unconfirmed transactions are not
+// considered. The wallet must be unlocked.
+func (w *Wallet) BalanceProjection() ([]modules.BalanceProjectionEntry, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	deltas := make(map[types.BlockHeight]types.Currency)
+
+	// Outputs with an active timelock become spendable once the timelock
+	// expires.
+	err = dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		timelock := w.keys[sco.UnlockHash].UnlockConditions.Timelock
+		if timelock > height && sco.Value.Cmp(dustValue()) > 0 {
+			deltas[timelock] = deltas[timelock].Add(sco.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Delayed outputs mature at most types.MaturityDelay blocks after they
+	// are confirmed, so only recent transactions need to be checked.
+	// Processed transactions are stored in chronological order.
+	c := w.dbTx.Bucket(bucketProcessedTransactions).Cursor()
+	for _, ptBytes := c.Last(); ptBytes != nil; _, ptBytes = c.Prev() {
+		var pt modules.ProcessedTransaction
+		if err := encoding.Unmarshal(ptBytes, &pt); err != nil {
+			// COMPATv1.2.1: transactions in the old format are far too old
+			// to contain immature outputs.
+			break
+		}
+		if pt.ConfirmationHeight+types.MaturityDelay <= height {
+			break
+		}
+		for _, output := range pt.Outputs {
+			if !output.WalletAddress || output.MaturityHeight <= height {
+				continue
+			} else if output.FundType != types.SpecifierMinerPayout && output.FundType != types.SpecifierClaimOutput {
+				continue
+			}
+			deltas[output.MaturityHeight] = deltas[output.MaturityHeight].Add(output.Value)
+		}
+	}
+
+	projection := make([]modules.BalanceProjectionEntry, 0, len(deltas))
+	for h, delta := range deltas {
+		projection = append(projection, modules.BalanceProjectionEntry{
+			Height:         h,
+			SpendableDelta: delta,
+		})
+	}
+	sort.Slice(projection, func(i, j int) bool {
+		return projection[i].Height < projection[j].Height
+	})
+	return projection, nil
+}
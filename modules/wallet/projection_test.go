@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestBalanceProjection checks that immature miner payouts and timelocked
+// outputs appear in the balance projection at the heights at which they become
+// spendable.
+func TestBalanceProjection(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Add a timelocked key to the wallet and send coins to it.
+	timelock := wt.cs.Height() + 5
+	sk := generateSpendableKey(modules.Seed{1, 2, 3}, 0)
+	sk.UnlockConditions.Timelock = timelock
+	wt.wallet.mu.Lock()
+	wt.wallet.integrateSpendableKey(wt.walletMasterKey, sk)
+	wt.wallet.mu.Unlock()
+	locked := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(locked, sk.UnlockConditions.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The miner payouts of the most recent blocks are immature.
+	height := wt.cs.Height()
+	expected := make(map[types.BlockHeight]types.Currency)
+	for h := types.BlockHeight(1); h <= height; h++ {
+		if h+types.MaturityDelay <= height {
+			continue
+		}
+		b, _ := wt.cs.BlockAtHeight(h)
+		for _, mp := range b.MinerPayouts {
+			expected[h+types.MaturityDelay] = expected[h+types.MaturityDelay].Add(mp.Value)
+		}
+	}
+	expected[timelock] = expected[timelock].Add(locked)
+
+	projection, err := wt.wallet.BalanceProjection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projection) != len(expected) {
+		t.Fatalf("expected %v projection entries, got %v", len(expected), len(projection))
+	}
+	for i, e := range projection {
+		if i > 0 && e.Height <= projection[i-1].Height {
+			t.Fatal("projection is not sorted by height")
+		}
+		if e.Height <= height {
+			t.Fatal("projection contains a past height:", e.Height)
+		}
+		if !e.SpendableDelta.Equals(expected[e.Height]) {
+			t.Fatalf("wrong delta at height %v: expected %v, got %v", e.Height, expected[e.Height], e.SpendableDelta)
+		}
+	}
+}
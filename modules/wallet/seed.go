@@ -190,6 +190,10 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	if !w.cs.Synced() {
 		return errors.New("cannot load seed until blockchain is synced")
 	}
+	key, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
 
 	if !w.scanLock.TryLock() {
 		return errScanInProgress
@@ -223,17 +227,17 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	seedProgress += seedProgress / 10
 	w.log.Printf("INFO: found key index %v in blockchain. Setting auxiliary seed progress to %v", s.largestIndexSeen, seedProgress)
 
-	err := func() error {
+	err = func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
 
-		err := checkMasterKey(w.dbTx, masterKey)
+		err := checkMasterKey(w.dbTx, key)
 		if err != nil {
 			return err
 		}
 
 		// create a seedFile for the seed
-		sf := createSeedFile(masterKey, seed)
+		sf := createSeedFile(key, seed)
 
 		// add the seedFile
 		var current []seedFile
@@ -258,6 +262,12 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 		if _, err = w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
 			return err
 		}
+		if err = w.dbTx.DeleteBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
+		if _, err = w.dbTx.CreateBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
 		w.unconfirmedProcessedTransactions = nil
 
 		// reset the consensus change ID and height in preparation for rescan
@@ -446,23 +456,32 @@ func (w *Wallet) SweepSeed(seed modules.Seed) (coins, funds types.Currency, err
 		txn, parents := tb.View()
 		for _, output := range txnSiacoinOutputs {
 			sk := generateSpendableKey(seed, output.seedIndex)
-			addSignatures(&txn, types.FullCoveredFields, sk.UnlockConditions, crypto.Hash(output.id), sk)
+			if _, err := addSignatures(&txn, types.FullCoveredFields, sk.UnlockConditions, crypto.Hash(output.id), sk.signers()); err != nil {
+				return types.Currency{}, types.Currency{}, err
+			}
 		}
 		for _, sfo := range txnSiafundOutputs {
 			sk := generateSpendableKey(seed, sfo.seedIndex)
-			addSignatures(&txn, types.FullCoveredFields, sk.UnlockConditions, crypto.Hash(sfo.id), sk)
+			if _, err := addSignatures(&txn, types.FullCoveredFields, sk.UnlockConditions, crypto.Hash(sfo.id), sk.signers()); err != nil {
+				return types.Currency{}, types.Currency{}, err
+			}
 		}
 		// Usually, all the inputs will come from swept outputs. However, there is
 		// an edge case in which inputs will be added from the wallet. To cover
 		// this case, we iterate through the SiacoinInputs and add a signature for
 		// any input that belongs to the wallet.
+		var walletInputs types.Transaction
 		w.mu.RLock()
 		for _, input := range txn.SiacoinInputs {
-			if key, ok := w.keys[input.UnlockConditions.UnlockHash()]; ok {
-				addSignatures(&txn, types.FullCoveredFields, input.UnlockConditions, crypto.Hash(input.ParentID), key)
+			if _, ok := w.keys[input.UnlockConditions.UnlockHash()]; ok {
+				walletInputs.SiacoinInputs = append(walletInputs.SiacoinInputs, input)
 			}
 		}
+		sigs := w.pendingSignatures(walletInputs)
 		w.mu.RUnlock()
+		if _, err := signTransaction(&txn, types.FullCoveredFields, sigs); err != nil {
+			return types.Currency{}, types.Currency{}, err
+		}
 
 		// Append transaction to txnSet
 		txnSet := append(parents, txn)
@@ -0,0 +1,45 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// errNoSiafunds is returned by ClaimSiafunds if the wallet does not have any
+// siafunds.
+var errNoSiafunds = errors.New("wallet does not have any siafunds")
+
+// UnclaimedSiafundBalance returns the value of the siacoin claims that the
+// wallet's siafunds have accrued since they were last spent. Claims are
+// realized by spending the siafunds, e.g. with ClaimSiafunds.
+func (w *Wallet) UnclaimedSiafundBalance() types.Currency {
+	_, _, claimBalance := w.ConfirmedBalance()
+	return claimBalance
+}
+
+// ClaimSiafunds realizes the siacoin claims accrued by the wallet's siafunds
+// by sending all of the siafunds to a fresh wallet address. The claims become
+// spendable siacoin outputs of the wallet after types.MaturityDelay blocks.
+// The transaction set is submitted to the transaction pool and is also
+// returned.
+func (w *Wallet) ClaimSiafunds() ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+
+	_, siafundBalance, _ := w.ConfirmedBalance()
+	if siafundBalance.IsZero() {
+		return nil, errNoSiafunds
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		return nil, err
+	}
+	return w.SendSiafunds(siafundBalance, uc.UnlockHash())
+}
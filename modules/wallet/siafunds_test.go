@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules/miner"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestClaimSiafunds checks that ClaimSiafunds realizes the siacoin claims that
+// the wallet's siafunds have accrued.
+func TestClaimSiafunds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Load a key holding siafunds into the wallet.
+	err = wt.wallet.LoadSiagKeys(wt.walletMasterKey, []string{"../../types/siag0of1of1.siakey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// need to reset the miner as well, since it depends on the wallet
+	wt.miner, err = miner.New(wt.cs, wt.tpool, wt.wallet, wt.wallet.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Form a file contract. The contract tax is added to the siafund pool,
+	// accruing a claim for the wallet's siafunds.
+	height := wt.cs.Height()
+	payout := types.SiacoinPrecision.Mul64(1000)
+	fc := types.FileContract{
+		WindowStart:        height + 10,
+		WindowEnd:          height + 20,
+		Payout:             payout,
+		ValidProofOutputs:  []types.SiacoinOutput{{Value: types.PostTax(height, payout)}},
+		MissedProofOutputs: []types.SiacoinOutput{{Value: types.PostTax(height, payout)}},
+	}
+	b := wt.wallet.StartTransaction()
+	if err := b.FundSiacoins(payout); err != nil {
+		t.Fatal(err)
+	}
+	b.AddFileContract(fc)
+	txnSet, err := b.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(txnSet); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	claim := wt.wallet.UnclaimedSiafundBalance()
+	if claim.IsZero() {
+		t.Fatal("expected a claim after forming a file contract")
+	}
+
+	// Claim the siafunds. The claim outputs should be created once the
+	// transaction is confirmed and should add up to the accrued claim.
+	txnSet, err = wt.wallet.ClaimSiafunds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claimIDs []types.SiacoinOutputID
+	for _, txn := range txnSet {
+		for _, sfi := range txn.SiafundInputs {
+			wt.wallet.mu.Lock()
+			ours := wt.wallet.isWalletAddress(sfi.ClaimUnlockHash)
+			wt.wallet.mu.Unlock()
+			if !ours {
+				t.Fatal("claim is not sent to a wallet address")
+			}
+			claimIDs = append(claimIDs, sfi.ParentID.SiaClaimOutputID())
+		}
+	}
+	if len(claimIDs) == 0 {
+		t.Fatal("claim transaction does not spend any siafunds")
+	}
+	for i := types.BlockHeight(0); i <= types.MaturityDelay; i++ {
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !wt.wallet.UnclaimedSiafundBalance().IsZero() {
+		t.Fatal("expected no claim after claiming siafunds")
+	}
+	_, siafundBalance, _ := wt.wallet.ConfirmedBalance()
+	if siafundBalance.Cmp(types.NewCurrency64(2000)) != 0 {
+		t.Fatal("expected siafunds to remain in the wallet, got", siafundBalance)
+	}
+	var claimed types.Currency
+	wt.wallet.mu.Lock()
+	for _, id := range claimIDs {
+		sco, err := dbGetSiacoinOutput(wt.wallet.dbTx, id)
+		if err != nil {
+			wt.wallet.mu.Unlock()
+			t.Fatal("claim output not found in wallet:", err)
+		}
+		claimed = claimed.Add(sco.Value)
+	}
+	wt.wallet.mu.Unlock()
+	if claimed.Cmp(claim) != 0 {
+		t.Fatalf("expected claim of %v, got %v", claim, claimed)
+	}
+}
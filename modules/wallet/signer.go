@@ -0,0 +1,171 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errDuplicateSigner is returned by AddSigner if the wallet already
+	// tracks the Signer's address.
+	errDuplicateSigner = errors.New("wallet already tracks the address of this signer")
+
+	// errNilSigner is returned by AddSigner if it is passed a nil Signer.
+	errNilSigner = errors.New("signer cannot be nil")
+)
+
+// keySigner is a modules.Signer that signs using a secret key held in memory.
+// It is the default Signer used by the wallet.
+type keySigner struct {
+	sk crypto.SecretKey
+}
+
+// PublicKey implements modules.Signer.
+func (ks keySigner) PublicKey() types.SiaPublicKey {
+	return types.Ed25519PublicKey(ks.sk.PublicKey())
+}
+
+// SignHash implements modules.Signer.
+func (ks keySigner) SignHash(hash crypto.Hash) (crypto.Signature, error) {
+	return crypto.SignHash(hash, ks.sk), nil
+}
+
+// signers returns a Signer for each of the secret keys in sk.
+func (sk spendableKey) signers() []modules.Signer {
+	signers := make([]modules.Signer, len(sk.SecretKeys))
+	for i := range sk.SecretKeys {
+		signers[i] = keySigner{sk: sk.SecretKeys[i]}
+	}
+	return signers
+}
+
+// signersFor returns all of the Signers that the wallet can use to sign for
+// uh, including both in-memory keys and external Signers.
+func (w *Wallet) signersFor(uh types.UnlockHash) []modules.Signer {
+	return append(w.keys[uh].signers(), w.signers[uh]...)
+}
+
+// AddSigner adds the standard address of s to the wallet. Outputs sent to the
+// address will be tracked by the wallet, and spending them will be signed
+// using s. Signers are not persisted, so they must be added again each time
+// the wallet is started. Outputs that were sent to the address before the
+// Signer was added are not tracked until the wallet rescans the blockchain.
+func (w *Wallet) AddSigner(s modules.Signer) (types.UnlockConditions, error) {
+	if s == nil {
+		return types.UnlockConditions{}, errNilSigner
+	}
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.UnlockConditions{}, modules.ErrLockedWallet
+	}
+
+	uc := types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{s.PublicKey()},
+		SignaturesRequired: 1,
+	}
+	uh := uc.UnlockHash()
+	if _, exists := w.keys[uh]; exists {
+		return types.UnlockConditions{}, errDuplicateSigner
+	}
+	w.keys[uh] = spendableKey{UnlockConditions: uc}
+	w.signers[uh] = append(w.signers[uh], s)
+	return uc, nil
+}
+
+// A pendingSignature describes the signatures that the wallet must add for
+// one input of a transaction. The wallet looks up the Signers while it holds
+// its lock, but only calls them after releasing it, because an external
+// Signer may block for a long time or call back into the wallet.
+type pendingSignature struct {
+	uc       types.UnlockConditions
+	parentID crypto.Hash
+	signers  []modules.Signer
+}
+
+// pendingSignatures returns the signatures needed to sign each input of txn.
+// w.mu must be held.
+func (w *Wallet) pendingSignatures(txn types.Transaction) []pendingSignature {
+	var sigs []pendingSignature
+	for _, sci := range txn.SiacoinInputs {
+		sigs = append(sigs, pendingSignature{
+			uc:       sci.UnlockConditions,
+			parentID: crypto.Hash(sci.ParentID),
+			signers:  w.signersFor(sci.UnlockConditions.UnlockHash()),
+		})
+	}
+	for _, sfi := range txn.SiafundInputs {
+		sigs = append(sigs, pendingSignature{
+			uc:       sfi.UnlockConditions,
+			parentID: crypto.Hash(sfi.ParentID),
+			signers:  w.signersFor(sfi.UnlockConditions.UnlockHash()),
+		})
+	}
+	return sigs
+}
+
+// signTransaction adds the pending signatures to txn, covering cf, and
+// returns the indices of the new signatures. w.mu must not be held.
+func signTransaction(txn *types.Transaction, cf types.CoveredFields, sigs []pendingSignature) ([]int, error) {
+	var newSigIndices []int
+	for _, ps := range sigs {
+		indices, err := addSignatures(txn, cf, ps.uc, ps.parentID, ps.signers)
+		if err != nil {
+			return nil, err
+		}
+		newSigIndices = append(newSigIndices, indices...)
+	}
+	return newSigIndices, nil
+}
+
+// signTransactionSet signs every transaction in txnSet with the pending
+// signatures at the same index of sigs, covering the whole transaction. w.mu
+// must not be held.
+func signTransactionSet(txnSet []types.Transaction, sigs [][]pendingSignature) error {
+	for i := range txnSet {
+		if _, err := signTransaction(&txnSet[i], types.FullCoveredFields, sigs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeholderSigner stands in for a Signer when only the size of a signed
+// transaction is needed. Its signatures are all zeros, but have the same
+// length as real ones.
+type placeholderSigner struct {
+	pk types.SiaPublicKey
+}
+
+// PublicKey implements modules.Signer.
+func (ps placeholderSigner) PublicKey() types.SiaPublicKey { return ps.pk }
+
+// SignHash implements modules.Signer.
+func (ps placeholderSigner) SignHash(crypto.Hash) (crypto.Signature, error) {
+	return crypto.Signature{}, nil
+}
+
+// signedSize returns the encoded size of txn once the pending signatures
+// have been added, covering the whole transaction. No Signer is called, so
+// signedSize may be called while w.mu is held.
+func signedSize(txn types.Transaction, sigs []pendingSignature) (uint64, error) {
+	txn.TransactionSignatures = append([]types.TransactionSignature(nil), txn.TransactionSignatures...)
+	for _, ps := range sigs {
+		placeholders := make([]modules.Signer, len(ps.signers))
+		for i, s := range ps.signers {
+			placeholders[i] = placeholderSigner{pk: s.PublicKey()}
+		}
+		if _, err := addSignatures(&txn, types.FullCoveredFields, ps.uc, ps.parentID, placeholders); err != nil {
+			return 0, err
+		}
+	}
+	return uint64(len(encoding.Marshal(txn))), nil
+}
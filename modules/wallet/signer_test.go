@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// mockSigner is a modules.Signer that counts the number of hashes it has
+// signed, standing in for an external device. If w is set, SignHash also
+// acquires the wallet's lock, as a Signer that calls back into the wallet
+// would.
+type mockSigner struct {
+	sk    crypto.SecretKey
+	signs int
+	w     *Wallet
+}
+
+func (ms *mockSigner) PublicKey() types.SiaPublicKey {
+	return types.Ed25519PublicKey(ms.sk.PublicKey())
+}
+
+func (ms *mockSigner) SignHash(hash crypto.Hash) (crypto.Signature, error) {
+	if ms.w != nil {
+		ms.w.mu.Lock()
+		ms.w.mu.Unlock()
+	}
+	ms.signs++
+	return crypto.SignHash(hash, ms.sk), nil
+}
+
+// TestAddSigner checks that the wallet tracks the address of an external
+// Signer and delegates signing to it when spending from that address.
+func TestAddSigner(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	sk, _ := crypto.GenerateKeyPair()
+	ms := &mockSigner{sk: sk, w: wt.wallet}
+	uc, err := wt.wallet.AddSigner(ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.AddSigner(ms); err != errDuplicateSigner {
+		t.Fatal("expected errDuplicateSigner, got", err)
+	}
+
+	// Send coins to the signer's address and confirm them.
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lock every other output, so that the payment must be funded by the
+	// signer's output.
+	var found bool
+	var others []types.SiacoinOutputID
+	wt.wallet.mu.Lock()
+	dbForEachSiacoinOutput(wt.wallet.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.UnlockHash == uc.UnlockHash() {
+			found = true
+		} else {
+			others = append(others, id)
+		}
+	})
+	wt.wallet.mu.Unlock()
+	if !found {
+		t.Fatal("wallet is not tracking the signer's output")
+	}
+	for _, id := range others {
+		if err := wt.wallet.LockOutput(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The transaction set is only accepted by the transaction pool if the
+	// signer produced valid signatures. The signer acquires the wallet's
+	// lock, so the send deadlocks if the wallet calls it while holding the
+	// lock.
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), types.UnlockHash{})
+		errChan <- err
+	}()
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("wallet called the signer while holding its lock")
+	}
+	if ms.signs == 0 {
+		t.Fatal("wallet did not delegate signing to the signer")
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,257 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// splitSendFee returns an upper bound on the miner fee paid by a transaction
+// of a split send that spends n inputs. The fee actually paid is computed from
+// the encoded size of the signed transaction.
+func splitSendFee(feePerByte types.Currency, n int) types.Currency {
+	return feePerByte.Mul64(splitSendOverhead + consolidationInputSize*uint64(n))
+}
+
+// selectSplitSendOutputs selects the outputs used to send amount in multiple
+// transactions, each spending at most maxSendInputs outputs. No outputs are
+// returned if the send can be funded by a single transaction.
+func (w *Wallet) selectSplitSendOutputs(consensusHeight types.BlockHeight, amount, feePerByte types.Currency) (sortedOutputs, error) {
+	required := amount.Add(splitSendFee(feePerByte, 0))
+	for {
+		fund, selected, err := w.selectSiacoinOutputs(consensusHeight, required)
+		if err != nil {
+			return sortedOutputs{}, err
+		} else if len(selected.ids) <= maxSendInputs {
+			return sortedOutputs{}, nil
+		}
+
+		// Each transaction pays a fee proportional to its number of inputs.
+		// Adding inputs to cover the fees may increase the fees, so select
+		// again until the fees are covered.
+		var fees types.Currency
+		for i := 0; i < len(selected.ids); i += maxSendInputs {
+			n := len(selected.ids) - i
+			if n > maxSendInputs {
+				n = maxSendInputs
+			}
+			fees = fees.Add(splitSendFee(feePerByte, n))
+		}
+		if fund.Cmp(amount.Add(fees)) >= 0 {
+			return selected, nil
+		}
+		required = amount.Add(fees)
+	}
+}
+
+// createSplitSend creates transactions that together send amount to dest,
+// spending the selected outputs. Each transaction spends at most
+// maxSendInputs outputs and pays part of the amount, returning any change to
+// the wallet. The transactions are independent of each other, so that each
+// one can be accepted by the transaction pool on its own. The selected outputs
+// are marked as spent. The transactions are returned unsigned, along with the
+// signatures that must be added to each of them with signTransactionSet once
+// w.mu has been released.
+func (w *Wallet) createSplitSend(consensusHeight types.BlockHeight, selected sortedOutputs, amount, feePerByte types.Currency, dest types.UnlockHash) ([]types.Transaction, [][]pendingSignature, error) {
+	var txns []types.Transaction
+	var sigs [][]pendingSignature
+	remaining := amount
+	for i := 0; i < len(selected.ids); i += maxSendInputs {
+		end := i + maxSendInputs
+		if end > len(selected.ids) {
+			end = len(selected.ids)
+		}
+		batch := sortedOutputs{
+			ids:     selected.ids[i:end],
+			outputs: selected.outputs[i:end],
+		}
+
+		var inputs []types.SiacoinInput
+		var fund types.Currency
+		for j, scoid := range batch.ids {
+			inputs = append(inputs, types.SiacoinInput{
+				ParentID:         scoid,
+				UnlockConditions: w.keys[batch.outputs[j].UnlockHash].UnlockConditions,
+			})
+			fund = fund.Add(batch.outputs[j].Value)
+		}
+
+		// The fee depends on the size of the signed transaction, which in
+		// turn depends on the fee and the outputs. Start from the estimate
+		// and rebuild the transaction until the fee covers its encoded size.
+		txnSigs := w.pendingSignatures(types.Transaction{SiacoinInputs: inputs})
+		var txn types.Transaction
+		var payment types.Currency
+		var changeAddr *types.UnlockHash
+		fee := splitSendFee(feePerByte, len(batch.ids))
+		for {
+			if fund.Cmp(fee) <= 0 {
+				return nil, nil, errConsolidationTooExpensive
+			}
+			txn = types.Transaction{
+				SiacoinInputs: inputs,
+				MinerFees:     []types.Currency{fee},
+			}
+			available := fund.Sub(fee)
+
+			// Pay as much of the remaining amount as possible, and return
+			// the rest as change.
+			payment = remaining
+			if payment.Cmp(available) > 0 {
+				payment = available
+			}
+			if !payment.IsZero() {
+				txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+					Value:      payment,
+					UnlockHash: dest,
+				})
+			}
+			if change := available.Sub(payment); !change.IsZero() {
+				if changeAddr == nil {
+					addr, err := w.changeAddress(w.dbTx, batch.outputs)
+					if err != nil {
+						return nil, nil, err
+					}
+					changeAddr = &addr
+				}
+				txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+					Value:      change,
+					UnlockHash: *changeAddr,
+				})
+			}
+
+			size, err := signedSize(txn, txnSigs)
+			if err != nil {
+				return nil, nil, err
+			}
+			if size > modules.TransactionSizeLimit {
+				return nil, nil, fmt.Errorf("split transaction is %v bytes, larger than the limit of %v", size, modules.TransactionSizeLimit)
+			}
+			if required := feePerByte.Mul64(size); fee.Cmp(required) < 0 {
+				fee = required
+				continue
+			}
+			break
+		}
+		remaining = remaining.Sub(payment)
+		txns = append(txns, txn)
+		sigs = append(sigs, txnSigs)
+	}
+	if !remaining.IsZero() {
+		return nil, nil, modules.ErrLowBalance
+	}
+
+	// Mark all outputs that were spent as spent.
+	for _, scoid := range selected.ids {
+		if err := dbPutSpentOutput(w.dbTx, types.OutputID(scoid), consensusHeight); err != nil {
+			return nil, nil, err
+		}
+	}
+	return txns, sigs, nil
+}
+
+// managedSplitSend sends amount to dest in multiple transactions if funding
+// the send requires more than maxSendInputs inputs. Each transaction is
+// submitted to the transaction pool separately. If the send fits in a single
+// transaction, nothing is sent and false is returned.
+//
+// All of the transactions are validated against the consensus set before any
+// of them is submitted. If the transaction pool still rejects one of the
+// transactions, the transactions that were already accepted are returned along with the error, and the
+// outputs spent by the remaining transactions are made available again.
+func (w *Wallet) managedSplitSend(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, bool, error) {
+	_, feePerByte := w.tpool.FeeEstimation()
+	w.mu.Lock()
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, false, err
+	}
+	selected, err := w.selectSplitSendOutputs(consensusHeight, amount, feePerByte)
+	if err != nil || len(selected.ids) == 0 {
+		// Let the regular send path report any funding errors.
+		w.mu.Unlock()
+		return nil, false, nil
+	}
+	txns, sigs, err := w.createSplitSend(consensusHeight, selected, amount, feePerByte, dest)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, false, err
+	}
+	unconfirmedParents := make(map[types.SiacoinOutputID]types.TransactionID)
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		for i := range upt.Transaction.SiacoinOutputs {
+			unconfirmedParents[upt.Transaction.SiacoinOutputID(uint64(i))] = upt.TransactionID
+		}
+	}
+	w.mu.Unlock()
+
+	if err := signTransactionSet(txns, sigs); err != nil {
+		w.managedUnmarkSpentOutputs(txns)
+		return nil, true, build.ExtendErr("could not sign split transaction", err)
+	}
+
+	// Check every transaction before submitting any of them, so that an
+	// invalid transaction does not leave the send partially complete.
+	if err := w.validateSplitSend(txns, unconfirmedParents); err != nil {
+		w.managedUnmarkSpentOutputs(txns)
+		return nil, true, build.ExtendErr("split transaction is invalid", err)
+	}
+
+	for i, txn := range txns {
+		if err := w.tpool.AcceptTransactionSet([]types.Transaction{txn}); err != nil {
+			w.managedUnmarkSpentOutputs(txns[i:])
+			w.log.Println("Split siacoin transfer failed after submitting", i, "of", len(txns), "transactions:", err)
+			return txns[:i], true, build.ExtendErr(fmt.Sprintf("transaction pool rejected split transaction %v of %v", i+1, len(txns)), err)
+		}
+	}
+	w.log.Println("Submitted a siacoin transfer split across", len(txns), "transactions for value", amount.HumanString(), "IDs:")
+	for _, txn := range txns {
+		w.log.Println("\t", txn.ID())
+	}
+	return txns, true, nil
+}
+
+// validateSplitSend checks that each transaction of a split send would be
+// valid in the next block, along with the unconfirmed transactions that
+// create its inputs. unconfirmedParents maps unconfirmed outputs to the
+// transactions that create them.
+func (w *Wallet) validateSplitSend(txns []types.Transaction, unconfirmedParents map[types.SiacoinOutputID]types.TransactionID) error {
+	for i, txn := range txns {
+		var set []types.Transaction
+		included := make(map[types.TransactionID]bool)
+		for _, sci := range txn.SiacoinInputs {
+			parentID, ok := unconfirmedParents[sci.ParentID]
+			if !ok || included[parentID] {
+				continue
+			}
+			parent, ancestors, exists := w.tpool.Transaction(parentID)
+			if !exists {
+				return fmt.Errorf("parent of split transaction %v of %v is no longer in the transaction pool", i+1, len(txns))
+			}
+			for _, t := range append(ancestors, parent) {
+				if id := t.ID(); !included[id] {
+					included[id] = true
+					set = append(set, t)
+				}
+			}
+		}
+		if _, err := w.cs.TryTransactionSet(append(set, txn)); err != nil {
+			return build.ExtendErr(fmt.Sprintf("split transaction %v of %v", i+1, len(txns)), err)
+		}
+	}
+	return nil
+}
+
+// managedUnmarkSpentOutputs makes the outputs spent by txns available again.
+func (w *Wallet) managedUnmarkSpentOutputs(txns []types.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, txn := range txns {
+		for _, sci := range txn.SiacoinInputs {
+			dbDeleteSpentOutput(w.dbTx, types.OutputID(sci.ParentID))
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package wallet
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// rejectingTPool is a transaction pool that rejects transaction sets paying
+// dest once it has accepted a given number of them. Other sets, such as those
+// created by a background defrag, are always passed through.
+type rejectingTPool struct {
+	modules.TransactionPool
+	accept int
+	dest   types.UnlockHash
+}
+
+// AcceptTransactionSet implements modules.TransactionPool.
+func (tp *rejectingTPool) AcceptTransactionSet(txns []types.Transaction) error {
+	pays := false
+	for _, txn := range txns {
+		for _, sco := range txn.SiacoinOutputs {
+			pays = pays || sco.UnlockHash == tp.dest
+		}
+	}
+	if !pays {
+		return tp.TransactionPool.AcceptTransactionSet(txns)
+	}
+	if tp.accept == 0 {
+		return errors.New("rejected")
+	}
+	tp.accept--
+	return tp.TransactionPool.AcceptTransactionSet(txns)
+}
+
+// rejectingCS is a consensus set that rejects every transaction set passed
+// to TryTransactionSet once it has checked a given number of them.
+type rejectingCS struct {
+	modules.ConsensusSet
+	accept int
+}
+
+// TryTransactionSet implements modules.ConsensusSet.
+func (cs *rejectingCS) TryTransactionSet(txns []types.Transaction) (modules.ConsensusChange, error) {
+	if cs.accept == 0 {
+		return modules.ConsensusChange{}, errors.New("rejected")
+	}
+	cs.accept--
+	return cs.ConsensusSet.TryTransactionSet(txns)
+}
+
+// newSplitSendWallet creates an unlocked wallet that uses tpool and holds
+// 2000 outputs worth 10 SC each.
+func newSplitSendWallet(t *testing.T, wt *walletTester, cs modules.ConsensusSet, tpool modules.TransactionPool) *Wallet {
+	w, err := New(cs, tpool, filepath.Join(wt.persistDir, "wallet2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var masterKey crypto.TwofishKey
+	fastrand.Read(masterKey[:])
+	if _, err := w.Encrypt(masterKey); err != nil {
+		t.Fatal(err)
+	} else if err := w.Unlock(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputValue := types.SiacoinPrecision.Mul64(10)
+	outputs := make([]types.SiacoinOutput, 250)
+	for i := range outputs {
+		outputs[i] = types.SiacoinOutput{Value: outputValue, UnlockHash: uc.UnlockHash()}
+	}
+	for i := 0; i < 8; i++ {
+		if _, err := wt.wallet.SendSiacoinsMulti(outputs); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w
+}
+
+// TestSplitSend checks that a send requiring more than maxSendInputs inputs
+// is split across multiple transactions that together pay the full amount.
+func TestSplitSend(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create a second wallet, and fill it with thousands of small outputs.
+	w := newSplitSendWallet(t, wt, wt.cs, wt.tpool)
+	defer w.Close()
+	outputValue := types.SiacoinPrecision.Mul64(10)
+
+	// Send most of the second wallet's balance. The send should be split
+	// into multiple transactions.
+	amount := outputValue.Mul64(1500)
+	dest := types.UnlockHash{1}
+	txns, err := w.SendSiacoins(amount, dest)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(txns) < 2 {
+		t.Fatal("send was not split into enough transactions:", len(txns))
+	}
+	_, feePerByte := wt.tpool.FeeEstimation()
+	var paid types.Currency
+	for _, txn := range txns {
+		if len(txn.SiacoinInputs) > maxSendInputs {
+			t.Fatal("transaction has too many inputs:", len(txn.SiacoinInputs))
+		}
+		size := len(encoding.Marshal(txn))
+		if txn.MinerFees[0].Cmp(feePerByte.Mul64(uint64(size))) < 0 {
+			t.Fatalf("transaction of %v bytes pays a fee of only %v", size, txn.MinerFees[0].HumanString())
+		}
+		for _, sco := range txn.SiacoinOutputs {
+			if sco.UnlockHash == dest {
+				paid = paid.Add(sco.Value)
+			}
+		}
+	}
+	if !paid.Equals(amount) {
+		t.Fatalf("transactions pay %v, expected %v", paid.HumanString(), amount.HumanString())
+	}
+
+	// All of the transactions should be valid and confirmed in the next
+	// block.
+	b, err := wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed := make(map[types.TransactionID]bool)
+	for _, txn := range b.Transactions {
+		confirmed[txn.ID()] = true
+	}
+	for _, txn := range txns {
+		if !confirmed[txn.ID()] {
+			t.Fatal("transaction was not confirmed:", txn.ID())
+		}
+	}
+}
+
+// TestSplitSendPartialFailure checks that when the transaction pool rejects
+// part of a split send, the accepted transactions are returned and the
+// outputs of the rejected transactions can be spent again.
+func TestSplitSendPartialFailure(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	tpool := &rejectingTPool{TransactionPool: wt.tpool, accept: 1 << 20, dest: types.UnlockHash{1}}
+	w := newSplitSendWallet(t, wt, wt.cs, tpool)
+	defer w.Close()
+
+	// Only accept the first transaction of the split send.
+	outputValue := types.SiacoinPrecision.Mul64(10)
+	amount := outputValue.Mul64(1500)
+	tpool.accept = 1
+	txns, err := w.SendSiacoins(amount, types.UnlockHash{1})
+	if err == nil {
+		t.Fatal("expected split send to fail")
+	} else if len(txns) != 1 {
+		t.Fatal("expected the accepted transaction to be returned, got", len(txns))
+	}
+
+	// The outputs of the rejected transactions should be available again,
+	// so the rest of the amount can be sent.
+	tpool.accept = 1 << 20
+	var sent types.Currency
+	for _, sco := range txns[0].SiacoinOutputs {
+		if sco.UnlockHash == (types.UnlockHash{1}) {
+			sent = sent.Add(sco.Value)
+		}
+	}
+	if _, err := w.SendSiacoins(amount.Sub(sent), types.UnlockHash{1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSplitSendValidation checks that no transaction of a split send is
+// submitted if any of them fails validation.
+func TestSplitSendValidation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	cs := &rejectingCS{ConsensusSet: wt.cs, accept: 1 << 20}
+	w := newSplitSendWallet(t, wt, cs, wt.tpool)
+	defer w.Close()
+
+	// Reject the second transaction of the split send during validation.
+	outputValue := types.SiacoinPrecision.Mul64(10)
+	amount := outputValue.Mul64(1500)
+	cs.accept = 1
+	txns, err := w.SendSiacoins(amount, types.UnlockHash{1})
+	if err == nil {
+		t.Fatal("expected split send to fail")
+	} else if len(txns) != 0 {
+		t.Fatal("expected no transactions to be submitted, got", len(txns))
+	}
+
+	// All of the outputs should be available again.
+	cs.accept = 1 << 20
+	if _, err := w.SendSiacoins(amount, types.UnlockHash{1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestConsolidationInputSize checks that consolidationInputSize matches the
+// encoded size of a signed input spending a standard address.
+func TestConsolidationInputSize(t *testing.T) {
+	_, pk := crypto.GenerateKeyPair()
+	sci := types.SiacoinInput{
+		UnlockConditions: types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+			SignaturesRequired: 1,
+		},
+	}
+	sig := types.TransactionSignature{
+		CoveredFields: types.FullCoveredFields,
+		Signature:     make([]byte, crypto.SignatureSize),
+	}
+	if size := len(encoding.Marshal(sci)) + len(encoding.Marshal(sig)); size != consolidationInputSize {
+		t.Fatalf("signed input is %v bytes, expected %v", size, consolidationInputSize)
+	}
+}
@@ -28,6 +28,10 @@ var (
 
 	// errDustOutput indicates an output is not spendable because it is dust.
 	errDustOutput = errors.New("output is too small")
+
+	// errOutputLocked indicates an output is not spendable because the user
+	// has locked it.
+	errOutputLocked = errors.New("output has been locked by the user")
 )
 
 // transactionBuilder allows transactions to be manually constructed, including
@@ -47,23 +51,23 @@ type transactionBuilder struct {
 	wallet *Wallet
 }
 
-// addSignatures will sign a transaction using a spendable key, with support
-// for multisig spendable keys. Because of the restricted input, the function
-// is compatible with both siacoin inputs and siafund inputs.
-func addSignatures(txn *types.Transaction, cf types.CoveredFields, uc types.UnlockConditions, parentID crypto.Hash, spendKey spendableKey) (newSigIndices []int) {
-	// Try to find the matching secret key for each public key - some public
-	// keys may not have a match. Some secret keys may be used multiple times,
-	// which is why public keys are used as the outer loop.
+// addSignatures will sign a transaction using a set of signers, with support
+// for multisig unlock conditions. Because of the restricted input, the
+// function is compatible with both siacoin inputs and siafund inputs.
+func addSignatures(txn *types.Transaction, cf types.CoveredFields, uc types.UnlockConditions, parentID crypto.Hash, signers []modules.Signer) (newSigIndices []int, err error) {
+	// Try to find the matching signer for each public key - some public keys
+	// may not have a match. Some signers may be used multiple times, which is
+	// why public keys are used as the outer loop.
 	totalSignatures := uint64(0)
 	for i, siaPubKey := range uc.PublicKeys {
-		// Search for the matching secret key to the public key.
-		for j := range spendKey.SecretKeys {
-			pubKey := spendKey.SecretKeys[j].PublicKey()
-			if !bytes.Equal(siaPubKey.Key, pubKey[:]) {
+		// Search for the matching signer to the public key.
+		for _, signer := range signers {
+			pubKey := signer.PublicKey()
+			if pubKey.Algorithm != siaPubKey.Algorithm || !bytes.Equal(siaPubKey.Key, pubKey.Key) {
 				continue
 			}
 
-			// Found the right secret key, add a signature.
+			// Found the right signer, add a signature.
 			sig := types.TransactionSignature{
 				ParentID:       parentID,
 				CoveredFields:  cf,
@@ -73,11 +77,14 @@ func addSignatures(txn *types.Transaction, cf types.CoveredFields, uc types.Unlo
 			txn.TransactionSignatures = append(txn.TransactionSignatures, sig)
 			sigIndex := len(txn.TransactionSignatures) - 1
 			sigHash := txn.SigHash(sigIndex)
-			encodedSig := crypto.SignHash(sigHash, spendKey.SecretKeys[j])
+			encodedSig, err := signer.SignHash(sigHash)
+			if err != nil {
+				return nil, err
+			}
 			txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
 
 			// Count that the signature has been added, and break out of the
-			// secret key loop.
+			// signer loop.
 			totalSignatures++
 			break
 		}
@@ -88,7 +95,7 @@ func addSignatures(txn *types.Transaction, cf types.CoveredFields, uc types.Unlo
 			break
 		}
 	}
-	return newSigIndices
+	return newSigIndices, nil
 }
 
 // checkOutput is a helper function used to determine if an output is usable.
@@ -97,6 +104,10 @@ func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id ty
 	if output.Value.Cmp(dustValue()) < 0 {
 		return errDustOutput
 	}
+	// Check that the output has not been locked by the user.
+	if locked, _ := dbGetLockedOutput(tx, id); locked {
+		return errOutputLocked
+	}
 	// Check that this output has not recently been spent by the wallet.
 	spendHeight, err := dbGetSpentOutput(tx, types.OutputID(id))
 	if err == nil {
@@ -112,33 +123,25 @@ func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id ty
 	return nil
 }
 
-// FundSiacoins will add a siacoin input of exactly 'amount' to the
-// transaction. A parent transaction may be needed to achieve an input with the
-// correct value. The siacoin input will not be signed until 'Sign' is called
-// on the transaction builder.
-func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
-	tb.wallet.mu.Lock()
-	defer tb.wallet.mu.Unlock()
-
-	consensusHeight, err := dbGetConsensusHeight(tb.wallet.dbTx)
-	if err != nil {
-		return err
-	}
-
+// selectSiacoinOutputs picks the largest spendable outputs of the wallet,
+// including unconfirmed outputs, until their value reaches 'amount'. The
+// selected outputs and their total value are returned. The outputs are not
+// marked as spent.
+func (w *Wallet) selectSiacoinOutputs(consensusHeight types.BlockHeight, amount types.Currency) (types.Currency, sortedOutputs, error) {
 	// Collect a value-sorted set of siacoin outputs.
 	var so sortedOutputs
-	err = dbForEachSiacoinOutput(tb.wallet.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+	err := dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
 		so.ids = append(so.ids, scoid)
 		so.outputs = append(so.outputs, sco)
 	})
 	if err != nil {
-		return err
+		return types.Currency{}, sortedOutputs{}, err
 	}
 	// Add all of the unconfirmed outputs as well.
-	for _, upt := range tb.wallet.unconfirmedProcessedTransactions {
+	for _, upt := range w.unconfirmedProcessedTransactions {
 		for i, sco := range upt.Transaction.SiacoinOutputs {
 			// Determine if the output belongs to the wallet.
-			_, exists := tb.wallet.keys[sco.UnlockHash]
+			_, exists := w.keys[sco.UnlockHash]
 			if !exists {
 				continue
 			}
@@ -148,34 +151,25 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	}
 	sort.Sort(sort.Reverse(so))
 
-	// Create and fund a parent transaction that will add the correct amount of
-	// siacoins to the transaction.
 	var fund types.Currency
 	// potentialFund tracks the balance of the wallet including outputs that
 	// have been spent in other unconfirmed transactions recently. This is to
 	// provide the user with a more useful error message in the event that they
 	// are overspending.
 	var potentialFund types.Currency
-	parentTxn := types.Transaction{}
-	var spentScoids []types.SiacoinOutputID
+	var selected sortedOutputs
 	for i := range so.ids {
 		scoid := so.ids[i]
 		sco := so.outputs[i]
 		// Check that the output can be spent.
-		if err := tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco); err != nil {
+		if err := w.checkOutput(w.dbTx, consensusHeight, scoid, sco); err != nil {
 			if err == errSpendHeightTooHigh {
 				potentialFund = potentialFund.Add(sco.Value)
 			}
 			continue
 		}
-
-		// Add a siacoin input for this output.
-		sci := types.SiacoinInput{
-			ParentID:         scoid,
-			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
-		}
-		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
-		spentScoids = append(spentScoids, scoid)
+		selected.ids = append(selected.ids, scoid)
+		selected.outputs = append(selected.outputs, sco)
 
 		// Add the output to the total fund
 		fund = fund.Add(sco.Value)
@@ -185,17 +179,85 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		}
 	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
-		return modules.ErrIncompleteTransactions
+		return types.Currency{}, sortedOutputs{}, modules.ErrIncompleteTransactions
 	}
 	if fund.Cmp(amount) < 0 {
-		return modules.ErrLowBalance
+		return types.Currency{}, sortedOutputs{}, modules.ErrLowBalance
+	}
+	return fund, selected, nil
+}
+
+// FundSiacoins will add a siacoin input of exactly 'amount' to the
+// transaction. A parent transaction may be needed to achieve an input with the
+// correct value. The siacoin input will not be signed until 'Sign' is called
+// on the transaction builder.
+func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
+	tb.wallet.mu.Lock()
+	parentTxn, parentUnlockConditions, sigs, err := tb.wallet.createSiacoinParent(amount)
+	tb.wallet.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Sign all of the inputs to the parent transaction. This is done without
+	// holding the wallet's lock, because an external Signer may block.
+	_, err = signTransaction(&parentTxn, types.FullCoveredFields, sigs)
+	if err != nil {
+		// Make the outputs available again, including the parent's output.
+		tb.wallet.mu.Lock()
+		for _, sci := range parentTxn.SiacoinInputs {
+			dbDeleteSpentOutput(tb.wallet.dbTx, types.OutputID(sci.ParentID))
+		}
+		dbDeleteSpentOutput(tb.wallet.dbTx, types.OutputID(parentTxn.SiacoinOutputID(0)))
+		tb.wallet.mu.Unlock()
+		return err
+	}
+
+	// Add the exact output.
+	newInput := types.SiacoinInput{
+		ParentID:         parentTxn.SiacoinOutputID(0),
+		UnlockConditions: parentUnlockConditions,
+	}
+	tb.newParents = append(tb.newParents, len(tb.parents))
+	tb.parents = append(tb.parents, parentTxn)
+	tb.siacoinInputs = append(tb.siacoinInputs, len(tb.transaction.SiacoinInputs))
+	tb.transaction.SiacoinInputs = append(tb.transaction.SiacoinInputs, newInput)
+	return nil
+}
+
+// createSiacoinParent creates an unsigned transaction that spends the
+// wallet's outputs to create an output of exactly amount, returning the
+// transaction, the unlock conditions of that output, and the signatures that
+// the transaction needs. The spent outputs and the new output are marked as
+// spent. w.mu must be held.
+func (w *Wallet) createSiacoinParent(amount types.Currency) (types.Transaction, types.UnlockConditions, []pendingSignature, error) {
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, types.UnlockConditions{}, nil, err
+	}
+
+	// Select the outputs that will fund the parent transaction.
+	fund, selected, err := w.selectSiacoinOutputs(consensusHeight, amount)
+	if err != nil {
+		return types.Transaction{}, types.UnlockConditions{}, nil, err
+	}
+
+	// Create and fund a parent transaction that will add the correct amount of
+	// siacoins to the transaction.
+	parentTxn := types.Transaction{}
+	for i, scoid := range selected.ids {
+		sci := types.SiacoinInput{
+			ParentID:         scoid,
+			UnlockConditions: w.keys[selected.outputs[i].UnlockHash].UnlockConditions,
+		}
+		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
 	}
 
 	// Create and add the output that will be used to fund the standard
 	// transaction.
-	parentUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	parentUnlockConditions, err := w.nextPrimarySeedAddress(w.dbTx)
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, nil, err
 	}
 
 	exactOutput := types.SiacoinOutput{
@@ -206,46 +268,33 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		refundAddr, err := w.changeAddress(w.dbTx, selected.outputs)
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, nil, err
 		}
 		refundOutput := types.SiacoinOutput{
 			Value:      fund.Sub(amount),
-			UnlockHash: refundUnlockConditions.UnlockHash(),
+			UnlockHash: refundAddr,
 		}
 		parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, refundOutput)
 	}
 
-	// Sign all of the inputs to the parent trancstion.
-	for _, sci := range parentTxn.SiacoinInputs {
-		addSignatures(&parentTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), tb.wallet.keys[sci.UnlockConditions.UnlockHash()])
-	}
-	// Mark the parent output as spent. Must be done after the transaction is
-	// finished because otherwise the txid and output id will change.
-	err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(parentTxn.SiacoinOutputID(0)), consensusHeight)
+	// Mark the parent output as spent. Signatures are not part of the
+	// transaction ID, so the output ID does not change once the parent is
+	// signed.
+	err = dbPutSpentOutput(w.dbTx, types.OutputID(parentTxn.SiacoinOutputID(0)), consensusHeight)
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, nil, err
 	}
 
-	// Add the exact output.
-	newInput := types.SiacoinInput{
-		ParentID:         parentTxn.SiacoinOutputID(0),
-		UnlockConditions: parentUnlockConditions,
-	}
-	tb.newParents = append(tb.newParents, len(tb.parents))
-	tb.parents = append(tb.parents, parentTxn)
-	tb.siacoinInputs = append(tb.siacoinInputs, len(tb.transaction.SiacoinInputs))
-	tb.transaction.SiacoinInputs = append(tb.transaction.SiacoinInputs, newInput)
-
 	// Mark all outputs that were spent as spent.
-	for _, scoid := range spentScoids {
-		err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(scoid), consensusHeight)
+	for _, scoid := range selected.ids {
+		err = dbPutSpentOutput(w.dbTx, types.OutputID(scoid), consensusHeight)
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, nil, err
 		}
 	}
-	return nil
+	return parentTxn, parentUnlockConditions, w.pendingSignatures(parentTxn), nil
 }
 
 // FundSiafunds will add a siafund input of exactly 'amount' to the
@@ -254,31 +303,66 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 // on the transaction builder.
 func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 	tb.wallet.mu.Lock()
-	defer tb.wallet.mu.Unlock()
+	parentTxn, parentUnlockConditions, claimUnlockConditions, sigs, err := tb.wallet.createSiafundParent(amount)
+	tb.wallet.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
-	consensusHeight, err := dbGetConsensusHeight(tb.wallet.dbTx)
+	// Sign all of the inputs to the parent transaction. This is done without
+	// holding the wallet's lock, because an external Signer may block.
+	_, err = signTransaction(&parentTxn, types.FullCoveredFields, sigs)
 	if err != nil {
+		tb.wallet.mu.Lock()
+		for _, sfi := range parentTxn.SiafundInputs {
+			dbDeleteSpentOutput(tb.wallet.dbTx, types.OutputID(sfi.ParentID))
+		}
+		tb.wallet.mu.Unlock()
 		return err
 	}
 
+	// Add the exact output.
+	newInput := types.SiafundInput{
+		ParentID:         parentTxn.SiafundOutputID(0),
+		UnlockConditions: parentUnlockConditions,
+		ClaimUnlockHash:  claimUnlockConditions.UnlockHash(),
+	}
+	tb.newParents = append(tb.newParents, len(tb.parents))
+	tb.parents = append(tb.parents, parentTxn)
+	tb.siafundInputs = append(tb.siafundInputs, len(tb.transaction.SiafundInputs))
+	tb.transaction.SiafundInputs = append(tb.transaction.SiafundInputs, newInput)
+	return nil
+}
+
+// createSiafundParent creates an unsigned transaction that spends the
+// wallet's siafund outputs to create an output of exactly amount, returning
+// the transaction, the unlock conditions of that output and of its claim, and
+// the signatures that the transaction needs. The spent outputs are marked as
+// spent. w.mu must be held.
+func (w *Wallet) createSiafundParent(amount types.Currency) (types.Transaction, types.UnlockConditions, types.UnlockConditions, []pendingSignature, error) {
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
+	}
+
 	// Create and fund a parent transaction that will add the correct amount of
 	// siafunds to the transaction.
 	var fund types.Currency
 	var potentialFund types.Currency
 	parentTxn := types.Transaction{}
 	var spentSfoids []types.SiafundOutputID
-	c := tb.wallet.dbTx.Bucket(bucketSiafundOutputs).Cursor()
+	c := w.dbTx.Bucket(bucketSiafundOutputs).Cursor()
 	for idBytes, sfoBytes := c.First(); idBytes != nil; idBytes, sfoBytes = c.Next() {
 		var sfoid types.SiafundOutputID
 		var sfo types.SiafundOutput
 		if err := encoding.Unmarshal(idBytes, &sfoid); err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 		} else if err := encoding.Unmarshal(sfoBytes, &sfo); err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 		}
 
 		// Check that this output has not recently been spent by the wallet.
-		spendHeight, err := dbGetSpentOutput(tb.wallet.dbTx, types.OutputID(sfoid))
+		spendHeight, err := dbGetSpentOutput(w.dbTx, types.OutputID(sfoid))
 		if err != nil {
 			// mimic map behavior: no entry means zero value
 			spendHeight = 0
@@ -292,15 +376,15 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 			potentialFund = potentialFund.Add(sfo.Value)
 			continue
 		}
-		outputUnlockConditions := tb.wallet.keys[sfo.UnlockHash].UnlockConditions
+		outputUnlockConditions := w.keys[sfo.UnlockHash].UnlockConditions
 		if consensusHeight < outputUnlockConditions.Timelock {
 			continue
 		}
 
 		// Add a siafund input for this output.
-		parentClaimUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		parentClaimUnlockConditions, err := w.nextPrimarySeedAddress(w.dbTx)
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 		}
 		sfi := types.SiafundInput{
 			ParentID:         sfoid,
@@ -318,17 +402,17 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 		}
 	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
-		return modules.ErrIncompleteTransactions
+		return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, modules.ErrIncompleteTransactions
 	}
 	if fund.Cmp(amount) < 0 {
-		return modules.ErrLowBalance
+		return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, modules.ErrLowBalance
 	}
 
 	// Create and add the output that will be used to fund the standard
 	// transaction.
-	parentUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	parentUnlockConditions, err := w.nextPrimarySeedAddress(w.dbTx)
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 	}
 	exactOutput := types.SiafundOutput{
 		Value:      amount,
@@ -338,9 +422,9 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		refundUnlockConditions, err := w.nextPrimarySeedAddress(w.dbTx)
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 		}
 		refundOutput := types.SiafundOutput{
 			Value:      fund.Sub(amount),
@@ -349,34 +433,20 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 		parentTxn.SiafundOutputs = append(parentTxn.SiafundOutputs, refundOutput)
 	}
 
-	// Sign all of the inputs to the parent trancstion.
-	for _, sfi := range parentTxn.SiafundInputs {
-		addSignatures(&parentTxn, types.FullCoveredFields, sfi.UnlockConditions, crypto.Hash(sfi.ParentID), tb.wallet.keys[sfi.UnlockConditions.UnlockHash()])
-	}
-
-	// Add the exact output.
-	claimUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	// Create the unlock conditions for the claim of the exact output.
+	claimUnlockConditions, err := w.nextPrimarySeedAddress(w.dbTx)
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 	}
-	newInput := types.SiafundInput{
-		ParentID:         parentTxn.SiafundOutputID(0),
-		UnlockConditions: parentUnlockConditions,
-		ClaimUnlockHash:  claimUnlockConditions.UnlockHash(),
-	}
-	tb.newParents = append(tb.newParents, len(tb.parents))
-	tb.parents = append(tb.parents, parentTxn)
-	tb.siafundInputs = append(tb.siafundInputs, len(tb.transaction.SiafundInputs))
-	tb.transaction.SiafundInputs = append(tb.transaction.SiafundInputs, newInput)
 
 	// Mark all outputs that were spent as spent.
 	for _, sfoid := range spentSfoids {
-		err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(sfoid), consensusHeight)
+		err = dbPutSpentOutput(w.dbTx, types.OutputID(sfoid), consensusHeight)
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, types.UnlockConditions{}, nil, err
 		}
 	}
-	return nil
+	return parentTxn, parentUnlockConditions, claimUnlockConditions, w.pendingSignatures(parentTxn), nil
 }
 
 // AddParents adds a set of parents to the transaction.
@@ -542,27 +612,35 @@ func (tb *transactionBuilder) Sign(wholeTransaction bool) ([]types.Transaction,
 		coveredFields.TransactionSignatures = append(coveredFields.TransactionSignatures, uint64(i))
 	}
 
-	// For each siacoin input in the transaction that we added, provide a
-	// signature.
+	// For each siacoin and siafund input in the transaction that we added,
+	// look up the signers. The signatures are added after the wallet's lock
+	// is released, because an external Signer may block.
+	var sigs []pendingSignature
 	tb.wallet.mu.RLock()
-	defer tb.wallet.mu.RUnlock()
 	for _, inputIndex := range tb.siacoinInputs {
 		input := tb.transaction.SiacoinInputs[inputIndex]
-		key, ok := tb.wallet.keys[input.UnlockConditions.UnlockHash()]
-		if !ok {
+		uh := input.UnlockConditions.UnlockHash()
+		if _, ok := tb.wallet.keys[uh]; !ok {
+			tb.wallet.mu.RUnlock()
 			return nil, errors.New("transaction builder added an input that it cannot sign")
 		}
-		newSigIndices := addSignatures(&tb.transaction, coveredFields, input.UnlockConditions, crypto.Hash(input.ParentID), key)
-		tb.transactionSignatures = append(tb.transactionSignatures, newSigIndices...)
-		tb.signed = true // Signed is set to true after one successful signature to indicate that future signings can cause issues.
+		sigs = append(sigs, tb.wallet.pendingSignatures(types.Transaction{SiacoinInputs: []types.SiacoinInput{input}})...)
 	}
 	for _, inputIndex := range tb.siafundInputs {
 		input := tb.transaction.SiafundInputs[inputIndex]
-		key, ok := tb.wallet.keys[input.UnlockConditions.UnlockHash()]
-		if !ok {
+		uh := input.UnlockConditions.UnlockHash()
+		if _, ok := tb.wallet.keys[uh]; !ok {
+			tb.wallet.mu.RUnlock()
 			return nil, errors.New("transaction builder added an input that it cannot sign")
 		}
-		newSigIndices := addSignatures(&tb.transaction, coveredFields, input.UnlockConditions, crypto.Hash(input.ParentID), key)
+		sigs = append(sigs, tb.wallet.pendingSignatures(types.Transaction{SiafundInputs: []types.SiafundInput{input}})...)
+	}
+	tb.wallet.mu.RUnlock()
+	for _, ps := range sigs {
+		newSigIndices, err := signTransaction(&tb.transaction, coveredFields, []pendingSignature{ps})
+		if err != nil {
+			return nil, err
+		}
 		tb.transactionSignatures = append(tb.transactionSignatures, newSigIndices...)
 		tb.signed = true // Signed is set to true after one successful signature to indicate that future signings can cause issues.
 	}
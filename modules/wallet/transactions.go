@@ -30,7 +30,7 @@ func (w *Wallet) AddressTransactions(uh types.UnlockHash) (pts []modules.Process
 			relevant = relevant || output.RelatedAddress == uh
 		}
 		if relevant {
-			pts = append(pts, pt)
+			pts = append(pts, w.attachMemos(pt))
 		}
 	}
 
@@ -62,7 +62,7 @@ func (w *Wallet) AddressUnconfirmedTransactions(uh types.UnlockHash) (pts []modu
 			}
 		}
 		if relevant {
-			pts = append(pts, pt)
+			pts = append(pts, w.attachMemos(pt))
 		}
 	}
 	return pts
@@ -80,7 +80,7 @@ func (w *Wallet) Transaction(txid types.TransactionID) (pt modules.ProcessedTran
 	for it.next() {
 		pt := it.value()
 		if pt.TransactionID == txid {
-			return pt, true
+			return w.attachMemos(pt), true
 		}
 	}
 	return modules.ProcessedTransaction{}, false
@@ -111,7 +111,7 @@ func (w *Wallet) Transactions(startHeight, endHeight types.BlockHeight) (pts []m
 			// break as soon as we are above endHeight
 			break
 		} else {
-			pts = append(pts, pt)
+			pts = append(pts, w.attachMemos(pt))
 		}
 	}
 	return
@@ -120,7 +120,11 @@ func (w *Wallet) Transactions(startHeight, endHeight types.BlockHeight) (pts []m
 // UnconfirmedTransactions returns the set of unconfirmed transactions that are
 // relevant to the wallet.
 func (w *Wallet) UnconfirmedTransactions() []modules.ProcessedTransaction {
-	w.mu.RLock()
-	defer w.mu.RUnlock()
-	return w.unconfirmedProcessedTransactions
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pts := make([]modules.ProcessedTransaction, len(w.unconfirmedProcessedTransactions))
+	for i, pt := range w.unconfirmedProcessedTransactions {
+		pts[i] = w.attachMemos(pt)
+	}
+	return pts
 }
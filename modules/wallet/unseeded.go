@@ -70,6 +70,7 @@ func decryptSpendableKeyFile(masterKey crypto.TwofishKey, uk spendableKeyFile) (
 // integrateSpendableKey loads a spendableKey into the wallet.
 func (w *Wallet) integrateSpendableKey(masterKey crypto.TwofishKey, sk spendableKey) {
 	w.keys[sk.UnlockConditions.UnlockHash()] = sk
+	w.unseededKeys[sk.UnlockConditions.UnlockHash()] = struct{}{}
 }
 
 // loadSpendableKey loads a spendable key into the wallet database.
@@ -170,11 +171,16 @@ func (w *Wallet) LoadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) er
 	}
 	defer w.tg.Done()
 
+	key, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
+
 	// load the keys and reset the consensus change ID and height in preparation for rescan
-	err := func() error {
+	err = func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
-		err := w.loadSiagKeys(masterKey, keyfiles)
+		err := w.loadSiagKeys(key, keyfiles)
 		if err != nil {
 			return err
 		}
@@ -185,6 +191,12 @@ func (w *Wallet) LoadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) er
 		if _, err = w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
 			return err
 		}
+		if err = w.dbTx.DeleteBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
+		if _, err = w.dbTx.CreateBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
 		w.unconfirmedProcessedTransactions = nil
 		err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
 		if err != nil {
@@ -220,8 +232,13 @@ func (w *Wallet) Load033xWallet(masterKey crypto.TwofishKey, filepath033x string
 	}
 	defer w.tg.Done()
 
+	key, err := w.managedWalletKey(masterKey)
+	if err != nil {
+		return err
+	}
+
 	// load the keys and reset the consensus change ID and height in preparation for rescan
-	err := func() error {
+	err = func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
 
@@ -236,14 +253,14 @@ func (w *Wallet) Load033xWallet(masterKey crypto.TwofishKey, filepath033x string
 				UnlockConditions: savedKey.UnlockConditions,
 				SecretKeys:       []crypto.SecretKey{savedKey.SecretKey},
 			}
-			err = w.loadSpendableKey(masterKey, spendKey)
+			err = w.loadSpendableKey(key, spendKey)
 			if err != nil && err != errDuplicateSpendableKey {
 				return err
 			}
 			if err == nil {
 				seedsLoaded++
 			}
-			w.integrateSpendableKey(masterKey, spendKey)
+			w.integrateSpendableKey(key, spendKey)
 		}
 		if seedsLoaded == 0 {
 			return errAllDuplicates
@@ -255,6 +272,12 @@ func (w *Wallet) Load033xWallet(masterKey crypto.TwofishKey, filepath033x string
 		if _, err = w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
 			return err
 		}
+		if err = w.dbTx.DeleteBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
+		if _, err = w.dbTx.CreateBucket(bucketAddressReceipts); err != nil {
+			return err
+		}
 		w.unconfirmedProcessedTransactions = nil
 		err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
 		if err != nil {
@@ -93,6 +93,9 @@ func (w *Wallet) revertHistory(tx *bolt.Tx, reverted []types.Block) error {
 			}
 			if txid == pt.TransactionID {
 				w.log.Println("A wallet transaction has been reverted due to a reorg:", txid)
+				if err := w.revertAddressReceipts(tx, pt); err != nil {
+					w.log.Severe("Could not revert address receipts:", err)
+				}
 				if err := dbDeleteLastProcessedTransaction(tx); err != nil {
 					w.log.Severe("Could not revert transaction:", err)
 				}
@@ -103,6 +106,11 @@ func (w *Wallet) revertHistory(tx *bolt.Tx, reverted []types.Block) error {
 		for i, mp := range block.MinerPayouts {
 			if w.isWalletAddress(mp.UnlockHash) {
 				w.log.Println("Miner payout has been reverted due to a reorg:", block.MinerPayoutID(uint64(i)), "::", mp.Value.HumanString())
+				if pt, err := dbGetLastProcessedTransaction(tx); err == nil {
+					if err := w.revertAddressReceipts(tx, pt); err != nil {
+						w.log.Severe("Could not revert address receipts:", err)
+					}
+				}
 				if err := dbDeleteLastProcessedTransaction(tx); err != nil {
 					w.log.Severe("Could not revert transaction:", err)
 				}
@@ -111,7 +119,7 @@ func (w *Wallet) revertHistory(tx *bolt.Tx, reverted []types.Block) error {
 		}
 
 		// decrement the consensus height
-		if block.ID() != types.GenesisID {
+		if !block.IsGenesis() {
 			consensusHeight, err := dbGetConsensusHeight(tx)
 			if err != nil {
 				return err
@@ -150,7 +158,7 @@ func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 			return err
 		}
 		// increment the consensus height
-		if block.ID() != types.GenesisID {
+		if !block.IsGenesis() {
 			consensusHeight++
 			err = dbPutConsensusHeight(tx, consensusHeight)
 			if err != nil {
@@ -186,6 +194,10 @@ func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 			if err != nil {
 				return fmt.Errorf("could not put processed miner transaction: %v", err)
 			}
+			err = w.applyAddressReceipts(tx, minerPT)
+			if err != nil {
+				return fmt.Errorf("could not update address receipts: %v", err)
+			}
 		}
 		for _, txn := range block.Transactions {
 			// determine if transaction is relevant
@@ -312,6 +324,10 @@ func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 			if err != nil {
 				return fmt.Errorf("could not put processed transaction: %v", err)
 			}
+			err = w.applyAddressReceipts(tx, pt)
+			if err != nil {
+				return fmt.Errorf("could not update address receipts: %v", err)
+			}
 		}
 	}
 
@@ -339,6 +355,7 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	if cc.Synced {
 		go w.threadedDefragWallet()
+		go w.threadedOpportunisticConsolidation()
 	}
 }
 
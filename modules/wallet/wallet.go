@@ -71,6 +71,15 @@ type Wallet struct {
 	seeds []modules.Seed
 	keys  map[types.UnlockHash]spendableKey
 
+	// signers holds external Signers added via AddSigner. Their addresses
+	// are also present in keys, with no secret keys.
+	signers map[types.UnlockHash][]modules.Signer
+
+	// unseededKeys is the set of keys in keys that were not derived from a
+	// seed, e.g. siag keys. They are tracked so that they can be included in
+	// a backup.
+	unseededKeys map[types.UnlockHash]struct{}
+
 	// unconfirmedProcessedTransactions tracks unconfirmed transactions.
 	//
 	// TODO: Replace this field with a linked list. Currently when a new
@@ -118,7 +127,9 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir stri
 		cs:    cs,
 		tpool: tpool,
 
-		keys: make(map[types.UnlockHash]spendableKey),
+		keys:         make(map[types.UnlockHash]spendableKey),
+		signers:      make(map[types.UnlockHash][]modules.Signer),
+		unseededKeys: make(map[types.UnlockHash]struct{}),
 
 		unconfirmedSets: make(map[modules.TransactionSetID][]types.TransactionID),
 
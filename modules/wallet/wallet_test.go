@@ -42,6 +42,11 @@ func createWalletTester(name string) (*walletTester, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The consensus set marks itself as synced in a background thread. Wait
+	// for it, as some wallet operations are refused until it is synced.
+	for !cs.Synced() {
+		time.Sleep(time.Millisecond)
+	}
 	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
 	if err != nil {
 		return nil, err
@@ -102,6 +107,11 @@ func createBlankWalletTester(name string) (*walletTester, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The consensus set marks itself as synced in a background thread. Wait
+	// for it, as some wallet operations are refused until it is synced.
+	for !cs.Synced() {
+		time.Sleep(time.Millisecond)
+	}
 	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
 	if err != nil {
 		return nil, err
@@ -358,6 +358,10 @@ func hostconfigcmd(param, value string) {
 	default:
 		die("\"" + param + "\" is not a host setting")
 	}
+	err = post("/host", param+"="+value+"&dryrun=true")
+	if err != nil {
+		die("Invalid host settings:", err)
+	}
 	err = post("/host", param+"="+value)
 	if err != nil {
 		die("Could not update host settings:", err)
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"text/tabwriter"
@@ -432,6 +434,68 @@ func (s bySiaPath) Len() int           { return len(s) }
 func (s bySiaPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 func (s bySiaPath) Less(i, j int) bool { return s[i].SiaPath < s[j].SiaPath }
 
+// bySize implements sort.Interface for []modules.FileInfo based on the
+// Filesize field, breaking ties with the SiaPath field.
+type bySize []modules.FileInfo
+
+func (s bySize) Len() int      { return len(s) }
+func (s bySize) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySize) Less(i, j int) bool {
+	if s[i].Filesize != s[j].Filesize {
+		return s[i].Filesize < s[j].Filesize
+	}
+	return s[i].SiaPath < s[j].SiaPath
+}
+
+// byRedundancy implements sort.Interface for []modules.FileInfo based on the
+// Redundancy field, breaking ties with the SiaPath field. Files with the
+// lowest redundancy come first, as they are the most at risk.
+type byRedundancy []modules.FileInfo
+
+func (s byRedundancy) Len() int      { return len(s) }
+func (s byRedundancy) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byRedundancy) Less(i, j int) bool {
+	if s[i].Redundancy != s[j].Redundancy {
+		return s[i].Redundancy < s[j].Redundancy
+	}
+	return s[i].SiaPath < s[j].SiaPath
+}
+
+// filterFiles returns the files whose siapath matches the provided glob
+// pattern. The pattern syntax is that of path.Match. An empty pattern matches
+// every file.
+func filterFiles(files []modules.FileInfo, pattern string) ([]modules.FileInfo, error) {
+	if pattern == "" {
+		return files, nil
+	}
+	var filtered []modules.FileInfo
+	for _, file := range files {
+		match, err := path.Match(pattern, file.SiaPath)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered, nil
+}
+
+// sortFiles sorts files by name, size, or redundancy.
+func sortFiles(files []modules.FileInfo, by string) error {
+	switch by {
+	case "name", "":
+		sort.Sort(bySiaPath(files))
+	case "size":
+		sort.Sort(bySize(files))
+	case "redundancy":
+		sort.Sort(byRedundancy(files))
+	default:
+		return errors.New("unknown sort order " + by + "; must be name, size, or redundancy")
+	}
+	return nil
+}
+
 // renterfileslistcmd is the handler for the command `siac renter list`.
 // Lists files known to the renter on the network.
 func renterfileslistcmd() {
@@ -444,13 +508,23 @@ func renterfileslistcmd() {
 		fmt.Println("No files have been uploaded.")
 		return
 	}
-	fmt.Println("Tracking", len(rf.Files), "files:")
+	files, err := filterFiles(rf.Files, renterListFilter)
+	if err != nil {
+		die("Invalid filter:", err)
+	}
+	if err := sortFiles(files, renterListSort); err != nil {
+		die(err)
+	}
+	if renterListFilter != "" {
+		fmt.Println("Tracking", len(rf.Files), "files,", len(files), "matching", renterListFilter+":")
+	} else {
+		fmt.Println("Tracking", len(rf.Files), "files:")
+	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	if renterListVerbose {
 		fmt.Fprintln(w, "File size\tAvailable\tProgress\tRedundancy\tRenewing\tSia path")
 	}
-	sort.Sort(bySiaPath(rf.Files))
-	for _, file := range rf.Files {
+	for _, file := range files {
 		fmt.Fprintf(w, "%9s", filesizeUnits(int64(file.Filesize)))
 		if renterListVerbose {
 			availableStr := yesNo(file.Available)
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestFilterFiles checks that filterFiles matches siapaths against glob
+// patterns.
+func TestFilterFiles(t *testing.T) {
+	files := []modules.FileInfo{
+		{SiaPath: "notes.txt"},
+		{SiaPath: "photos/beach.jpg"},
+		{SiaPath: "photos/city.png"},
+		{SiaPath: "photos/2017/snow.jpg"},
+		{SiaPath: "videos/beach.mp4"},
+	}
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"", []string{"notes.txt", "photos/beach.jpg", "photos/city.png", "photos/2017/snow.jpg", "videos/beach.mp4"}},
+		{"*", []string{"notes.txt"}},
+		{"photos/*", []string{"photos/beach.jpg", "photos/city.png"}},
+		{"photos/*.jpg", []string{"photos/beach.jpg"}},
+		{"*/beach.*", []string{"photos/beach.jpg", "videos/beach.mp4"}},
+		{"photos/*/*", []string{"photos/2017/snow.jpg"}},
+		{"photos/[a-c]*", []string{"photos/beach.jpg", "photos/city.png"}},
+		{"music/*", nil},
+	}
+	for _, test := range tests {
+		filtered, err := filterFiles(files, test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filtered) != len(test.expected) {
+			t.Errorf("pattern %q: expected %v files, got %v", test.pattern, len(test.expected), len(filtered))
+			continue
+		}
+		for i := range filtered {
+			if filtered[i].SiaPath != test.expected[i] {
+				t.Errorf("pattern %q: expected %v, got %v", test.pattern, test.expected[i], filtered[i].SiaPath)
+			}
+		}
+	}
+
+	// Malformed patterns should be rejected.
+	if _, err := filterFiles(files, "photos/[a-"); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
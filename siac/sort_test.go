@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -31,3 +32,36 @@ func TestSortByValue(t *testing.T) {
 		}
 	}
 }
+
+// TestSortFiles tests that sortFiles orders files by each supported field.
+func TestSortFiles(t *testing.T) {
+	files := []modules.FileInfo{
+		{SiaPath: "c", Filesize: 10, Redundancy: 1.5},
+		{SiaPath: "a", Filesize: 30, Redundancy: 3},
+		{SiaPath: "d", Filesize: 10, Redundancy: -1},
+		{SiaPath: "b", Filesize: 20, Redundancy: 1.5},
+	}
+	tests := []struct {
+		by       string
+		expected string
+	}{
+		{"name", "abcd"},
+		{"size", "cdba"},
+		{"redundancy", "dbca"},
+	}
+	for _, test := range tests {
+		if err := sortFiles(files, test.by); err != nil {
+			t.Fatal(err)
+		}
+		var order string
+		for _, file := range files {
+			order += file.SiaPath
+		}
+		if order != test.expected {
+			t.Errorf("sorting by %v: expected %v, got %v", test.by, test.expected, order)
+		}
+	}
+	if err := sortFiles(files, "age"); err == nil {
+		t.Error("expected an error for an unknown sort order")
+	}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/bgentry/speakeasy"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -52,6 +60,29 @@ The smallest unit of siacoins is the hasting. One siacoin is 10^24 hastings. Oth
 		Run:   wrap(walletchangepasswordcmd),
 	}
 
+	walletContactsCmd = &cobra.Command{
+		Use:   "contacts",
+		Short: "List the contacts in the address book",
+		Long: `List the named addresses in the wallet's address book. Contact names can be
+used in place of addresses when sending siacoins or siafunds.`,
+		Run: wrap(walletcontactscmd),
+	}
+
+	walletContactsAddCmd = &cobra.Command{
+		Use:     "add [name] [address]",
+		Short:   "Add a contact to the address book",
+		Long:    "Add a named address to the wallet's address book. Names must be unique.",
+		Example: "siac wallet contacts add alice 1f1a...",
+		Run:     wrap(walletcontactsaddcmd),
+	}
+
+	walletContactsRemoveCmd = &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove a contact from the address book",
+		Long:  "Remove a named address from the wallet's address book.",
+		Run:   wrap(walletcontactsremovecmd),
+	}
+
 	walletInitCmd = &cobra.Command{
 		Use:   "init",
 		Short: "Initialize and encrypt a new wallet",
@@ -67,6 +98,22 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletinitseedcmd),
 	}
 
+	walletLargeSendThresholdCmd = &cobra.Command{
+		Use:   "largesendthreshold",
+		Short: "View the large send threshold",
+		Long:  "View the amount above which sends must be confirmed. A threshold of zero disables the check.",
+		Run:   wrap(walletlargesendthresholdcmd),
+	}
+
+	walletLargeSendThresholdSetCmd = &cobra.Command{
+		Use:   "set [amount]",
+		Short: "Set the large send threshold",
+		Long: `Set the amount above which sends must be confirmed. A threshold of zero
+disables the check. Amount is given in units of siacoins, e.g. 100KS.`,
+		Example: "siac wallet largesendthreshold set 100KS",
+		Run:     wrap(walletlargesendthresholdsetcmd),
+	}
+
 	walletLoadCmd = &cobra.Command{
 		Use:   "load",
 		Short: "Load a wallet seed, v0.3.3.x wallet, or siag keyset",
@@ -106,7 +153,7 @@ By default the wallet encryption / unlock password is the same as the generated
 	walletSeedsCmd = &cobra.Command{
 		Use:   "seeds",
 		Short: "View information about your seeds",
-		Long:  "View your primary and auxiliary wallet seeds.",
+		Long:  "View your primary and auxiliary wallet seeds. The wallet password is required.",
 		Run:   wrap(walletseedscmd),
 	}
 
@@ -121,7 +168,8 @@ By default the wallet encryption / unlock password is the same as the generated
 	walletSendSiacoinsCmd = &cobra.Command{
 		Use:   "siacoins [amount] [dest]",
 		Short: "Send siacoins to an address",
-		Long: `Send siacoins to an address. 'dest' must be a 76-byte hexadecimal address.
+		Long: `Send siacoins to an address. 'dest' must be a 76-byte hexadecimal address, or
+the name of a contact in the wallet's address book.
 'amount' can be specified in units, e.g. 1.23KS. Run 'wallet --help' for a list of units.
 If no unit is supplied, hastings will be assumed.
 
@@ -133,6 +181,7 @@ A miner fee of 10 SC is levied on all transactions.`,
 		Use:   "siafunds [amount] [dest]",
 		Short: "Send siafunds",
 		Long: `Send siafunds to an address, and transfer the claim siacoins to your wallet.
+'dest' may also be the name of a contact in the wallet's address book.
 Run 'wallet send --help' to see a list of available units.`,
 		Run: wrap(walletsendsiafundscmd),
 	}
@@ -313,6 +362,90 @@ func walletloadsiagcmd(keyfiles string) {
 	fmt.Println("Wallet loading successful.")
 }
 
+// walletlargesendthresholdcmd prints the wallet's large send threshold.
+func walletlargesendthresholdcmd() {
+	var lst api.WalletLargeSendThresholdGET
+	err := getAPI("/wallet/largesendthreshold", &lst)
+	if err != nil {
+		die("Could not get large send threshold:", err)
+	}
+	if lst.Threshold.IsZero() {
+		fmt.Println("Large send threshold: none")
+		return
+	}
+	fmt.Println("Large send threshold:", currencyUnits(lst.Threshold))
+}
+
+// walletlargesendthresholdsetcmd sets the wallet's large send threshold.
+func walletlargesendthresholdsetcmd(amount string) {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	err = post("/wallet/largesendthreshold", "threshold="+hastings)
+	if err != nil {
+		die("Could not set large send threshold:", err)
+	}
+	fmt.Println("Large send threshold set to", amount)
+}
+
+// walletcontactscmd lists the contacts in the wallet's address book.
+func walletcontactscmd() {
+	var wcg api.WalletContactsGET
+	err := getAPI("/wallet/contacts", &wcg)
+	if err != nil {
+		die("Could not get contacts:", err)
+	}
+	if len(wcg.Contacts) == 0 {
+		fmt.Println("No contacts.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tAddress")
+	for _, c := range wcg.Contacts {
+		fmt.Fprintf(w, "%v\t%v\n", c.Name, c.Address)
+	}
+	w.Flush()
+}
+
+// walletcontactsaddcmd adds a contact to the wallet's address book.
+func walletcontactsaddcmd(name, addr string) {
+	err := post("/wallet/contacts/add", url.Values{"name": {name}, "address": {addr}}.Encode())
+	if err != nil {
+		die("Could not add contact:", err)
+	}
+	fmt.Printf("Added contact %v\n", name)
+}
+
+// walletcontactsremovecmd removes a contact from the wallet's address book.
+func walletcontactsremovecmd(name string) {
+	err := post("/wallet/contacts/remove", url.Values{"name": {name}}.Encode())
+	if err != nil {
+		die("Could not remove contact:", err)
+	}
+	fmt.Printf("Removed contact %v\n", name)
+}
+
+// resolveDestination returns dest if it is a valid address. Otherwise, dest
+// is treated as a contact name and the corresponding address from the wallet's
+// address book is returned.
+func resolveDestination(dest string) (string, error) {
+	var addr types.UnlockHash
+	if addr.LoadString(dest) == nil {
+		return dest, nil
+	}
+	var wcg api.WalletContactsGET
+	if err := getAPI("/wallet/contacts", &wcg); err != nil {
+		return "", err
+	}
+	for _, c := range wcg.Contacts {
+		if c.Name == dest {
+			return c.Address.String(), nil
+		}
+	}
+	return "", fmt.Errorf("%q is neither a valid address nor a known contact", dest)
+}
+
 // walletlockcmd locks the wallet
 func walletlockcmd() {
 	err := post("/wallet/lock", "")
@@ -323,8 +456,12 @@ func walletlockcmd() {
 
 // walletseedcmd returns the current seed {
 func walletseedscmd() {
+	password, err := passwordPrompt("Wallet password: ")
+	if err != nil {
+		die("Reading password failed:", err)
+	}
 	var seedInfo api.WalletSeedsGET
-	err := getAPI("/wallet/seeds", &seedInfo)
+	err = postResp("/wallet/seeds", "encryptionpassword="+url.QueryEscape(password), &seedInfo)
 	if err != nil {
 		die("Error retrieving the current seed:", err)
 	}
@@ -351,7 +488,19 @@ func walletsendsiacoinscmd(amount, dest string) {
 	if err != nil {
 		die("Could not parse amount:", err)
 	}
+	dest, err = resolveDestination(dest)
+	if err != nil {
+		die("Could not resolve destination:", err)
+	}
 	err = post("/wallet/siacoins", fmt.Sprintf("amount=%s&destination=%s", hastings, dest))
+	if err != nil && strings.Contains(err.Error(), modules.ErrLargeSendNeedsConfirmation.Error()) {
+		fmt.Printf("%v exceeds the wallet's large send threshold. Send anyway? [y/N]: ", amount)
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if resp := strings.ToLower(strings.TrimSpace(line)); resp != "y" && resp != "yes" {
+			die("Send cancelled.")
+		}
+		err = post("/wallet/siacoins", fmt.Sprintf("amount=%s&destination=%s&confirmed=true", hastings, dest))
+	}
 	if err != nil {
 		die("Could not send siacoins:", err)
 	}
@@ -360,7 +509,11 @@ func walletsendsiacoinscmd(amount, dest string) {
 
 // walletsendsiafundscmd sends siafunds to a destination address.
 func walletsendsiafundscmd(amount, dest string) {
-	err := post("/wallet/siafunds", fmt.Sprintf("amount=%s&destination=%s", amount, dest))
+	dest, err := resolveDestination(dest)
+	if err != nil {
+		die("Could not resolve destination:", err)
+	}
+	err = post("/wallet/siafunds", fmt.Sprintf("amount=%s&destination=%s", amount, dest))
 	if err != nil {
 		die("Could not send siafunds:", err)
 	}
@@ -479,9 +632,37 @@ func wallettransactionscmd() {
 	}
 }
 
+// passwordPrompt asks the user for a password. If stdin is a terminal, the
+// password is read with echo disabled. Otherwise, a single line is read from
+// stdin, which allows the password to be piped in without appearing in the
+// shell history.
+func passwordPrompt(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return readPasswordLine(os.Stdin)
+	}
+	fmt.Print(prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// readPasswordLine reads a single line from r and returns it without the
+// trailing newline.
+func readPasswordLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
 // walletunlockcmd unlocks a saved wallet
 func walletunlockcmd() {
-	password, err := speakeasy.Ask("Wallet password: ")
+	password, err := passwordPrompt("Wallet password: ")
 	if err != nil {
 		die("Reading password failed:", err)
 	}
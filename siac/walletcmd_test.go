@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/consensus"
+	"github.com/NebulousLabs/Sia/modules/gateway"
+	"github.com/NebulousLabs/Sia/modules/transactionpool"
+	"github.com/NebulousLabs/Sia/modules/wallet"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestReadPasswordLine tests that readPasswordLine reads a single line and
+// strips the line ending.
+func TestReadPasswordLine(t *testing.T) {
+	tests := []struct {
+		in, out string
+		err     error
+	}{
+		{"password\n", "password", nil},
+		{"password\r\n", "password", nil},
+		{"password", "password", nil},
+		{"pass word\nsecond line\n", "pass word", nil},
+		{"\n", "", nil},
+		{"", "", io.EOF},
+	}
+	for _, test := range tests {
+		res, err := readPasswordLine(strings.NewReader(test.in))
+		if res != test.out || err != test.err {
+			t.Errorf("readPasswordLine(%q): expected %q %v, got %q %v", test.in, test.out, test.err, res, err)
+		}
+	}
+}
+
+// TestWalletUnlockPiped tests that walletunlockcmd reads the password from
+// stdin when stdin is not a terminal, and that the wallet is unlocked with it.
+func TestWalletUnlockPiped(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testdir := build.TempDir("siac", t.Name())
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	cs, err := consensus.New(g, false, filepath.Join(testdir, modules.ConsensusDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	tp, err := transactionpool.New(cs, g, filepath.Join(testdir, modules.TransactionPoolDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.Close()
+	w, err := wallet.New(cs, tp, filepath.Join(testdir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Encrypt(crypto.TwofishKey(crypto.HashObject("hunter2"))); err != nil {
+		t.Fatal(err)
+	}
+	if w.Unlocked() {
+		t.Fatal("wallet should be locked after encryption")
+	}
+
+	srv := httptest.NewServer(api.New("Sia-Agent", "", cs, nil, g, nil, nil, nil, tp, w))
+	defer srv.Close()
+	oldAddr := addr
+	addr = strings.TrimPrefix(srv.URL, "http://")
+	defer func() { addr = oldAddr }()
+
+	// Pipe the password into stdin.
+	r, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+	if _, err := pw.Write([]byte("hunter2\n")); err != nil {
+		t.Fatal(err)
+	}
+	pw.Close()
+
+	walletunlockcmd()
+	if !w.Unlocked() {
+		t.Fatal("wallet was not unlocked by the piped password")
+	}
+}
+
+// TestResolveDestination tests that resolveDestination passes addresses
+// through unchanged and resolves contact names using the address book.
+func TestResolveDestination(t *testing.T) {
+	var alice types.UnlockHash
+	alice[0] = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/wallet/contacts" {
+			http.NotFound(w, req)
+			return
+		}
+		json.NewEncoder(w).Encode(api.WalletContactsGET{
+			Contacts: []modules.WalletContact{{Name: "alice", Address: alice}},
+		})
+	}))
+	defer srv.Close()
+	oldAddr := addr
+	addr = strings.TrimPrefix(srv.URL, "http://")
+	defer func() { addr = oldAddr }()
+
+	var bob types.UnlockHash
+	bob[0] = 2
+	if dest, err := resolveDestination(bob.String()); err != nil || dest != bob.String() {
+		t.Fatalf("expected address to be unchanged, got %q %v", dest, err)
+	}
+	if dest, err := resolveDestination("alice"); err != nil || dest != alice.String() {
+		t.Fatalf("expected contact to resolve to %v, got %q %v", alice, dest, err)
+	}
+	if _, err := resolveDestination("carol"); err == nil {
+		t.Fatal("expected unknown contact to be rejected")
+	}
+}
@@ -30,6 +30,14 @@ import (
 // verifyAPISecurity checks that the security values are consistent with a
 // sane, secure system.
 func verifyAPISecurity(config Config) error {
+	// Requests from allowed cross-origin clients skip the user agent check,
+	// so any website allowed by --api-cors-origins can use the API through a
+	// visiting user's browser. The API password is the only protection left,
+	// so it must be set.
+	if config.Siad.CORSOrigins != "" && !config.Siad.AuthenticateAPI {
+		return errors.New("cannot use --api-cors-origins without setting an api password")
+	}
+
 	// Make sure that only the loopback address is allowed unless the
 	// --disable-api-security flag has been used.
 	if !config.Siad.AllowAPIBind {
@@ -151,7 +159,7 @@ func startDaemon(config Config) (err error) {
 	if strings.Contains(config.Siad.Modules, "g") {
 		i++
 		fmt.Printf("(%d/%d) Loading gateway...\n", i, len(config.Siad.Modules))
-		g, err = gateway.New(config.Siad.RPCaddr, !config.Siad.NoBootstrap, filepath.Join(config.Siad.SiaDir, modules.GatewayDir))
+		g, err = gateway.NewCustomGateway(config.Siad.RPCaddr, !config.Siad.NoBootstrap, !config.Siad.NoUPnP, filepath.Join(config.Siad.SiaDir, modules.GatewayDir))
 		if err != nil {
 			return err
 		}
@@ -289,6 +297,9 @@ func startDaemon(config Config) (err error) {
 		tpool,
 		w,
 	)
+	if config.Siad.CORSOrigins != "" {
+		a.SetCORS(strings.Split(config.Siad.CORSOrigins, ","), strings.Split(config.Siad.CORSMethods, ","))
+	}
 
 	// connect the API to the server
 	srv.mux.Handle("/", a)
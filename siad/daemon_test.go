@@ -199,4 +199,19 @@ func TestVerifyAPISecurity(t *testing.T) {
 	if err != nil {
 		t.Error("public + securityOff with authentication was rejected:", err)
 	}
+
+	// Check that cross-origin requests cannot be allowed without an api
+	// password.
+	var corsLoopback Config
+	corsLoopback.Siad.APIaddr = "127.0.0.1:9980"
+	corsLoopback.Siad.CORSOrigins = "*"
+	err = verifyAPISecurity(corsLoopback)
+	if err == nil {
+		t.Error("cors origins were accepted without authentication")
+	}
+	corsLoopback.Siad.AuthenticateAPI = true
+	err = verifyAPISecurity(corsLoopback)
+	if err != nil {
+		t.Error("cors origins with authentication were rejected:", err)
+	}
 }
@@ -39,8 +39,11 @@ type Config struct {
 
 		Modules           string
 		NoBootstrap       bool
+		NoUPnP            bool
 		RequiredUserAgent string
 		AuthenticateAPI   bool
+		CORSOrigins       string
+		CORSMethods       string
 
 		Profile    string
 		ProfileDir string
@@ -163,10 +166,13 @@ func main() {
 	root.Flags().StringVarP(&globalConfig.Siad.APIaddr, "api-addr", "", "localhost:9980", "which host:port the API server listens on")
 	root.Flags().StringVarP(&globalConfig.Siad.SiaDir, "sia-directory", "d", "", "location of the sia directory")
 	root.Flags().BoolVarP(&globalConfig.Siad.NoBootstrap, "no-bootstrap", "", false, "disable bootstrapping on this run")
+	root.Flags().BoolVarP(&globalConfig.Siad.NoUPnP, "no-upnp", "", false, "disable UPnP port forwarding on this run")
 	root.Flags().StringVarP(&globalConfig.Siad.Profile, "profile", "", "", "enable profiling with flags 'cmt' for CPU, memory, trace")
 	root.Flags().StringVarP(&globalConfig.Siad.RPCaddr, "rpc-addr", "", ":9981", "which port the gateway listens on")
 	root.Flags().StringVarP(&globalConfig.Siad.Modules, "modules", "M", "cghrtw", "enabled modules, see 'siad modules' for more info")
 	root.Flags().BoolVarP(&globalConfig.Siad.AuthenticateAPI, "authenticate-api", "", false, "enable API password protection")
+	root.Flags().StringVarP(&globalConfig.Siad.CORSOrigins, "api-cors-origins", "", "", "comma-separated list of origins allowed to make cross-origin API requests (requires --authenticate-api)")
+	root.Flags().StringVarP(&globalConfig.Siad.CORSMethods, "api-cors-methods", "", "GET,POST", "comma-separated list of methods allowed in cross-origin API requests")
 	root.Flags().BoolVarP(&globalConfig.Siad.AllowAPIBind, "disable-api-security", "", false, "allow siad to listen on a non-localhost address (DANGEROUS)")
 
 	// Parse cmdline flags, overwriting both the default values and the config
@@ -3,6 +3,7 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +17,8 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/api"
 	"github.com/NebulousLabs/Sia/build"
@@ -35,6 +38,11 @@ type (
 		httpServer *http.Server
 		mux        *http.ServeMux
 		listener   net.Listener
+
+		// closeChan is closed once the server has finished shutting down.
+		closeChan chan struct{}
+		closeErr  error
+		closeOnce sync.Once
 	}
 
 	// SiaConstants is a struct listing all of the constants in use.
@@ -109,6 +117,10 @@ krBuVo9OBMV0cYBWpjo3UI9j3lHESCYhLnCz7SPap7C1yORc2ydJh+qjKqdLBHom
 t+JydcdJLbIG+kb3jB9QIIu5A4TlSGlHV6ewtxIWLS1473jEkITiVTt0Y5k+VLfW
 bwIDAQAB
 -----END PUBLIC KEY-----`
+
+	// shutdownTimeout is the amount of time that the server will wait for
+	// in-flight requests to complete before forcibly closing them.
+	shutdownTimeout = 2 * time.Minute
 )
 
 // version returns the version number of a non-LTS release. This assumes that
@@ -348,9 +360,13 @@ func (srv *Server) daemonStopHandler(w http.ResponseWriter, _ *http.Request, _ h
 	}
 	f.Flush()
 
-	if err := srv.Close(); err != nil {
-		build.Critical(err)
-	}
+	// Close waits for in-flight requests to complete, including this one, so
+	// it must be called after the handler returns.
+	go func() {
+		if err := srv.Close(); err != nil {
+			build.Critical(err)
+		}
+	}()
 }
 
 func (srv *Server) daemonHandler(password string) http.Handler {
@@ -383,6 +399,7 @@ func NewServer(bindAddr, requiredUserAgent, requiredPassword string) (*Server, e
 		httpServer: &http.Server{
 			Handler: mux,
 		},
+		closeChan: make(chan struct{}),
 	}
 
 	// Register siad routes
@@ -391,22 +408,37 @@ func NewServer(bindAddr, requiredUserAgent, requiredPassword string) (*Server, e
 	return srv, nil
 }
 
+// Serve serves the API until the server is closed. If the server is closed,
+// Serve does not return until in-flight requests have been drained.
 func (srv *Server) Serve() error {
 	// The server will run until an error is encountered or the listener is
 	// closed, via either the Close method or the signal handling above.
 	// Closing the listener will result in the benign error handled below.
 	err := srv.httpServer.Serve(srv.listener)
+	if err == http.ErrServerClosed {
+		<-srv.closeChan
+		return nil
+	}
 	if err != nil && !strings.HasSuffix(err.Error(), "use of closed network connection") {
 		return err
 	}
 	return nil
 }
 
-// Close closes the Server's listener, causing the HTTP server to shut down.
+// Close gracefully shuts down the server. The server stops accepting new
+// requests immediately, then waits up to shutdownTimeout for in-flight
+// requests to complete before forcibly closing their connections. Calling
+// Close more than once has no additional effect.
 func (srv *Server) Close() error {
-	// Close the listener, which will cause Server.Serve() to return.
-	if err := srv.listener.Close(); err != nil {
-		return err
-	}
-	return nil
+	srv.closeOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.closeErr = srv.httpServer.Shutdown(ctx)
+		if srv.closeErr == context.DeadlineExceeded {
+			// Some requests did not finish in time; cut them off.
+			srv.closeErr = srv.httpServer.Close()
+		}
+		close(srv.closeChan)
+	})
+	return srv.closeErr
 }
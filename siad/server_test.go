@@ -1,6 +1,11 @@
 package main
 
-import "testing"
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
 
 // TestLatestRelease tests that the latestRelease function properly processes a
 // set of GitHub releases, returning the release with the highest version
@@ -66,3 +71,77 @@ func TestLatestRelease(t *testing.T) {
 		}
 	}
 }
+
+// TestServerGracefulShutdown checks that closing the server allows in-flight
+// requests to complete while refusing new ones.
+func TestServerGracefulShutdown(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	srv, err := NewServer("localhost:0", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv.mux.HandleFunc("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+	servErr := make(chan error)
+	go func() {
+		servErr <- srv.Serve()
+	}()
+	addr := "http://" + srv.listener.Addr().String()
+
+	// Start a slow request and wait for the handler to begin.
+	type result struct {
+		body string
+		err  error
+	}
+	slowResult := make(chan result)
+	go func() {
+		resp, err := http.Get(addr + "/slow")
+		if err != nil {
+			slowResult <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		slowResult <- result{string(body), err}
+	}()
+	<-started
+
+	// Begin shutting down. Serve should not return while the slow request is
+	// in flight.
+	closeErr := make(chan error)
+	go func() {
+		closeErr <- srv.Close()
+	}()
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-servErr:
+		t.Fatal("Serve returned before in-flight requests completed")
+	default:
+	}
+
+	// New requests should be refused.
+	if _, err := http.Get(addr + "/slow"); err == nil {
+		t.Error("request started after shutdown succeeded")
+	}
+
+	// Let the slow request finish; it should complete successfully.
+	close(release)
+	if r := <-slowResult; r.err != nil {
+		t.Fatal(r.err)
+	} else if r.body != "done" {
+		t.Fatalf("expected in-flight request to complete, got %q", r.body)
+	}
+	if err := <-closeErr; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-servErr; err != nil {
+		t.Fatal(err)
+	}
+}
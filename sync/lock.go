@@ -21,6 +21,23 @@ type RWMutex struct {
 	mu sync.RWMutex
 }
 
+// mutexes is the set of RWMutexes that currently have open locks, used to
+// report the locks held across all of them. A mutex is added when its first
+// lock is opened and removed when its last lock is closed, so that unused
+// mutexes are not kept alive by the set.
+var mutexes struct {
+	set map[*RWMutex]struct{}
+	mu  sync.Mutex
+}
+
+// A HeldLock describes a lock that is currently held on an RWMutex.
+type HeldLock struct {
+	ID       int           `json:"id"`
+	Read     bool          `json:"read"`
+	Duration time.Duration `json:"duration"`
+	Callers  []string      `json:"callers"`
+}
+
 // lockInfo contains information about when and how a lock call was made.
 type lockInfo struct {
 	// When the lock was called.
@@ -45,10 +62,61 @@ func New(maxLockTime time.Duration, callDepth int) *RWMutex {
 	}
 
 	go rwm.threadedDeadlockFinder()
-
 	return rwm
 }
 
+// trackOpenLocks adds the RWMutex to the global set of mutexes if it has open
+// locks, and removes it otherwise. openLocksMutex must be held.
+func (rwm *RWMutex) trackOpenLocks() {
+	mutexes.mu.Lock()
+	defer mutexes.mu.Unlock()
+	if len(rwm.openLocks) == 0 {
+		delete(mutexes.set, rwm)
+		return
+	}
+	if mutexes.set == nil {
+		mutexes.set = make(map[*RWMutex]struct{})
+	}
+	mutexes.set[rwm] = struct{}{}
+}
+
+// HeldLocks returns the locks that are currently held on the RWMutex.
+func (rwm *RWMutex) HeldLocks() []HeldLock {
+	rwm.openLocksMutex.Lock()
+	defer rwm.openLocksMutex.Unlock()
+
+	var locks []HeldLock
+	for id, info := range rwm.openLocks {
+		hl := HeldLock{
+			ID:       id,
+			Read:     info.read,
+			Duration: time.Since(info.lockTime),
+		}
+		for i := range info.callingFiles {
+			hl.Callers = append(hl.Callers, fmt.Sprintf("%v:%v", info.callingFiles[i], info.callingLines[i]))
+		}
+		locks = append(locks, hl)
+	}
+	return locks
+}
+
+// HeldLocks returns the locks that are currently held on every RWMutex created
+// by New.
+func HeldLocks() []HeldLock {
+	mutexes.mu.Lock()
+	set := make([]*RWMutex, 0, len(mutexes.set))
+	for rwm := range mutexes.set {
+		set = append(set, rwm)
+	}
+	mutexes.mu.Unlock()
+
+	var locks []HeldLock
+	for _, rwm := range set {
+		locks = append(locks, rwm.HeldLocks()...)
+	}
+	return locks
+}
+
 // threadedDeadlockFinder occasionally freezes the mutexes and scans all open mutexes,
 // reporting any that have exceeded their time limit.
 func (rwm *RWMutex) threadedDeadlockFinder() {
@@ -73,6 +141,7 @@ func (rwm *RWMutex) threadedDeadlockFinder() {
 				delete(rwm.openLocks, id)
 			}
 		}
+		rwm.trackOpenLocks()
 		rwm.openLocksMutex.Unlock()
 
 		time.Sleep(rwm.maxLockTime)
@@ -104,6 +173,7 @@ func (rwm *RWMutex) safeLock(read bool) int {
 	id := rwm.openLocksCounter
 	rwm.openLocks[id] = li
 	rwm.openLocksCounter++
+	rwm.trackOpenLocks()
 	rwm.openLocksMutex.Unlock()
 
 	return id
@@ -139,6 +209,7 @@ func (rwm *RWMutex) safeUnlock(read bool, id int) {
 		rwm.mu.Unlock()
 	}
 	delete(rwm.openLocks, id)
+	rwm.trackOpenLocks()
 }
 
 // RLock will read lock the RWMutex. The return value must be used as input
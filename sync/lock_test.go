@@ -147,3 +147,54 @@ func TestLockSafety(t *testing.T) {
 		t.Error("test took too long to complete")
 	}
 }
+
+// TestHeldLocks checks that held locks are reported until they are released.
+func TestHeldLocks(t *testing.T) {
+	rwm := New(time.Minute, 1)
+	if locks := rwm.HeldLocks(); len(locks) != 0 {
+		t.Fatal("expected no held locks, got", locks)
+	}
+
+	id := rwm.Lock()
+	rid := -1
+	done := make(chan struct{})
+	go func() {
+		// This call blocks until the write lock is released.
+		rid = rwm.RLock()
+		close(done)
+	}()
+	locks := rwm.HeldLocks()
+	if len(locks) != 1 || locks[0].ID != id || locks[0].Read {
+		t.Fatal("expected the write lock to be held, got", locks)
+	} else if len(locks[0].Callers) != 2 {
+		t.Fatal("expected 2 callers, got", locks[0].Callers)
+	}
+	found := false
+	for _, l := range HeldLocks() {
+		if l.ID == id && l.Callers[0] == locks[0].Callers[0] {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("lock missing from global set of held locks")
+	}
+
+	rwm.Unlock(id)
+	<-done
+	if locks := rwm.HeldLocks(); len(locks) != 1 || locks[0].ID != rid || !locks[0].Read {
+		t.Fatal("expected the read lock to be held, got", locks)
+	}
+	rwm.RUnlock(rid)
+	if locks := rwm.HeldLocks(); len(locks) != 0 {
+		t.Fatal("expected no held locks, got", locks)
+	}
+
+	// The mutex should be dropped from the global set once it has no open
+	// locks.
+	mutexes.mu.Lock()
+	_, tracked := mutexes.set[rwm]
+	mutexes.mu.Unlock()
+	if tracked {
+		t.Fatal("unused mutex was not removed from the global set")
+	}
+}
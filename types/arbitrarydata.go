@@ -0,0 +1,54 @@
+package types
+
+// arbitrarydata.go defines helpers for the ArbitraryData field of a
+// transaction. By convention, each piece of arbitrary data begins with a
+// Specifier that identifies the application that created it, followed by an
+// application-defined payload. Host announcements, for example, begin with
+// the "HostAnnouncement" specifier. Following the convention allows multiple
+// applications to share the ArbitraryData field without misinterpreting each
+// other's data.
+
+import (
+	"errors"
+)
+
+var (
+	// ErrArbitraryDataTooShort is returned when decoding arbitrary data that
+	// is too short to contain a prefix.
+	ErrArbitraryDataTooShort = errors.New("arbitrary data is too short to contain a prefix")
+
+	// ErrArbitraryDataPrefixMismatch is returned when decoding arbitrary data
+	// whose prefix does not match the expected prefix.
+	ErrArbitraryDataPrefixMismatch = errors.New("arbitrary data has an unexpected prefix")
+)
+
+// EncodeArbitraryData returns a piece of arbitrary data consisting of the
+// prefix followed by the data.
+func EncodeArbitraryData(prefix Specifier, data []byte) []byte {
+	arb := make([]byte, SpecifierLen+len(data))
+	copy(arb, prefix[:])
+	copy(arb[SpecifierLen:], data)
+	return arb
+}
+
+// DecodeArbitraryData splits a piece of arbitrary data into its prefix and
+// its payload. The payload aliases arb.
+func DecodeArbitraryData(arb []byte) (prefix Specifier, data []byte, err error) {
+	if len(arb) < SpecifierLen {
+		return Specifier{}, nil, ErrArbitraryDataTooShort
+	}
+	copy(prefix[:], arb)
+	return prefix, arb[SpecifierLen:], nil
+}
+
+// DecodeArbitraryDataWithPrefix returns the payload of a piece of arbitrary
+// data, checking that it begins with the expected prefix.
+func DecodeArbitraryDataWithPrefix(arb []byte, prefix Specifier) ([]byte, error) {
+	p, data, err := DecodeArbitraryData(arb)
+	if err != nil {
+		return nil, err
+	} else if p != prefix {
+		return nil, ErrArbitraryDataPrefixMismatch
+	}
+	return data, nil
+}
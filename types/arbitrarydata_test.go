@@ -0,0 +1,55 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestArbitraryDataRoundTrip checks that encoded arbitrary data decodes to the
+// original prefix and payload.
+func TestArbitraryDataRoundTrip(t *testing.T) {
+	prefix := Specifier{'t', 'e', 's', 't'}
+	for _, data := range [][]byte{nil, {}, {0}, []byte("hello, world"), bytes.Repeat([]byte{0xFF}, 1000)} {
+		arb := EncodeArbitraryData(prefix, data)
+		if len(arb) != SpecifierLen+len(data) {
+			t.Fatalf("encoded data has wrong length: expected %v, got %v", SpecifierLen+len(data), len(arb))
+		}
+		p, payload, err := DecodeArbitraryData(arb)
+		if err != nil {
+			t.Fatal(err)
+		} else if p != prefix {
+			t.Fatalf("wrong prefix: expected %v, got %v", prefix, p)
+		} else if !bytes.Equal(payload, data) {
+			t.Fatalf("wrong payload: expected %v, got %v", data, payload)
+		}
+		payload, err = DecodeArbitraryDataWithPrefix(arb, prefix)
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(payload, data) {
+			t.Fatalf("wrong payload: expected %v, got %v", data, payload)
+		}
+	}
+
+	// Encoding should not alias the input.
+	data := []byte("data")
+	arb := EncodeArbitraryData(prefix, data)
+	data[0] = 'x'
+	if _, payload, _ := DecodeArbitraryData(arb); string(payload) != "data" {
+		t.Error("encoded arbitrary data aliases its input")
+	}
+}
+
+// TestArbitraryDataPrefixMismatch checks that decoding detects mismatched
+// prefixes and data that is too short to contain a prefix.
+func TestArbitraryDataPrefixMismatch(t *testing.T) {
+	arb := EncodeArbitraryData(Specifier{'f', 'o', 'o'}, []byte("payload"))
+	if _, err := DecodeArbitraryDataWithPrefix(arb, Specifier{'b', 'a', 'r'}); err != ErrArbitraryDataPrefixMismatch {
+		t.Errorf("expected %v, got %v", ErrArbitraryDataPrefixMismatch, err)
+	}
+	if _, _, err := DecodeArbitraryData(arb[:SpecifierLen-1]); err != ErrArbitraryDataTooShort {
+		t.Errorf("expected %v, got %v", ErrArbitraryDataTooShort, err)
+	}
+	if _, err := DecodeArbitraryDataWithPrefix(nil, Specifier{}); err != ErrArbitraryDataTooShort {
+		t.Errorf("expected %v, got %v", ErrArbitraryDataTooShort, err)
+	}
+}
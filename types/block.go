@@ -78,6 +78,14 @@ func (h BlockHeader) ID() BlockID {
 	return BlockID(crypto.HashObject(h))
 }
 
+// ValidateProofOfWork returns true if the header's ID meets the given target.
+// Only the proof of work is checked, which allows light clients to validate
+// a chain of headers without downloading the full blocks.
+func (h BlockHeader) ValidateProofOfWork(target Target) bool {
+	id := h.ID()
+	return bytes.Compare(target[:], id[:]) >= 0
+}
+
 // CalculateSubsidy takes a block and a height and determines the block
 // subsidy.
 func (b Block) CalculateSubsidy(height BlockHeight) Currency {
@@ -107,6 +115,13 @@ func (b Block) ID() BlockID {
 	return b.Header().ID()
 }
 
+// IsGenesis reports whether b is a genesis block. The genesis block is the
+// only block without a parent, so this also identifies the genesis blocks of
+// test networks that do not use GenesisBlock.
+func (b Block) IsGenesis() bool {
+	return b.ParentID == BlockID{}
+}
+
 // MerkleRoot calculates the Merkle root of a Block. The leaves of the Merkle
 // tree are composed of the miner outputs (one leaf per payout), and the
 // transactions (one leaf per transaction).
@@ -151,6 +151,45 @@ func TestHeaderID(t *testing.T) {
 	}
 }
 
+// TestHeaderValidateProofOfWork checks that a header meeting the target
+// passes validation, and that tampering with its nonce invalidates it.
+func TestHeaderValidateProofOfWork(t *testing.T) {
+	// Every header meets the easiest possible target.
+	var easiest Target
+	for i := range easiest {
+		easiest[i] = 0xFF
+	}
+	var h BlockHeader
+	if !h.ValidateProofOfWork(easiest) {
+		t.Fatal("header does not meet the easiest target")
+	}
+
+	// Find a nonce that meets a target requiring a leading zero byte.
+	target := easiest
+	target[0] = 0
+	for nonce := uint64(0); !h.ValidateProofOfWork(target); nonce++ {
+		copy(h.Nonce[:], encoding.EncUint64(nonce))
+	}
+	id := h.ID()
+	if id[0] != 0 {
+		t.Fatal("header meeting the target has a nonzero leading byte")
+	}
+
+	// Tamper with the nonce until the ID no longer has a leading zero byte.
+	// The tampered header should fail validation.
+	tampered := h
+	for id := tampered.ID(); id[0] == 0; id = tampered.ID() {
+		tampered.Nonce[7]++
+	}
+	if tampered.ValidateProofOfWork(target) {
+		t.Fatal("header with tampered nonce passed validation")
+	}
+	// The original header should still pass.
+	if !h.ValidateProofOfWork(target) {
+		t.Fatal("header no longer meets the target")
+	}
+}
+
 // TestBlockCalculateSubsidy probes the CalculateSubsidy function of the block
 // type.
 func TestBlockCalculateSubsidy(t *testing.T) {
@@ -306,6 +306,27 @@ func (s *Specifier) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// CanonicalJSON returns a canonical JSON encoding of the transaction, suitable
+// for displaying to a user before signing. Object keys are sorted,
+// currencies are encoded as decimal strings, and hashes as hex strings. The
+// result can be decoded with json.Unmarshal.
+func (t Transaction) CanonicalJSON() ([]byte, error) {
+	js, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	// Decoding into an interface{} turns each object into a map, which
+	// encoding/json always encodes with sorted keys. Numbers are decoded as
+	// json.Number so that large integers do not lose precision.
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
 // MarshalSia implements the encoding.SiaMarshaler interface.
 func (t Transaction) MarshalSia(w io.Writer) error {
 	enc := encoding.NewEncoder(w)
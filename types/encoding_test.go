@@ -212,6 +212,71 @@ func TestTransactionEncoding(t *testing.T) {
 	}
 }
 
+// TestTransactionCanonicalJSON checks that the canonical JSON encoding of a
+// transaction is deterministic, has sorted keys, and decodes to an equivalent
+// transaction.
+func TestTransactionCanonicalJSON(t *testing.T) {
+	txn := Transaction{
+		SiacoinInputs: []SiacoinInput{{
+			ParentID: SiacoinOutputID{1},
+			UnlockConditions: UnlockConditions{
+				Timelock:           1 << 62,
+				PublicKeys:         []SiaPublicKey{{Algorithm: SignatureEd25519, Key: fastrand.Bytes(32)}},
+				SignaturesRequired: 1,
+			},
+		}},
+		SiacoinOutputs: []SiacoinOutput{{
+			Value:      SiacoinPrecision.Mul64(1e9),
+			UnlockHash: UnlockHash{2},
+		}},
+		StorageProofs: []StorageProof{{
+			ParentID: FileContractID{3},
+			HashSet:  []crypto.Hash{{4}, {5}},
+		}},
+		MinerFees:     []Currency{NewCurrency64(7)},
+		ArbitraryData: [][]byte{[]byte("<foo> & bar")},
+		TransactionSignatures: []TransactionSignature{{
+			ParentID:      crypto.Hash{1},
+			CoveredFields: CoveredFields{WholeTransaction: true},
+			Signature:     fastrand.Bytes(64),
+		}},
+	}
+
+	js, err := txn.CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		js2, err := txn.CanonicalJSON()
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(js, js2) {
+			t.Fatal("CanonicalJSON is not deterministic")
+		}
+	}
+
+	// Keys should be sorted; in the standard encoding, siacoininputs comes
+	// first.
+	if strings.Index(string(js), `"arbitrarydata"`) > strings.Index(string(js), `"siacoininputs"`) {
+		t.Fatal("keys are not sorted:", string(js))
+	}
+	// Currencies should be strings, and hashes hex.
+	if !strings.Contains(string(js), `"minerfees":["7"]`) {
+		t.Fatal("currencies are not encoded as strings:", string(js))
+	}
+	if !strings.Contains(string(js), crypto.Hash{4}.String()) {
+		t.Fatal("hashes are not encoded as hex:", string(js))
+	}
+
+	var txn2 Transaction
+	if err := json.Unmarshal(js, &txn2); err != nil {
+		t.Fatal(err)
+	}
+	if txn2.ID() != txn.ID() || !bytes.Equal(encoding.Marshal(txn2), encoding.Marshal(txn)) {
+		t.Fatal("decoded transaction does not match original")
+	}
+}
+
 // TestSiacoinInputEncoding tests that optimizations applied to the encoding
 // of the SiacoinInput type do not change its encoding.
 func TestSiacoinInputEncoding(t *testing.T) {
@@ -561,3 +626,45 @@ func TestCurrencyUnits(t *testing.T) {
 		}
 	}
 }
+
+// TestTransactionEncodingV2 checks that a typical transaction round-trips in
+// the v2 object format, and that its v2 encoding is smaller.
+func TestTransactionEncodingV2(t *testing.T) {
+	_, pk := crypto.GenerateKeyPair()
+	uc := UnlockConditions{
+		PublicKeys:         []SiaPublicKey{Ed25519PublicKey(pk)},
+		SignaturesRequired: 1,
+	}
+	var parentID SiacoinOutputID
+	fastrand.Read(parentID[:])
+	txn := Transaction{
+		SiacoinInputs: []SiacoinInput{{
+			ParentID:         parentID,
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []SiacoinOutput{
+			{Value: SiacoinPrecision.Mul64(100), UnlockHash: uc.UnlockHash()},
+			{Value: SiacoinPrecision.Mul64(25), UnlockHash: UnlockHash{1}},
+		},
+		MinerFees: []Currency{SiacoinPrecision},
+		TransactionSignatures: []TransactionSignature{{
+			ParentID:       crypto.Hash(parentID),
+			PublicKeyIndex: 0,
+			CoveredFields:  CoveredFields{WholeTransaction: true},
+			Signature:      fastrand.Bytes(crypto.SignatureSize),
+		}},
+	}
+
+	v1, v2 := encoding.Marshal(txn), encoding.MarshalV2(txn)
+	if len(v2) >= len(v1) {
+		t.Fatalf("v2 encoding (%v bytes) is not smaller than v1 encoding (%v bytes)", len(v2), len(v1))
+	}
+	var decTxn Transaction
+	err := encoding.UnmarshalV2(v2, &decTxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decTxn.ID() != txn.ID() || !bytes.Equal(encoding.Marshal(decTxn), v1) {
+		t.Fatal("transaction changed after v2 encode/decode")
+	}
+}
@@ -4,6 +4,8 @@ package types
 // contracts.
 
 import (
+	"errors"
+
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 )
@@ -11,6 +13,10 @@ import (
 var (
 	ProofValid  ProofStatus = true
 	ProofMissed ProofStatus = false
+
+	// ErrSegmentIndexOutOfRange is returned by BuildStorageProof if the
+	// segment index does not refer to a segment of the data.
+	ErrSegmentIndexOutOfRange = errors.New("segment index is out of range")
 )
 
 type (
@@ -108,6 +114,36 @@ func (fcid FileContractID) StorageProofOutputID(proofStatus ProofStatus, i uint6
 	))
 }
 
+// Validate checks that the file contract would be accepted by consensus if it
+// were submitted in the block following currentHeight. The payout must be
+// non-zero, the proof window must start in the future and end after it
+// starts, and both the valid and missed proof outputs must sum to the payout
+// less the siafund fee.
+func (fc FileContract) Validate(currentHeight BlockHeight) error {
+	if fc.Payout.IsZero() {
+		return ErrZeroOutput
+	}
+	return Transaction{FileContracts: []FileContract{fc}}.correctFileContracts(currentHeight)
+}
+
+// BuildStorageProof builds a storage proof for the file contract fcid, whose
+// data is 'data', proving the segment at segmentIndex. segmentIndex must be the
+// challenge index chosen by consensus for the contract; a proof for any other
+// segment will be rejected. If the final segment is shorter than
+// crypto.SegmentSize, it is padded with zeros.
+func BuildStorageProof(fcid FileContractID, data []byte, segmentIndex uint64) (StorageProof, error) {
+	if segmentIndex >= crypto.CalculateLeaves(uint64(len(data))) {
+		return StorageProof{}, ErrSegmentIndexOutOfRange
+	}
+	base, hashSet := crypto.MerkleProof(data, segmentIndex)
+	sp := StorageProof{
+		ParentID: fcid,
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], base)
+	return sp, nil
+}
+
 // PostTax returns the amount of currency remaining in a file contract payout
 // after tax.
 func PostTax(height BlockHeight, payout Currency) Currency {
@@ -37,3 +37,53 @@ func TestTax(t *testing.T) {
 		}
 	}
 }
+
+// TestFileContractValidate probes the Validate method of the FileContract
+// type.
+func TestFileContractValidate(t *testing.T) {
+	// validContract returns a file contract that is valid at height 30.
+	payout := NewCurrency64(1e6)
+	validContract := func() FileContract {
+		return FileContract{
+			WindowStart:        35,
+			WindowEnd:          40,
+			Payout:             payout,
+			ValidProofOutputs:  []SiacoinOutput{{Value: PostTax(30, payout)}},
+			MissedProofOutputs: []SiacoinOutput{{Value: PostTax(30, payout)}},
+		}
+	}
+	if err := validContract().Validate(30); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc   string
+		modify func(*FileContract)
+		err    error
+	}{
+		{"zero payout", func(fc *FileContract) { fc.Payout = ZeroCurrency }, ErrZeroOutput},
+		{"window starts now", func(fc *FileContract) { fc.WindowStart = 30 }, ErrFileContractWindowStartViolation},
+		{"window starts in the past", func(fc *FileContract) { fc.WindowStart = 10 }, ErrFileContractWindowStartViolation},
+		{"window ends when it starts", func(fc *FileContract) { fc.WindowEnd = fc.WindowStart }, ErrFileContractWindowEndViolation},
+		{"window ends before it starts", func(fc *FileContract) { fc.WindowEnd = fc.WindowStart - 1 }, ErrFileContractWindowEndViolation},
+		{"valid outputs ignore the fee", func(fc *FileContract) { fc.ValidProofOutputs[0].Value = fc.Payout }, ErrFileContractOutputSumViolation},
+		{"missed outputs ignore the fee", func(fc *FileContract) { fc.MissedProofOutputs[0].Value = fc.Payout }, ErrFileContractOutputSumViolation},
+		{"valid outputs too low", func(fc *FileContract) { fc.ValidProofOutputs[0].Value = NewCurrency64(1) }, ErrFileContractOutputSumViolation},
+		{"missing missed outputs", func(fc *FileContract) { fc.MissedProofOutputs = nil }, ErrFileContractOutputSumViolation},
+	}
+	for _, test := range tests {
+		fc := validContract()
+		test.modify(&fc)
+		if err := fc.Validate(30); err != test.err {
+			t.Errorf("%v: expected %v, got %v", test.desc, test.err, err)
+		}
+	}
+
+	// Splitting the outputs should not affect validity.
+	fc := validContract()
+	half := fc.ValidProofOutputs[0].Value.Div64(2)
+	fc.ValidProofOutputs = []SiacoinOutput{{Value: half}, {Value: fc.ValidProofOutputs[0].Value.Sub(half)}}
+	if err := fc.Validate(30); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+)
+
+var (
+	// ErrUnknownSignatureAlgorithm is returned by VerifySignature if the
+	// algorithm of the public key is not in the signature algorithm table.
+	ErrUnknownSignatureAlgorithm = errors.New("unknown signature algorithm")
+
+	// signatureAlgorithms lists the signature algorithms recognized by
+	// consensus, along with the height at which each one activates. Adding an
+	// algorithm changes the consensus rules, so new entries must be given an
+	// activation height in the future and shipped as a soft fork. The table is
+	// never modified at runtime.
+	signatureAlgorithms = []signatureAlgorithm{
+		{spec: SignatureEd25519, height: 0, verify: verifyEd25519},
+	}
+)
+
+// A signatureAlgorithm is an entry in the signature algorithm table.
+type signatureAlgorithm struct {
+	spec   Specifier
+	height BlockHeight
+	verify SignatureVerifier
+}
+
+// A SignatureVerifier checks that sig is a valid signature of hash by the
+// public key pk, returning an error if it is not.
+type SignatureVerifier func(pk []byte, hash crypto.Hash, sig []byte) error
+
+// verifyEd25519 is the SignatureVerifier for SignatureEd25519 keys.
+func verifyEd25519(pk []byte, hash crypto.Hash, sig []byte) error {
+	var edPK crypto.PublicKey
+	err := encoding.Unmarshal(pk, &edPK)
+	if err != nil {
+		return err
+	}
+	var edSig [crypto.SignatureSize]byte
+	err = encoding.Unmarshal(sig, &edSig)
+	if err != nil {
+		return err
+	}
+	return crypto.VerifyHash(hash, edPK, crypto.Signature(edSig))
+}
+
+// signatureVerifier returns the verifier for spec if the algorithm is active
+// at height.
+func signatureVerifier(spec Specifier, height BlockHeight) (SignatureVerifier, bool) {
+	for _, sa := range signatureAlgorithms {
+		if sa.spec == spec && height >= sa.height {
+			return sa.verify, true
+		}
+	}
+	return nil, false
+}
+
+// VerifySignature checks that sig is a valid signature of hash by pk. Unlike
+// transaction validation, which accepts signatures from algorithms that are
+// not yet active so that new algorithms can be soft-forked in, VerifySignature
+// rejects keys whose algorithm is not in the table, and checks algorithms in
+// the table regardless of their activation height.
+func VerifySignature(pk SiaPublicKey, hash crypto.Hash, sig []byte) error {
+	if pk.Algorithm == SignatureEntropy {
+		return ErrEntropyKey
+	}
+	verify, ok := signatureVerifier(pk.Algorithm, ^BlockHeight(0))
+	if !ok {
+		return ErrUnknownSignatureAlgorithm
+	}
+	return verify(pk.Key, hash, sig)
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+)
+
+// TestSignatureAlgorithms checks that transaction signatures are checked by
+// the verifier for their algorithm once the algorithm has activated.
+func TestSignatureAlgorithms(t *testing.T) {
+	// The mock algorithm accepts a signature if it is the hash of the key and
+	// the signed hash.
+	mockAlgorithm := Specifier{'m', 'o', 'c', 'k'}
+	errMockSig := errors.New("bad mock signature")
+	mockSign := func(pk []byte, hash crypto.Hash) []byte {
+		h := crypto.HashAll(pk, hash)
+		return h[:]
+	}
+	mockVerify := func(pk []byte, hash crypto.Hash, sig []byte) error {
+		if !bytes.Equal(sig, mockSign(pk, hash)) {
+			return errMockSig
+		}
+		return nil
+	}
+
+	pk := SiaPublicKey{Algorithm: mockAlgorithm, Key: []byte("foo")}
+	txn := Transaction{
+		SiacoinInputs: []SiacoinInput{{
+			UnlockConditions: UnlockConditions{
+				PublicKeys:         []SiaPublicKey{pk},
+				SignaturesRequired: 1,
+			},
+		}},
+		TransactionSignatures: []TransactionSignature{{
+			CoveredFields: FullCoveredFields,
+			Signature:     []byte("bad signature"),
+		}},
+	}
+
+	// An algorithm that is not in the table is accepted by consensus, but
+	// rejected by VerifySignature.
+	if err := txn.validSignatures(0); err != nil {
+		t.Fatal("unknown algorithm should be treated as valid by consensus:", err)
+	}
+	if err := VerifySignature(pk, txn.SigHash(0), txn.TransactionSignatures[0].Signature); err != ErrUnknownSignatureAlgorithm {
+		t.Fatal("expected ErrUnknownSignatureAlgorithm, got", err)
+	}
+
+	// Add the algorithm to the table, activating at height 10. The table is
+	// restored before any parallel tests run.
+	defer func(table []signatureAlgorithm) {
+		signatureAlgorithms = table
+	}(signatureAlgorithms)
+	signatureAlgorithms = append(signatureAlgorithms[:len(signatureAlgorithms):len(signatureAlgorithms)], signatureAlgorithm{
+		spec:   mockAlgorithm,
+		height: 10,
+		verify: mockVerify,
+	})
+
+	// Before the activation height, consensus still accepts any signature.
+	if err := txn.validSignatures(9); err != nil {
+		t.Fatal("inactive algorithm should be treated as valid by consensus:", err)
+	}
+
+	// Once active, the bad signature should be rejected and a correct
+	// signature should be accepted.
+	if err := txn.validSignatures(10); err != errMockSig {
+		t.Fatal("expected errMockSig, got", err)
+	}
+	if err := VerifySignature(pk, txn.SigHash(0), txn.TransactionSignatures[0].Signature); err != errMockSig {
+		t.Fatal("expected errMockSig, got", err)
+	}
+	txn.TransactionSignatures[0].Signature = mockSign(pk.Key, txn.SigHash(0))
+	if err := txn.validSignatures(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(pk, txn.SigHash(0), txn.TransactionSignatures[0].Signature); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -354,37 +354,20 @@ func (t *Transaction) validSignatures(currentHeight BlockHeight) error {
 		}
 
 		// Check that the signature verifies. Multiple signature schemes are
-		// supported.
+		// supported; see signatureAlgorithms.
 		publicKey := inSig.possibleKeys[sig.PublicKeyIndex]
-		switch publicKey.Algorithm {
-		case SignatureEntropy:
+		if publicKey.Algorithm == SignatureEntropy {
 			// Entropy cannot ever be used to sign a transaction.
 			return ErrEntropyKey
-
-		case SignatureEd25519:
-			// Decode the public key and signature.
-			var edPK crypto.PublicKey
-			err := encoding.Unmarshal([]byte(publicKey.Key), &edPK)
-			if err != nil {
-				return err
-			}
-			var edSig [crypto.SignatureSize]byte
-			err = encoding.Unmarshal([]byte(sig.Signature), &edSig)
-			if err != nil {
-				return err
-			}
-			cryptoSig := crypto.Signature(edSig)
-
-			sigHash := t.SigHash(i)
-			err = crypto.VerifyHash(sigHash, edPK, cryptoSig)
+		}
+		// If the identifier is not recognized, or its algorithm has not
+		// activated yet, assume that the signature is valid. This allows more
+		// signature types to be added via soft forking.
+		if verify, ok := signatureVerifier(publicKey.Algorithm, currentHeight); ok {
+			err := verify(publicKey.Key, t.SigHash(i), sig.Signature)
 			if err != nil {
 				return err
 			}
-
-		default:
-			// If the identifier is not recognized, assume that the signature
-			// is valid. This allows more signature types to be added via soft
-			// forking.
 		}
 
 		inSig.usedKeys[sig.PublicKeyIndex] = struct{}{}
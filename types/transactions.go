@@ -216,6 +216,52 @@ func (t Transaction) ID() TransactionID {
 	return txid
 }
 
+// A CachedTransaction is an immutable Transaction whose ID is computed once,
+// when the CachedTransaction is created. The wrapped Transaction shares memory
+// with the Transaction it was created from; neither should be modified after
+// wrapping, or the cached ID will be wrong.
+type CachedTransaction struct {
+	txn Transaction
+	id  TransactionID
+}
+
+// NewCachedTransaction wraps a Transaction, computing its ID.
+func NewCachedTransaction(t Transaction) CachedTransaction {
+	return CachedTransaction{
+		txn: t,
+		id:  t.ID(),
+	}
+}
+
+// NewCachedTransactionSet wraps each Transaction in a transaction set.
+func NewCachedTransactionSet(txns []Transaction) []CachedTransaction {
+	cts := make([]CachedTransaction, len(txns))
+	for i := range txns {
+		cts[i] = NewCachedTransaction(txns[i])
+	}
+	return cts
+}
+
+// ID returns the cached id of the transaction.
+func (ct CachedTransaction) ID() TransactionID {
+	return ct.id
+}
+
+// Transaction returns the wrapped Transaction. It must not be modified.
+func (ct CachedTransaction) Transaction() Transaction {
+	return ct.txn
+}
+
+// UnwrapTransactionSet returns the Transactions wrapped by a set of
+// CachedTransactions.
+func UnwrapTransactionSet(cts []CachedTransaction) []Transaction {
+	txns := make([]Transaction, len(cts))
+	for i := range cts {
+		txns[i] = cts[i].txn
+	}
+	return txns
+}
+
 // SiacoinOutputID returns the ID of a siacoin output at the given index,
 // which is calculated by hashing the concatenation of the SiacoinOutput
 // Specifier, all of the fields in the transaction (except the signatures),
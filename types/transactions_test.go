@@ -67,3 +67,65 @@ func TestTransactionSiacoinOutputSum(t *testing.T) {
 		t.Error("wrong siacoin output sum was calculated, got:", txn.SiacoinOutputSum())
 	}
 }
+
+// TestCachedTransaction checks that a CachedTransaction reports the same ID
+// as the transaction it wraps.
+func TestCachedTransaction(t *testing.T) {
+	txns := []Transaction{
+		{},
+		{
+			SiacoinOutputs: []SiacoinOutput{{Value: NewCurrency64(1)}},
+			MinerFees:      []Currency{NewCurrency64(2)},
+			ArbitraryData:  [][]byte{[]byte("foo")},
+		},
+	}
+	cts := NewCachedTransactionSet(txns)
+	if len(cts) != len(txns) {
+		t.Fatal("wrong number of cached transactions")
+	}
+	for i, ct := range cts {
+		if ct.ID() != txns[i].ID() {
+			t.Error("cached ID does not match transaction ID", i)
+		}
+		if ct.Transaction().ID() != txns[i].ID() {
+			t.Error("wrapped transaction does not match original", i)
+		}
+	}
+	unwrapped := UnwrapTransactionSet(cts)
+	for i := range unwrapped {
+		if unwrapped[i].ID() != txns[i].ID() {
+			t.Error("unwrapped transaction does not match original", i)
+		}
+	}
+}
+
+// benchmarkTransaction returns a transaction with a typical number of inputs
+// and outputs.
+func benchmarkTransaction() Transaction {
+	var txn Transaction
+	for i := 0; i < 5; i++ {
+		txn.SiacoinInputs = append(txn.SiacoinInputs, SiacoinInput{})
+		txn.SiacoinOutputs = append(txn.SiacoinOutputs, SiacoinOutput{Value: NewCurrency64(uint64(i))})
+	}
+	txn.MinerFees = []Currency{NewCurrency64(1)}
+	return txn
+}
+
+// BenchmarkTransactionID benchmarks computing the ID of a transaction.
+func BenchmarkTransactionID(b *testing.B) {
+	txn := benchmarkTransaction()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = txn.ID()
+	}
+}
+
+// BenchmarkCachedTransactionID benchmarks looking up the ID of a
+// CachedTransaction.
+func BenchmarkCachedTransactionID(b *testing.B) {
+	ct := NewCachedTransaction(benchmarkTransaction())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ct.ID()
+	}
+}